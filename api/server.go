@@ -0,0 +1,355 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/beacondns"
+	"github.com/activecm/rita-legacy/pkg/beaconproxy"
+	"github.com/activecm/rita-legacy/pkg/beaconsni"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/certificate"
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
+	"github.com/activecm/rita-legacy/pkg/exfil"
+	"github.com/activecm/rita-legacy/pkg/explodeddns"
+	"github.com/activecm/rita-legacy/pkg/hostname"
+	"github.com/activecm/rita-legacy/pkg/lateral"
+	"github.com/activecm/rita-legacy/pkg/scan"
+	"github.com/activecm/rita-legacy/pkg/threat"
+	"github.com/activecm/rita-legacy/pkg/timeline"
+	"github.com/activecm/rita-legacy/pkg/uconn"
+	"github.com/activecm/rita-legacy/pkg/useragent"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultPerPage is the number of results returned per page if the client does not ask for a page size
+	defaultPerPage = 100
+	// maxPerPage is the largest page size a client may request
+	maxPerPage = 1000
+	// longConnThresh is the minimum duration in seconds for a connection to be reported as long
+	longConnThresh = 60
+)
+
+type (
+	// Server exposes the results of analyzed datasets as JSON over HTTP
+	Server struct {
+		res *resources.Resources // shared resources; each request copies the DB handle
+		mux *http.ServeMux       // routes requests to the result handlers
+	}
+
+	// resultsFunc gathers a single page of results from the database selected in res,
+	// skipping the first skip results and returning at most limit of them, along with
+	// the total number of results available
+	resultsFunc func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error)
+
+	// Page is a single page of results from one analysis module
+	Page struct {
+		Database string        `json:"database"`
+		Page     int           `json:"page"`
+		PerPage  int           `json:"per_page"`
+		Total    int           `json:"total"`
+		Results  []interface{} `json:"results"`
+	}
+
+	// errorResponse is returned to the client whenever a request fails
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	// requestError is an error caused by the client's request rather than the server
+	requestError struct {
+		msg string
+	}
+)
+
+func (e requestError) Error() string {
+	return e.msg
+}
+
+// NewServer creates a new Server which serves results from the databases
+// tracked in the MetaDB
+func NewServer(res *resources.Resources) *Server {
+	s := &Server{
+		res: res,
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/v1/databases", s.handleDatabases)
+
+	s.handleResults("hosts", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(threat.ResultsPage(res, skip, limit))
+	})
+	s.handleResults("beacons", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(beacon.ResultsPage(res, 0, skip, limit))
+	})
+	s.handleResults("beacons-sni", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(beaconsni.ResultsPage(res, 0, skip, limit))
+	})
+	s.handleResults("beacons-proxy", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(beaconproxy.ResultsPage(res, 0, skip, limit))
+	})
+	s.handleResults("beacons-dns", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(beacondns.ResultsPage(res, 0, skip, limit))
+	})
+	s.handleResults("strobes", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(beacon.StrobeResultsPage(res, -1, skip, limit))
+	})
+	s.handleResults("scans", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		scanType := r.URL.Query().Get("type")
+		if scanType != "" && scanType != scan.VerticalScan && scanType != scan.HorizontalScan {
+			return nil, 0, requestError{"type must be vertical or horizontal"}
+		}
+		return serializable(scan.ResultsPage(res, scanType, skip, limit))
+	})
+	s.handleResults("exfil", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(exfil.ResultsPage(res, skip, limit))
+	})
+	s.handleResults("lateral", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(lateral.ResultsPage(res, r.URL.Query().Get("all") != "true", skip, limit))
+	})
+	s.handleResults("bl-source-ips", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		sort, err := blSortParam(r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return serializable(blacklist.SrcIPResultsPage(res, sort, skip, limit))
+	})
+	s.handleResults("bl-dest-ips", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		sort, err := blSortParam(r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return serializable(blacklist.DstIPResultsPage(res, sort, skip, limit))
+	})
+	s.handleResults("bl-hostnames", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(blacklist.HostnameResultsPage(res, "conn_count", skip, limit))
+	})
+	s.handleResults("certificates", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(certificate.ResultsPage(res, skip, limit))
+	})
+	s.handleResults("long-connections", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(uconn.LongConnResultsPage(res, longConnThresh, skip, limit))
+	})
+	s.handleResults("open-connections", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(uconn.OpenConnResultsPage(res, longConnThresh, skip, limit))
+	})
+	s.handleResults("exploded-dns", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(explodeddns.ResultsPage(res, skip, limit))
+	})
+	s.handleResults("dns-tunneling", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(dnstunnel.ResultsPage(res, skip, limit))
+	})
+	s.handleResults("useragents", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		return serializable(useragent.ResultsPage(res, -1, skip, limit))
+	})
+	s.handleResults("fqdn-ips", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		fqdn := r.URL.Query().Get("fqdn")
+		if fqdn == "" {
+			return nil, 0, requestError{"the fqdn query parameter is required"}
+		}
+		return serializable(hostname.IPResultsPage(res, fqdn, skip, limit))
+	})
+	s.handleResults("ip-fqdns", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		ip := r.URL.Query().Get("ip")
+		if ip == "" {
+			return nil, 0, requestError{"the ip query parameter is required"}
+		}
+		return serializable(hostname.FQDNResultsPage(res, ip, skip, limit))
+	})
+	s.handleResults("host-timeline", func(res *resources.Resources, r *http.Request, skip, limit int) ([]interface{}, int, error) {
+		ip := net.ParseIP(r.URL.Query().Get("ip"))
+		if ip == nil {
+			return nil, 0, requestError{"the ip query parameter must be an IP address"}
+		}
+		var networkUUID uuid.UUID
+		if param := r.URL.Query().Get("network_uuid"); param != "" {
+			var err error
+			networkUUID, err = uuid.Parse(param)
+			if err != nil {
+				return nil, 0, requestError{"the network_uuid query parameter must be a UUID"}
+			}
+		}
+
+		host, err := timeline.ResolveHost(res, ip, networkUUID)
+		if err == timeline.ErrHostNotFound {
+			return []interface{}{}, 0, nil
+		}
+		var ambiguous timeline.AmbiguousHostError
+		if errors.As(err, &ambiguous) {
+			return nil, 0, requestError{ambiguous.Error()}
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// the timeline is merged from several collections, so it is paged after merging
+		events, err := timeline.Results(res, host)
+		if err != nil {
+			return nil, 0, err
+		}
+		return serializable(pageSlice(events, skip, limit), len(events), nil)
+	})
+
+	return s
+}
+
+// ServeHTTP routes a request to the appropriate handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the API on the given address until an error occurs
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s)
+}
+
+// handleDatabases lists the analyzed databases which may be queried
+func (s *Server) handleDatabases(w http.ResponseWriter, r *http.Request) {
+	dbs := s.res.MetaDB.GetAnalyzedDatabases()
+	if dbs == nil {
+		dbs = []string{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Databases []string `json:"databases"`
+	}{dbs})
+}
+
+// handleResults registers an endpoint which serves paginated results from
+// the given resultsFunc for a single database
+func (s *Server) handleResults(name string, results resultsFunc) {
+	s.mux.HandleFunc("GET /api/v1/databases/{db}/"+name, func(w http.ResponseWriter, r *http.Request) {
+		db := r.PathValue("db")
+
+		page, perPage, err := parsePagination(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if !s.isAnalyzed(db) {
+			writeError(w, http.StatusNotFound, errors.New("no analyzed database named "+db))
+			return
+		}
+
+		// copy the DB handle so concurrent requests may target different databases
+		dbRes := &resources.Resources{
+			Config: s.res.Config,
+			Log:    s.res.Log,
+			DB:     s.res.DB.CopyForDB(db),
+			MetaDB: s.res.MetaDB,
+		}
+
+		data, total, err := results(dbRes, r, (page-1)*perPage, perPage)
+		if err != nil {
+			var reqErr requestError
+			if errors.As(err, &reqErr) {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			s.res.Log.WithFields(log.Fields{
+				"Module":   "api",
+				"Database": db,
+				"Endpoint": name,
+			}).Error(err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, Page{
+			Database: db,
+			Page:     page,
+			PerPage:  perPage,
+			Total:    total,
+			Results:  data,
+		})
+	})
+}
+
+// isAnalyzed returns true if the given database has finished analysis
+func (s *Server) isAnalyzed(db string) bool {
+	for _, analyzed := range s.res.MetaDB.GetAnalyzedDatabases() {
+		if analyzed == db {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePagination reads the page and per_page query parameters
+func parsePagination(r *http.Request) (int, int, error) {
+	page, perPage := 1, defaultPerPage
+	query := r.URL.Query()
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		parsed, err := strconv.Atoi(pageStr)
+		if err != nil || parsed < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+		page = parsed
+	}
+
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		parsed, err := strconv.Atoi(perPageStr)
+		if err != nil || parsed < 1 || parsed > maxPerPage {
+			return 0, 0, errors.New("per_page must be an integer between 1 and " + strconv.Itoa(maxPerPage))
+		}
+		perPage = parsed
+	}
+
+	return page, perPage, nil
+}
+
+// blSortParam reads the sort query parameter used by the blacklisted IP endpoints
+func blSortParam(r *http.Request) (string, error) {
+	sort := r.URL.Query().Get("sort")
+	if sort == "" {
+		return "conn_count", nil
+	}
+	if sort != "conn_count" && sort != "total_bytes" {
+		return "", requestError{"sort must be conn_count or total_bytes"}
+	}
+	return sort, nil
+}
+
+// serializable converts a page of results into the form returned to clients. It accepts
+// the return values of the *ResultsPage functions directly.
+func serializable[T any](results []T, total int, err error) ([]interface{}, int, error) {
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		out = append(out, util.ToSerializable(result))
+	}
+	return out, total, nil
+}
+
+// pageSlice selects a single page from results which were gathered in full
+func pageSlice[T any](results []T, skip, limit int) []T {
+	if skip >= len(results) {
+		return nil
+	}
+	end := skip + limit
+	if end > len(results) {
+		end = len(results)
+	}
+	return results[skip:end]
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/activecm/rita-legacy/pkg/useragent"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePagination(t *testing.T) {
+	testCases := []struct {
+		query   string
+		page    int
+		perPage int
+		err     bool
+	}{
+		{"", 1, defaultPerPage, false},
+		{"?page=3", 3, defaultPerPage, false},
+		{"?page=2&per_page=25", 2, 25, false},
+		{"?per_page=1000", 1, 1000, false},
+		{"?page=0", 0, 0, true},
+		{"?page=abc", 0, 0, true},
+		{"?per_page=0", 0, 0, true},
+		{"?per_page=1001", 0, 0, true},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("GET", "/api/v1/databases/test/beacons"+tc.query, nil)
+		page, perPage, err := parsePagination(req)
+		if tc.err {
+			assert.NotNil(t, err, tc.query)
+			continue
+		}
+		require.Nil(t, err, tc.query)
+		assert.Equal(t, tc.page, page, tc.query)
+		assert.Equal(t, tc.perPage, perPage, tc.query)
+	}
+}
+
+func TestSerializable(t *testing.T) {
+	var agents []useragent.Result
+	for i := 0; i < 2; i++ {
+		agents = append(agents, useragent.Result{UserAgent: string(rune('a' + i)), TimesUsed: int64(i)})
+	}
+
+	results, total, err := serializable(agents, 5, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, results, 2)
+	assert.Equal(t, util.ToSerializable(agents[0]), results[0])
+	assert.Equal(t, util.ToSerializable(agents[1]), results[1])
+
+	// an empty page is still encoded as a JSON array
+	results, _, err = serializable([]useragent.Result(nil), 5, nil)
+	require.Nil(t, err)
+	assert.NotNil(t, results)
+	assert.Empty(t, results)
+
+	_, _, err = serializable(agents, 5, errors.New("query failed"))
+	assert.NotNil(t, err)
+}
+
+func TestPageSlice(t *testing.T) {
+	events := []int{0, 1, 2, 3, 4}
+
+	assert.Equal(t, []int{2, 3}, pageSlice(events, 2, 2))
+	assert.Equal(t, []int{4}, pageSlice(events, 4, 2))
+	assert.Empty(t, pageSlice(events, 6, 2))
+}
+
+func TestBLSortParam(t *testing.T) {
+	sort, err := blSortParam(httptest.NewRequest("GET", "/x", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "conn_count", sort)
+
+	sort, err = blSortParam(httptest.NewRequest("GET", "/x?sort=total_bytes", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "total_bytes", sort)
+
+	_, err = blSortParam(httptest.NewRequest("GET", "/x?sort=bogus", nil))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:  "allowlist",
+		Usage: "Manage the IPs, domains, JA3 hashes, and user agents which suppress findings",
+		Description: "Findings involving allowlisted values are hidden by the show commands and the html report " +
+			"but remain in the database. Pass --suppressed to a show command to include them. " +
+			"IP entries may be addresses or CIDR ranges and domain entries may start with a wildcard, e.g. *.example.com.",
+		Flags: []cli.Flag{
+			ConfigFlag,
+		},
+		Subcommands: []cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Add an entry to the allowlist",
+				ArgsUsage: "<" + strings.Join(database.AllowlistTypes, "|") + "> <value>",
+				Flags: []cli.Flag{
+					ConfigFlag,
+					cli.StringFlag{
+						Name:  "comment, m",
+						Usage: "Note explaining why the entry is trusted",
+					},
+				},
+				Before: SetConfigFilePath,
+				Action: addAllowlistEntry,
+			},
+			{
+				Name:      "remove",
+				Usage:     "Remove an entry from the allowlist",
+				ArgsUsage: "<" + strings.Join(database.AllowlistTypes, "|") + "> <value>",
+				Flags: []cli.Flag{
+					ConfigFlag,
+				},
+				Before: SetConfigFilePath,
+				Action: removeAllowlistEntry,
+			},
+			{
+				Name:  "list",
+				Usage: "Print the entries on the allowlist",
+				Flags: []cli.Flag{
+					ConfigFlag,
+					humanFlag,
+					delimFlag,
+				},
+				Before: SetConfigFilePath,
+				Action: listAllowlist,
+			},
+		},
+	}
+
+	bootstrapCommands(command)
+}
+
+// parseAllowlistArgs reads and validates the entry type and value from the command line
+func parseAllowlistArgs(c *cli.Context) (string, string, error) {
+	if c.NArg() != 2 {
+		return "", "", cli.NewExitError("Specify an entry type ("+strings.Join(database.AllowlistTypes, ", ")+") and value", -1)
+	}
+	entryType := strings.ToLower(c.Args().Get(0))
+	value, err := allowlist.Normalize(entryType, c.Args().Get(1))
+	if err != nil {
+		return "", "", cli.NewExitError(err.Error(), -1)
+	}
+	return entryType, value, nil
+}
+
+func addAllowlistEntry(c *cli.Context) error {
+	entryType, value, err := parseAllowlistArgs(c)
+	if err != nil {
+		return err
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+
+	err = res.MetaDB.AddAllowlistEntry(database.AllowlistEntry{
+		Type:    entryType,
+		Value:   value,
+		Comment: c.String("comment"),
+		Added:   time.Now(),
+	})
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func removeAllowlistEntry(c *cli.Context) error {
+	entryType, value, err := parseAllowlistArgs(c)
+	if err != nil {
+		return err
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+
+	removed, err := res.MetaDB.RemoveAllowlistEntry(entryType, value)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	if !removed {
+		return cli.NewExitError(entryType+" "+value+" is not on the allowlist", -1)
+	}
+	return nil
+}
+
+func listAllowlist(c *cli.Context) error {
+	res := resources.InitResources(getConfigFilePath(c))
+
+	data, err := res.MetaDB.GetAllowlist()
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if len(data) == 0 {
+		return cli.NewExitError("The allowlist is empty", -1)
+	}
+
+	headerFields := []string{"Type", "Value", "Comment", "Added"}
+
+	if c.Bool("human-readable") {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(headerFields)
+		for _, entry := range data {
+			table.Append([]string{entry.Type, entry.Value, entry.Comment, entry.Added.Format(time.RFC3339)})
+		}
+		table.Render()
+		return nil
+	}
+
+	delim := c.String("delimiter")
+	fmt.Println(strings.Join(headerFields, delim))
+	for _, entry := range data {
+		fmt.Println(strings.Join([]string{entry.Type, entry.Value, entry.Comment, entry.Added.Format(time.RFC3339)}, delim))
+	}
+	return nil
+}
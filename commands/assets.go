@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:  "assets",
+		Usage: "Manage the asset inventory used to label internal hosts",
+		Description: "Assets label the hosts in an IP address or CIDR range with a hostname, owner, role, and " +
+			"criticality. The show commands print the labels in separate columns and the html report shows them next to matching IPs. " +
+			"Assets may be limited to a single network by giving its network UUID.",
+		Flags: []cli.Flag{
+			ConfigFlag,
+		},
+		Subcommands: []cli.Command{
+			{
+				Name:      "import",
+				Usage:     "Import assets from a csv or json file",
+				ArgsUsage: "<file>",
+				Description: "csv files must start with a header row naming the columns: ip, network_uuid, hostname, owner, " +
+					"role, and criticality. json files must hold an array of objects with the same fields. Only ip is required; " +
+					"it may be an IP address or a CIDR range. Higher criticality values mark more important assets.",
+				Flags: []cli.Flag{
+					ConfigFlag,
+					cli.StringFlag{
+						Name:  "format, f",
+						Usage: "Read the file as `FORMAT` (csv or json) instead of guessing from the file extension",
+					},
+					cli.BoolFlag{
+						Name:  "replace",
+						Usage: "Remove the existing inventory before importing",
+					},
+				},
+				Before: SetConfigFilePath,
+				Action: importAssets,
+			},
+			{
+				Name:  "list",
+				Usage: "Print the asset inventory",
+				Flags: []cli.Flag{
+					ConfigFlag,
+					humanFlag,
+					delimFlag,
+				},
+				Before: SetConfigFilePath,
+				Action: listAssets,
+			},
+			{
+				Name:  "clear",
+				Usage: "Remove every asset from the inventory",
+				Flags: []cli.Flag{
+					ConfigFlag,
+				},
+				Before: SetConfigFilePath,
+				Action: clearAssets,
+			},
+		},
+	}
+
+	bootstrapCommands(command)
+}
+
+func importAssets(c *cli.Context) error {
+	path := c.Args().Get(0)
+	if path == "" {
+		return cli.NewExitError("Specify a csv or json file to import", -1)
+	}
+
+	format := strings.ToLower(c.String("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if format != "csv" && format != "json" {
+		return cli.NewExitError("Specify the --format of "+path+" (csv or json)", -1)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	defer file.Close()
+
+	var assets []database.Asset
+	if format == "csv" {
+		assets, err = asset.ParseCSV(file)
+	} else {
+		assets, err = asset.ParseJSON(file)
+	}
+	if err != nil {
+		return cli.NewExitError("Could not read "+path+": "+err.Error(), -1)
+	}
+	if len(assets) == 0 {
+		return cli.NewExitError("No assets were found in "+path, -1)
+	}
+
+	imported := time.Now()
+	for idx := range assets {
+		assets[idx].Imported = imported
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+
+	err = res.MetaDB.ImportAssets(assets, c.Bool("replace"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	fmt.Printf("Imported %d assets\n", len(assets))
+	return nil
+}
+
+func listAssets(c *cli.Context) error {
+	res := resources.InitResources(getConfigFilePath(c))
+
+	data, err := res.MetaDB.GetAssets()
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if len(data) == 0 {
+		return cli.NewExitError("The asset inventory is empty", -1)
+	}
+
+	headerFields := []string{"IP", "Network UUID", "Hostname", "Owner", "Role", "Criticality", "Imported"}
+
+	if c.Bool("human-readable") {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(headerFields)
+		for _, entry := range data {
+			table.Append(assetRow(entry))
+		}
+		table.Render()
+		return nil
+	}
+
+	delim := c.String("delimiter")
+	fmt.Println(strings.Join(headerFields, delim))
+	for _, entry := range data {
+		fmt.Println(strings.Join(assetRow(entry), delim))
+	}
+	return nil
+}
+
+func assetRow(entry database.Asset) []string {
+	return []string{
+		entry.Subnet, entry.NetworkUUID, entry.Hostname, entry.Owner, entry.Role,
+		strconv.Itoa(entry.Criticality), entry.Imported.Format(time.RFC3339),
+	}
+}
+
+func clearAssets(c *cli.Context) error {
+	res := resources.InitResources(getConfigFilePath(c))
+
+	removed, err := res.MetaDB.ClearAssets()
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	fmt.Printf("Removed %d assets\n", removed)
+	return nil
+}
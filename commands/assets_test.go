@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/threat"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestSortByCriticality(t *testing.T) {
+	newContext := func(args ...string) *cli.Context {
+		set := flag.NewFlagSet("show", flag.ContinueOnError)
+		set.Int("limit", 1000, "")
+		set.Bool("no-limit", false, "")
+		set.Bool("sort-criticality", false, "")
+		require.NoError(t, set.Parse(args))
+		return cli.NewContext(nil, set, nil)
+	}
+	results := func() []data.UniqueIP {
+		return []data.UniqueIP{
+			{IP: "10.0.0.1"},
+			{IP: "10.0.0.2", Asset: data.AssetLabel{Criticality: 1}},
+			{IP: "10.0.0.3", Asset: data.AssetLabel{Criticality: 5}},
+		}
+	}
+
+	// the limit is applied by the query unless sorting by criticality
+	c := newContext("--limit", "2")
+	require.False(t, queryNoLimit(c))
+	require.Equal(t, results(), sortByCriticality(c, results()))
+
+	// the most critical result must survive the limit
+	c = newContext("--limit", "2", "--sort-criticality")
+	require.True(t, queryNoLimit(c))
+	sorted := sortByCriticality(c, results())
+	require.Len(t, sorted, 2)
+	require.Equal(t, "10.0.0.3", sorted[0].IP)
+	require.Equal(t, "10.0.0.2", sorted[1].IP)
+
+	c = newContext("--limit", "2", "--no-limit", "--sort-criticality")
+	require.Len(t, sortByCriticality(c, results()), 3)
+}
+
+func TestHostsRowAssetColumns(t *testing.T) {
+	result := threat.Result{Score: 0.5}
+	result.IP = "10.0.0.5"
+	result.NetworkName = "sensor"
+	result.Asset = data.AssetLabel{Hostname: "dc01", Owner: "it", Role: "domain controller", Criticality: 5}
+
+	require.Equal(t,
+		[]string{"Score", "Network", "Host", "Evidence", "Hostname", "Owner", "Role", "Criticality"},
+		hostsHeaders(true, true),
+	)
+	require.Equal(t,
+		[]string{"0.5", "sensor", "10.0.0.5", "", "dc01", "it", "domain controller", "5"},
+		hostsRow(result, true, true),
+	)
+	require.Equal(t, []string{"0.5", "10.0.0.5", ""}, hostsRow(result, false, false))
+
+	// hosts outside of the inventory get empty asset columns
+	require.Equal(t, []string{"", "", "", ""}, assetColumns(data.AssetLabel{}))
+	require.Equal(t, []string{"Source Hostname", "Source Owner", "Source Role", "Source Criticality"}, assetHeaders("Source"))
+}
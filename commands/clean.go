@@ -34,13 +34,19 @@ func cleanDatabase(c *cli.Context) error {
 		res.Config.T.Structure.HostTable:            "Host Analysis",
 		res.Config.T.DNS.HostnamesTable:             "Hostnames Analysis",
 		res.Config.T.DNS.ExplodedDNSTable:           "ExplodedDNS Analysis",
+		res.Config.T.DNS.DNSTunnelTable:             "DNS Tunnel Analysis",
 		res.Config.T.Structure.UniqueConnProxyTable: "Uconn Proxy Analysis",
 		res.Config.T.BeaconProxy.BeaconProxyTable:   "Proxy Beacon Analysis",
+		res.Config.T.Structure.UniqueConnDNSTable:   "Uconn DNS Analysis",
+		res.Config.T.BeaconDNS.BeaconDNSTable:       "DNS Beacon Analysis",
 		res.Config.T.Beacon.BeaconTable:             "Beacon Analysis",
 		res.Config.T.Structure.SNIConnTable:         "SNI Beacon Analysis",
 		res.Config.T.BeaconSNI.BeaconSNITable:       "SNI Connection Analysis",
 		res.Config.T.UserAgent.UserAgentTable:       "UserAgent Analysis",
 		res.Config.T.Cert.CertificateTable:          "Certificate Analysis",
+		res.Config.T.Scan.ScanTable:                 "Port Scan Analysis",
+		res.Config.T.Lateral.LateralTable:           "Lateral Movement Analysis",
+		res.Config.T.Exfil.ExfilTable:               "Data Exfiltration Analysis",
 	}
 
 	session := res.DB.Session.Copy()
@@ -3,6 +3,8 @@ package commands
 import (
 	"runtime"
 
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 	"github.com/activecm/rita-legacy/resources"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -116,11 +118,47 @@ var (
 		Value: ",", //default to comma-separated
 	}
 
+	// outputFlag selects a machine-readable serialization of the full results
+	// for use in scripts and other tooling
+	outputFlag = cli.StringFlag{
+		Name:  "output, o",
+		Usage: "Print every result field using the given `FORMAT` (json, ndjson, or csv). csv output uses --delimiter and quotes values as needed",
+	}
+
 	netNamesFlag = cli.BoolFlag{
 		Name:  "network-names, nn",
 		Usage: "Show network names associated with IP addresses. Helps when private IPs are reused across multiple physical networks.",
 	}
 
+	// findings triaged as benign are hidden unless the user asks to see them
+	triageFlag = cli.BoolFlag{
+		Name:  "triage, tr",
+		Usage: "Show findings triaged as benign and add a column with the triage status of each finding",
+	}
+
+	// findings involving allowlisted values are hidden unless the user asks to see them
+	suppressedFlag = cli.BoolFlag{
+		Name:  "suppressed, su",
+		Usage: "Include findings which involve IPs, domains, JA3 hashes, or user agents on the allowlist",
+	}
+
+	// hosts labeled with a high criticality in the asset inventory may be listed first
+	criticalityFlag = cli.BoolFlag{
+		Name:  "sort-criticality, sc",
+		Usage: "Sort results by the highest asset inventory criticality of the hosts involved",
+	}
+
+	// results may be narrowed down to hosts owned by particular autonomous systems or countries
+	asnFlag = cli.StringFlag{
+		Name:  "asn",
+		Usage: "Only show results for hosts in the comma separated list of `ASNs` (requires GeoIP enrichment)",
+	}
+
+	countryFlag = cli.StringFlag{
+		Name:  "country",
+		Usage: "Only show results for hosts in the comma separated list of two letter `COUNTRY` codes (requires GeoIP enrichment)",
+	}
+
 	noBrowserFlag = cli.BoolFlag{
 		Name:  "no-browser, nb",
 		Usage: "Prevent auto-launching of default browser.",
@@ -148,6 +186,44 @@ func getConfigFilePath(c *cli.Context) string {
 	}
 }
 
+// getGeoFilter builds a GeoIP filter from the --asn and --country flags
+func getGeoFilter(c *cli.Context) (geoip.Filter, error) {
+	filter, err := geoip.NewFilter(c.String("asn"), c.String("country"))
+	if err != nil {
+		return filter, cli.NewExitError(err.Error(), -1)
+	}
+	return filter, nil
+}
+
+// queryNoLimit returns true if results should be queried without a limit. Sorting by
+// criticality has to see every result, so sortByCriticality applies --limit instead.
+func queryNoLimit(c *cli.Context) bool {
+	return c.Bool("no-limit") || c.Bool("sort-criticality")
+}
+
+// sortByCriticality sorts results queried with queryNoLimit by the asset criticality
+// of their hosts if --sort-criticality is set and then applies --limit
+func sortByCriticality[T any](c *cli.Context, results []T) []T {
+	if !c.Bool("sort-criticality") {
+		return results
+	}
+	asset.SortByCriticality(results)
+	if !c.Bool("no-limit") && len(results) > c.Int("limit") {
+		results = results[:c.Int("limit")]
+	}
+	return results
+}
+
+// hasAssets returns true if the asset inventory holds any assets, in which case
+// the show commands print the inventory details of each host in extra columns
+func hasAssets(res *resources.Resources) (bool, error) {
+	assets, err := res.MetaDB.GetAssets()
+	if err != nil {
+		return false, err
+	}
+	return len(assets) > 0, nil
+}
+
 // bootstrapCommands simply adds a given command to the allCommands array
 func bootstrapCommands(commands ...cli.Command) {
 	for _, command := range commands {
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/urfave/cli"
+)
+
+// explainHistogramWidth is the width of the longest bar in the connection histogram
+const explainHistogramWidth = 50
+
+func init() {
+	command := cli.Command{
+		Name:      "explain-beacon",
+		Usage:     "Print each intermediate value used to score the beacon between two hosts",
+		ArgsUsage: "<database> <source ip> <destination ip>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+		},
+		Action: explainBeacon,
+	}
+
+	bootstrapCommands(command)
+}
+
+func explainBeacon(c *cli.Context) error {
+	db, src, dst := c.Args().Get(0), c.Args().Get(1), c.Args().Get(2)
+	if db == "" || src == "" || dst == "" {
+		return cli.NewExitError("Specify a database, source ip, and destination ip", -1)
+	}
+	res := resources.InitResources(getConfigFilePath(c))
+	res.DB.SelectDB(db)
+
+	explanation, err := beacon.Explain(res, src, dst)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	err = showBeaconExplanation(os.Stdout, explanation, res.Config.S.Beacon.TsWeight, res.Config.S.Beacon.DsWeight,
+		res.Config.S.Beacon.DurWeight, res.Config.S.Beacon.HistWeight, res.Config.S.Beacon.PeriodogramWeight)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func showBeaconExplanation(w io.Writer, e *beacon.Explanation, tsWeight, dsWeight, durWeight, histWeight, periodogramWeight float64) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Beacon from %s (%s) to %s (%s)\n", e.SrcIP, e.SrcNetworkName, e.DstIP, e.DstNetworkName)
+	fmt.Fprintf(tw, "Connections:\t%d\n", e.Connections)
+	fmt.Fprintf(tw, "Total Bytes:\t%d\n", e.TotalBytes)
+	fmt.Fprintf(tw, "Dataset Range:\t%s - %s\n", explainTime(e.TsMin), explainTime(e.TsMax))
+	if periodogramWeight > 0 {
+		fmt.Fprintf(tw, "Score:\t%s * %s + %s * %s + %s * %s + %s * %s + %s * %s = %s\n",
+			f(tsWeight), f(e.Ts.Score), f(dsWeight), f(e.Ds.Score),
+			f(durWeight), f(e.DurScore), f(histWeight), f(e.Hist.Score),
+			f(periodogramWeight), f(e.Periodogram.Score), f(e.Score))
+		fmt.Fprintln(tw, "\t(timestamp, data size, duration, histogram, and periodogram weights times their scores)")
+	} else {
+		fmt.Fprintf(tw, "Score:\t%s * %s + %s * %s + %s * %s + %s * %s = %s\n",
+			f(tsWeight), f(e.Ts.Score), f(dsWeight), f(e.Ds.Score),
+			f(durWeight), f(e.DurScore), f(histWeight), f(e.Hist.Score), f(e.Score))
+		fmt.Fprintln(tw, "\t(timestamp, data size, duration, and histogram weights times their scores)")
+	}
+
+	fmt.Fprintln(tw, "\nTimestamp Score (delta times between connections in seconds)")
+	fmt.Fprintf(tw, "  Quartiles (Q1 / Q2 / Q3):\t%d / %d / %d\n", e.Ts.Low, e.Ts.Mid, e.Ts.High)
+	fmt.Fprintf(tw, "  Bowley Skew:\t(Q1 + Q3 - 2 * Q2) / (Q3 - Q1) = %d / %d = %s%s\n",
+		e.Ts.BowleyNum, e.Ts.BowleyDen, f(e.Ts.Skew), explainSkewNote(e.Ts.Low, e.Ts.Mid, e.Ts.High))
+	fmt.Fprintf(tw, "  Skew Score:\t1 - |skew| = %s\n", f(e.Ts.SkewScore))
+	fmt.Fprintf(tw, "  MADM:\t%d\n", e.Ts.Madm)
+	fmt.Fprintf(tw, "  MADM Score:\t1 - MADM / Q2 = %s\n", f(e.Ts.MadmScore))
+	fmt.Fprintf(tw, "  Range:\t%d\n", e.Ts.Range)
+	fmt.Fprintf(tw, "  Mode:\t%d (seen %d times)\n", e.Ts.Mode, e.Ts.ModeCount)
+	fmt.Fprintf(tw, "  Score:\t(skew score + MADM score) / 2 = %s\n", f(e.Ts.Score))
+
+	fmt.Fprintln(tw, "\nData Size Score (bytes sent by the source)")
+	fmt.Fprintf(tw, "  Quartiles (Q1 / Q2 / Q3):\t%d / %d / %d\n", e.Ds.Low, e.Ds.Mid, e.Ds.High)
+	fmt.Fprintf(tw, "  Bowley Skew:\t(Q1 + Q3 - 2 * Q2) / (Q3 - Q1) = %d / %d = %s%s\n",
+		e.Ds.BowleyNum, e.Ds.BowleyDen, f(e.Ds.Skew), explainSkewNote(e.Ds.Low, e.Ds.Mid, e.Ds.High))
+	fmt.Fprintf(tw, "  Skew Score:\t1 - |skew| = %s\n", f(e.Ds.SkewScore))
+	fmt.Fprintf(tw, "  MADM:\t%d\n", e.Ds.Madm)
+	fmt.Fprintf(tw, "  MADM Score:\t1 - MADM / Q2 = %s\n", f(e.Ds.MadmScore))
+	fmt.Fprintf(tw, "  Range:\t%d\n", e.Ds.Range)
+	fmt.Fprintf(tw, "  Mode:\t%d (seen %d times)\n", e.Ds.Mode, e.Ds.ModeCount)
+	fmt.Fprintf(tw, "  Smallness Score:\t1 - mode / 65535 = %s\n", f(e.Ds.SmallnessScore))
+	fmt.Fprintf(tw, "  Score:\t(skew score + MADM score + smallness score) / 3 = %s\n", f(e.Ds.Score))
+
+	fmt.Fprintln(tw, "\nHistogram and Duration Scores")
+	fmt.Fprintf(tw, "  Bucket Divs:\t%s\n", explainJoin(e.Hist.BucketDivs))
+	fmt.Fprintf(tw, "  Freq List:\t%s\n", explainJoin(e.Hist.FreqList))
+	fmt.Fprintf(tw, "  Total Bars:\t%d\n", e.Hist.TotalBars)
+	fmt.Fprintf(tw, "  Longest Run:\t%d\n", e.Hist.LongestRun)
+	fmt.Fprintf(tw, "  First / Last Connection:\t%s / %s\n",
+		explainTime(e.TsList[0]), explainTime(e.TsList[len(e.TsList)-1]))
+	fmt.Fprintf(tw, "  Histogram Score:\t%s\n", f(e.Hist.Score))
+	fmt.Fprintf(tw, "  Duration Score:\t%s\n", f(e.DurScore))
+
+	if periodogramWeight > 0 {
+		fmt.Fprintln(tw, "\nPeriodogram Score")
+		fmt.Fprintf(tw, "  Dominant Period:\t%d seconds\n", e.Periodogram.Period)
+		fmt.Fprintf(tw, "  Power:\t%s\n", f(e.Periodogram.Power))
+		fmt.Fprintf(tw, "  Noise Floor:\t%s\n", f(e.Periodogram.NoiseFloor))
+		fmt.Fprintf(tw, "  Score:\t%s\n", f(e.Periodogram.Score))
+	}
+
+	fmt.Fprintln(tw, "\nConnection Histogram")
+	largest := 0
+	for _, freq := range e.Hist.FreqList {
+		if freq > largest {
+			largest = freq
+		}
+	}
+	for idx, freq := range e.Hist.FreqList {
+		bar := 0
+		if largest > 0 {
+			bar = freq * explainHistogramWidth / largest
+		}
+		if freq > 0 && bar == 0 {
+			bar = 1
+		}
+		fmt.Fprintf(tw, "  %s\t|%s %d\n", explainTime(e.Hist.BucketDivs[idx]), strings.Repeat("#", bar), freq)
+	}
+
+	return tw.Flush()
+}
+
+// explainSkewNote notes when Bowley's measure of skew was ignored since it is unreliable
+func explainSkewNote(low, mid, high int64) string {
+	if high-low < 10 || mid == low || mid == high {
+		return " (unreliable when Q3 - Q1 < 10 or Q2 equals Q1 or Q3, treated as 0)"
+	}
+	return ""
+}
+
+func explainTime(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("2006-01-02 15:04:05")
+}
+
+// explainJoin prints the values of a slice separated by spaces
+func explainJoin(values interface{}) string {
+	return strings.Trim(fmt.Sprint(values), "[]")
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/activecm/rita-legacy/pkg/stix"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "export-stix",
+		Usage:     "Export findings as a STIX 2.1 bundle",
+		ArgsUsage: "<database>",
+		Description: "Writes the beacons, SNI and proxy beacons, blacklisted IPs and hostnames, and strobes " +
+			"found in the database as STIX 2.1 indicators, observed data, and sightings. Beacon scores are " +
+			"carried over as the confidence of each sighting. Findings suppressed by the allowlist or triaged " +
+			"as benign are not exported.",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			cli.Float64Flag{
+				Name:  "threshold, t",
+				Usage: "Only export beacons scoring at least `SCORE`",
+				Value: 0.7,
+			},
+			cli.StringFlag{
+				Name:  "file, f",
+				Usage: "Write the bundle to `PATH` instead of standard output",
+			},
+		},
+		Action: exportSTIX,
+	}
+
+	bootstrapCommands(command)
+}
+
+func exportSTIX(c *cli.Context) error {
+	db := c.Args().Get(0)
+	if db == "" {
+		return cli.NewExitError("Specify a database", -1)
+	}
+	threshold := c.Float64("threshold")
+	if threshold < 0 || threshold > 1 {
+		return cli.NewExitError("Threshold must be between 0 and 1", -1)
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+	res.DB.SelectDB(db)
+
+	bundle, err := stix.Export(res, threshold)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	out := os.Stdout
+	if path := c.String("file"); path != "" {
+		out, err = os.Create(path)
+		if err != nil {
+			return cli.NewExitError(err, -1)
+		}
+		defer out.Close()
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(bundle)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+	return nil
+}
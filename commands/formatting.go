@@ -2,6 +2,8 @@ package commands
 
 import (
 	"strconv"
+
+	"github.com/activecm/rita-legacy/pkg/data"
 )
 
 // helper functions for formatting floats and integers
@@ -11,3 +13,21 @@ func f(f float64) string {
 func i(i int64) string {
 	return strconv.FormatInt(i, 10)
 }
+
+// assetHeaders returns the headers of the asset inventory columns for the host
+// described by name. The headers are unprefixed if name is empty.
+func assetHeaders(name string) []string {
+	if name != "" {
+		name += " "
+	}
+	return []string{name + "Hostname", name + "Owner", name + "Role", name + "Criticality"}
+}
+
+// assetColumns formats the asset inventory details of a host as columns of assetHeaders
+func assetColumns(label data.AssetLabel) []string {
+	criticality := ""
+	if label.Criticality != 0 {
+		criticality = strconv.Itoa(label.Criticality)
+	}
+	return []string{label.Hostname, label.Owner, label.Role, criticality}
+}
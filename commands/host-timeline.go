@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/timeline"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "host-timeline",
+		Usage:     "Print a chronological timeline of everything a host did",
+		ArgsUsage: "<database> <ip>",
+		Description: "Merges the connections, strobes, beacons, DNS queries, user agents, certificates, and " +
+			"blacklist hits involving the host into a single timeline ordered by chunk and time. " +
+			"Artifacts which don't record when they occurred are listed at the end of their chunk. " +
+			"If the IP address was seen on several networks, select the host with --network-uuid.",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			delimFlag,
+			outputFlag,
+			netNamesFlag,
+			cli.StringFlag{
+				Name:  "network-uuid",
+				Usage: "Network `UUID` of the host if its IP address was seen on several networks",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			ip := c.Args().Get(1)
+			if db == "" || ip == "" {
+				return cli.NewExitError("Specify a database and an IP address", -1)
+			}
+			parsedIP := net.ParseIP(ip)
+			if parsedIP == nil {
+				return cli.NewExitError("Invalid IP address: "+ip, -1)
+			}
+			var networkUUID uuid.UUID
+			if c.String("network-uuid") != "" {
+				var err error
+				networkUUID, err = uuid.Parse(c.String("network-uuid"))
+				if err != nil {
+					return cli.NewExitError("Invalid network UUID: "+c.String("network-uuid"), -1)
+				}
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			host, err := timeline.ResolveHost(res, parsedIP, networkUUID)
+			if err == timeline.ErrHostNotFound {
+				return cli.NewExitError("No results were found for "+ip+" in "+db, -1)
+			}
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data, err := timeline.Results(res, host)
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+ip+" in "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			if c.Bool("human-readable") {
+				err := showTimelineHuman(data, c.Bool("network-names"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showTimeline(data, c.String("delimiter"), c.Bool("network-names"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func timelineHeaders(showNetNames bool) []string {
+	if showNetNames {
+		return []string{"Chunk", "First Seen", "Last Seen", "Type", "Network", "Peer", "Detail"}
+	}
+	return []string{"Chunk", "First Seen", "Last Seen", "Type", "Peer", "Detail"}
+}
+
+func timelineRow(event timeline.Event, firstSeen, lastSeen string, showNetNames bool) []string {
+	if showNetNames {
+		return []string{strconv.Itoa(event.CID), firstSeen, lastSeen, event.Type, event.NetworkName, event.Peer, event.Detail}
+	}
+	return []string{strconv.Itoa(event.CID), firstSeen, lastSeen, event.Type, event.Peer, event.Detail}
+}
+
+// timelineTime formats a timestamp for the human readable timeline, leaving it
+// blank if the event doesn't record when it occurred
+func timelineTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
+
+func showTimeline(events []timeline.Event, delim string, showNetNames bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(timelineHeaders(showNetNames), delim))
+	for _, event := range events {
+		fmt.Println(strings.Join(timelineRow(event, i(event.FirstSeen), i(event.LastSeen), showNetNames), delim))
+	}
+	return nil
+}
+
+func showTimelineHuman(events []timeline.Event, showNetNames bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(timelineHeaders(showNetNames))
+	table.SetColWidth(100)
+	for _, event := range events {
+		table.Append(timelineRow(event, timelineTime(event.FirstSeen), timelineTime(event.LastSeen), showNetNames))
+	}
+	table.Render()
+	return nil
+}
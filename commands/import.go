@@ -1,12 +1,20 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/parser"
+	"github.com/activecm/rita-legacy/parser/files"
 	"github.com/activecm/rita-legacy/pkg/remover"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/activecm/rita-legacy/util"
@@ -18,9 +26,14 @@ func init() {
 	importCommand := cli.Command{
 		Name:  "import",
 		Usage: "Import zeek logs into a target database",
-		UsageText: "rita import [command options] <import directory|file> [<import directory|file>...] <database name>\n\n" +
+		UsageText: "rita import [command options] <import directory|file> [<import directory|file>...] <database name>\n" +
+			"   rita import --stream [command options] <database name>\n\n" +
 			"Logs directly in <import directory> will be imported into a database" +
-			" named <database name>.",
+			" named <database name>. With --recursive, logs in subdirectories such as" +
+			" Zeek's logs/YYYY-MM-DD/ archive directories are imported as well, and" +
+			" --chunk-per-day imports each day's logs into its own chunk. With --stream, Zeek JSON streaming logs are read" +
+			" from stdin or a Unix socket and flushed into a new chunk of the rolling" +
+			" database named <database name> every --interval.",
 		Flags: []cli.Flag{
 			ConfigFlag,
 			threadFlag,
@@ -28,6 +41,43 @@ func init() {
 			rollingFlag,
 			totalChunksFlag,
 			currentChunkFlag,
+			cli.BoolFlag{
+				Name:  "stream, s",
+				Usage: "Implies --rolling: Read JSON streaming logs from stdin or --socket instead of files",
+			},
+			cli.StringFlag{
+				Name:  "socket",
+				Usage: "Read JSON streaming logs from connections to the Unix socket at `PATH` when streaming",
+			},
+			cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Flush streamed logs into the next chunk each time the clock crosses a multiple of `INTERVAL`",
+				Value: time.Hour,
+			},
+			cli.BoolFlag{
+				Name:  "recursive, r",
+				Usage: "Import logs found in subdirectories of the import directories",
+			},
+			cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "Only import logs whose name or relative path matches the glob `PATTERN` (repeatable)",
+			},
+			cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Skip logs and directories whose name or relative path matches the glob `PATTERN` (repeatable)",
+			},
+			cli.StringFlag{
+				Name:  "from",
+				Usage: "Only import logs written on or after `YYYY-MM-DD`",
+			},
+			cli.StringFlag{
+				Name:  "to",
+				Usage: "Only import logs written on or before `YYYY-MM-DD`",
+			},
+			cli.BoolFlag{
+				Name:  "chunk-per-day",
+				Usage: "Implies --rolling: Import the logs written on each day into a separate chunk, oldest first",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			importer := NewImporter(c)
@@ -53,6 +103,13 @@ type (
 		userTotalChunks int
 		userCurrChunk   int
 		threads         int
+		stream          bool
+		socketPath      string
+		flushInterval   time.Duration
+		gatherOpts      files.GatherOptions
+		fromDay         string
+		toDay           string
+		chunkPerDay     bool
 	}
 )
 
@@ -62,15 +119,30 @@ func NewImporter(c *cli.Context) *Importer {
 		configFile:      getConfigFilePath(c),
 		args:            c.Args(),
 		deleteOldData:   c.Bool("delete"),
-		userRolling:     c.Bool("rolling"),
+		userRolling:     c.Bool("rolling") || c.Bool("stream") || c.Bool("chunk-per-day"),
 		userTotalChunks: c.Int("numchunks"),
 		userCurrChunk:   c.Int("chunk"),
 		threads:         util.Max(c.Int("threads")/2, 1),
+		stream:          c.Bool("stream"),
+		socketPath:      c.String("socket"),
+		flushInterval:   c.Duration("interval"),
+		gatherOpts: files.GatherOptions{
+			Recursive: c.Bool("recursive"),
+			Include:   c.StringSlice("include"),
+			Exclude:   c.StringSlice("exclude"),
+		},
+		fromDay:     c.String("from"),
+		toDay:       c.String("to"),
+		chunkPerDay: c.Bool("chunk-per-day"),
 	}
 }
 
 // parseArgs handles parsing the positional import arguments
 func (i *Importer) parseArgs() error {
+	if i.stream {
+		return i.parseStreamArgs()
+	}
+
 	if len(i.args) < 2 {
 		return cli.NewExitError("\n\t[!] Both <files/directory to import> and <database name> are required.", -1)
 	}
@@ -94,6 +166,70 @@ func (i *Importer) parseArgs() error {
 		return cli.NewExitError(err.Error(), -1)
 	}
 
+	return i.parseGatherArgs()
+}
+
+// parseGatherArgs validates the options which select the logs to import
+func (i *Importer) parseGatherArgs() error {
+	for _, pattern := range append(i.gatherOpts.Include, i.gatherOpts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return cli.NewExitError(fmt.Errorf("\n\t[!] Invalid glob pattern %s: %v", pattern, err), -1)
+		}
+	}
+
+	var err error
+	if i.fromDay != "" {
+		i.gatherOpts.From, err = time.Parse(files.DayFormat, i.fromDay)
+		if err != nil {
+			return cli.NewExitError("\n\t[!] --from must be a date formatted as YYYY-MM-DD.", -1)
+		}
+	}
+	if i.toDay != "" {
+		i.gatherOpts.To, err = time.Parse(files.DayFormat, i.toDay)
+		if err != nil {
+			return cli.NewExitError("\n\t[!] --to must be a date formatted as YYYY-MM-DD.", -1)
+		}
+	}
+	if !i.gatherOpts.From.IsZero() && !i.gatherOpts.To.IsZero() && i.gatherOpts.To.Before(i.gatherOpts.From) {
+		return cli.NewExitError("\n\t[!] --from must not be after --to.", -1)
+	}
+
+	if i.chunkPerDay {
+		if i.userCurrChunk != -1 {
+			return cli.NewExitError("\n\t[!] --chunk cannot be used with --chunk-per-day.", -1)
+		}
+		if i.deleteOldData {
+			return cli.NewExitError("\n\t[!] --delete cannot be used with --chunk-per-day.", -1)
+		}
+	}
+
+	return nil
+}
+
+// parseStreamArgs handles parsing the positional import arguments when streaming
+func (i *Importer) parseStreamArgs() error {
+	if len(i.args) != 1 || i.args[0] == "" {
+		return cli.NewExitError("\n\t[!] Only <database name> may be given when streaming logs.", -1)
+	}
+	i.targetDatabase = i.args[0]
+
+	if i.deleteOldData {
+		return cli.NewExitError("\n\t[!] --delete cannot be used when streaming logs.", -1)
+	}
+
+	if i.chunkPerDay {
+		return cli.NewExitError("\n\t[!] --chunk-per-day cannot be used when streaming logs.", -1)
+	}
+
+	if i.flushInterval < time.Minute {
+		return cli.NewExitError("\n\t[!] --interval must be at least one minute.", -1)
+	}
+
+	err := i.checkForInvalidDBChars(i.targetDatabase)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
 	return nil
 }
 
@@ -213,6 +349,10 @@ func (i *Importer) run() error {
 	// set up target database
 	i.res.DB.SelectDB(i.targetDatabase)
 
+	if i.chunkPerDay {
+		return i.runPerDay()
+	}
+
 	// set up the rolling configuration
 	// grab the current rolling settings from the MetaDB
 	exists, isRolling, currChunk, totalChunks, err := i.res.MetaDB.GetRollingSettings(i.targetDatabase)
@@ -240,7 +380,11 @@ func (i *Importer) run() error {
 		return cli.NewExitError(fmt.Errorf("error creating new file system importer: %v", err.Error()), -1)
 	}
 
-	indexedFiles := importer.CollectFileDetails(i.importFiles, i.threads)
+	if i.stream {
+		return i.runStream(importer)
+	}
+
+	indexedFiles := importer.CollectFileDetails(i.importFiles, i.gatherOpts, i.threads)
 	// if no compatible files for import were found, exit
 	if len(indexedFiles) == 0 {
 		return cli.NewExitError("No compatible log files found", -1)
@@ -282,6 +426,165 @@ func (i *Importer) run() error {
 	return nil
 }
 
+// runPerDay imports the logs written on each day into the next chunk of the
+// rolling database, oldest day first
+func (i *Importer) runPerDay() error {
+	days := files.GroupLogFilesByDay(i.importFiles, i.gatherOpts, i.res.Log)
+	if len(days) == 0 {
+		return cli.NewExitError("No compatible log files written on a known day found", -1)
+	}
+
+	exists, isRolling, _, _, err := i.res.MetaDB.GetRollingSettings(i.targetDatabase)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("\n\t[!] Error while reading existing database settings: %v", err.Error()), -1)
+	}
+
+	rollingCfg, err := i.perDayRollingCfg()
+	if err != nil {
+		return err
+	}
+
+	// about to import into and convert an existing, non-rolling database
+	if exists && !isRolling {
+		i.res.Log.Infof("Non-rolling database %v will be converted to rolling\n", i.targetDatabase)
+		fmt.Printf("\t[+] Non-rolling database %v will be converted to rolling\n", i.targetDatabase)
+	}
+
+	// older days would be overwritten by newer days within the same run
+	if len(days) > rollingCfg.TotalChunks {
+		skipped := len(days) - rollingCfg.TotalChunks
+		fmt.Printf("\n\t[!] Logs span %d days but the database holds %d chunks. Skipping the oldest %d days.\n",
+			len(days), rollingCfg.TotalChunks, skipped)
+		i.res.Log.Warnf("Skipping logs written before %s\n", days[skipped].Day.Format(files.DayFormat))
+		days = days[skipped:]
+	}
+
+	for _, day := range days {
+		// the current chunk advances after each day is imported
+		rollingCfg, err := i.perDayRollingCfg()
+		if err != nil {
+			return err
+		}
+		i.res.Config.S.Rolling = rollingCfg
+
+		importer, err := parser.NewFSImporter(i.res)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("error creating new file system importer: %v", err.Error()), -1)
+		}
+		if len(importer.GetInternalSubnets()) == 0 {
+			return cli.NewExitError("Internal subnets are not defined. Please set the InternalSubnets section of the config file.", -1)
+		}
+
+		// the paths have already been filtered
+		indexedFiles := importer.CollectFileDetails(day.Paths, files.GatherOptions{}, i.threads)
+		if len(indexedFiles) == 0 {
+			continue
+		}
+
+		dayName := day.Day.Format(files.DayFormat)
+		i.res.Log.Infof("Importing logs from %s into chunk %d\n", dayName, rollingCfg.CurrentChunk)
+		fmt.Printf("\n\t[+] Importing logs from %s into chunk %d:\n", dayName, rollingCfg.CurrentChunk)
+
+		importer.Run(indexedFiles, i.threads)
+	}
+
+	i.res.Log.Infof("Finished importing %v\n", i.importFiles)
+
+	return nil
+}
+
+// perDayRollingCfg determines the rolling configuration for importing the next day
+// of logs into the target database
+func (i *Importer) perDayRollingCfg() (config.RollingStaticCfg, error) {
+	exists, isRolling, currChunk, totalChunks, err := i.res.MetaDB.GetRollingSettings(i.targetDatabase)
+	if err != nil {
+		return config.RollingStaticCfg{}, cli.NewExitError(fmt.Errorf("\n\t[!] Error while reading existing database settings: %v", err.Error()), -1)
+	}
+
+	rollingCfg, err := parseFlags(
+		exists, isRolling, currChunk, totalChunks,
+		true, -1, i.userTotalChunks, i.res.Config.S.Rolling.DefaultChunks,
+		false,
+	)
+	if err != nil {
+		return rollingCfg, cli.NewExitError(err.Error(), -1)
+	}
+	return rollingCfg, nil
+}
+
+// runStream imports logs from stdin or a Unix socket until the stream ends or
+// the process is interrupted
+func (i *Importer) runStream(importer *parser.FSImporter) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lines, err := i.openStream(ctx)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("error opening log stream: %v", err.Error()), -1)
+	}
+
+	i.res.Log.Infof("Streaming logs into %v\n", i.targetDatabase)
+	fmt.Printf("\n\t[+] Streaming logs into %v:\n", i.targetDatabase)
+
+	err = importer.RunStream(ctx, lines, i.flushInterval)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("error streaming logs: %v", err.Error()), -1)
+	}
+
+	i.res.Log.Infof("Finished streaming logs into %v\n", i.targetDatabase)
+	return nil
+}
+
+// openStream starts reading log lines from stdin or from each connection made to the
+// Unix socket. The returned channel is closed when stdin is exhausted.
+func (i *Importer) openStream(ctx context.Context) (<-chan []byte, error) {
+	lines := make(chan []byte, 1024)
+
+	if i.socketPath == "" {
+		go func() {
+			files.ScanStream(os.Stdin, lines, i.res.Log)
+			close(lines)
+		}()
+		return lines, nil
+	}
+
+	// clean up the socket left behind by a previous run
+	if info, err := os.Stat(i.socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(i.socketPath)
+	}
+
+	listener, err := net.Listen("unix", i.socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close() // also removes the socket file
+	}()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					i.res.Log.WithFields(log.Fields{
+						"socket": i.socketPath,
+						"error":  err.Error(),
+					}).Error("Could not accept connection to log stream socket")
+				}
+				return
+			}
+			go func() {
+				defer conn.Close()
+				files.ScanStream(conn, lines, i.res.Log)
+			}()
+		}
+	}()
+
+	return lines, nil
+}
+
 func (i *Importer) handleDeleteOldData() error {
 	if !i.res.Config.S.Rolling.Rolling {
 		fmt.Printf("\t[+] Removing database: %s\n", i.targetDatabase)
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/activecm/rita-legacy/util"
+)
+
+// supported values for the --output flag
+const (
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputCSV    = "csv"
+)
+
+// validateOutputFormat ensures the --output flag names a supported format
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", outputJSON, outputNDJSON, outputCSV:
+		return nil
+	}
+	return errors.New("Invalid option passed to output flag, expected json, ndjson, or csv")
+}
+
+// printResults writes out every result in the results slice to stdout
+// using the given output format
+func printResults(results interface{}, format string, delim string) error {
+	return writeResults(os.Stdout, results, format, delim)
+}
+
+// writeResults serializes every field of each result in the results slice.
+// Nested structs are flattened into dotted column names for csv output.
+func writeResults(w io.Writer, results interface{}, format string, delim string) error {
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+
+	resultsVal := reflect.ValueOf(results)
+	if resultsVal.Kind() != reflect.Slice {
+		return errors.New("results must be provided as a slice")
+	}
+
+	records := make([]interface{}, resultsVal.Len())
+	for idx := 0; idx < resultsVal.Len(); idx++ {
+		records[idx] = util.ToSerializable(resultsVal.Index(idx).Interface())
+	}
+
+	switch format {
+	case outputNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		return writeCSV(w, resultsVal.Type().Elem(), records, delim)
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+}
+
+// writeCSV writes the records out as a csv document, quoting any
+// values which contain the delimiter, quotes, or newlines. The columns
+// are derived from the result type so that every row lines up with the
+// header, even when a nested value is missing from some records.
+func writeCSV(w io.Writer, resultType reflect.Type, records []interface{}, delim string) error {
+	if utf8.RuneCountInString(delim) != 1 {
+		return errors.New("csv output requires a single character delimiter")
+	}
+	comma, _ := utf8.DecodeRuneInString(delim)
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = comma
+
+	headers := util.SerializableColumns(resultType)
+	if err := csvWriter.Write(headers); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		cells := make(map[string]string)
+		if err := flattenOutput("", record, cells); err != nil {
+			return err
+		}
+		row := make([]string, len(headers))
+		for idx, header := range headers {
+			row[idx] = cells[header]
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// flattenOutput walks an output value, recording the cell value for every
+// scalar or list it encounters under its dotted column name
+func flattenOutput(prefix string, val interface{}, cells map[string]string) error {
+	switch typed := val.(type) {
+	case util.OrderedObject:
+		for _, field := range typed {
+			key := field.Key
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flattenOutput(key, field.Value, cells); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		cell, err := formatListCell(typed)
+		if err != nil {
+			return err
+		}
+		cells[prefix] = cell
+		return nil
+	default:
+		cells[prefix] = formatScalarCell(typed)
+		return nil
+	}
+}
+
+// formatListCell joins lists of scalars with spaces. Lists containing
+// nested objects are written out as JSON.
+func formatListCell(items []interface{}) (string, error) {
+	cells := make([]string, 0, len(items))
+	for _, item := range items {
+		switch item.(type) {
+		case util.OrderedObject, []interface{}:
+			encoded, err := json.Marshal(items)
+			return string(encoded), err
+		}
+		cells = append(cells, formatScalarCell(item))
+	}
+	return strings.Join(cells, " "), nil
+}
+
+// formatScalarCell formats a single value for csv output
+func formatScalarCell(val interface{}) string {
+	switch typed := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case float64:
+		return f(typed)
+	case float32:
+		return f(float64(typed))
+	case bool:
+		return strconv.FormatBool(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
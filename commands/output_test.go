@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/useragent"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteResultsCSVEscapesDelimiter(t *testing.T) {
+	agents := []useragent.Result{
+		{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64), \"quoted\"", TimesUsed: 12},
+		{UserAgent: "curl/7.68.0", TimesUsed: 3, Suppressed: true},
+	}
+
+	for _, delim := range []string{",", "\t", ";"} {
+		var buf bytes.Buffer
+		require.Nil(t, writeResults(&buf, agents, outputCSV, delim))
+
+		reader := csv.NewReader(&buf)
+		reader.Comma = []rune(delim)[0]
+		rows, err := reader.ReadAll()
+		require.Nil(t, err)
+
+		assert.Equal(t, [][]string{
+			{"user_agent", "seen", "suppressed"},
+			{agents[0].UserAgent, "12", "false"},
+			{agents[1].UserAgent, "3", "true"},
+		}, rows, "delimiter %q", delim)
+	}
+}
+
+func TestWriteResultsCSVFlattensNestedFields(t *testing.T) {
+	results := []beacon.Result{{
+		UniqueIPPair: data.NewUniqueIPPair(
+			data.UniqueIP{IP: "10.0.0.1", NetworkUUID: util.UnknownPrivateNetworkUUID, NetworkName: util.UnknownPrivateNetworkName},
+			data.UniqueIP{IP: "8.8.8.8", NetworkUUID: util.PublicNetworkUUID, NetworkName: util.PublicNetworkName},
+		),
+		Connections: 10,
+		Ts:          beacon.TSData{Score: 0.5, Mode: 60},
+		Ds:          beacon.DSData{Score: 0.25},
+		Score:       0.75,
+	}}
+
+	var buf bytes.Buffer
+	require.Nil(t, writeResults(&buf, results, outputCSV, ","))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.Nil(t, err)
+	require.Len(t, rows, 2)
+
+	row := make(map[string]string)
+	for idx, header := range rows[0] {
+		row[header] = rows[1][idx]
+	}
+	assert.Equal(t, "10.0.0.1", row["src"])
+	assert.Equal(t, "8.8.8.8", row["dst"])
+	assert.Equal(t, "10", row["connection_count"])
+	assert.Equal(t, "0.5", row["ts.score"])
+	assert.Equal(t, "60", row["ts.mode"])
+	assert.Equal(t, "0.25", row["ds.score"])
+	assert.Equal(t, "0.75", row["score"])
+	assert.Equal(t, "ffffffff-ffff-ffff-ffff-ffffffffffff", row["dst_network_uuid"])
+}
+
+func TestWriteResultsCSVHeaderFromType(t *testing.T) {
+	type detail struct {
+		Issuer string `bson:"issuer"`
+		Serial string `bson:"serial"`
+	}
+	type result struct {
+		Host    string   `bson:"host"`
+		Cert    *detail  `bson:"cert"`
+		Tags    []string `bson:"tags"`
+		Details []detail `bson:"details"`
+		Score   float64  `bson:"score"`
+	}
+
+	// the first record is missing the nested values the second record has
+	results := []result{
+		{Host: "a.example.com", Score: 0.5},
+		{
+			Host:    "b.example.com",
+			Cert:    &detail{Issuer: "CN=ca", Serial: "01"},
+			Tags:    []string{"x", "y"},
+			Details: []detail{{Issuer: "CN=ca"}},
+			Score:   0.25,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, writeResults(&buf, results, outputCSV, ","))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"host", "cert.issuer", "cert.serial", "tags", "details", "score"},
+		{"a.example.com", "", "", "", "", "0.5"},
+		{"b.example.com", "CN=ca", "01", "x y", `[{"issuer":"CN=ca","serial":""}]`, "0.25"},
+	}, rows)
+
+	// the header is written even if there are no results
+	buf.Reset()
+	require.Nil(t, writeResults(&buf, []result{}, outputCSV, ","))
+	assert.Equal(t, "host,cert.issuer,cert.serial,tags,details,score\n", buf.String())
+}
+
+func TestWriteResultsNDJSON(t *testing.T) {
+	results := []beacon.Result{
+		{Connections: 1, Ts: beacon.TSData{Mode: 30}},
+		{Connections: 2, Ts: beacon.TSData{Mode: 60}},
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, writeResults(&buf, results, outputNDJSON, ","))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	for idx, line := range lines {
+		var record map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(line), &record))
+		assert.EqualValues(t, results[idx].Connections, record["connection_count"])
+		assert.EqualValues(t, results[idx].Ts.Mode, record["ts"].(map[string]interface{})["mode"])
+	}
+}
+
+func TestWriteResultsRejectsUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NotNil(t, writeResults(&buf, []useragent.Result{}, "xml", ","))
+	assert.NotNil(t, writeResults(&buf, []useragent.Result{}, outputCSV, "::"))
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/activecm/rita-legacy/api"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:  "serve",
+		Usage: "Serve the results of analyzed databases as JSON over HTTP",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			cli.StringFlag{
+				Name:  "listen, l",
+				Usage: "Listen for HTTP requests on `ADDRESS`",
+				Value: "127.0.0.1:8080",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			res := resources.InitResources(getConfigFilePath(c))
+
+			addr := c.String("listen")
+			fmt.Printf("\t[+] Serving analyzed databases at http://%s/api/v1/databases\n", addr)
+
+			err := api.NewServer(res).ListenAndServe(addr)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+
+	bootstrapCommands(command)
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/pkg/beacondns"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-beacons-dns",
+		Usage:     "Print hosts which show signs of C2 software (DNS Analysis)",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+			triageFlag,
+		},
+		Action: showBeaconsDNS,
+	}
+
+	bootstrapCommands(command)
+}
+
+func showBeaconsDNS(c *cli.Context) error {
+	db := c.Args().Get(0)
+	if db == "" {
+		return cli.NewExitError("Specify a database", -1)
+	}
+	res := resources.InitResources(getConfigFilePath(c))
+	res.DB.SelectDB(db)
+
+	data, err := beacondns.Results(res, 0, c.Bool("suppressed"))
+
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if c.Bool("sort-criticality") {
+		asset.SortByCriticality(data)
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		var untriaged []beacondns.Result
+		for _, d := range data {
+			if !triage.IsBenign(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)) {
+				untriaged = append(untriaged, d)
+			}
+		}
+		data = untriaged
+	}
+
+	if !(len(data) > 0) {
+		return cli.NewExitError("No results were found for "+db, -1)
+	}
+
+	showNetNames := c.Bool("network-names")
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	if c.Bool("human-readable") {
+		err := showBeaconsDNSHuman(data, showNetNames, showTriage, showAssets, triage)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	err = showBeaconsDNSDelim(data, c.String("delimiter"), showNetNames, showTriage, showAssets, triage)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func showBeaconsDNSHuman(data []beacondns.Result, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	var headerFields []string
+	if showNetNames {
+		headerFields = []string{
+			"Score", "Source Network", "Source IP", "Domain", "Resolver Network", "Resolver IP",
+			"Queries", "TS Score", "Dur Score", "Hist Score", "Top Intvl",
+		}
+	} else {
+		headerFields = []string{
+			"Score", "Source IP", "Domain", "Resolver IP",
+			"Queries", "TS Score", "Dur Score", "Hist Score", "Top Intvl",
+		}
+	}
+
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Resolver")...)
+	}
+
+	table.SetHeader(headerFields)
+
+	for _, d := range data {
+		var row []string
+
+		if showNetNames {
+			row = []string{
+				f(d.Score), d.SrcNetworkName,
+				d.SrcIP, d.FQDN, d.Resolver.NetworkName, d.Resolver.IP,
+				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
+			}
+		} else {
+			row = []string{
+				f(d.Score), d.SrcIP, d.FQDN, d.Resolver.IP,
+				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
+			}
+		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.Resolver.Asset)...)
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
+
+func showBeaconsDNSDelim(data []beacondns.Result, delim string, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
+	var headerFields []string
+	if showNetNames {
+		headerFields = []string{
+			"Score", "Source Network", "Source IP", "Domain", "Resolver Network", "Resolver IP",
+			"Queries", "TS Score", "Dur Score", "Hist Score", "Top Intvl",
+		}
+	} else {
+		headerFields = []string{
+			"Score", "Source IP", "Domain", "Resolver IP",
+			"Queries", "TS Score", "Dur Score", "Hist Score", "Top Intvl",
+		}
+	}
+
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Resolver")...)
+	}
+
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(headerFields, delim))
+	for _, d := range data {
+
+		var row []string
+		if showNetNames {
+			row = []string{
+				f(d.Score), d.SrcNetworkName,
+				d.SrcIP, d.FQDN, d.Resolver.NetworkName, d.Resolver.IP,
+				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
+			}
+		} else {
+			row = []string{
+				f(d.Score), d.SrcIP, d.FQDN, d.Resolver.IP,
+				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
+			}
+		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.Resolver.Asset)...)
+		}
+
+		fmt.Println(strings.Join(row, delim))
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/pkg/beaconproxy"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/olekukonko/tablewriter"
@@ -20,7 +22,11 @@ func init() {
 			ConfigFlag,
 			humanFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
 		},
 		Action: showBeaconsProxy,
 	}
@@ -36,35 +42,68 @@ func showBeaconsProxy(c *cli.Context) error {
 	res := resources.InitResources(c.String("config"))
 	res.DB.SelectDB(db)
 
-	data, err := beaconproxy.Results(res, 0)
+	data, err := beaconproxy.Results(res, 0, c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	if c.Bool("sort-criticality") {
+		asset.SortByCriticality(data)
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		var untriaged []beaconproxy.Result
+		for _, d := range data {
+			if !triage.IsBenign(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)) {
+				untriaged = append(untriaged, d)
+			}
+		}
+		data = untriaged
+	}
+
 	if !(len(data) > 0) {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
 	showNetNames := c.Bool("network-names")
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
 
 	if c.Bool("human-readable") {
-		err := showBeaconsProxyHuman(data, showNetNames)
+		err := showBeaconsProxyHuman(data, showNetNames, showTriage, showAssets, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 		return nil
 	}
 
-	err = showBeaconsProxyDelim(data, c.String("delimiter"), showNetNames)
+	err = showBeaconsProxyDelim(data, c.String("delimiter"), showNetNames, showTriage, showAssets, triage)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 	return nil
 }
 
-func showBeaconsProxyHuman(data []beaconproxy.Result, showNetNames bool) error {
+func showBeaconsProxyHuman(data []beaconproxy.Result, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	var headerFields []string
 	if showNetNames {
@@ -79,6 +118,14 @@ func showBeaconsProxyHuman(data []beaconproxy.Result, showNetNames bool) error {
 		}
 	}
 
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Proxy")...)
+	}
+
 	table.SetHeader(headerFields)
 
 	for _, d := range data {
@@ -96,13 +143,20 @@ func showBeaconsProxyHuman(data []beaconproxy.Result, showNetNames bool) error {
 				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.Proxy.Asset)...)
+		}
 		table.Append(row)
 	}
 	table.Render()
 	return nil
 }
 
-func showBeaconsProxyDelim(data []beaconproxy.Result, delim string, showNetNames bool) error {
+func showBeaconsProxyDelim(data []beaconproxy.Result, delim string, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
 	var headerFields []string
 	if showNetNames {
 		headerFields = []string{
@@ -116,6 +170,14 @@ func showBeaconsProxyDelim(data []beaconproxy.Result, delim string, showNetNames
 		}
 	}
 
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Proxy")...)
+	}
+
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
 	for _, d := range data {
@@ -133,6 +195,13 @@ func showBeaconsProxyDelim(data []beaconproxy.Result, delim string, showNetNames
 				i(d.Connections), f(d.Ts.Score), f(d.DurScore), f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.Proxy.Asset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
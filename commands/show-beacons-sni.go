@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/pkg/beaconsni"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/olekukonko/tablewriter"
@@ -20,7 +22,11 @@ func init() {
 			ConfigFlag,
 			humanFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
 		},
 		Action: showBeaconsSNI,
 	}
@@ -36,35 +42,68 @@ func showBeaconsSNI(c *cli.Context) error {
 	res := resources.InitResources(getConfigFilePath(c))
 	res.DB.SelectDB(db)
 
-	data, err := beaconsni.Results(res, 0)
+	data, err := beaconsni.Results(res, 0, c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	if c.Bool("sort-criticality") {
+		asset.SortByCriticality(data)
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		var untriaged []beaconsni.Result
+		for _, d := range data {
+			if !triage.IsBenign(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)) {
+				untriaged = append(untriaged, d)
+			}
+		}
+		data = untriaged
+	}
+
 	if !(len(data) > 0) {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
 	showNetNames := c.Bool("network-names")
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
 
 	if c.Bool("human-readable") {
-		err := showBeaconsSNIHuman(data, showNetNames)
+		err := showBeaconsSNIHuman(data, showNetNames, showTriage, showAssets, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 		return nil
 	}
 
-	err = showBeaconsSNIDelim(data, c.String("delimiter"), showNetNames)
+	err = showBeaconsSNIDelim(data, c.String("delimiter"), showNetNames, showTriage, showAssets, triage)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 	return nil
 }
 
-func showBeaconsSNIHuman(data []beaconsni.Result, showNetNames bool) error {
+func showBeaconsSNIHuman(data []beaconsni.Result, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	var headerFields []string
 	if showNetNames {
@@ -81,6 +120,13 @@ func showBeaconsSNIHuman(data []beaconsni.Result, showNetNames bool) error {
 		}
 	}
 
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+	}
+
 	table.SetHeader(headerFields)
 
 	for _, d := range data {
@@ -99,13 +145,19 @@ func showBeaconsSNIHuman(data []beaconsni.Result, showNetNames bool) error {
 				f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+		}
 		table.Append(row)
 	}
 	table.Render()
 	return nil
 }
 
-func showBeaconsSNIDelim(data []beaconsni.Result, delim string, showNetNames bool) error {
+func showBeaconsSNIDelim(data []beaconsni.Result, delim string, showNetNames, showTriage, showAssets bool, triage database.TriageIndex) error {
 	var headerFields []string
 	if showNetNames {
 		headerFields = []string{
@@ -121,6 +173,13 @@ func showBeaconsSNIDelim(data []beaconsni.Result, delim string, showNetNames boo
 		}
 	}
 
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+	}
+
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
 	for _, d := range data {
@@ -139,6 +198,12 @@ func showBeaconsSNIDelim(data []beaconsni.Result, delim string, showNetNames boo
 				f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewSrcFQDNTriageKey(d.UniqueSrcFQDNPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/pkg/beacon"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/olekukonko/tablewriter"
@@ -20,7 +22,13 @@ func init() {
 			ConfigFlag,
 			humanFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
+			asnFlag,
+			countryFlag,
 		},
 		Action: showBeacons,
 	}
@@ -33,38 +41,87 @@ func showBeacons(c *cli.Context) error {
 	if db == "" {
 		return cli.NewExitError("Specify a database", -1)
 	}
+	geoFilter, err := getGeoFilter(c)
+	if err != nil {
+		return err
+	}
 	res := resources.InitResources(getConfigFilePath(c))
 	res.DB.SelectDB(db)
 
-	data, err := beacon.Results(res, 0)
+	data, err := beacon.Results(res, 0, c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	if c.Bool("sort-criticality") {
+		asset.SortByCriticality(data)
+	}
+
+	if !geoFilter.Empty() {
+		var matched []beacon.Result
+		for _, d := range data {
+			if geoFilter.Match(d.DstGeo) {
+				matched = append(matched, d)
+			}
+		}
+		data = matched
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		var untriaged []beacon.Result
+		for _, d := range data {
+			if !triage.IsBenign(database.NewIPPairTriageKey(d.UniqueIPPair)) {
+				untriaged = append(untriaged, d)
+			}
+		}
+		data = untriaged
+	}
+
 	if !(len(data) > 0) {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
 	showNetNames := c.Bool("network-names")
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+	showPeriod := res.Config.S.Beacon.PeriodogramWeight > 0
+	showGeo := res.Config.S.GeoIP.Enabled
+
+	if format := c.String("output"); format != "" {
+		err := printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
 
 	if c.Bool("human-readable") {
-		err := showBeaconsHuman(data, showNetNames)
+		err := showBeaconsHuman(data, showNetNames, showPeriod, showGeo, showTriage, showAssets, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 		return nil
 	}
 
-	err = showBeaconsDelim(data, c.String("delimiter"), showNetNames)
+	err = showBeaconsDelim(data, c.String("delimiter"), showNetNames, showPeriod, showGeo, showTriage, showAssets, triage)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
 	return nil
 }
 
-func showBeaconsHuman(data []beacon.Result, showNetNames bool) error {
+func showBeaconsHuman(data []beacon.Result, showNetNames, showPeriod, showGeo, showTriage, showAssets bool, triage database.TriageIndex) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	var headerFields []string
 	if showNetNames {
@@ -80,6 +137,19 @@ func showBeaconsHuman(data []beacon.Result, showNetNames bool) error {
 			"Hist Score", "Top Intvl",
 		}
 	}
+	if showPeriod {
+		headerFields = append(headerFields, "Period Score", "Top Period")
+	}
+	if showGeo {
+		headerFields = append(headerFields, "Dst. Country", "Dst. AS")
+	}
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	table.SetHeader(headerFields)
 
@@ -98,13 +168,26 @@ func showBeaconsHuman(data []beacon.Result, showNetNames bool) error {
 				f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showPeriod {
+			row = append(row, f(d.Periodogram.Score), i(d.Periodogram.Period))
+		}
+		if showGeo {
+			row = append(row, d.DstGeo.Country, d.DstGeo.AS())
+		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewIPPairTriageKey(d.UniqueIPPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.DstAsset)...)
+		}
 		table.Append(row)
 	}
 	table.Render()
 	return nil
 }
 
-func showBeaconsDelim(data []beacon.Result, delim string, showNetNames bool) error {
+func showBeaconsDelim(data []beacon.Result, delim string, showNetNames, showPeriod, showGeo, showTriage, showAssets bool, triage database.TriageIndex) error {
 	var headerFields []string
 	if showNetNames {
 		headerFields = []string{
@@ -119,6 +202,19 @@ func showBeaconsDelim(data []beacon.Result, delim string, showNetNames bool) err
 			"Hist Score", "Top Intvl",
 		}
 	}
+	if showPeriod {
+		headerFields = append(headerFields, "Period Score", "Top Period")
+	}
+	if showGeo {
+		headerFields = append(headerFields, "Dst. Country", "Dst. AS")
+	}
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -138,6 +234,19 @@ func showBeaconsDelim(data []beacon.Result, delim string, showNetNames bool) err
 				f(d.HistScore), i(d.Ts.Mode),
 			}
 		}
+		if showPeriod {
+			row = append(row, f(d.Periodogram.Score), i(d.Periodogram.Period))
+		}
+		if showGeo {
+			row = append(row, d.DstGeo.Country, d.DstGeo.AS())
+		}
+		if showTriage {
+			row = append(row, triage.Lookup(database.NewIPPairTriageKey(d.UniqueIPPair)).Label())
+		}
+		if showAssets {
+			row = append(row, assetColumns(d.SrcAsset)...)
+			row = append(row, assetColumns(d.DstAsset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/olekukonko/tablewriter"
@@ -24,7 +25,11 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
 		},
 		Usage:  "Print blacklisted hostnames which received connections",
 		Action: printBLHostnames,
@@ -43,24 +48,50 @@ func printBLHostnames(c *cli.Context) error {
 	res := resources.InitResources(getConfigFilePath(c))
 	res.DB.SelectDB(db)
 
-	data, err := blacklist.HostnameResults(res, "conn_count", c.Int("limit"), c.Bool("no-limit"))
+	data, err := blacklist.HostnameResults(res, "conn_count", c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	data = sortByCriticality(c, data)
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		var untriaged []blacklist.HostnameResult
+		for _, entry := range data {
+			if !triage.IsBenign(database.NewFQDNTriageKey(entry.Host)) {
+				untriaged = append(untriaged, entry)
+			}
+		}
+		data = untriaged
+	}
+
 	if len(data) == 0 {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
+	if format := c.String("output"); format != "" {
+		err := printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
 	if c.Bool("human-readable") {
-		err = showBLHostnamesHuman(data, c.Bool("network-names"))
+		err = showBLHostnamesHuman(data, c.Bool("network-names"), showTriage, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 	} else {
-		err = showBLHostnames(data, c.String("delimiter"), c.Bool("network-names"))
+		err = showBLHostnames(data, c.String("delimiter"), c.Bool("network-names"), showTriage, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
@@ -69,8 +100,11 @@ func printBLHostnames(c *cli.Context) error {
 	return nil
 }
 
-func showBLHostnames(hostnames []blacklist.HostnameResult, delim string, showNetNames bool) error {
-	headers := []string{"Host", "Connections", "Unique Connections", "Total Bytes", "Sources"}
+func showBLHostnames(hostnames []blacklist.HostnameResult, delim string, showNetNames, showTriage bool, triage database.TriageIndex) error {
+	headers := []string{"Host", "Connections", "Unique Connections", "Total Bytes", "Sources", "Threat Intel"}
+	if showTriage {
+		headers = append(headers, "Triage")
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headers, delim))
@@ -98,7 +132,10 @@ func showBLHostnames(hostnames []blacklist.HostnameResult, delim string, showNet
 		}
 
 		sort.Strings(sourceIPs)
-		serialized = append(serialized, strings.Join(sourceIPs, " "))
+		serialized = append(serialized, strings.Join(sourceIPs, " "), blacklist.FormatSources(entry.Sources))
+		if showTriage {
+			serialized = append(serialized, triage.Lookup(database.NewFQDNTriageKey(entry.Host)).Label())
+		}
 
 		fmt.Println(
 			strings.Join(
@@ -111,9 +148,12 @@ func showBLHostnames(hostnames []blacklist.HostnameResult, delim string, showNet
 	return nil
 }
 
-func showBLHostnamesHuman(hostnames []blacklist.HostnameResult, showNetNames bool) error {
+func showBLHostnamesHuman(hostnames []blacklist.HostnameResult, showNetNames, showTriage bool, triage database.TriageIndex) error {
 	table := tablewriter.NewWriter(os.Stdout)
-	headers := []string{"Hostname", "Connections", "Unique Connections", "Total Bytes", "Sources"}
+	headers := []string{"Hostname", "Connections", "Unique Connections", "Total Bytes", "Sources", "Threat Intel"}
+	if showTriage {
+		headers = append(headers, "Triage")
+	}
 
 	table.SetHeader(headers)
 	for _, entry := range hostnames {
@@ -140,7 +180,10 @@ func showBLHostnamesHuman(hostnames []blacklist.HostnameResult, showNetNames boo
 		}
 
 		sort.Strings(sourceIPs)
-		serialized = append(serialized, strings.Join(sourceIPs, " "))
+		serialized = append(serialized, strings.Join(sourceIPs, " "), blacklist.FormatSources(entry.Sources))
+		if showTriage {
+			serialized = append(serialized, triage.Lookup(database.NewFQDNTriageKey(entry.Host)).Label())
+		}
 
 		table.Append(serialized)
 	}
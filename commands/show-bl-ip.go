@@ -7,7 +7,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli"
@@ -25,7 +28,13 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
+			asnFlag,
+			countryFlag,
 		},
 		Usage:  "Print blacklisted IPs which initiated connections",
 		Action: printBLSourceIPs,
@@ -42,7 +51,13 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
+			triageFlag,
+			asnFlag,
+			countryFlag,
 		},
 		Usage:  "Print blacklisted IPs which received connections",
 		Action: printBLDestIPs,
@@ -71,27 +86,62 @@ func printBLSourceIPs(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	geoFilter, err := getGeoFilter(c)
+	if err != nil {
+		return err
+	}
 	res := resources.InitResources(getConfigFilePath(c))
 	res.DB.SelectDB(db)
 
-	data, err := blacklist.SrcIPResults(res, sort, c.Int("limit"), c.Bool("no-limit"))
+	data, err := blacklist.SrcIPResults(res, sort, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	data = sortByCriticality(c, data)
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		data = filterBenignBLIPs(data, triage, true)
+	}
+
+	if !geoFilter.Empty() {
+		data = filterGeoBLIPs(data, geoFilter)
+	}
+
 	if len(data) == 0 {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
+	showGeo := res.Config.S.GeoIP.Enabled
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err = printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
 	if human {
-		err = showBLIPsHuman(data, connected, showNetNames, true)
+		err = showBLIPsHuman(data, connected, showNetNames, true, showGeo, showTriage, showAssets, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 	} else {
-		err = showBLIPs(data, connected, showNetNames, true, c.String("delimiter"))
+		err = showBLIPs(data, connected, showNetNames, true, showGeo, showTriage, showAssets, triage, c.String("delimiter"))
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
@@ -104,28 +154,63 @@ func printBLDestIPs(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	geoFilter, err := getGeoFilter(c)
+	if err != nil {
+		return err
+	}
 
 	res := resources.InitResources(getConfigFilePath(c))
 	res.DB.SelectDB(db)
 
-	data, err := blacklist.DstIPResults(res, sort, c.Int("limit"), c.Bool("no-limit"))
+	data, err := blacklist.DstIPResults(res, sort, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 	if err != nil {
 		res.Log.Error(err)
 		return cli.NewExitError(err, -1)
 	}
 
+	data = sortByCriticality(c, data)
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return cli.NewExitError(err, -1)
+	}
+
+	showTriage := c.Bool("triage")
+	if !showTriage {
+		data = filterBenignBLIPs(data, triage, false)
+	}
+
+	if !geoFilter.Empty() {
+		data = filterGeoBLIPs(data, geoFilter)
+	}
+
 	if len(data) == 0 {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
+	showGeo := res.Config.S.GeoIP.Enabled
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err = printResults(data, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
 	if human {
-		err = showBLIPsHuman(data, connected, showNetNames, false)
+		err = showBLIPsHuman(data, connected, showNetNames, false, showGeo, showTriage, showAssets, triage)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 	} else {
-		err = showBLIPs(data, connected, showNetNames, false, c.String("delimiter"))
+		err = showBLIPs(data, connected, showNetNames, false, showGeo, showTriage, showAssets, triage, c.String("delimiter"))
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
@@ -133,7 +218,38 @@ func printBLDestIPs(c *cli.Context) error {
 	return nil
 }
 
-func showBLIPs(ips []blacklist.IPResult, connectedHosts, showNetNames, source bool, delim string) error {
+// blIPTriage returns the triage entry for a blacklisted IP. source is true if the
+// blacklisted IP initiated the connections.
+func blIPTriage(triage database.TriageIndex, ip data.UniqueIP, source bool) database.TriageEntry {
+	if source {
+		return triage.Lookup(database.NewSrcTriageKey(ip))
+	}
+	return triage.Lookup(database.NewDstTriageKey(ip))
+}
+
+// filterBenignBLIPs removes the blacklisted IPs which were triaged as benign
+func filterBenignBLIPs(ips []blacklist.IPResult, triage database.TriageIndex, source bool) []blacklist.IPResult {
+	var untriaged []blacklist.IPResult
+	for _, entry := range ips {
+		if blIPTriage(triage, entry.Host, source).Status != database.TriageBenign {
+			untriaged = append(untriaged, entry)
+		}
+	}
+	return untriaged
+}
+
+// filterGeoBLIPs removes the blacklisted IPs which don't match the ASN and country filter
+func filterGeoBLIPs(ips []blacklist.IPResult, filter geoip.Filter) []blacklist.IPResult {
+	var matched []blacklist.IPResult
+	for _, entry := range ips {
+		if filter.Match(entry.Geo) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func showBLIPs(ips []blacklist.IPResult, connectedHosts, showNetNames, source, showGeo, showTriage, showAssets bool, triage database.TriageIndex, delim string) error {
 	var headerFields []string
 	if !showNetNames && !connectedHosts {
 		headerFields = []string{"IP", "Connections", "Unique Connections", "Total Bytes"}
@@ -148,6 +264,16 @@ func showBLIPs(ips []blacklist.IPResult, connectedHosts, showNetNames, source bo
 	} else if showNetNames && connectedHosts && !source {
 		headerFields = []string{"IP", "Network", "Connections", "Unique Connections", "Total Bytes", "Sources"}
 	}
+	headerFields = append(headerFields, "Threat Intel")
+	if showGeo {
+		headerFields = append(headerFields, "Country", "AS")
+	}
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("")...)
+	}
 
 	// Print the headerFields and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -183,6 +309,16 @@ func showBLIPs(ips []blacklist.IPResult, connectedHosts, showNetNames, source bo
 			sort.Strings(connectedHostsIPs)
 			serialized = append(serialized, strings.Join(connectedHostsIPs, " "))
 		}
+		serialized = append(serialized, blacklist.FormatSources(entry.Sources))
+		if showGeo {
+			serialized = append(serialized, entry.Geo.Country, entry.Geo.AS())
+		}
+		if showTriage {
+			serialized = append(serialized, blIPTriage(triage, entry.Host, source).Label())
+		}
+		if showAssets {
+			serialized = append(serialized, assetColumns(entry.Host.Asset)...)
+		}
 		fmt.Println(
 			strings.Join(
 				serialized,
@@ -193,7 +329,7 @@ func showBLIPs(ips []blacklist.IPResult, connectedHosts, showNetNames, source bo
 	return nil
 }
 
-func showBLIPsHuman(ips []blacklist.IPResult, connectedHosts, showNetNames, source bool) error {
+func showBLIPsHuman(ips []blacklist.IPResult, connectedHosts, showNetNames, source, showGeo, showTriage, showAssets bool, triage database.TriageIndex) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	var headerFields []string
 
@@ -210,6 +346,16 @@ func showBLIPsHuman(ips []blacklist.IPResult, connectedHosts, showNetNames, sour
 	} else if showNetNames && connectedHosts && !source {
 		headerFields = []string{"IP", "Network", "Connections", "Unique Connections", "Total Bytes", "Sources"}
 	}
+	headerFields = append(headerFields, "Threat Intel")
+	if showGeo {
+		headerFields = append(headerFields, "Country", "AS")
+	}
+	if showTriage {
+		headerFields = append(headerFields, "Triage")
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("")...)
+	}
 
 	table.SetHeader(headerFields)
 	for _, entry := range ips {
@@ -245,6 +391,16 @@ func showBLIPsHuman(ips []blacklist.IPResult, connectedHosts, showNetNames, sour
 			sort.Strings(connectedHostsIPs)
 			serialized = append(serialized, strings.Join(connectedHostsIPs, " "))
 		}
+		serialized = append(serialized, blacklist.FormatSources(entry.Sources))
+		if showGeo {
+			serialized = append(serialized, entry.Geo.Country, entry.Geo.AS())
+		}
+		if showTriage {
+			serialized = append(serialized, blIPTriage(triage, entry.Host, source).Label())
+		}
+		if showAssets {
+			serialized = append(serialized, assetColumns(entry.Host.Asset)...)
+		}
 		table.Append(serialized)
 	}
 	table.Render()
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+
+	blJA3 := cli.Command{
+		Name:      "show-bl-ja3",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Usage: "Print blacklisted JA3, JA3S, and certificate fingerprints seen in TLS connections",
+		Description: "Lists the fingerprints found on the CustomJA3Blacklists, CustomJA3SBlacklists, and " +
+			"CustomCertSHA1Blacklists along with the source hosts which presented or received them " +
+			"and the server names and IPs they connected to.",
+		Action: printBLFingerprints,
+	}
+
+	bootstrapCommands(blJA3)
+}
+
+func printBLFingerprints(c *cli.Context) error {
+	db := c.Args().Get(0)
+
+	if db == "" {
+		return cli.NewExitError("Specify a database", -1)
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+	res.DB.SelectDB(db)
+
+	results, err := blacklist.FingerprintResults(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	results = sortByCriticality(c, results)
+
+	if len(results) == 0 {
+		return cli.NewExitError("No results were found for "+db, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(results, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	if c.Bool("human-readable") {
+		err = showBLFingerprintsHuman(results, c.Bool("network-names"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	} else {
+		err = showBLFingerprints(results, c.String("delimiter"), c.Bool("network-names"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+
+	return nil
+}
+
+var blFingerprintHeaders = []string{"Type", "Fingerprint", "Reason", "Connections", "Sources", "Destinations", "Destination IPs", "List"}
+
+func showBLFingerprints(fingerprints []blacklist.FingerprintResult, delim string, showNetNames bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(blFingerprintHeaders, delim))
+	for _, entry := range fingerprints {
+		fmt.Println(
+			strings.Join(
+				serializeBLFingerprint(entry, showNetNames),
+				delim,
+			),
+		)
+	}
+	return nil
+}
+
+func showBLFingerprintsHuman(fingerprints []blacklist.FingerprintResult, showNetNames bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(blFingerprintHeaders)
+	for _, entry := range fingerprints {
+		table.Append(serializeBLFingerprint(entry, showNetNames))
+	}
+	table.Render()
+	return nil
+}
+
+// serializeBLFingerprint formats a blacklisted fingerprint result as a row of blFingerprintHeaders
+func serializeBLFingerprint(entry blacklist.FingerprintResult, showNetNames bool) []string {
+	fqdns := append([]string{}, entry.FQDNs...)
+	sort.Strings(fqdns)
+
+	return []string{
+		entry.Type,
+		entry.Hash,
+		entry.Reason,
+		strconv.Itoa(entry.Connections),
+		joinBLFingerprintIPs(entry.Hosts, showNetNames),
+		strings.Join(fqdns, " "),
+		joinBLFingerprintIPs(entry.RespondingIPs, showNetNames),
+		entry.List,
+	}
+}
+
+func joinBLFingerprintIPs(ips []data.UniqueIP, showNetNames bool) string {
+	var ipStrs []string
+	for _, uniqIP := range ips {
+		if showNetNames {
+			escapedNetName := strings.ReplaceAll(uniqIP.NetworkName, " ", "_")
+			escapedNetName = strings.ReplaceAll(escapedNetName, ":", "_")
+			ipStrs = append(ipStrs, escapedNetName+":"+uniqIP.IP)
+		} else {
+			ipStrs = append(ipStrs, uniqIP.IP)
+		}
+	}
+	sort.Strings(ipStrs)
+	return strings.Join(ipStrs, " ")
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/certificate"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-certificates",
+		Usage:     "Print expired, self-signed, short-lived, and freshly issued certificates",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := certificate.Results(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showCertificatesHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showCertificates(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func certificateHeaders(showNetNames, showAssets bool) []string {
+	headerFields := []string{"Server IP", "Subject", "Issuer", "Not Valid Before", "Not Valid After", "Key Length", "Flags", "Beacon Score"}
+	if showNetNames {
+		headerFields = append([]string{"Server Network"}, headerFields...)
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Server")...)
+	}
+	return headerFields
+}
+
+// certificateFlags lists the reasons a certificate was reported
+func certificateFlags(cert certificate.Details) string {
+	return strings.Join(cert.Flags(), " ")
+}
+
+func showCertificates(certs []certificate.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(certificateHeaders(showNetNames, showAssets), delim))
+	for _, cert := range certs {
+		row := []string{
+			cert.Host.IP,
+			cert.Certificate.Subject,
+			cert.Certificate.Issuer,
+			i(cert.Certificate.NotValidBefore),
+			i(cert.Certificate.NotValidAfter),
+			strconv.Itoa(cert.Certificate.KeyLength),
+			certificateFlags(cert.Certificate),
+			f(cert.BeaconScore),
+		}
+		if showNetNames {
+			row = append([]string{cert.Host.NetworkName}, row...)
+		}
+		if showAssets {
+			row = append(row, assetColumns(cert.Host.Asset)...)
+		}
+		fmt.Println(strings.Join(row, delim))
+	}
+	return nil
+}
+
+func showCertificatesHuman(certs []certificate.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(certificateHeaders(showNetNames, showAssets))
+	for _, cert := range certs {
+		row := []string{
+			cert.Host.IP,
+			cert.Certificate.Subject,
+			cert.Certificate.Issuer,
+			time.Unix(cert.Certificate.NotValidBefore, 0).UTC().Format(time.RFC3339),
+			time.Unix(cert.Certificate.NotValidAfter, 0).UTC().Format(time.RFC3339),
+			strconv.Itoa(cert.Certificate.KeyLength),
+			certificateFlags(cert.Certificate),
+			f(cert.BeaconScore),
+		}
+		if showNetNames {
+			row = append([]string{cert.Host.NetworkName}, row...)
+		}
+		if showAssets {
+			row = append(row, assetColumns(cert.Host.Asset)...)
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
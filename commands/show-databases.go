@@ -3,10 +3,22 @@ package commands
 import (
 	"fmt"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/urfave/cli"
 )
 
+// databaseResult summarizes a database tracked in the metadatabase for --output
+type databaseResult struct {
+	Name           string         `bson:"name"`
+	Analyzed       bool           `bson:"analyzed"`
+	AnalyzeVersion string         `bson:"analyze_version"`
+	Rolling        bool           `bson:"rolling"`
+	CurrentChunk   int            `bson:"current_chunk"`
+	TotalChunks    int            `bson:"total_chunks"`
+	TsRange        database.Range `bson:"ts_range"`
+}
+
 func init() {
 
 	databases := cli.Command{
@@ -15,18 +27,50 @@ func init() {
 		Usage:   "Print the databases currently stored",
 		Flags: []cli.Flag{
 			ConfigFlag,
+			delimFlag,
+			outputFlag,
 		},
 		Action: func(c *cli.Context) error {
+			format := c.String("output")
+			if err := validateOutputFormat(format); err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+
 			res := resources.InitResources(getConfigFilePath(c))
 
-			if res != nil {
+			if res == nil {
+				fmt.Println("\t[-] Cannot display databases due to outdated metadatabase entries.")
+				return nil
+			}
+
+			if format == "" {
 				for _, name := range res.MetaDB.GetDatabases() {
 					fmt.Println(name)
 				}
-			} else {
-				fmt.Println("\t[-] Cannot display databases due to outdated metadatabase entries.")
+				return nil
+			}
+
+			var data []databaseResult
+			for _, name := range res.MetaDB.GetDatabases() {
+				info, err := res.MetaDB.GetDBMetaInfo(name)
+				if err != nil {
+					res.Log.Error(err)
+					return cli.NewExitError(err, -1)
+				}
+				data = append(data, databaseResult{
+					Name:           info.Name,
+					Analyzed:       info.Analyzed,
+					AnalyzeVersion: info.AnalyzeVersion,
+					Rolling:        info.Rolling,
+					CurrentChunk:   info.CurrentChunk,
+					TotalChunks:    info.TotalChunks,
+					TsRange:        info.TsRange,
+				})
 			}
 
+			if err := printResults(data, format, c.String("delimiter")); err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
 			return nil
 		},
 	}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/hostname"
 	"github.com/activecm/rita-legacy/resources"
@@ -21,7 +22,9 @@ func init() {
 			ConfigFlag,
 			humanFlag,
 			delimFlag,
+			outputFlag,
 			netNamesFlag,
+			criticalityFlag,
 		},
 		Action: showFqdnIps,
 	}
@@ -44,21 +47,38 @@ func showFqdnIps(c *cli.Context) error {
 		return cli.NewExitError(err, -1)
 	}
 
+	if c.Bool("sort-criticality") {
+		asset.SortByCriticality(ipResults)
+	}
+
 	if !(len(ipResults) > 0) {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
 	showNetNames := c.Bool("network-names")
+	showAssets, err := hasAssets(res)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(ipResults, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
 
 	if c.Bool("human-readable") {
-		err := showFqdnIpsHuman(ipResults, showNetNames)
+		err := showFqdnIpsHuman(ipResults, showNetNames, showAssets)
 		if err != nil {
 			return cli.NewExitError(err.Error(), -1)
 		}
 		return nil
 	}
 
-	err = showFqdnIpsDelim(ipResults, c.String("delimiter"), showNetNames)
+	err = showFqdnIpsDelim(ipResults, c.String("delimiter"), showNetNames, showAssets)
 	if err != nil {
 		return cli.NewExitError(err.Error(), -1)
 	}
@@ -66,7 +86,7 @@ func showFqdnIps(c *cli.Context) error {
 	return nil
 }
 
-func showFqdnIpsHuman(data []data.UniqueIP, showNetNames bool) error {
+func showFqdnIpsHuman(data []data.UniqueIP, showNetNames, showAssets bool) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	var headerFields []string
 	if showNetNames {
@@ -78,6 +98,9 @@ func showFqdnIpsHuman(data []data.UniqueIP, showNetNames bool) error {
 			"Resolved IP",
 		}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("")...)
+	}
 
 	table.SetHeader(headerFields)
 
@@ -92,13 +115,16 @@ func showFqdnIpsHuman(data []data.UniqueIP, showNetNames bool) error {
 				d.IP,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(d.Asset)...)
+		}
 		table.Append(row)
 	}
 	table.Render()
 	return nil
 }
 
-func showFqdnIpsDelim(data []data.UniqueIP, delim string, showNetNames bool) error {
+func showFqdnIpsDelim(data []data.UniqueIP, delim string, showNetNames, showAssets bool) error {
 	var headerFields []string
 	if showNetNames {
 		headerFields = []string{
@@ -109,6 +135,9 @@ func showFqdnIpsDelim(data []data.UniqueIP, delim string, showNetNames bool) err
 			"Resolved IP",
 		}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("")...)
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -123,6 +152,9 @@ func showFqdnIpsDelim(data []data.UniqueIP, delim string, showNetNames bool) err
 				d.IP,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(d.Asset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
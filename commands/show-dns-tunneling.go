@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-dns-tunneling",
+		Usage:     "Print clients which show signs of tunneling data over DNS",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := dnstunnel.Results(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showDNSTunnelingHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showDNSTunneling(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func dnsTunnelingHeaders(showNetNames, showAssets bool) []string {
+	headerFields := []string{
+		"Score", "Source IP", "Domain", "Queries", "Entropy", "Avg Query Length",
+		"Query Length Std Dev", "Max Query Length", "Unique Subdomain Ratio", "TXT/NULL Share", "NXDOMAIN Rate",
+	}
+	if showNetNames {
+		headerFields = append([]string{headerFields[0], "Source Network"}, headerFields[1:]...)
+	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+	}
+	return headerFields
+}
+
+func dnsTunnelingRow(result dnstunnel.Result, showNetNames, showAssets bool) []string {
+	row := []string{
+		f(result.Score), result.SrcIP, result.FQDN, i(result.QueryCount), f(result.Entropy), f(result.QueryLengthMean),
+		f(result.QueryLengthStdDev), i(result.QueryLengthMax), f(result.UniqueSubdomainRatio), f(result.TXTNullShare), f(result.NXDomainRate),
+	}
+	if showNetNames {
+		row = append([]string{row[0], result.SrcNetworkName}, row[1:]...)
+	}
+	if showAssets {
+		row = append(row, assetColumns(result.SrcAsset)...)
+	}
+	return row
+}
+
+func showDNSTunneling(results []dnstunnel.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(dnsTunnelingHeaders(showNetNames, showAssets), delim))
+	for _, result := range results {
+		fmt.Println(strings.Join(dnsTunnelingRow(result, showNetNames, showAssets), delim))
+	}
+	return nil
+}
+
+func showDNSTunnelingHuman(results []dnstunnel.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(dnsTunnelingHeaders(showNetNames, showAssets))
+	for _, result := range results {
+		table.Append(dnsTunnelingRow(result, showNetNames, showAssets))
+	}
+	table.Render()
+	return nil
+}
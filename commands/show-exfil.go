@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activecm/rita-legacy/pkg/exfil"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-exfil",
+		Usage:     "Print internal hosts which uploaded far more data than they downloaded",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := exfil.Results(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showExfilHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showExfil(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func exfilHeaders(showNetNames, showAssets bool) []string {
+	headers := []string{
+		"Score", "Source IP", "Destination",
+		"Uploaded Bytes", "Downloaded Bytes", "Connections", "Asymmetry Score", "Volume Score", "Time of Day Score",
+	}
+	if showNetNames {
+		headers = []string{
+			"Score", "Source Network", "Source IP", "Destination Network", "Destination",
+			"Uploaded Bytes", "Downloaded Bytes", "Connections", "Asymmetry Score", "Volume Score", "Time of Day Score",
+		}
+	}
+	if showAssets {
+		headers = append(headers, assetHeaders("Source")...)
+		headers = append(headers, assetHeaders("Destination")...)
+	}
+	return headers
+}
+
+// exfilDestination returns the external IP address or FQDN the data was uploaded to
+func exfilDestination(result exfil.Result) string {
+	if result.Type == exfil.FQDNPeer {
+		return result.FQDN
+	}
+	return result.DstIP
+}
+
+func exfilRow(result exfil.Result, showNetNames, showAssets bool) []string {
+	row := []string{
+		f(result.Score), result.SrcIP, exfilDestination(result),
+		i(result.OrigBytes), i(result.RespBytes), i(result.Connections),
+		f(result.AsymmetryScore), f(result.VolumeScore), f(result.TimeOfDayScore),
+	}
+	if showNetNames {
+		row = []string{
+			f(result.Score), result.SrcNetworkName, result.SrcIP, result.DstNetworkName, exfilDestination(result),
+			i(result.OrigBytes), i(result.RespBytes), i(result.Connections),
+			f(result.AsymmetryScore), f(result.VolumeScore), f(result.TimeOfDayScore),
+		}
+	}
+	if showAssets {
+		row = append(row, assetColumns(result.SrcAsset)...)
+		row = append(row, assetColumns(result.DstAsset)...)
+	}
+	return row
+}
+
+func showExfil(results []exfil.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(exfilHeaders(showNetNames, showAssets), delim))
+	for _, result := range results {
+		fmt.Println(strings.Join(exfilRow(result, showNetNames, showAssets), delim))
+	}
+	return nil
+}
+
+func showExfilHuman(results []exfil.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(exfilHeaders(showNetNames, showAssets))
+	for _, result := range results {
+		table.Append(exfilRow(result, showNetNames, showAssets))
+	}
+	table.Render()
+	return nil
+}
@@ -24,6 +24,8 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
 		},
 		Action: func(c *cli.Context) error {
 			db := c.Args().Get(0)
@@ -34,7 +36,7 @@ func init() {
 			res := resources.InitResources(getConfigFilePath(c))
 			res.DB.SelectDB(db)
 
-			data, err := explodeddns.Results(res, c.Int("limit"), c.Bool("no-limit"))
+			data, err := explodeddns.Results(res, c.Int("limit"), c.Bool("no-limit"), c.Bool("suppressed"))
 
 			if err != nil {
 				res.Log.Error(err)
@@ -45,6 +47,14 @@ func init() {
 				return cli.NewExitError("No results were found for "+db, -1)
 			}
 
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
 			if c.Bool("human-readable") {
 				err := showDNSResultsHuman(data)
 				if err != nil {
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/activecm/rita-legacy/pkg/threat"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-hosts",
+		Usage:     "Print internal hosts ranked by their combined threat score",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := threat.Results(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showHostsHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showHosts(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func hostsHeaders(showNetNames, showAssets bool) []string {
+	headers := []string{"Score", "Host", "Evidence"}
+	if showNetNames {
+		headers = []string{"Score", "Network", "Host", "Evidence"}
+	}
+	if showAssets {
+		headers = append(headers, assetHeaders("")...)
+	}
+	return headers
+}
+
+// hostEvidence lists the findings behind a host's threat score, separated by semicolons
+func hostEvidence(result threat.Result) string {
+	findings := result.Findings()
+	findingStrs := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		findingStrs = append(findingStrs, finding.String())
+	}
+	return strings.Join(findingStrs, "; ")
+}
+
+func hostsRow(result threat.Result, showNetNames, showAssets bool) []string {
+	row := []string{f(result.Score), result.IP, hostEvidence(result)}
+	if showNetNames {
+		row = []string{f(result.Score), result.NetworkName, result.IP, hostEvidence(result)}
+	}
+	if showAssets {
+		row = append(row, assetColumns(result.Asset)...)
+	}
+	return row
+}
+
+func showHosts(results []threat.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(hostsHeaders(showNetNames, showAssets), delim))
+	for _, result := range results {
+		fmt.Println(strings.Join(hostsRow(result, showNetNames, showAssets), delim))
+	}
+	return nil
+}
+
+func showHostsHuman(results []threat.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(hostsHeaders(showNetNames, showAssets))
+	table.SetColWidth(100)
+	for _, result := range results {
+		table.Append(hostsRow(result, showNetNames, showAssets))
+	}
+	table.Render()
+	return nil
+}
@@ -19,6 +19,7 @@ func init() {
 			ConfigFlag,
 			humanFlag,
 			delimFlag,
+			outputFlag,
 		},
 		Action: showIPFqdns,
 	}
@@ -46,6 +47,14 @@ func showIPFqdns(c *cli.Context) error {
 		return cli.NewExitError("No results were found for "+db, -1)
 	}
 
+	if format := c.String("output"); format != "" {
+		err := printResults(fqdnResults, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
 	if c.Bool("human-readable") {
 		err := showIPFqdnsHuman(fqdnResults)
 		if err != nil {
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/lateral"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-lateral",
+		Usage:     "Print internal hosts which suddenly connected to many internal peers on administrative ports",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			cli.BoolFlag{
+				Name:  "all, a",
+				Usage: "Print the activity of every host rather than only the hosts which fanned out",
+			},
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := lateral.Results(res, !c.Bool("all"), c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showLateralHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showLateral(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func lateralHeaders(showNetNames, showAssets bool) []string {
+	headers := []string{
+		"Host", "Peers", "Baseline", "Fan Out", "Connections",
+		"Ports", "First Seen", "Last Seen", "Sample Peers",
+	}
+	if showNetNames {
+		headers = append([]string{"Network"}, headers...)
+	}
+	if showAssets {
+		headers = append(headers, assetHeaders("")...)
+	}
+	return headers
+}
+
+// lateralPorts lists the number of peers contacted on each port as port:peers
+func lateralPorts(ports []lateral.PortCount) string {
+	portStrs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		portStrs = append(portStrs, strconv.Itoa(port.Port)+":"+i(port.Peers))
+	}
+	return strings.Join(portStrs, " ")
+}
+
+func lateralRow(result lateral.Result, firstSeen, lastSeen string, showNetNames, showAssets bool) []string {
+	row := []string{
+		result.IP, i(result.Peers), f(result.Baseline), strconv.FormatBool(result.FanOut), i(result.Connections),
+		lateralPorts(result.Ports), firstSeen, lastSeen, strings.Join(result.PeerSample, " "),
+	}
+	if showNetNames {
+		row = append([]string{result.NetworkName}, row...)
+	}
+	if showAssets {
+		row = append(row, assetColumns(result.Asset)...)
+	}
+	return row
+}
+
+func showLateral(results []lateral.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(lateralHeaders(showNetNames, showAssets), delim))
+	for _, result := range results {
+		fmt.Println(strings.Join(lateralRow(result, i(result.FirstSeen), i(result.LastSeen), showNetNames, showAssets), delim))
+	}
+	return nil
+}
+
+func showLateralHuman(results []lateral.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(lateralHeaders(showNetNames, showAssets))
+	for _, result := range results {
+		firstSeen := time.Unix(result.FirstSeen, 0).UTC().Format(time.RFC3339)
+		lastSeen := time.Unix(result.LastSeen, 0).UTC().Format(time.RFC3339)
+		table.Append(lateralRow(result, firstSeen, lastSeen, showNetNames, showAssets))
+	}
+	table.Render()
+	return nil
+}
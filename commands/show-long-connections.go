@@ -25,6 +25,9 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
 		},
 		Action: func(c *cli.Context) error {
@@ -37,25 +40,41 @@ func init() {
 			res.DB.SelectDB(db)
 
 			thresh := 60 // 1 minute
-			data, err := uconn.LongConnResults(res, thresh, c.Int("limit"), c.Bool("no-limit"))
+			data, err := uconn.LongConnResults(res, thresh, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 			if err != nil {
 				res.Log.Error(err)
 				return cli.NewExitError(err, -1)
 			}
 
+			data = sortByCriticality(c, data)
+
 			if !(len(data) > 0) {
 				return cli.NewExitError("No results were found for "+db, -1)
 			}
 
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
 			if c.Bool("human-readable") {
-				err := showConnsHuman(data, c.Bool("network-names"))
+				err := showConnsHuman(data, c.Bool("network-names"), showAssets)
 				if err != nil {
 					return cli.NewExitError(err.Error(), -1)
 				}
 				return nil
 			}
-			err = showConns(data, c.String("delimiter"), c.Bool("network-names"))
+			err = showConns(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
 			if err != nil {
 				return cli.NewExitError(err.Error(), -1)
 			}
@@ -65,7 +84,7 @@ func init() {
 	bootstrapCommands(command)
 }
 
-func showConns(connResults []uconn.LongConnResult, delim string, showNetNames bool) error {
+func showConns(connResults []uconn.LongConnResult, delim string, showNetNames, showAssets bool) error {
 
 	var headerFields []string
 	if showNetNames {
@@ -73,6 +92,10 @@ func showConns(connResults []uconn.LongConnResult, delim string, showNetNames bo
 	} else {
 		headerFields = []string{"Source IP", "Destination IP", "Port:Protocol:Service", "Total Duration", "Longest Duration", "Connections", "Total Bytes", "State"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -110,13 +133,17 @@ func showConns(connResults []uconn.LongConnResult, delim string, showNetNames bo
 				state,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(result.SrcAsset)...)
+			row = append(row, assetColumns(result.DstAsset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
 	return nil
 }
 
-func showConnsHuman(connResults []uconn.LongConnResult, showNetNames bool) error {
+func showConnsHuman(connResults []uconn.LongConnResult, showNetNames, showAssets bool) error {
 	table := tablewriter.NewWriter(os.Stdout)
 
 	var headerFields []string
@@ -125,6 +152,10 @@ func showConnsHuman(connResults []uconn.LongConnResult, showNetNames bool) error
 	} else {
 		headerFields = []string{"Source IP", "Destination IP", "Port:Protocol:Service", "Total Duration", "Longest Duration", "Connections", "Total Bytes", "State"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	table.SetHeader(headerFields)
 	for _, result := range connResults {
@@ -161,6 +192,10 @@ func showConnsHuman(connResults []uconn.LongConnResult, showNetNames bool) error
 				state,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(result.SrcAsset)...)
+			row = append(row, assetColumns(result.DstAsset)...)
+		}
 
 		table.Append(row)
 	}
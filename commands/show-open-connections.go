@@ -25,6 +25,9 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
 		},
 		Action: func(c *cli.Context) error {
@@ -37,25 +40,41 @@ func init() {
 			res.DB.SelectDB(db)
 
 			thresh := 60 // 1 minute
-			data, err := uconn.OpenConnResults(res, thresh, c.Int("limit"), c.Bool("no-limit"))
+			data, err := uconn.OpenConnResults(res, thresh, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 			if err != nil {
 				res.Log.Error(err)
 				return cli.NewExitError(err, -1)
 			}
 
+			data = sortByCriticality(c, data)
+
 			if !(len(data) > 0) {
 				return cli.NewExitError("No results were found for "+db, -1)
 			}
 
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
 			if c.Bool("human-readable") {
-				err := showOpenConnsHuman(data, c.Bool("network-names"))
+				err := showOpenConnsHuman(data, c.Bool("network-names"), showAssets)
 				if err != nil {
 					return cli.NewExitError(err.Error(), -1)
 				}
 				return nil
 			}
-			err = showOpenConns(data, c.String("delimiter"), c.Bool("network-names"))
+			err = showOpenConns(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
 			if err != nil {
 				return cli.NewExitError(err.Error(), -1)
 			}
@@ -92,7 +111,7 @@ func openDuration(d time.Duration) string {
 	return b.String()
 }
 
-func showOpenConns(connResults []uconn.OpenConnResult, delim string, showNetNames bool) error {
+func showOpenConns(connResults []uconn.OpenConnResult, delim string, showNetNames, showAssets bool) error {
 
 	var headerFields []string
 	if showNetNames {
@@ -100,6 +119,10 @@ func showOpenConns(connResults []uconn.OpenConnResult, delim string, showNetName
 	} else {
 		headerFields = []string{"Source IP", "Destination IP", "Port:Protocol:Service", "Duration", "Bytes", "Zeek UID"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -127,13 +150,17 @@ func showOpenConns(connResults []uconn.OpenConnResult, delim string, showNetName
 				result.UID,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(result.SrcAsset)...)
+			row = append(row, assetColumns(result.DstAsset)...)
+		}
 
 		fmt.Println(strings.Join(row, delim))
 	}
 	return nil
 }
 
-func showOpenConnsHuman(connResults []uconn.OpenConnResult, showNetNames bool) error {
+func showOpenConnsHuman(connResults []uconn.OpenConnResult, showNetNames, showAssets bool) error {
 	table := tablewriter.NewWriter(os.Stdout)
 
 	var headerFields []string
@@ -142,6 +169,10 @@ func showOpenConnsHuman(connResults []uconn.OpenConnResult, showNetNames bool) e
 	} else {
 		headerFields = []string{"Source IP", "Destination IP", "Port:Protocol:Service", "Duration", "Bytes", "Zeek UID"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	table.SetHeader(headerFields)
 	for _, result := range connResults {
@@ -168,6 +199,10 @@ func showOpenConnsHuman(connResults []uconn.OpenConnResult, showNetNames bool) e
 				result.UID,
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(result.SrcAsset)...)
+			row = append(row, assetColumns(result.DstAsset)...)
+		}
 
 		table.Append(row)
 	}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/activecm/rita-legacy/pkg/raretls"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	rareTLS := cli.Command{
+		Name:      "show-rare-tls",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Usage: "Print JA3, JA3S, and SNI combinations seen on only a few internal hosts",
+		Description: "Lists the client and server TLS fingerprint pairings used by at most MaxComboHosts " +
+			"internal hosts to reach an SNI which at most MaxSNIHosts internal hosts connected to. " +
+			"Combinations are scored by how few hosts across the fleet used them and the SNI.",
+		Action: showRareTLS,
+	}
+
+	bootstrapCommands(rareTLS)
+}
+
+func showRareTLS(c *cli.Context) error {
+	db := c.Args().Get(0)
+	if db == "" {
+		return cli.NewExitError("Specify a database", -1)
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+	res.DB.SelectDB(db)
+
+	results, err := raretls.Results(res, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	results = sortByCriticality(c, results)
+
+	if len(results) == 0 {
+		return cli.NewExitError("No results were found for "+db, -1)
+	}
+
+	if format := c.String("output"); format != "" {
+		err := printResults(results, format, c.String("delimiter"))
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	if c.Bool("human-readable") {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(rareTLSHeaders)
+		for _, entry := range results {
+			table.Append(serializeRareTLS(entry, c.Bool("network-names")))
+		}
+		table.Render()
+		return nil
+	}
+
+	delim := c.String("delimiter")
+	fmt.Println(strings.Join(rareTLSHeaders, delim))
+	for _, entry := range results {
+		fmt.Println(strings.Join(serializeRareTLS(entry, c.Bool("network-names")), delim))
+	}
+	return nil
+}
+
+var rareTLSHeaders = []string{"Score", "Source Hosts", "SNI", "JA3", "JA3S", "SNI Hosts", "Fleet Size", "Connections"}
+
+// serializeRareTLS formats a rare TLS combination as a row of rareTLSHeaders
+func serializeRareTLS(entry raretls.Result, showNetNames bool) []string {
+	var hosts []string
+	for _, host := range entry.Hosts {
+		if showNetNames {
+			escapedNetName := strings.ReplaceAll(host.NetworkName, " ", "_")
+			escapedNetName = strings.ReplaceAll(escapedNetName, ":", "_")
+			hosts = append(hosts, escapedNetName+":"+host.IP)
+		} else {
+			hosts = append(hosts, host.IP)
+		}
+	}
+	sort.Strings(hosts)
+
+	ja3s := entry.JA3S
+	if ja3s == "" {
+		ja3s = "-"
+	}
+
+	return []string{
+		strconv.FormatFloat(entry.Score, 'f', 3, 64),
+		strings.Join(hosts, " "),
+		entry.FQDN,
+		entry.JA3,
+		ja3s,
+		strconv.Itoa(entry.SNIHosts),
+		strconv.Itoa(entry.FleetSize),
+		strconv.FormatInt(entry.Connections, 10),
+	}
+}
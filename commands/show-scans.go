@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/scan"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	command := cli.Command{
+		Name:      "show-scans",
+		Usage:     "Print hosts which scanned many ports on one host or many hosts on one port",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			cli.StringFlag{
+				Name:  "type, t",
+				Usage: "Only print scans of the given `TYPE` (vertical or horizontal)",
+			},
+			limitFlag,
+			noLimitFlag,
+			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
+			netNamesFlag,
+		},
+		Action: func(c *cli.Context) error {
+			db := c.Args().Get(0)
+			if db == "" {
+				return cli.NewExitError("Specify a database", -1)
+			}
+
+			scanType := c.String("type")
+			if scanType != "" && scanType != scan.VerticalScan && scanType != scan.HorizontalScan {
+				return cli.NewExitError("Scan type must be vertical or horizontal", -1)
+			}
+
+			res := resources.InitResources(getConfigFilePath(c))
+			res.DB.SelectDB(db)
+
+			data, err := scan.Results(res, scanType, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
+
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			data = sortByCriticality(c, data)
+
+			if !(len(data) > 0) {
+				return cli.NewExitError("No results were found for "+db, -1)
+			}
+
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
+			if c.Bool("human-readable") {
+				err := showScansHuman(data, c.Bool("network-names"), showAssets)
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+			err = showScans(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
+			if err != nil {
+				return cli.NewExitError(err.Error(), -1)
+			}
+			return nil
+		},
+	}
+	bootstrapCommands(command)
+}
+
+func scanHeaders(showNetNames, showAssets bool) []string {
+	headers := []string{
+		"Score", "Type", "Source IP", "Scanned",
+		"Targets", "Connections", "Failed", "Windows", "Start", "End", "Sample Targets",
+	}
+	if showNetNames {
+		headers = []string{
+			"Score", "Type", "Source Network", "Source IP", "Destination Network", "Scanned",
+			"Targets", "Connections", "Failed", "Windows", "Start", "End", "Sample Targets",
+		}
+	}
+	if showAssets {
+		headers = append(headers, assetHeaders("Source")...)
+		headers = append(headers, assetHeaders("Destination")...)
+	}
+	return headers
+}
+
+// scannedTarget describes what was scanned: the destination of a vertical
+// scan or the port of a horizontal scan
+func scannedTarget(result scan.Result) string {
+	if result.Type == scan.VerticalScan {
+		return result.DstIP
+	}
+	return strconv.Itoa(result.Port) + ":" + result.Proto
+}
+
+func scanRow(result scan.Result, start, end string, showNetNames, showAssets bool) []string {
+	row := []string{
+		f(result.Score), result.Type, result.SrcIP, scannedTarget(result),
+		i(result.Targets), i(result.Connections), i(result.FailedConnections), strconv.Itoa(result.Windows),
+		start, end, strings.Join(result.SampleTargets, " "),
+	}
+	if showNetNames {
+		row = []string{
+			f(result.Score), result.Type, result.SrcNetworkName, result.SrcIP, result.DstNetworkName, scannedTarget(result),
+			i(result.Targets), i(result.Connections), i(result.FailedConnections), strconv.Itoa(result.Windows),
+			start, end, strings.Join(result.SampleTargets, " "),
+		}
+	}
+	if showAssets {
+		row = append(row, assetColumns(result.SrcAsset)...)
+		row = append(row, assetColumns(result.DstAsset)...)
+	}
+	return row
+}
+
+func showScans(results []scan.Result, delim string, showNetNames, showAssets bool) error {
+	// Print the headers and analytic values, separated by a delimiter
+	fmt.Println(strings.Join(scanHeaders(showNetNames, showAssets), delim))
+	for _, result := range results {
+		fmt.Println(strings.Join(scanRow(result, i(result.Start), i(result.End), showNetNames, showAssets), delim))
+	}
+	return nil
+}
+
+func showScansHuman(results []scan.Result, showNetNames, showAssets bool) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(scanHeaders(showNetNames, showAssets))
+	for _, result := range results {
+		start := time.Unix(result.Start, 0).UTC().Format(time.RFC3339)
+		end := time.Unix(result.End, 0).UTC().Format(time.RFC3339)
+		table.Append(scanRow(result, start, end, showNetNames, showAssets))
+	}
+	table.Render()
+	return nil
+}
@@ -27,6 +27,9 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
+			criticalityFlag,
 			netNamesFlag,
 		},
 		Action: func(c *cli.Context) error {
@@ -43,25 +46,41 @@ func init() {
 				sortDirection = 1
 			}
 
-			data, err := beacon.StrobeResults(res, sortDirection, c.Int("limit"), c.Bool("no-limit"))
+			data, err := beacon.StrobeResults(res, sortDirection, c.Int("limit"), queryNoLimit(c), c.Bool("suppressed"))
 
 			if err != nil {
 				res.Log.Error(err)
 				return cli.NewExitError(err, -1)
 			}
 
+			data = sortByCriticality(c, data)
+
 			if len(data) == 0 {
 				return cli.NewExitError("No results were found for "+db, -1)
 			}
 
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
+			showAssets, err := hasAssets(res)
+			if err != nil {
+				res.Log.Error(err)
+				return cli.NewExitError(err, -1)
+			}
+
 			if c.Bool("human-readable") {
-				err := showStrobesHuman(data, c.Bool("network-names"))
+				err := showStrobesHuman(data, c.Bool("network-names"), showAssets)
 				if err != nil {
 					return cli.NewExitError(err.Error(), -1)
 				}
 				return nil
 			}
-			err = showStrobes(data, c.String("delimiter"), c.Bool("network-names"))
+			err = showStrobes(data, c.String("delimiter"), c.Bool("network-names"), showAssets)
 			if err != nil {
 				return cli.NewExitError(err.Error(), -1)
 			}
@@ -71,13 +90,17 @@ func init() {
 	bootstrapCommands(command)
 }
 
-func showStrobes(strobes []beacon.StrobeResult, delim string, showNetNames bool) error {
+func showStrobes(strobes []beacon.StrobeResult, delim string, showNetNames, showAssets bool) error {
 	var headerFields []string
 	if showNetNames {
 		headerFields = []string{"Source Network", "Destination Network", "Source", "Destination", "Connection Count"}
 	} else {
 		headerFields = []string{"Source", "Destination", "Connection Count"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 
 	// Print the headers and analytic values, separated by a delimiter
 	fmt.Println(strings.Join(headerFields, delim))
@@ -98,12 +121,16 @@ func showStrobes(strobes []beacon.StrobeResult, delim string, showNetNames bool)
 				i(strobe.ConnectionCount),
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(strobe.SrcAsset)...)
+			row = append(row, assetColumns(strobe.DstAsset)...)
+		}
 		fmt.Println(strings.Join(row, delim))
 	}
 	return nil
 }
 
-func showStrobesHuman(strobes []beacon.StrobeResult, showNetNames bool) error {
+func showStrobesHuman(strobes []beacon.StrobeResult, showNetNames, showAssets bool) error {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetColWidth(100)
 
@@ -113,6 +140,10 @@ func showStrobesHuman(strobes []beacon.StrobeResult, showNetNames bool) error {
 	} else {
 		headerFields = []string{"Source", "Destination", "Connection Count"}
 	}
+	if showAssets {
+		headerFields = append(headerFields, assetHeaders("Source")...)
+		headerFields = append(headerFields, assetHeaders("Destination")...)
+	}
 	table.SetHeader(headerFields)
 
 	for _, strobe := range strobes {
@@ -132,6 +163,10 @@ func showStrobesHuman(strobes []beacon.StrobeResult, showNetNames bool) error {
 				i(strobe.ConnectionCount),
 			}
 		}
+		if showAssets {
+			row = append(row, assetColumns(strobe.SrcAsset)...)
+			row = append(row, assetColumns(strobe.DstAsset)...)
+		}
 		table.Append(row)
 	}
 	table.Render()
@@ -27,6 +27,8 @@ func init() {
 			limitFlag,
 			noLimitFlag,
 			delimFlag,
+			outputFlag,
+			suppressedFlag,
 		},
 		Action: func(c *cli.Context) error {
 			db := c.Args().Get(0)
@@ -42,7 +44,7 @@ func init() {
 				sortDirection = -1
 			}
 
-			data, err := useragent.Results(res, sortDirection, c.Int("limit"), c.Bool("no-limit"))
+			data, err := useragent.Results(res, sortDirection, c.Int("limit"), c.Bool("no-limit"), c.Bool("suppressed"))
 
 			if err != nil {
 				res.Log.Error(err)
@@ -53,6 +55,14 @@ func init() {
 				return cli.NewExitError("No results were found for "+db, -1)
 			}
 
+			if format := c.String("output"); format != "" {
+				err := printResults(data, format, c.String("delimiter"))
+				if err != nil {
+					return cli.NewExitError(err.Error(), -1)
+				}
+				return nil
+			}
+
 			if c.Bool("human-readable") {
 				err := showAgentsHuman(data)
 				if err != nil {
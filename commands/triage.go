@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	triage := cli.Command{
+		Name:      "triage",
+		Usage:     "Record the triage status, owner, and notes for a finding",
+		ArgsUsage: "<database>",
+		Description: "Findings are identified by a source and destination IP (beacons), a source IP and FQDN " +
+			"(SNI, proxy, and DNS beacons), a lone FQDN (blacklisted hostnames), or a lone source or destination IP " +
+			"(blacklisted IPs). Triaging a destination IP or FQDN on its own applies to every source which contacted it. " +
+			"Private IPs seen by a Zeek sensor with a network UUID must be qualified with --src-network-uuid or --dst-network-uuid. " +
+			"Findings marked " + database.TriageBenign + " are hidden by the show commands and the html report.",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			cli.BoolFlag{
+				Name:  "global, g",
+				Usage: "Apply the triage entry to every database instead of a single database",
+			},
+			cli.StringFlag{
+				Name:  "src",
+				Usage: "Source `IP` of the finding",
+			},
+			cli.StringFlag{
+				Name:  "src-network-uuid",
+				Usage: "Network `UUID` of a private source IP",
+			},
+			cli.StringFlag{
+				Name:  "dst",
+				Usage: "Destination `IP` of the finding",
+			},
+			cli.StringFlag{
+				Name:  "dst-network-uuid",
+				Usage: "Network `UUID` of a private destination IP",
+			},
+			cli.StringFlag{
+				Name:  "fqdn",
+				Usage: "Hostname or SNI of the finding",
+			},
+			cli.StringFlag{
+				Name:  "status, s",
+				Usage: "Triage `STATUS` of the finding (" + strings.Join(database.TriageStatuses, ", ") + ")",
+			},
+			cli.StringFlag{
+				Name:  "owner, w",
+				Usage: "Analyst responsible for the finding",
+			},
+			cli.StringFlag{
+				Name:  "notes, n",
+				Usage: "Notes explaining the triage status",
+			},
+			cli.BoolFlag{
+				Name:  "remove",
+				Usage: "Remove the triage entry for the finding",
+			},
+		},
+		Action: setTriage,
+	}
+
+	showTriage := cli.Command{
+		Name:      "show-triage",
+		Usage:     "Print the triage entries which apply to a database",
+		ArgsUsage: "<database>",
+		Flags: []cli.Flag{
+			ConfigFlag,
+			humanFlag,
+			delimFlag,
+			cli.BoolFlag{
+				Name:  "global, g",
+				Usage: "Only print the triage entries which apply to every database",
+			},
+		},
+		Action: showTriage,
+	}
+
+	bootstrapCommands(triage, showTriage)
+}
+
+// parseTriageArgs reads the database and finding identifiers from the command line
+func parseTriageArgs(c *cli.Context) (string, database.TriageKey, error) {
+	db := c.Args().Get(0)
+	if c.Bool("global") {
+		if db != "" {
+			return "", database.TriageKey{}, cli.NewExitError("Specify either a database or --global", -1)
+		}
+	} else if db == "" {
+		return "", database.TriageKey{}, cli.NewExitError("Specify a database or --global", -1)
+	}
+
+	src := strings.TrimSpace(c.String("src"))
+	dst := strings.TrimSpace(c.String("dst"))
+	fqdn := strings.ToLower(strings.TrimSpace(c.String("fqdn")))
+
+	if src == "" && dst == "" && fqdn == "" {
+		return "", database.TriageKey{}, cli.NewExitError("Specify the finding with --src, --dst, and/or --fqdn", -1)
+	}
+	if dst != "" && fqdn != "" {
+		return "", database.TriageKey{}, cli.NewExitError("Specify either --dst or --fqdn, not both", -1)
+	}
+
+	key := database.TriageKey{FQDN: fqdn}
+	if src != "" {
+		srcIP, err := parseTriageIP(src, strings.TrimSpace(c.String("src-network-uuid")))
+		if err != nil {
+			return "", key, cli.NewExitError(err.Error(), -1)
+		}
+		srcKey := database.NewSrcTriageKey(srcIP)
+		key.Src, key.SrcNetworkUUID = srcKey.Src, srcKey.SrcNetworkUUID
+	} else if c.IsSet("src-network-uuid") {
+		return "", key, cli.NewExitError("--src-network-uuid requires --src", -1)
+	}
+	if dst != "" {
+		dstIP, err := parseTriageIP(dst, strings.TrimSpace(c.String("dst-network-uuid")))
+		if err != nil {
+			return "", key, cli.NewExitError(err.Error(), -1)
+		}
+		dstKey := database.NewDstTriageKey(dstIP)
+		key.Dst, key.DstNetworkUUID = dstKey.Dst, dstKey.DstNetworkUUID
+	} else if c.IsSet("dst-network-uuid") {
+		return "", key, cli.NewExitError("--dst-network-uuid requires --dst", -1)
+	}
+
+	return db, key, nil
+}
+
+// parseTriageIP converts an IP given on the command line to the UniqueIP RITA records
+// for it. Publicly routable IPs are bound to the public network. Private IPs are bound
+// to the given network UUID or the unknown private network if it is empty.
+func parseTriageIP(ip, networkUUID string) (data.UniqueIP, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return data.UniqueIP{}, fmt.Errorf("invalid IP address: %s", ip)
+	}
+
+	// use the canonical form so the key matches the IPs stored by the import
+	unique := data.UniqueIP{IP: parsed.String(), NetworkUUID: util.UnknownPrivateNetworkUUID}
+	if util.IPIsPubliclyRoutable(parsed) {
+		unique.NetworkUUID = util.PublicNetworkUUID
+	} else if networkUUID != "" {
+		id, err := uuid.Parse(networkUUID)
+		if err != nil {
+			return data.UniqueIP{}, fmt.Errorf("invalid network UUID: %s", networkUUID)
+		}
+		unique.NetworkUUID = bson.Binary{Kind: bson.BinaryUUID, Data: id[:]}
+	}
+	return unique, nil
+}
+
+func setTriage(c *cli.Context) error {
+	db, key, err := parseTriageArgs(c)
+	if err != nil {
+		return err
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+
+	if db != "" {
+		exists, err := res.MetaDB.DBExists(db)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		if !exists {
+			return cli.NewExitError("No database named "+db, -1)
+		}
+	}
+
+	if c.Bool("remove") {
+		err = res.MetaDB.RemoveTriage(db, key)
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+		return nil
+	}
+
+	// start from the existing entry so that flags which aren't given are left untouched
+	entries, err := res.MetaDB.GetTriage(db)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	entry := database.TriageEntry{Database: db, TriageKey: key}
+	for _, existing := range entries {
+		if existing.Database == db && existing.TriageKey == key {
+			entry = existing
+			break
+		}
+	}
+
+	if c.IsSet("status") {
+		entry.Status = strings.ToLower(c.String("status"))
+	}
+	if c.IsSet("owner") {
+		entry.Owner = c.String("owner")
+	}
+	if c.IsSet("notes") {
+		entry.Notes = c.String("notes")
+	}
+
+	if !database.IsTriageStatus(entry.Status) {
+		return cli.NewExitError("Specify a --status of "+strings.Join(database.TriageStatuses, ", "), -1)
+	}
+
+	entry.Updated = time.Now()
+
+	err = res.MetaDB.SetTriage(entry)
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+	return nil
+}
+
+func showTriage(c *cli.Context) error {
+	db := c.Args().Get(0)
+	if db == "" && !c.Bool("global") {
+		return cli.NewExitError("Specify a database or --global", -1)
+	}
+	if c.Bool("global") {
+		db = ""
+	}
+
+	res := resources.InitResources(getConfigFilePath(c))
+
+	data, err := res.MetaDB.GetTriage(db)
+	if err != nil {
+		res.Log.Error(err)
+		return cli.NewExitError(err, -1)
+	}
+
+	if len(data) == 0 {
+		return cli.NewExitError("No triage entries were found", -1)
+	}
+
+	if c.Bool("human-readable") {
+		return showTriageHuman(data)
+	}
+	return showTriageDelim(data, c.String("delimiter"))
+}
+
+var triageHeader = []string{
+	"Database", "Source IP", "Source Network UUID", "Destination IP", "Destination Network UUID",
+	"FQDN", "Status", "Owner", "Notes", "Updated",
+}
+
+func triageRow(entry database.TriageEntry) []string {
+	db := entry.Database
+	if db == "" {
+		db = "(global)"
+	}
+	return []string{
+		db, entry.Src, entry.SrcNetworkUUID, entry.Dst, entry.DstNetworkUUID,
+		entry.FQDN, entry.Status, entry.Owner, entry.Notes,
+		entry.Updated.Format(time.RFC3339),
+	}
+}
+
+func showTriageHuman(data []database.TriageEntry) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(triageHeader)
+	for _, entry := range data {
+		table.Append(triageRow(entry))
+	}
+	table.Render()
+	return nil
+}
+
+func showTriageDelim(data []database.TriageEntry, delim string) error {
+	fmt.Println(strings.Join(triageHeader, delim))
+	for _, entry := range data {
+		fmt.Println(strings.Join(triageRow(entry), delim))
+	}
+	return nil
+}
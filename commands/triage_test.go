@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestParseTriageIP(t *testing.T) {
+	ip, err := parseTriageIP("FD00:0:0::1", "")
+	require.NoError(t, err)
+	require.Equal(t, "fd00::1", ip.IP)
+	require.Equal(t, util.UnknownPrivateNetworkUUID, ip.NetworkUUID)
+
+	// public IPs are always bound to the public network
+	ip, err = parseTriageIP("8.8.8.8", "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d")
+	require.NoError(t, err)
+	require.Equal(t, util.PublicNetworkUUID, ip.NetworkUUID)
+
+	ip, err = parseTriageIP("10.0.0.1", "A1B2C3D4-E5F6-4A5B-8C7D-9E8F7A6B5C4D")
+	require.NoError(t, err)
+	require.Equal(t, "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d", database.NewSrcTriageKey(ip).SrcNetworkUUID)
+
+	_, err = parseTriageIP("10.0.0.256", "")
+	require.Error(t, err)
+	_, err = parseTriageIP("10.0.0.1", "not-a-uuid")
+	require.Error(t, err)
+}
+
+func TestParseTriageArgs(t *testing.T) {
+	newContext := func(args ...string) *cli.Context {
+		set := flag.NewFlagSet("triage", flag.ContinueOnError)
+		for _, name := range []string{"src", "src-network-uuid", "dst", "dst-network-uuid", "fqdn"} {
+			set.String(name, "", "")
+		}
+		set.Bool("global", false, "")
+		require.NoError(t, set.Parse(args))
+		return cli.NewContext(nil, set, nil)
+	}
+
+	db, key, err := parseTriageArgs(newContext(
+		"--src", "10.0.0.1", "--src-network-uuid", "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d",
+		"--dst", "2001:0DB8::0001", "dataset",
+	))
+	require.NoError(t, err)
+	require.Equal(t, "dataset", db)
+	require.Equal(t, database.TriageKey{
+		Src: "10.0.0.1", SrcNetworkUUID: "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d",
+		Dst: "2001:db8::1", DstNetworkUUID: "ffffffff-ffff-ffff-ffff-ffffffffffff",
+	}, key)
+
+	_, key, err = parseTriageArgs(newContext("--global", "--src", "192.168.1.1", "--fqdn", "Example.COM"))
+	require.NoError(t, err)
+	require.Equal(t, database.TriageKey{
+		Src: "192.168.1.1", SrcNetworkUUID: "ffffffff-ffff-ffff-ffff-fffffffffffe", FQDN: "example.com",
+	}, key)
+
+	_, _, err = parseTriageArgs(newContext("--dst-network-uuid", "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d", "--fqdn", "example.com", "dataset"))
+	require.Error(t, err)
+	_, _, err = parseTriageArgs(newContext("--dst", "1.2.3.4", "--fqdn", "example.com", "dataset"))
+	require.Error(t, err)
+	_, _, err = parseTriageArgs(newContext("dataset"))
+	require.Error(t, err)
+}
@@ -31,21 +31,29 @@ const minBeaconConnectionThreshLimit int = 23
 type (
 	//StaticCfg is the container for other static config sections
 	StaticCfg struct {
-		UserConfig   UserCfgStaticCfg     `yaml:"UserConfig"`
-		MongoDB      MongoDBStaticCfg     `yaml:"MongoDB"`
-		Rolling      RollingStaticCfg     `yaml:"Rolling"`
-		Log          LogStaticCfg         `yaml:"LogConfig"`
-		Blacklisted  BlacklistedStaticCfg `yaml:"BlackListed"`
-		Beacon       BeaconStaticCfg      `yaml:"Beacon"`
-		BeaconProxy  BeaconProxyStaticCfg `yaml:"BeaconProxy"`
-		BeaconSNI    BeaconSNIStaticCfg   `yaml:"BeaconSNI"`
-		DNS          DNSStaticCfg         `yaml:"DNS"`
-		UserAgent    UserAgentStaticCfg   `yaml:"UserAgent"`
-		Bro          BroStaticCfg         `yaml:"Bro"` // kept in for MetaDB backwards compatibility
-		Filtering    FilteringStaticCfg   `yaml:"Filtering"`
-		Strobe       StrobeStaticCfg      `yaml:"Strobe"`
-		Version      string
-		ExactVersion string
+		UserConfig      UserCfgStaticCfg         `yaml:"UserConfig"`
+		MongoDB         MongoDBStaticCfg         `yaml:"MongoDB"`
+		Rolling         RollingStaticCfg         `yaml:"Rolling"`
+		Log             LogStaticCfg             `yaml:"LogConfig"`
+		Blacklisted     BlacklistedStaticCfg     `yaml:"BlackListed"`
+		Beacon          BeaconStaticCfg          `yaml:"Beacon"`
+		BeaconProxy     BeaconProxyStaticCfg     `yaml:"BeaconProxy"`
+		BeaconSNI       BeaconSNIStaticCfg       `yaml:"BeaconSNI"`
+		BeaconDNS       BeaconDNSStaticCfg       `yaml:"BeaconDNS"`
+		DNS             DNSStaticCfg             `yaml:"DNS"`
+		DNSTunnel       DNSTunnelStaticCfg       `yaml:"DNSTunnel"`
+		Exfil           ExfilStaticCfg           `yaml:"Exfil"`
+		UserAgent       UserAgentStaticCfg       `yaml:"UserAgent"`
+		RareTLS         RareTLSStaticCfg         `yaml:"RareTLS"`
+		Scan            ScanStaticCfg            `yaml:"Scan"`
+		LateralMovement LateralMovementStaticCfg `yaml:"LateralMovement"`
+		Bro             BroStaticCfg             `yaml:"Bro"` // kept in for MetaDB backwards compatibility
+		Filtering       FilteringStaticCfg       `yaml:"Filtering"`
+		Strobe          StrobeStaticCfg          `yaml:"Strobe"`
+		GeoIP           GeoIPStaticCfg           `yaml:"GeoIP"`
+		ThreatScore     ThreatScoreStaticCfg     `yaml:"ThreatScore"`
+		Version         string
+		ExactVersion    string
 	}
 
 	//MongoDBStaticCfg contains the means for connecting to MongoDB
@@ -97,6 +105,10 @@ type (
 		BlacklistDatabase  string   `yaml:"BlacklistDatabase" default:"rita-bl"`
 		IPBlacklists       []string `yaml:"CustomIPBlacklists" default:"[]"`
 		HostnameBlacklists []string `yaml:"CustomHostnameBlacklists" default:"[]"`
+		ThreatFeeds        []string `yaml:"ThreatFeeds" default:"[]"`
+		JA3Blacklists      []string `yaml:"CustomJA3Blacklists" default:"[]"`
+		JA3SBlacklists     []string `yaml:"CustomJA3SBlacklists" default:"[]"`
+		CertBlacklists     []string `yaml:"CustomCertSHA1Blacklists" default:"[]"`
 	}
 
 	//BeaconStaticCfg is used to control the beaconing analysis module
@@ -107,6 +119,7 @@ type (
 		DsWeight                     float64 `yaml:"DatasizeScoreWeight" default:"0.25"`
 		DurWeight                    float64 `yaml:"DurationScoreWeight" default:"0.25"`
 		HistWeight                   float64 `yaml:"HistogramScoreWeight" default:"0.25"`
+		PeriodogramWeight            float64 `yaml:"PeriodogramScoreWeight" default:"0"`
 		DurMinHoursSeen              int     `yaml:"DurationMinHoursSeen" default:"6"`
 		DurConsistencyIdealHoursSeen int     `yaml:"DurationConsistencyIdealHoursSeen" default:"12"`
 		HistBimodalBucketSize        float64 `yaml:"HistogramBimodalBucketSize" default:"0.05"`
@@ -143,16 +156,72 @@ type (
 		HistBimodalMinHoursSeen      int     `yaml:"HistogramBimodalMinHoursSeen" default:"11"`
 	}
 
+	//BeaconDNSStaticCfg is used to control the DNS beaconing analysis module
+	BeaconDNSStaticCfg struct {
+		Enabled                      bool    `yaml:"Enabled" default:"true"`
+		DefaultConnectionThresh      int     `yaml:"DefaultConnectionThresh" default:"23"`
+		TsWeight                     float64 `yaml:"TimestampScoreWeight" default:"0.333"`
+		DurWeight                    float64 `yaml:"DurationScoreWeight" default:"0.333"`
+		HistWeight                   float64 `yaml:"HistogramScoreWeight" default:"0.333"`
+		DurMinHoursSeen              int     `yaml:"DurationMinHoursSeen" default:"6"`
+		DurConsistencyIdealHoursSeen int     `yaml:"DurationConsistencyIdealHoursSeen" default:"12"`
+		HistBimodalBucketSize        float64 `yaml:"HistogramBimodalBucketSize" default:"0.05"`
+		HistBimodalOutlierRemoval    int     `yaml:"HistogramBimodalOutlierRemoval" default:"1"`
+		HistBimodalMinHoursSeen      int     `yaml:"HistogramBimodalMinHoursSeen" default:"11"`
+		GroupByRegisteredDomain      bool    `yaml:"GroupByRegisteredDomain" default:"false"`
+	}
+
 	//DNSStaticCfg is used to control the DNS analysis module
 	DNSStaticCfg struct {
 		Enabled bool `yaml:"Enabled" default:"true"`
 	}
 
+	//DNSTunnelStaticCfg is used to control the DNS tunneling analysis module
+	DNSTunnelStaticCfg struct {
+		Enabled               bool    `yaml:"Enabled" default:"true"`
+		MinQueryCount         int64   `yaml:"MinQueryCount" default:"50"`
+		EntropyWeight         float64 `yaml:"EntropyScoreWeight" default:"0.2"`
+		QueryLengthWeight     float64 `yaml:"QueryLengthScoreWeight" default:"0.2"`
+		UniqueSubdomainWeight float64 `yaml:"UniqueSubdomainScoreWeight" default:"0.2"`
+		TXTNullWeight         float64 `yaml:"TXTNullScoreWeight" default:"0.2"`
+		NXDomainWeight        float64 `yaml:"NXDomainScoreWeight" default:"0.2"`
+	}
+
+	//ExfilStaticCfg is used to control the data exfiltration analysis module
+	ExfilStaticCfg struct {
+		Enabled         bool    `yaml:"Enabled" default:"true"`
+		MinUploadBytes  int64   `yaml:"MinUploadBytes" default:"1000000"`
+		AsymmetryWeight float64 `yaml:"AsymmetryScoreWeight" default:"0.4"`
+		VolumeWeight    float64 `yaml:"VolumeScoreWeight" default:"0.4"`
+		TimeOfDayWeight float64 `yaml:"TimeOfDayScoreWeight" default:"0.2"`
+	}
+
 	//UserAgentStaticCfg is used to control the User Agent analysis module
 	UserAgentStaticCfg struct {
 		Enabled bool `yaml:"Enabled" default:"true"`
 	}
 
+	//RareTLSStaticCfg controls which JA3, JA3S, and SNI combinations are considered rare
+	RareTLSStaticCfg struct {
+		MaxComboHosts int `yaml:"MaxComboHosts" default:"2"`
+		MaxSNIHosts   int `yaml:"MaxSNIHosts" default:"5"`
+	}
+
+	//ScanStaticCfg is used to control the port scan analysis module
+	ScanStaticCfg struct {
+		Enabled             bool    `yaml:"Enabled" default:"true"`
+		VerticalThreshold   float64 `yaml:"VerticalThreshold" default:"100"`
+		HorizontalThreshold float64 `yaml:"HorizontalThreshold" default:"100"`
+	}
+
+	//LateralMovementStaticCfg is used to control the lateral movement analysis module
+	LateralMovementStaticCfg struct {
+		Enabled         bool    `yaml:"Enabled" default:"false"`
+		Ports           []int   `yaml:"Ports" default:"[445, 3389, 5985, 5986, 22, 135]"`
+		FanOutThreshold int     `yaml:"FanOutThreshold" default:"10"`
+		FanOutRatio     float64 `yaml:"FanOutRatio" default:"3"`
+	}
+
 	//FilteringStaticCfg controls address filtering
 	FilteringStaticCfg struct {
 		AlwaysInclude            []string `yaml:"AlwaysInclude" default:"[]"`
@@ -167,6 +236,27 @@ type (
 	StrobeStaticCfg struct {
 		ConnectionLimit int `yaml:"ConnectionLimit" default:"86400"`
 	}
+
+	//GeoIPStaticCfg controls the enrichment of hosts with MaxMind format country and ASN databases
+	GeoIPStaticCfg struct {
+		Enabled         bool   `yaml:"Enabled" default:"false"`
+		CountryDatabase string `yaml:"CountryDatabase" default:""`
+		ASNDatabase     string `yaml:"ASNDatabase" default:""`
+	}
+
+	//ThreatScoreStaticCfg controls the combined threat score computed for each internal host
+	ThreatScoreStaticCfg struct {
+		Enabled             bool    `yaml:"Enabled" default:"true"`
+		BeaconWeight        float64 `yaml:"BeaconScoreWeight" default:"0.2"`
+		BeaconSNIWeight     float64 `yaml:"BeaconSNIScoreWeight" default:"0.1"`
+		BeaconProxyWeight   float64 `yaml:"BeaconProxyScoreWeight" default:"0.1"`
+		BeaconDNSWeight     float64 `yaml:"BeaconDNSScoreWeight" default:"0.1"`
+		LongConnWeight      float64 `yaml:"LongConnectionScoreWeight" default:"0.15"`
+		ExfilWeight         float64 `yaml:"ExfilScoreWeight" default:"0.1"`
+		BlacklistedWeight   float64 `yaml:"BlacklistedScoreWeight" default:"0.15"`
+		RareSignatureWeight float64 `yaml:"RareSignatureScoreWeight" default:"0.1"`
+		LongConnHours       float64 `yaml:"LongConnectionHours" default:"12"`
+	}
 )
 
 // readStaticConfigFile attempts to read the contents of the
@@ -221,6 +311,11 @@ func parseStaticConfig(cfgFile []byte, config *StaticCfg) error {
 		config.BeaconProxy.DefaultConnectionThresh = minBeaconConnectionThreshLimit
 	}
 
+	// limit the beacon dns threshold to the minimum allowed
+	if config.BeaconDNS.DefaultConnectionThresh < minBeaconConnectionThreshLimit {
+		config.BeaconDNS.DefaultConnectionThresh = minBeaconConnectionThreshLimit
+	}
+
 	// make sure value is above zero to avoid division by zero
 	if config.Beacon.DurConsistencyIdealHoursSeen < 1 {
 		config.Beacon.DurConsistencyIdealHoursSeen = 1
@@ -231,6 +326,9 @@ func parseStaticConfig(cfgFile []byte, config *StaticCfg) error {
 	if config.BeaconSNI.DurConsistencyIdealHoursSeen < 1 {
 		config.BeaconSNI.DurConsistencyIdealHoursSeen = 1
 	}
+	if config.BeaconDNS.DurConsistencyIdealHoursSeen < 1 {
+		config.BeaconDNS.DurConsistencyIdealHoursSeen = 1
+	}
 
 	// expand env variables, config is a pointer
 	// so we have to call elem on the reflect value
@@ -241,6 +339,12 @@ func parseStaticConfig(cfgFile []byte, config *StaticCfg) error {
 
 	// clean all filepaths
 	config.Log.RitaLogPath = filepath.Clean(config.Log.RitaLogPath)
+	if config.GeoIP.CountryDatabase != "" {
+		config.GeoIP.CountryDatabase = filepath.Clean(config.GeoIP.CountryDatabase)
+	}
+	if config.GeoIP.ASNDatabase != "" {
+		config.GeoIP.ASNDatabase = filepath.Clean(config.GeoIP.ASNDatabase)
+	}
 
 	// grab the version constants set by the build process
 	config.Version = Version
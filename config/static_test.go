@@ -29,6 +29,10 @@ BlackListed:
     BlacklistDatabase: "rita-bl"
     CustomIPBlacklists: [test1]
     CustomHostnameBlacklists: [test2]
+    ThreatFeeds: [test3]
+    CustomJA3Blacklists: [test4]
+    CustomJA3SBlacklists: [test5]
+    CustomCertSHA1Blacklists: [test6]
 DNS:
     Enabled: true
 Beacon:
@@ -66,6 +70,18 @@ BeaconProxy:
     HistogramBimodalBucketSize: 0.05
     HistogramBimodalOutlierRemoval: 1
     HistogramBimodalMinHoursSeen: 11
+BeaconDNS:
+    Enabled: true
+    DefaultConnectionThresh: 5
+    TimestampScoreWeight: 0.333
+    DurationScoreWeight: 0.333
+    HistogramScoreWeight: 0.333
+    DurationMinHoursSeen: 6
+    DurationConsistencyIdealHoursSeen: 12
+    HistogramBimodalBucketSize: 0.05
+    HistogramBimodalOutlierRemoval: 1
+    HistogramBimodalMinHoursSeen: 11
+    GroupByRegisteredDomain: true
 Strobe:
     ConnectionLimit: 250000
 Filtering:
@@ -75,6 +91,13 @@ Filtering:
     AlwaysIncludeDomain: ["bad.com", "google.com", "*.myotherdomain.com"]
     NeverIncludeDomain: ["good.com", "google.com", "*.mydomain.com"]
     FilterExternalToInternal: true
+GeoIP:
+    Enabled: true
+    CountryDatabase: /usr/share/GeoIP/GeoLite2-Country.mmdb
+    ASNDatabase: /usr/share/GeoIP/GeoLite2-ASN.mmdb
+RareTLS:
+    MaxComboHosts: 1
+    MaxSNIHosts: 3
 `
 
 var testConfigFullExp = StaticCfg{
@@ -103,6 +126,10 @@ var testConfigFullExp = StaticCfg{
 		BlacklistDatabase:  "rita-bl",
 		IPBlacklists:       []string{"test1"},
 		HostnameBlacklists: []string{"test2"},
+		ThreatFeeds:        []string{"test3"},
+		JA3Blacklists:      []string{"test4"},
+		JA3SBlacklists:     []string{"test5"},
+		CertBlacklists:     []string{"test6"},
 	},
 	DNS: DNSStaticCfg{
 		Enabled: true,
@@ -145,6 +172,19 @@ var testConfigFullExp = StaticCfg{
 		HistBimodalOutlierRemoval:    1,
 		HistBimodalMinHoursSeen:      11,
 	},
+	BeaconDNS: BeaconDNSStaticCfg{
+		Enabled:                      true,
+		DefaultConnectionThresh:      minBeaconConnectionThreshLimit,
+		TsWeight:                     0.333,
+		DurWeight:                    0.333,
+		HistWeight:                   0.333,
+		DurMinHoursSeen:              6,
+		DurConsistencyIdealHoursSeen: 12,
+		HistBimodalBucketSize:        0.05,
+		HistBimodalOutlierRemoval:    1,
+		HistBimodalMinHoursSeen:      11,
+		GroupByRegisteredDomain:      true,
+	},
 	Strobe: StrobeStaticCfg{
 		ConnectionLimit: maxStrobeConnectionLimit,
 	},
@@ -156,6 +196,15 @@ var testConfigFullExp = StaticCfg{
 		NeverIncludeDomain:       []string{"good.com", "google.com", "*.mydomain.com"},
 		FilterExternalToInternal: true,
 	},
+	GeoIP: GeoIPStaticCfg{
+		Enabled:         true,
+		CountryDatabase: "/usr/share/GeoIP/GeoLite2-Country.mmdb",
+		ASNDatabase:     "/usr/share/GeoIP/GeoLite2-ASN.mmdb",
+	},
+	RareTLS: RareTLSStaticCfg{
+		MaxComboHosts: 1,
+		MaxSNIHosts:   3,
+	},
 }
 
 // TestParseStaticConfig ensures that a yaml config
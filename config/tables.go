@@ -9,8 +9,12 @@ type (
 		Beacon      BeaconTableCfg
 		BeaconSNI   BeaconSNITableCfg
 		BeaconProxy BeaconProxyTableCfg
+		BeaconDNS   BeaconDNSTableCfg
 		UserAgent   UserAgentTableCfg
 		Cert        CertificateTableCfg
+		Scan        ScanTableCfg
+		Lateral     LateralTableCfg
+		Exfil       ExfilTableCfg
 		Meta        MetaTableCfg
 	}
 
@@ -29,13 +33,17 @@ type (
 		SSLTable             string `default:"ssl"`
 		UniqueConnTable      string `default:"uconn"`
 		UniqueConnProxyTable string `default:"uconnProxy"`
+		UniqueConnDNSTable   string `default:"uconnDNS"`
 		SNIConnTable         string `default:"SNIconn"`
+		X509Table            string `default:"x509"`
+		EveTable             string `default:"eve"`
 	}
 
 	//DNSTableCfg is used to control the dns analysis module
 	DNSTableCfg struct {
 		ExplodedDNSTable string `default:"explodedDns"`
 		HostnamesTable   string `default:"hostnames"`
+		DNSTunnelTable   string `default:"dnsTunnel"`
 	}
 
 	//BeaconTableCfg is used to control the beaconing analysis module
@@ -53,6 +61,11 @@ type (
 		BeaconProxyTable string `default:"beaconProxy"`
 	}
 
+	//BeaconDNSTableCfg is used to control the DNS beaconing analysis module
+	BeaconDNSTableCfg struct {
+		BeaconDNSTable string `default:"beaconDNS"`
+	}
+
 	//UserAgentTableCfg is used to control the useragent analysis module
 	UserAgentTableCfg struct {
 		UserAgentTable string `default:"useragent"`
@@ -63,9 +76,27 @@ type (
 		CertificateTable string `default:"cert"`
 	}
 
+	//ScanTableCfg is used to control the port scan analysis module
+	ScanTableCfg struct {
+		ScanTable string `default:"scan"`
+	}
+
+	//LateralTableCfg is used to control the lateral movement analysis module
+	LateralTableCfg struct {
+		LateralTable string `default:"lateral"`
+	}
+
+	//ExfilTableCfg is used to control the data exfiltration analysis module
+	ExfilTableCfg struct {
+		ExfilTable string `default:"exfil"`
+	}
+
 	//MetaTableCfg contains the meta db collection names
 	MetaTableCfg struct {
 		FilesTable     string `default:"files"`
 		DatabasesTable string `default:"databases"`
+		TriageTable    string `default:"triage"`
+		AllowlistTable string `default:"allowlist"`
+		AssetsTable    string `default:"assets"`
 	}
 )
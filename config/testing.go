@@ -64,6 +64,17 @@ BeaconProxy:
     HistogramBimodalBucketSize: 0.05
     HistogramBimodalOutlierRemoval: 1
     HistogramBimodalMinHoursSeen: 11
+BeaconDNS:
+    Enabled: true
+    DefaultConnectionThresh: 23
+    TimestampScoreWeight: 0.333
+    DurationScoreWeight: 0.333
+    HistogramScoreWeight: 0.333
+    DurationMinHoursSeen: 6
+    DurationConsistencyIdealHoursSeen: 12
+    HistogramBimodalBucketSize: 0.05
+    HistogramBimodalOutlierRemoval: 1
+    HistogramBimodalMinHoursSeen: 11
 Strobe:
     ConnectionLimit: 250000
 Filtering:
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AllowlistIP marks an allowlist entry holding an IP address or CIDR range
+	AllowlistIP = "ip"
+	// AllowlistDomain marks an allowlist entry holding a domain, which may start with a wildcard
+	AllowlistDomain = "domain"
+	// AllowlistJA3 marks an allowlist entry holding a TLS client JA3 hash
+	AllowlistJA3 = "ja3"
+	// AllowlistUserAgent marks an allowlist entry holding an HTTP user agent
+	AllowlistUserAgent = "useragent"
+)
+
+// AllowlistTypes lists the valid allowlist entry types
+var AllowlistTypes = []string{AllowlistIP, AllowlistDomain, AllowlistJA3, AllowlistUserAgent}
+
+// AllowlistEntry is a trusted value which suppresses findings when results are queried
+type AllowlistEntry struct {
+	ID      bson.ObjectId `bson:"_id,omitempty"`
+	Type    string        `bson:"type"`
+	Value   string        `bson:"value"`
+	Comment string        `bson:"comment"`
+	Added   time.Time     `bson:"added"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                Allowlist                                  //
+///////////////////////////////////////////////////////////////////////////////
+
+// GetAllowlist returns every entry on the allowlist
+func (m *MetaDB) GetAllowlist() ([]AllowlistEntry, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	var entries []AllowlistEntry
+	err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AllowlistTable).
+		Find(nil).Sort("type", "value").All(&entries)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("could not fetch the allowlist from the meta database")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddAllowlistEntry adds an entry to the allowlist, replacing the comment
+// if the entry is already present
+func (m *MetaDB) AddAllowlistEntry(entry AllowlistEntry) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	_, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AllowlistTable).Upsert(
+		bson.M{"type": entry.Type, "value": entry.Value},
+		bson.M{
+			"$set":         bson.M{"comment": entry.Comment},
+			"$setOnInsert": bson.M{"added": entry.Added},
+		},
+	)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"type":  entry.Type,
+			"value": entry.Value,
+			"error": err.Error(),
+		}).Error("could not add entry to the allowlist")
+	}
+	return err
+}
+
+// RemoveAllowlistEntry removes an entry from the allowlist. Returns false
+// if the entry was not on the allowlist.
+func (m *MetaDB) RemoveAllowlistEntry(entryType, value string) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	info, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AllowlistTable).
+		RemoveAll(bson.M{"type": entryType, "value": value})
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"type":  entryType,
+			"value": value,
+			"error": err.Error(),
+		}).Error("could not remove entry from the allowlist")
+		return false, err
+	}
+	return info.Removed > 0, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/globalsign/mgo/bson"
+	log "github.com/sirupsen/logrus"
+)
+
+// Asset labels the internal hosts in an IP address or CIDR range with
+// details from the organization's asset inventory
+type Asset struct {
+	ID              bson.ObjectId `bson:"_id,omitempty"`
+	Subnet          string        `bson:"subnet"`       // IP address or CIDR range
+	NetworkUUID     string        `bson:"network_uuid"` // empty if the asset applies to every network
+	data.AssetLabel `bson:",inline"`
+	Imported        time.Time `bson:"imported"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                  Assets                                   //
+///////////////////////////////////////////////////////////////////////////////
+
+// GetAssets returns every asset in the inventory
+func (m *MetaDB) GetAssets() ([]Asset, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	var assets []Asset
+	err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AssetsTable).
+		Find(nil).Sort("network_uuid", "subnet").All(&assets)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("could not fetch the asset inventory from the meta database")
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ImportAssets adds the given assets to the inventory, replacing the labels
+// of assets with the same subnet and network UUID. If replace is set, the
+// existing inventory is removed first.
+func (m *MetaDB) ImportAssets(assets []Asset, replace bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	coll := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AssetsTable)
+
+	if replace {
+		_, err := coll.RemoveAll(nil)
+		if err != nil {
+			m.log.WithFields(log.Fields{
+				"error": err.Error(),
+			}).Error("could not clear the asset inventory")
+			return err
+		}
+	}
+
+	bulk := coll.Bulk()
+	bulk.Unordered()
+	for _, asset := range assets {
+		bulk.Upsert(
+			bson.M{"subnet": asset.Subnet, "network_uuid": asset.NetworkUUID},
+			bson.M{"$set": bson.M{
+				"hostname":    asset.Hostname,
+				"owner":       asset.Owner,
+				"role":        asset.Role,
+				"criticality": asset.Criticality,
+				"imported":    asset.Imported,
+			}},
+		)
+	}
+
+	_, err := bulk.Run()
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"count": len(assets),
+			"error": err.Error(),
+		}).Error("could not import assets into the meta database")
+	}
+	return err
+}
+
+// ClearAssets removes every asset from the inventory and returns the number removed
+func (m *MetaDB) ClearAssets() (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	info, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.AssetsTable).RemoveAll(nil)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("could not clear the asset inventory")
+		return 0, err
+	}
+	return info.Removed, nil
+}
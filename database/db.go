@@ -100,6 +100,17 @@ func (d *DB) SelectDB(db string) {
 	d.selected = db
 }
 
+// CopyForDB returns a new DB handle which shares the same session but has
+// the given database selected. This allows concurrent callers to work with
+// different databases without racing on SelectDB.
+func (d *DB) CopyForDB(db string) *DB {
+	return &DB{
+		Session:  d.Session,
+		log:      d.log,
+		selected: db,
+	}
+}
+
 // GetSelectedDB retrieves the currently selected database for analysis
 func (d *DB) GetSelectedDB() string {
 	return d.selected
@@ -183,6 +194,56 @@ func (d *DB) AggregateCollection(sourceCollection string,
 	return iter
 }
 
+// PipePage runs an aggregation pipeline against the collection, skipping the first skip
+// results and returning at most limit of them. The total number of results the pipeline
+// produces is returned as well so clients may page through them.
+func PipePage(collection *mgo.Collection, pipeline []bson.M, skip, limit int) (*mgo.Iter, int, error) {
+	countQuery, pageQuery := pagePipelines(pipeline, skip, limit)
+
+	var count struct {
+		Total int `bson:"total"`
+	}
+	err := collection.Pipe(countQuery).AllowDiskUse().One(&count)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, 0, err
+	}
+
+	return collection.Pipe(pageQuery).AllowDiskUse().Iter(), count.Total, nil
+}
+
+// pagePipelines derives the pipelines which count the results of an aggregation and which
+// select a single page of them. Sorting doesn't change the count, so a trailing $sort stage
+// is left out of the count pipeline.
+func pagePipelines(pipeline []bson.M, skip, limit int) ([]bson.M, []bson.M) {
+	countStages := len(pipeline)
+	if countStages > 0 {
+		if _, ok := pipeline[countStages-1]["$sort"]; ok {
+			countStages--
+		}
+	}
+
+	countQuery := make([]bson.M, 0, countStages+1)
+	countQuery = append(countQuery, pipeline[:countStages]...)
+	countQuery = append(countQuery, bson.M{"$count": "total"})
+
+	pageQuery := make([]bson.M, 0, len(pipeline)+2)
+	pageQuery = append(pageQuery, pipeline...)
+	pageQuery = append(pageQuery, bson.M{"$skip": skip}, bson.M{"$limit": limit})
+
+	return countQuery, pageQuery
+}
+
+// FindPage finds the documents in the collection matching the selector in the given sort
+// order, skipping the first skip documents and returning at most limit of them. The total
+// number of matching documents is returned as well so clients may page through them.
+func FindPage(collection *mgo.Collection, selector bson.M, sort string, skip, limit int) (*mgo.Iter, int, error) {
+	total, err := collection.Find(selector).Count()
+	if err != nil {
+		return nil, 0, err
+	}
+	return collection.Find(selector).Sort(sort).Skip(skip).Limit(limit).Iter(), total, nil
+}
+
 // MergeBSONMaps recursively merges several bson.M objects into a single map.
 // When merging slices of maps with the same associated key, the slices are concatenated.
 // If two or more maps define the same key and they are not both bson.M objects,
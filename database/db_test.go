@@ -0,0 +1,26 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagePipelines(t *testing.T) {
+	match := bson.M{"$match": bson.M{"score": bson.M{"$gt": 0}}}
+	sort := bson.M{"$sort": bson.M{"score": -1}}
+	pipeline := []bson.M{match, sort}
+
+	countQuery, pageQuery := pagePipelines(pipeline, 200, 100)
+	require.Equal(t, []bson.M{match, {"$count": "total"}}, countQuery)
+	require.Equal(t, []bson.M{match, sort, {"$skip": 200}, {"$limit": 100}}, pageQuery)
+
+	// the pipeline is shared with other queries and must not be modified
+	require.Equal(t, []bson.M{match, sort}, pipeline)
+
+	// sorts followed by other stages may change the results and are counted
+	project := bson.M{"$project": bson.M{"_id": 0}}
+	countQuery, _ = pagePipelines([]bson.M{sort, project}, 0, 10)
+	require.Equal(t, []bson.M{sort, project, {"$count": "total"}}, countQuery)
+}
@@ -47,6 +47,13 @@ type (
 		TotalChunks    int           `bson:"total_chunks"`
 		CurrentChunk   int           `bson:"current_chunk"`
 		TsRange        Range         `bson:"ts_range"`
+		CIDList        []ChunkInfo   `bson:"cid_list,omitempty"`
+	}
+
+	// ChunkInfo defines some information about a chunk of a rolling database
+	ChunkInfo struct {
+		Set     bool  `bson:"set"`      // Has data been imported into this chunk
+		TsRange Range `bson:"ts_range"` // Timestamps streamed into this chunk
 	}
 )
 
@@ -236,6 +243,12 @@ func (m *MetaDB) DeleteDB(name string) error {
 		return err
 	}
 
+	//delete any triage entries specific to the database
+	_, err = ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.TriageTable).RemoveAll(bson.M{"database": name})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -398,12 +411,46 @@ func (m *MetaDB) SetChunk(cid int, db string, analyzed bool) error {
 	ssn := m.dbHandle.Copy()
 	defer ssn.Close()
 
+	update := bson.M{
+		"$set": bson.M{
+			"cid_list." + strconv.Itoa(cid) + ".set": analyzed,
+		},
+	}
+	// the chunk no longer holds the streamed time range once it is cleared
+	if !analyzed {
+		update["$unset"] = bson.M{"cid_list." + strconv.Itoa(cid) + ".ts_range": ""}
+	}
+
+	_, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.DatabasesTable).
+		Upsert(bson.M{"name": db}, update)
+
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"metadb_attempted":   m.config.S.MongoDB.MetaDB,
+			"database_requested": db,
+			"error":              err.Error(),
+		}).Error("Could not update CID analyzed value for database entry in metadatabase")
+		return err
+	}
+	return nil
+}
+
+// SetChunkTSRange records the range of timestamps which were streamed into a chunk.
+// Streaming imports use these ranges in place of file hashes to avoid importing
+// the same data twice.
+func (m *MetaDB) SetChunkTSRange(cid int, db string, min int64, max int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
 	_, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.DatabasesTable).
 		Upsert(
 			bson.M{"name": db},
 			bson.M{
 				"$set": bson.M{
-					"cid_list." + strconv.Itoa(cid) + ".set": analyzed,
+					"cid_list." + strconv.Itoa(cid) + ".ts_range": Range{Min: min, Max: max},
 				}},
 		)
 
@@ -411,13 +458,34 @@ func (m *MetaDB) SetChunk(cid int, db string, analyzed bool) error {
 		m.log.WithFields(log.Fields{
 			"metadb_attempted":   m.config.S.MongoDB.MetaDB,
 			"database_requested": db,
+			"cid":                cid,
 			"error":              err.Error(),
-		}).Error("Could not update CID analyzed value for database entry in metadatabase")
+		}).Error("Could not update CID timestamp range for database entry in metadatabase")
 		return err
 	}
 	return nil
 }
 
+// GetChunkTSRanges returns the range of timestamps streamed into each chunk of a database
+func (m *MetaDB) GetChunkTSRanges(db string) (map[int]Range, error) {
+	ranges := make(map[int]Range)
+
+	result, err := m.GetDBMetaInfo(db)
+	if err == mgo.ErrNotFound {
+		return ranges, nil
+	}
+	if err != nil {
+		return ranges, err
+	}
+
+	for cid, chunk := range result.CIDList {
+		if chunk.Set && chunk.TsRange.Max != 0 {
+			ranges[cid] = chunk.TsRange
+		}
+	}
+	return ranges, nil
+}
+
 // IsChunkSet ....
 func (m *MetaDB) IsChunkSet(cid int, db string) (bool, error) {
 	m.lock.Lock()
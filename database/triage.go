@@ -0,0 +1,239 @@
+package database
+
+import (
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TriageBenign marks a finding which was reviewed and found to be harmless.
+	// Benign findings are hidden by the show commands and the html report.
+	TriageBenign = "benign"
+	// TriageInvestigating marks a finding which is still being reviewed
+	TriageInvestigating = "investigating"
+	// TriageEscalated marks a finding which was handed off for incident response
+	TriageEscalated = "escalated"
+)
+
+// TriageStatuses lists the valid triage statuses
+var TriageStatuses = []string{TriageBenign, TriageInvestigating, TriageEscalated}
+
+type (
+	// TriageKey identifies the finding a triage entry applies to. Source and destination
+	// IPs identify a unique connection pair, a source IP and FQDN identify a unique
+	// source to FQDN pair, and a lone FQDN or IP identifies a hostname or host.
+	// Like UniqueIPs, IPs are qualified by the network UUID they were seen on.
+	TriageKey struct {
+		Src            string `bson:"src"`
+		SrcNetworkUUID string `bson:"src_network_uuid"`
+		Dst            string `bson:"dst"`
+		DstNetworkUUID string `bson:"dst_network_uuid"`
+		FQDN           string `bson:"fqdn"`
+	}
+
+	// TriageEntry records the state of an analyst's review of a finding
+	TriageEntry struct {
+		ID        bson.ObjectId `bson:"_id,omitempty"`
+		Database  string        `bson:"database"` // empty if the entry applies to every database
+		TriageKey `bson:",inline"`
+		Status    string    `bson:"status"`
+		Owner     string    `bson:"owner"`
+		Notes     string    `bson:"notes"`
+		Updated   time.Time `bson:"updated"`
+	}
+
+	// TriageIndex maps findings to the triage entries which apply to them
+	TriageIndex map[TriageKey]TriageEntry
+)
+
+// Label summarizes the triage status and owner of the entry for display
+func (t TriageEntry) Label() string {
+	if t.Owner == "" {
+		return t.Status
+	}
+	return t.Status + " (" + t.Owner + ")"
+}
+
+// IsTriageStatus returns true if status is a valid triage status
+func IsTriageStatus(status string) bool {
+	for _, valid := range TriageStatuses {
+		if status == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// NewIPPairTriageKey returns the TriageKey identifying a unique connection pair
+func NewIPPairTriageKey(pair data.UniqueIPPair) TriageKey {
+	return TriageKey{
+		Src:            pair.SrcIP,
+		SrcNetworkUUID: networkUUIDString(pair.SrcNetworkUUID),
+		Dst:            pair.DstIP,
+		DstNetworkUUID: networkUUIDString(pair.DstNetworkUUID),
+	}
+}
+
+// NewSrcFQDNTriageKey returns the TriageKey identifying a unique source to FQDN pair
+func NewSrcFQDNTriageKey(pair data.UniqueSrcFQDNPair) TriageKey {
+	return TriageKey{
+		Src:            pair.SrcIP,
+		SrcNetworkUUID: networkUUIDString(pair.SrcNetworkUUID),
+		FQDN:           pair.FQDN,
+	}
+}
+
+// NewSrcTriageKey returns the TriageKey identifying a host acting as a source
+func NewSrcTriageKey(ip data.UniqueIP) TriageKey {
+	return TriageKey{Src: ip.IP, SrcNetworkUUID: networkUUIDString(ip.NetworkUUID)}
+}
+
+// NewDstTriageKey returns the TriageKey identifying a host acting as a destination
+func NewDstTriageKey(ip data.UniqueIP) TriageKey {
+	return TriageKey{Dst: ip.IP, DstNetworkUUID: networkUUIDString(ip.NetworkUUID)}
+}
+
+// NewFQDNTriageKey returns the TriageKey identifying a hostname
+func NewFQDNTriageKey(fqdn string) TriageKey {
+	return TriageKey{FQDN: fqdn}
+}
+
+// networkUUIDString formats a network UUID in its canonical string form.
+// An empty string is returned if the UUID is malformed.
+func networkUUIDString(networkUUID bson.Binary) string {
+	id, err := uuid.FromBytes(networkUUID.Data)
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
+
+// NewTriageIndex builds a TriageIndex from a list of triage entries. Entries
+// for a specific database take precedence over global entries.
+func NewTriageIndex(entries []TriageEntry) TriageIndex {
+	index := make(TriageIndex)
+	for _, entry := range entries {
+		if existing, ok := index[entry.TriageKey]; ok && existing.Database != "" {
+			continue
+		}
+		index[entry.TriageKey] = entry
+	}
+	return index
+}
+
+// Lookup returns the triage entry for the finding identified by key.
+// If the finding hasn't been triaged, the triage entry for the finding's destination
+// (dst or fqdn) is returned instead, so marking a destination applies to every source
+// contacting it. The zero value is returned if neither has been triaged.
+func (t TriageIndex) Lookup(key TriageKey) TriageEntry {
+	if entry, ok := t[key]; ok {
+		return entry
+	}
+	if key.Src != "" && (key.Dst != "" || key.FQDN != "") {
+		key.Src, key.SrcNetworkUUID = "", ""
+		if entry, ok := t[key]; ok {
+			return entry
+		}
+	}
+	return TriageEntry{}
+}
+
+// IsBenign returns true if the finding identified by key was triaged as benign
+func (t TriageIndex) IsBenign(key TriageKey) bool {
+	return t.Lookup(key).Status == TriageBenign
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//                                 Triage                                    //
+///////////////////////////////////////////////////////////////////////////////
+
+// GetTriage returns the triage entries which apply to the given database,
+// including global entries. If name is empty, only the global entries are returned.
+func (m *MetaDB) GetTriage(name string) ([]TriageEntry, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	var entries []TriageEntry
+	err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.TriageTable).
+		Find(bson.M{"database": bson.M{"$in": []string{"", name}}}).
+		Sort("database", "src", "src_network_uuid", "dst", "dst_network_uuid", "fqdn").All(&entries)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"database": name,
+			"error":    err.Error(),
+		}).Error("could not fetch triage entries from the meta database")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetTriageIndex returns a TriageIndex covering the given database
+func (m *MetaDB) GetTriageIndex(name string) (TriageIndex, error) {
+	entries, err := m.GetTriage(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewTriageIndex(entries), nil
+}
+
+// SetTriage creates or replaces the triage entry for the entry's database and key
+func (m *MetaDB) SetTriage(entry TriageEntry) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	selector := bson.M{
+		"database":         entry.Database,
+		"src":              entry.Src,
+		"src_network_uuid": entry.SrcNetworkUUID,
+		"dst":              entry.Dst,
+		"dst_network_uuid": entry.DstNetworkUUID,
+		"fqdn":             entry.FQDN,
+	}
+	update := bson.M{"$set": bson.M{
+		"status":  entry.Status,
+		"owner":   entry.Owner,
+		"notes":   entry.Notes,
+		"updated": entry.Updated,
+	}}
+
+	_, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.TriageTable).Upsert(selector, update)
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"database": entry.Database,
+			"error":    err.Error(),
+		}).Error("could not update triage entry in the meta database")
+	}
+	return err
+}
+
+// RemoveTriage removes the triage entry for the given database and key
+func (m *MetaDB) RemoveTriage(name string, key TriageKey) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ssn := m.dbHandle.Copy()
+	defer ssn.Close()
+
+	_, err := ssn.DB(m.config.S.MongoDB.MetaDB).C(m.config.T.Meta.TriageTable).RemoveAll(bson.M{
+		"database":         name,
+		"src":              key.Src,
+		"src_network_uuid": key.SrcNetworkUUID,
+		"dst":              key.Dst,
+		"dst_network_uuid": key.DstNetworkUUID,
+		"fqdn":             key.FQDN,
+	})
+	if err != nil {
+		m.log.WithFields(log.Fields{
+			"database": name,
+			"error":    err.Error(),
+		}).Error("could not remove triage entry from the meta database")
+	}
+	return err
+}
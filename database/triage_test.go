@@ -0,0 +1,96 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriageIndexLookup(t *testing.T) {
+	sensorUUID := "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d"
+	sensorNetwork := bson.Binary{
+		Kind: bson.BinaryUUID,
+		Data: []byte{0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x4a, 0x5b, 0x8c, 0x7d, 0x9e, 0x8f, 0x7a, 0x6b, 0x5c, 0x4d},
+	}
+	unknownUUID := "ffffffff-ffff-ffff-ffff-fffffffffffe"
+	publicUUID := "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+	private := func(ip string) data.UniqueIP {
+		return data.UniqueIP{IP: ip, NetworkUUID: util.UnknownPrivateNetworkUUID}
+	}
+	sensor := func(ip string) data.UniqueIP {
+		return data.UniqueIP{IP: ip, NetworkUUID: sensorNetwork}
+	}
+	public := func(ip string) data.UniqueIP {
+		return data.UniqueIP{IP: ip, NetworkUUID: util.PublicNetworkUUID}
+	}
+
+	index := NewTriageIndex([]TriageEntry{
+		{TriageKey: TriageKey{Src: "10.0.0.1", SrcNetworkUUID: unknownUUID, Dst: "1.2.3.4", DstNetworkUUID: publicUUID}, Status: TriageInvestigating},
+		{TriageKey: TriageKey{Dst: "1.2.3.4", DstNetworkUUID: publicUUID}, Status: TriageBenign},
+		{TriageKey: TriageKey{FQDN: "windowsupdate.com"}, Status: TriageBenign, Owner: "global"},
+		{Database: "test", TriageKey: TriageKey{FQDN: "windowsupdate.com"}, Status: TriageEscalated, Owner: "local"},
+		{Database: "test", TriageKey: TriageKey{Src: "10.0.0.9", SrcNetworkUUID: unknownUUID}, Status: TriageEscalated},
+		{TriageKey: TriageKey{Src: "10.0.0.5", SrcNetworkUUID: sensorUUID, FQDN: "example.com"}, Status: TriageBenign},
+	})
+
+	testCases := []struct {
+		name   string
+		key    TriageKey
+		status string
+		owner  string
+	}{
+		{"exact pair", NewIPPairTriageKey(data.NewUniqueIPPair(private("10.0.0.1"), public("1.2.3.4"))), TriageInvestigating, ""},
+		{"destination fallback", NewIPPairTriageKey(data.NewUniqueIPPair(private("10.0.0.2"), public("1.2.3.4"))), TriageBenign, ""},
+		{"pair on another network", NewIPPairTriageKey(data.NewUniqueIPPair(sensor("10.0.0.1"), public("1.2.3.4"))), TriageBenign, ""},
+		{"database overrides global", NewFQDNTriageKey("windowsupdate.com"), TriageEscalated, "local"},
+		{"fqdn fallback", NewSrcFQDNTriageKey(data.NewUniqueSrcFQDNPair(private("10.0.0.2"), "windowsupdate.com")), TriageEscalated, "local"},
+		{"source fqdn pair", NewSrcFQDNTriageKey(data.NewUniqueSrcFQDNPair(sensor("10.0.0.5"), "example.com")), TriageBenign, ""},
+		{"source fqdn pair on another network", NewSrcFQDNTriageKey(data.NewUniqueSrcFQDNPair(private("10.0.0.5"), "example.com")), "", ""},
+		{"lone source", NewSrcTriageKey(private("10.0.0.9")), TriageEscalated, ""},
+		{"lone source on another network", NewSrcTriageKey(sensor("10.0.0.9")), "", ""},
+		{"lone destination", NewDstTriageKey(public("1.2.3.4")), TriageBenign, ""},
+		{"source does not apply to pairs", NewIPPairTriageKey(data.NewUniqueIPPair(private("10.0.0.9"), public("5.6.7.8"))), "", ""},
+		{"untriaged", NewIPPairTriageKey(data.NewUniqueIPPair(private("10.0.0.3"), public("5.6.7.8"))), "", ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			entry := index.Lookup(test.key)
+			require.Equal(t, test.status, entry.Status)
+			require.Equal(t, test.owner, entry.Owner)
+			require.Equal(t, test.status == TriageBenign, index.IsBenign(test.key))
+		})
+	}
+}
+
+func TestNewTriageKeys(t *testing.T) {
+	src := data.UniqueIP{IP: "10.0.0.1", NetworkUUID: util.UnknownPrivateNetworkUUID}
+	dst := data.UniqueIP{IP: "1.2.3.4", NetworkUUID: util.PublicNetworkUUID}
+
+	require.Equal(t,
+		TriageKey{
+			Src: "10.0.0.1", SrcNetworkUUID: "ffffffff-ffff-ffff-ffff-fffffffffffe",
+			Dst: "1.2.3.4", DstNetworkUUID: "ffffffff-ffff-ffff-ffff-ffffffffffff",
+		},
+		NewIPPairTriageKey(data.NewUniqueIPPair(src, dst)),
+	)
+	require.Equal(t,
+		TriageKey{Src: "10.0.0.1", SrcNetworkUUID: "ffffffff-ffff-ffff-ffff-fffffffffffe", FQDN: "example.com"},
+		NewSrcFQDNTriageKey(data.NewUniqueSrcFQDNPair(src, "example.com")),
+	)
+	require.Equal(t, TriageKey{Dst: "1.2.3.4", DstNetworkUUID: "ffffffff-ffff-ffff-ffff-ffffffffffff"}, NewDstTriageKey(dst))
+	require.Equal(t, TriageKey{FQDN: "example.com"}, NewFQDNTriageKey("example.com"))
+
+	// malformed network UUIDs don't match any triage entry with a network UUID
+	require.Equal(t, TriageKey{Src: "10.0.0.1"}, NewSrcTriageKey(data.UniqueIP{IP: "10.0.0.1"}))
+}
+
+func TestTriageEntryLabel(t *testing.T) {
+	require.Equal(t, "benign", TriageEntry{Status: TriageBenign}.Label())
+	require.Equal(t, "escalated (jdoe)", TriageEntry{Status: TriageEscalated, Owner: "jdoe"}.Label())
+	require.Equal(t, "", TriageEntry{}.Label())
+}
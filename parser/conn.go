@@ -4,10 +4,13 @@ import (
 	"math"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/activecm/rita-legacy/parser/parsetypes"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/host"
+	"github.com/activecm/rita-legacy/pkg/lateral"
+	"github.com/activecm/rita-legacy/pkg/scan"
 	"github.com/activecm/rita-legacy/pkg/uconn"
 	"github.com/activecm/rita-legacy/util"
 
@@ -34,6 +37,12 @@ func parseConnEntry(parseConn *parsetypes.Conn, filter filter, retVals ParseResu
 		return
 	}
 
+	// profile internal connections on administrative ports before the
+	// internal to internal connections are filtered out below
+	if !filter.filterLateralConn(srcIP, dstIP, parseConn.DestinationPort) {
+		updateLateralByConn(srcIP, dstIP, parseConn, retVals)
+	}
+
 	// Run conn pair through filter to filter out certain connections
 	ignore := filter.filterConnPair(srcIP, dstIP)
 
@@ -73,7 +82,11 @@ func parseConnEntry(parseConn *parsetypes.Conn, filter filter, retVals ParseResu
 
 	updateCertificatesByConn(dstKey, tuple, retVals)
 
-	updateZeekUIDRecordsByConn(parseConn.UID, parseConn.OrigIPBytes, parseConn.RespBytes, roundedDuration, retVals)
+	updateScansByConn(srcUniqIP, dstUniqIP, srcDstPair, srcKey, dstKey, parseConn, retVals)
+
+	updateZeekUIDRecordsByConn(
+		parseConn.UID, parseConn.TimeStamp, parseConn.OrigIPBytes, parseConn.RespBytes, parseConn.RespIPBytes, roundedDuration, retVals,
+	)
 }
 
 func updateUniqueConnectionsByConn(srcIP, dstIP net.IP, srcDstPair data.UniqueIPPair, srcDstKey string,
@@ -243,7 +256,122 @@ func updateCertificatesByConn(dstKey string, tuple string, retVals ParseResults)
 	}
 }
 
-func updateZeekUIDRecordsByConn(uid string, origIPBytes int64, respIPBytes int64, duration float64, retVals ParseResults) {
+// connectionFailed returns true if the connection was rejected or went unanswered,
+// as is typical of probes sent by port scanners
+func connectionFailed(parseConn *parsetypes.Conn) bool {
+	switch parseConn.ConnState {
+	case "REJ", "S0", "RSTOS0", "SH":
+		return true
+	case "":
+		// fall back to the history if the connection state wasn't logged.
+		// the responder never acknowledged the SYN (h) or sent data (d).
+		return len(parseConn.History) > 0 && !strings.ContainsAny(parseConn.History, "hd")
+	default:
+		return false
+	}
+}
+
+func updateScansByConn(srcUniqIP, dstUniqIP data.UniqueIP, srcDstPair data.UniqueIPPair, srcKey, dstKey string,
+	parseConn *parsetypes.Conn, retVals ParseResults) {
+
+	failed := connectionFailed(parseConn)
+	portProto := strconv.Itoa(parseConn.DestinationPort) + ":" + parseConn.Proto
+
+	// vertical scans are keyed by the source and destination,
+	// horizontal scans are keyed by the source and destination port
+	verticalKey := scan.VerticalScan + srcDstPair.MapKey()
+	horizontalKey := scan.HorizontalScan + srcKey + portProto
+
+	retVals.ScanLock.Lock()
+	defer retVals.ScanLock.Unlock()
+
+	if _, ok := retVals.ScanMap[verticalKey]; !ok {
+		retVals.ScanMap[verticalKey] = &scan.Input{
+			Type:      scan.VerticalScan,
+			Src:       srcUniqIP,
+			Dst:       dstUniqIP,
+			Targets:   make(map[string]*scan.Probe),
+			FirstSeen: parseConn.TimeStamp,
+			LastSeen:  parseConn.TimeStamp,
+		}
+	}
+
+	if _, ok := retVals.ScanMap[horizontalKey]; !ok {
+		retVals.ScanMap[horizontalKey] = &scan.Input{
+			Type:      scan.HorizontalScan,
+			Src:       srcUniqIP,
+			Port:      parseConn.DestinationPort,
+			Proto:     parseConn.Proto,
+			Targets:   make(map[string]*scan.Probe),
+			FirstSeen: parseConn.TimeStamp,
+			LastSeen:  parseConn.TimeStamp,
+		}
+	}
+
+	// ///// RECORD THE PORT PROBED BY THE SOURCE ON THE DESTINATION /////
+	updateScanProbe(retVals.ScanMap[verticalKey], portProto, portProto, failed, parseConn.TimeStamp)
+
+	// ///// RECORD THE DESTINATION PROBED BY THE SOURCE ON THE PORT /////
+	updateScanProbe(retVals.ScanMap[horizontalKey], dstKey, dstUniqIP.IP, failed, parseConn.TimeStamp)
+}
+
+func updateScanProbe(scanInput *scan.Input, targetKey, target string, failed bool, ts int64) {
+	if _, ok := scanInput.Targets[targetKey]; !ok {
+		scanInput.Targets[targetKey] = &scan.Probe{Target: target}
+	}
+
+	scanInput.Targets[targetKey].Connections++
+	if failed {
+		scanInput.Targets[targetKey].Failed++
+	}
+
+	if ts < scanInput.FirstSeen {
+		scanInput.FirstSeen = ts
+	}
+	if ts > scanInput.LastSeen {
+		scanInput.LastSeen = ts
+	}
+}
+
+func updateLateralByConn(srcIP, dstIP net.IP, parseConn *parsetypes.Conn, retVals ParseResults) {
+	srcUniqIP := data.NewUniqueIP(srcIP, parseConn.AgentUUID, parseConn.AgentHostname)
+	dstUniqIP := data.NewUniqueIP(dstIP, parseConn.AgentUUID, parseConn.AgentHostname)
+	srcKey := srcUniqIP.MapKey()
+
+	retVals.LateralLock.Lock()
+	defer retVals.LateralLock.Unlock()
+
+	// ///// CREATE LATERAL MOVEMENT RECORD FOR THE SOURCE IF IT DOES NOT EXIST /////
+	if _, ok := retVals.LateralMap[srcKey]; !ok {
+		retVals.LateralMap[srcKey] = &lateral.Input{
+			Host:      srcUniqIP,
+			Peers:     make(data.UniqueIPSet),
+			PortPeers: make(map[int]data.UniqueIPSet),
+			FirstSeen: parseConn.TimeStamp,
+			LastSeen:  parseConn.TimeStamp,
+		}
+	}
+	lateralInput := retVals.LateralMap[srcKey]
+
+	// ///// RECORD THE PEER CONTACTED BY THE SOURCE /////
+	lateralInput.Peers.Insert(dstUniqIP)
+
+	if _, ok := lateralInput.PortPeers[parseConn.DestinationPort]; !ok {
+		lateralInput.PortPeers[parseConn.DestinationPort] = make(data.UniqueIPSet)
+	}
+	lateralInput.PortPeers[parseConn.DestinationPort].Insert(dstUniqIP)
+
+	lateralInput.Connections++
+
+	if parseConn.TimeStamp < lateralInput.FirstSeen {
+		lateralInput.FirstSeen = parseConn.TimeStamp
+	}
+	if parseConn.TimeStamp > lateralInput.LastSeen {
+		lateralInput.LastSeen = parseConn.TimeStamp
+	}
+}
+
+func updateZeekUIDRecordsByConn(uid string, ts int64, origIPBytes int64, respBytes int64, respIPBytes int64, duration float64, retVals ParseResults) {
 	// Don't do any work if the UID is missing
 	if len(uid) == 0 {
 		return
@@ -257,7 +385,9 @@ func updateZeekUIDRecordsByConn(uid string, origIPBytes int64, respIPBytes int64
 		retVals.ZeekUIDMap[uid] = &data.ZeekUIDRecord{}
 	}
 
+	retVals.ZeekUIDMap[uid].Conn.Ts = ts
 	retVals.ZeekUIDMap[uid].Conn.OrigBytes = origIPBytes
-	retVals.ZeekUIDMap[uid].Conn.RespBytes = respIPBytes
+	retVals.ZeekUIDMap[uid].Conn.RespBytes = respBytes
+	retVals.ZeekUIDMap[uid].Conn.RespIPBytes = respIPBytes
 	retVals.ZeekUIDMap[uid].Conn.Duration = duration
 }
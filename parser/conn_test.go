@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateZeekUIDRecordsByConn(t *testing.T) {
+	retVals := newParseResults()
+
+	updateZeekUIDRecordsByConn("C1", 1700000000, 1500, 4000, 4800, 2.5, retVals)
+
+	record, ok := retVals.ZeekUIDMap["C1"]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, int64(1700000000), record.Conn.Ts)
+	assert.Equal(t, int64(1500), record.Conn.OrigBytes)
+	assert.Equal(t, int64(4000), record.Conn.RespBytes, "SNIconn totals use the responder's payload bytes")
+	assert.Equal(t, int64(4800), record.Conn.RespIPBytes)
+	assert.Equal(t, 2.5, record.Conn.Duration)
+
+	updateZeekUIDRecordsByConn("", 1700000000, 1, 1, 1, 1, retVals)
+	assert.Len(t, retVals.ZeekUIDMap, 1, "records without a UID are ignored")
+}
@@ -2,15 +2,20 @@ package parser
 
 import (
 	"net"
+	"strings"
 
+	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/parser/parsetypes"
 	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
 	"github.com/activecm/rita-legacy/pkg/hostname"
+	"github.com/activecm/rita-legacy/pkg/uconndns"
+	"github.com/activecm/rita-legacy/util"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func parseDNSEntry(parseDNS *parsetypes.DNS, filter filter, retVals ParseResults, logger *log.Logger) {
+func parseDNSEntry(parseDNS *parsetypes.DNS, filter filter, conf *config.Config, retVals ParseResults, logger *log.Logger) {
 	// get source destination pair
 	src := parseDNS.Source
 	dst := parseDNS.Destination
@@ -42,9 +47,12 @@ func parseDNSEntry(parseDNS *parsetypes.DNS, filter filter, retVals ParseResults
 	}
 
 	srcUniqIP := data.NewUniqueIP(srcIP, parseDNS.AgentUUID, parseDNS.AgentHostname)
+	dstUniqIP := data.NewUniqueIP(dstIP, parseDNS.AgentUUID, parseDNS.AgentHostname)
 
 	updateExplodedDNSbyDNS(domain, retVals)
 	updateHostnamesByDNS(srcUniqIP, domain, parseDNS, retVals)
+	updateDNSTunnelByDNS(srcUniqIP, domain, parseDNS, retVals)
+	updateDNSUniqueConnectionsByDNS(srcUniqIP, dstUniqIP, domain, conf.S.BeaconDNS.GroupByRegisteredDomain, parseDNS, retVals)
 }
 
 func updateExplodedDNSbyDNS(domain string, retVals ParseResults) {
@@ -83,3 +91,93 @@ func updateHostnamesByDNS(srcUniqIP data.UniqueIP, domain string, parseDNS *pars
 		}
 	}
 }
+
+func updateDNSTunnelByDNS(srcUniqIP data.UniqueIP, domain string, parseDNS *parsetypes.DNS, retVals ParseResults) {
+	// tunneling tools may randomize the case of queries, so compare them in lowercase
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+
+	// reverse lookups are made in bulk by legitimate software and are not
+	// useful for tunneling analysis
+	if domain == "" || strings.HasSuffix(domain, ".arpa") {
+		return
+	}
+
+	registeredDomain := util.RegisteredDomain(domain)
+	subdomain := strings.TrimSuffix(strings.TrimSuffix(domain, registeredDomain), ".")
+	queryLength := int64(len(domain))
+
+	hosts := data.NewUniqueSrcFQDNPair(srcUniqIP, registeredDomain)
+	hostsKey := hosts.MapKey()
+
+	retVals.DNSTunnelLock.Lock()
+	defer retVals.DNSTunnelLock.Unlock()
+
+	if _, ok := retVals.DNSTunnelMap[hostsKey]; !ok {
+		retVals.DNSTunnelMap[hostsKey] = &dnstunnel.Input{
+			Hosts:      hosts,
+			Subdomains: make(data.StringSet),
+		}
+	}
+
+	tunnel := retVals.DNSTunnelMap[hostsKey]
+
+	// ///// INCREMENT QUERY COUNT AND QUERY LENGTH STATISTICS /////
+	tunnel.QueryCount++
+	tunnel.QueryLengthTotal += queryLength
+	tunnel.QueryLengthSquaresTotal += queryLength * queryLength
+	if queryLength > tunnel.MaxQueryLength {
+		tunnel.MaxQueryLength = queryLength
+	}
+
+	// ///// ADD SUBDOMAIN ENTROPY AND UNION SUBDOMAIN INTO SUBDOMAIN SET /////
+	if len(subdomain) > 0 {
+		tunnel.SubdomainQueryCount++
+		tunnel.EntropyTotal += util.ShannonEntropy(strings.ReplaceAll(subdomain, ".", ""))
+		tunnel.Subdomains.Insert(subdomain)
+	}
+
+	// ///// INCREMENT RECORD TYPE AND RESPONSE CODE COUNTERS /////
+	if parseDNS.QTypeName == "TXT" || parseDNS.QTypeName == "NULL" {
+		tunnel.TXTNullCount++
+	}
+
+	if parseDNS.RCodeName == "NXDOMAIN" {
+		tunnel.NXDomainCount++
+	}
+}
+
+func updateDNSUniqueConnectionsByDNS(srcUniqIP data.UniqueIP, dstUniqIP data.UniqueIP, domain string,
+	groupByRegisteredDomain bool, parseDNS *parsetypes.DNS, retVals ParseResults) {
+	// group queries by the queried FQDN unless the registered domain is requested,
+	// in which case polling a new subdomain for each request counts towards the same beacon
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	if domain == "" || strings.HasSuffix(domain, ".arpa") {
+		return
+	}
+	if groupByRegisteredDomain {
+		domain = util.RegisteredDomain(domain)
+	}
+
+	srcFQDNPair := data.NewUniqueSrcFQDNPair(srcUniqIP, domain)
+	srcFQDNKey := srcFQDNPair.MapKey()
+
+	retVals.DNSUniqueConnLock.Lock()
+	defer retVals.DNSUniqueConnLock.Unlock()
+
+	if _, ok := retVals.DNSUniqueConnMap[srcFQDNKey]; !ok {
+		retVals.DNSUniqueConnMap[srcFQDNKey] = &uconndns.Input{
+			Hosts: srcFQDNPair,
+		}
+	}
+
+	// ///// RECORD THE LAST RESOLVER QUERIED FOR THE DOMAIN /////
+	retVals.DNSUniqueConnMap[srcFQDNKey].Resolver = dstUniqIP
+
+	// ///// INCREMENT THE QUERY COUNT FOR THE DNS UNIQUE CONNECTION /////
+	retVals.DNSUniqueConnMap[srcFQDNKey].ConnectionCount++
+
+	// ///// APPEND TIMESTAMP TO DNS UNIQUE CONNECTION TIMESTAMP LIST /////
+	retVals.DNSUniqueConnMap[srcFQDNKey].TsList = append(
+		retVals.DNSUniqueConnMap[srcFQDNKey].TsList, parseDNS.TimeStamp,
+	)
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"net"
+	"testing"
+
+	"github.com/activecm/rita-legacy/parser/parsetypes"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDNSUniqueConnectionsByDNS(t *testing.T) {
+	src := data.NewUniqueIP(net.ParseIP("10.0.0.1"), "", "")
+	firstResolver := data.NewUniqueIP(net.ParseIP("10.0.0.53"), "", "")
+	lastResolver := data.NewUniqueIP(net.ParseIP("10.0.0.54"), "", "")
+
+	queries := []struct {
+		resolver data.UniqueIP
+		query    string
+		ts       int64
+	}{
+		{firstResolver, "a1b2.Example.com.", 100},
+		{firstResolver, "c3d4.example.com", 160},
+		{firstResolver, "A1B2.example.com", 190},
+		{lastResolver, "example.com", 220},
+		{lastResolver, "1.0.0.10.in-addr.arpa", 280},
+	}
+
+	collect := func(groupByRegisteredDomain bool) ParseResults {
+		retVals := newParseResults()
+		for _, q := range queries {
+			parseDNS := &parsetypes.DNS{Query: q.query, TimeStamp: q.ts}
+			updateDNSUniqueConnectionsByDNS(src, q.resolver, q.query, groupByRegisteredDomain, parseDNS, retVals)
+		}
+		return retVals
+	}
+
+	// queries are grouped by the queried FQDN by default
+	retVals := collect(false)
+	assert.Len(t, retVals.DNSUniqueConnMap, 3, "reverse lookups are ignored")
+
+	pair := data.NewUniqueSrcFQDNPair(src, "a1b2.example.com")
+	uconn, ok := retVals.DNSUniqueConnMap[pair.MapKey()]
+	if !assert.True(t, ok, "queries are compared in lowercase without the trailing dot") {
+		return
+	}
+	assert.Equal(t, pair, uconn.Hosts)
+	assert.Equal(t, firstResolver, uconn.Resolver)
+	assert.Equal(t, int64(2), uconn.ConnectionCount)
+	assert.Equal(t, []int64{100, 190}, uconn.TsList)
+
+	uconn, ok = retVals.DNSUniqueConnMap[data.NewUniqueSrcFQDNPair(src, "example.com").MapKey()]
+	if assert.True(t, ok) {
+		assert.Equal(t, int64(1), uconn.ConnectionCount)
+	}
+
+	// queries for each subdomain are merged when grouping by registered domain
+	retVals = collect(true)
+	assert.Len(t, retVals.DNSUniqueConnMap, 1, "reverse lookups are ignored")
+
+	pair = data.NewUniqueSrcFQDNPair(src, "example.com")
+	uconn, ok = retVals.DNSUniqueConnMap[pair.MapKey()]
+	if !assert.True(t, ok, "queries are grouped by registered domain") {
+		return
+	}
+	assert.Equal(t, pair, uconn.Hosts)
+	assert.Equal(t, lastResolver, uconn.Resolver)
+	assert.Equal(t, int64(4), uconn.ConnectionCount)
+	assert.Equal(t, []int64{100, 160, 190, 220}, uconn.TsList)
+}
+
+func TestUpdateDNSTunnelByDNS(t *testing.T) {
+	src := data.NewUniqueIP(net.ParseIP("10.0.0.1"), "", "")
+	retVals := newParseResults()
+
+	for _, query := range []string{"a1b2.example.com", "c3d4.example.com", "example.com", "1.0.0.10.in-addr.arpa"} {
+		updateDNSTunnelByDNS(src, query, &parsetypes.DNS{Query: query, QTypeName: "A"}, retVals)
+	}
+
+	tunnel, ok := retVals.DNSTunnelMap[data.NewUniqueSrcFQDNPair(src, "example.com").MapKey()]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, int64(3), tunnel.QueryCount)
+	assert.Equal(t, int64(2), tunnel.SubdomainQueryCount, "queries for the registered domain have no subdomain")
+	assert.Equal(t, 2, len(tunnel.Subdomains))
+	assert.InDelta(t, 4.0, tunnel.EntropyTotal, 0.0001)
+}
@@ -0,0 +1,314 @@
+package files
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// DayFormat is the layout of the dates in Zeek's archive directory names, e.g. logs/2006-01-02/
+const DayFormat = "2006-01-02"
+
+// dayDirRegex matches the names of Zeek's daily archive directories
+var dayDirRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// GatherOptions controls which log files GatherLogFiles selects from the given paths
+type GatherOptions struct {
+	Recursive bool      // descend into subdirectories
+	Include   []string  // if set, files must match one of these glob patterns
+	Exclude   []string  // files and directories matching any of these glob patterns are skipped
+	From      time.Time // skip logs written before this day, unbounded if zero
+	To        time.Time // skip logs written after this day, unbounded if zero
+}
+
+// filtersDays returns true if logs are selected by the day they were written
+func (o GatherOptions) filtersDays() bool {
+	return !o.From.IsZero() || !o.To.IsZero()
+}
+
+// DayFiles lists the log files written on a given day
+type DayFiles struct {
+	Day   time.Time
+	Paths []string
+}
+
+// GatherLogFiles reads the files and directories looking for log, json, and gz files
+func GatherLogFiles(paths []string, opts GatherOptions, logger *log.Logger) []string {
+	var toReturn []string
+	for _, logFile := range gatherLogFiles(paths, opts, logger) {
+		toReturn = append(toReturn, logFile.path)
+	}
+	return toReturn
+}
+
+// GroupLogFilesByDay gathers the log files like GatherLogFiles and groups them by the day
+// they were written, oldest first. Files written on an unknown day are skipped.
+func GroupLogFilesByDay(paths []string, opts GatherOptions, logger *log.Logger) []DayFiles {
+	dayMap := make(map[time.Time][]string)
+	for _, logFile := range gatherLogFiles(paths, opts, logger) {
+		day, ok := logFile.day()
+		if !ok {
+			logger.WithFields(log.Fields{
+				"path": logFile.path,
+			}).Warn("Skipping log file written on an unknown day")
+			continue
+		}
+		dayMap[day] = append(dayMap[day], logFile.path)
+	}
+
+	var days []DayFiles
+	for day, dayPaths := range dayMap {
+		days = append(days, DayFiles{Day: day, Paths: dayPaths})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+	return days
+}
+
+// logFile is a gathered log file. The day it was written is looked up when needed
+// since reading the log header is relatively expensive.
+type logFile struct {
+	path      string
+	dayCached bool
+	dayKnown  bool
+	dayValue  time.Time
+}
+
+func (l *logFile) day() (time.Time, bool) {
+	if !l.dayCached {
+		l.dayValue, l.dayKnown = logDay(l.path)
+		l.dayCached = true
+	}
+	return l.dayValue, l.dayKnown
+}
+
+// gatherLogFiles implements GatherLogFiles and GroupLogFilesByDay
+func gatherLogFiles(paths []string, opts GatherOptions, logger *log.Logger) []*logFile {
+	var toReturn []*logFile
+
+	for _, path := range paths {
+		var candidates []string
+		if util.IsDir(path) {
+			candidates = gatherDir(path, opts, logger)
+		} else if isLogFile(path) {
+			candidates = []string{path}
+		} else {
+			logger.WithFields(log.Fields{
+				"path": path,
+			}).Warn("Ignoring non .log, .json, or .gz file")
+			continue
+		}
+
+		for _, candidate := range candidates {
+			logFile := &logFile{path: candidate}
+			if opts.filtersDays() && !inDayRange(logFile, opts, logger) {
+				continue
+			}
+			toReturn = append(toReturn, logFile)
+		}
+	}
+
+	return toReturn
+}
+
+// gatherDir reads the directory looking for .log, .json, and .gz files which satisfy
+// the include and exclude patterns. Subdirectories are only read if opts.Recursive is set.
+// Symlinked log files are gathered, but symlinked directories are not followed so that
+// the "current" symlink to Zeek's spool is skipped.
+func gatherDir(root string, opts GatherOptions, logger *log.Logger) []string {
+	var toReturn []string
+
+	// WalkDir doesn't follow root if it is a symlink, so walk its target instead
+	// and report the files under the path the user gave
+	walkRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"error": err.Error(),
+			"path":  root,
+		}).Error("Error when reading directory")
+		return nil
+	}
+
+	err = filepath.WalkDir(walkRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"error": err.Error(),
+				"path":  path,
+			}).Error("Error when reading directory")
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(walkRoot, path)
+		if entry.IsDir() {
+			if path == walkRoot {
+				return nil
+			}
+			if !opts.Recursive || matchesAny(opts.Exclude, relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			// os.Stat follows the link
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+		} else if !entry.Type().IsRegular() {
+			return nil
+		}
+		if !isLogFile(entry.Name()) {
+			return nil
+		}
+		if len(opts.Include) > 0 && !matchesAny(opts.Include, relPath) {
+			return nil
+		}
+		if matchesAny(opts.Exclude, relPath) {
+			return nil
+		}
+		toReturn = append(toReturn, filepath.Join(root, relPath))
+		return nil
+	})
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"error": err.Error(),
+			"path":  root,
+		}).Error("Error when reading directory")
+	}
+	return toReturn
+}
+
+// isLogFile returns true if the file name has a log file extension
+func isLogFile(name string) bool {
+	return strings.HasSuffix(name, ".gz") ||
+		strings.HasSuffix(name, ".log") ||
+		strings.HasSuffix(name, ".json")
+}
+
+// matchesAny returns true if the base name or the slash separated relative path
+// matches one of the glob patterns
+func matchesAny(patterns []string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	base := filepath.Base(relPath)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inDayRange returns true if the log was written between opts.From and opts.To
+func inDayRange(logFile *logFile, opts GatherOptions, logger *log.Logger) bool {
+	day, ok := logFile.day()
+	if !ok {
+		logger.WithFields(log.Fields{
+			"path": logFile.path,
+		}).Warn("Skipping log file written on an unknown day")
+		return false
+	}
+	if !opts.From.IsZero() && day.Before(opts.From) {
+		return false
+	}
+	if !opts.To.IsZero() && day.After(opts.To) {
+		return false
+	}
+	return true
+}
+
+// logDay finds the day a log was written. The nearest parent directory named
+// like Zeek's daily archive directories is used if there is one. Otherwise the
+// #open header of TSV logs or the timestamp of the first record of JSON logs is used.
+func logDay(path string) (time.Time, bool) {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		name := filepath.Base(dir)
+		if dayDirRegex.MatchString(name) {
+			if day, err := time.Parse(DayFormat, name); err == nil {
+				return day, true
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	fileHandle, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer fileHandle.Close()
+
+	var reader io.Reader = fileHandle
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(fileHandle)
+		if err != nil {
+			return time.Time{}, false
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	return scanLogDay(reader)
+}
+
+// scanLogDay reads the day a log was written from the #open header of a TSV log
+// or from the timestamp of the first record of a JSON log
+func scanLogDay(reader io.Reader) (time.Time, bool) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			continue
+		}
+
+		// TSV logs record when they were opened, e.g. #open	2006-01-02-15-04-05
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) > 1 && fields[0] == "#open" && len(fields[1]) >= len(DayFormat) {
+				day, err := time.Parse(DayFormat, fields[1][:len(DayFormat)])
+				return day, err == nil
+			}
+			continue
+		}
+
+		// JSON logs record the time of each entry as epoch seconds or in ISO 8601 format
+		var record struct {
+			TS interface{} `json:"ts"`
+		}
+		if json.Unmarshal([]byte(line), &record) != nil {
+			return time.Time{}, false
+		}
+		switch ts := record.TS.(type) {
+		case float64:
+			return truncateDay(time.Unix(int64(ts), 0)), true
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return truncateDay(parsed), true
+		}
+		return time.Time{}, false
+	}
+	return time.Time{}, false
+}
+
+// truncateDay returns midnight UTC of the day t falls on in UTC
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
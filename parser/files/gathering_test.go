@@ -0,0 +1,151 @@
+package files
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestLog creates a log file and its parent directories under root
+func writeTestLog(t *testing.T, root, relPath, contents string) string {
+	path := filepath.Join(root, filepath.FromSlash(relPath))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+
+	fileHandle, err := os.Create(path)
+	require.NoError(t, err)
+	defer fileHandle.Close()
+
+	if strings.HasSuffix(path, ".gz") {
+		gzipWriter := gzip.NewWriter(fileHandle)
+		_, err = gzipWriter.Write([]byte(contents))
+		require.NoError(t, err)
+		require.NoError(t, gzipWriter.Close())
+		return path
+	}
+	_, err = fileHandle.Write([]byte(contents))
+	require.NoError(t, err)
+	return path
+}
+
+func relPaths(t *testing.T, root string, paths []string) []string {
+	var rel []string
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		require.NoError(t, err)
+		rel = append(rel, filepath.ToSlash(relPath))
+	}
+	sort.Strings(rel)
+	return rel
+}
+
+func TestGatherLogFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestLog(t, root, "conn.log", "")
+	writeTestLog(t, root, "notes.txt", "")
+	writeTestLog(t, root, "2026-10-01/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "2026-10-01/dns.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "2026-10-02/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "stats/stats.log", "")
+	logger := log.New()
+
+	// subdirectories are skipped unless recursing
+	paths := GatherLogFiles([]string{root}, GatherOptions{}, logger)
+	require.Equal(t, []string{"conn.log"}, relPaths(t, root, paths))
+
+	paths = GatherLogFiles([]string{root}, GatherOptions{Recursive: true}, logger)
+	require.Equal(t, []string{
+		"2026-10-01/conn.00:00:00-01:00:00.log.gz",
+		"2026-10-01/dns.00:00:00-01:00:00.log.gz",
+		"2026-10-02/conn.00:00:00-01:00:00.log.gz",
+		"conn.log",
+		"stats/stats.log",
+	}, relPaths(t, root, paths))
+
+	// globs match file names or paths relative to the import directory
+	paths = GatherLogFiles([]string{root}, GatherOptions{
+		Recursive: true,
+		Include:   []string{"conn.*"},
+		Exclude:   []string{"2026-10-02"},
+	}, logger)
+	require.Equal(t, []string{
+		"2026-10-01/conn.00:00:00-01:00:00.log.gz",
+		"conn.log",
+	}, relPaths(t, root, paths))
+
+	paths = GatherLogFiles([]string{root}, GatherOptions{
+		Recursive: true,
+		Include:   []string{"*/dns.*"},
+	}, logger)
+	require.Equal(t, []string{"2026-10-01/dns.00:00:00-01:00:00.log.gz"}, relPaths(t, root, paths))
+}
+
+func TestGatherLogFilesSymlinks(t *testing.T) {
+	root := t.TempDir()
+	spool := t.TempDir()
+	target := writeTestLog(t, spool, "conn.log", "")
+	writeTestLog(t, spool, "dns.log", "")
+	writeTestLog(t, root, "2026-10-01/http.00:00:00-01:00:00.log.gz", "")
+
+	// symlinked log files are gathered while symlinked directories such as
+	// Zeek's current spool are not followed
+	require.NoError(t, os.Symlink(target, filepath.Join(root, "conn.log")))
+	require.NoError(t, os.Symlink(spool, filepath.Join(root, "current")))
+	require.NoError(t, os.Symlink(filepath.Join(spool, "missing.log"), filepath.Join(root, "broken.log")))
+	logger := log.New()
+
+	paths := GatherLogFiles([]string{root}, GatherOptions{}, logger)
+	require.Equal(t, []string{"conn.log"}, relPaths(t, root, paths))
+
+	paths = GatherLogFiles([]string{root}, GatherOptions{Recursive: true}, logger)
+	require.Equal(t, []string{
+		"2026-10-01/http.00:00:00-01:00:00.log.gz",
+		"conn.log",
+	}, relPaths(t, root, paths))
+
+	// a symlinked directory given as an import path is still read
+	paths = GatherLogFiles([]string{filepath.Join(root, "current")}, GatherOptions{}, logger)
+	require.Len(t, paths, 2)
+}
+
+func TestGatherLogFilesDayRange(t *testing.T) {
+	root := t.TempDir()
+	writeTestLog(t, root, "logs/2026-09-30/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "logs/2026-10-01/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "logs/2026-10-07/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "logs/2026-10-08/conn.00:00:00-01:00:00.log.gz", "")
+	writeTestLog(t, root, "spool/conn.log", "#separator \\x09\n#open\t2026-10-03-12-00-00\n#fields\tts\n")
+	writeTestLog(t, root, "spool/dns.json", `{"ts":1791374400.5,"uid":"C1"}`+"\n") // 2026-10-07
+	writeTestLog(t, root, "spool/http.json", `{"ts":"2026-10-09T00:00:00.000000Z"}`+"\n")
+	writeTestLog(t, root, "spool/weird.log", "")
+
+	opts := GatherOptions{
+		Recursive: true,
+		From:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC),
+	}
+	paths := GatherLogFiles([]string{root}, opts, log.New())
+	require.Equal(t, []string{
+		"logs/2026-10-01/conn.00:00:00-01:00:00.log.gz",
+		"logs/2026-10-07/conn.00:00:00-01:00:00.log.gz",
+		"spool/conn.log",
+		"spool/dns.json",
+	}, relPaths(t, root, paths))
+
+	days := GroupLogFilesByDay([]string{root}, opts, log.New())
+	require.Len(t, days, 3)
+	require.Equal(t, "2026-10-01", days[0].Day.Format(DayFormat))
+	require.Equal(t, "2026-10-03", days[1].Day.Format(DayFormat))
+	require.Equal(t, []string{"spool/conn.log"}, relPaths(t, root, days[1].Paths))
+	require.Equal(t, "2026-10-07", days[2].Day.Format(DayFormat))
+	require.Equal(t, []string{
+		"logs/2026-10-07/conn.00:00:00-01:00:00.log.gz",
+		"spool/dns.json",
+	}, relPaths(t, root, days[2].Paths))
+}
@@ -63,6 +63,17 @@ func newIndexedFile(filePath string, targetDB string, targetCID int,
 	} else if scanner.Err() == nil && len(scanner.Bytes()) > 0 && // no error and there is text
 		json.Valid(scanner.Bytes()) {
 		toReturn.SetJSON()
+
+		// Suricata eve.json files mix several event types in a single file
+		// so each line is mapped to a parse type as it is read
+		if isSuricataEvent(scanner.Bytes()) {
+			toReturn.SetSuricata()
+			toReturn.TargetCollection = conf.T.Structure.EveTable
+			toReturn.TargetDatabase = targetDB
+			toReturn.CID = targetCID
+			return toReturn, nil
+		}
+
 		// check if "_path" is provided in the JSON data
 		// https://github.com/corelight/json-streaming-logs
 		t := struct {
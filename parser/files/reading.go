@@ -8,68 +8,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
-	"path"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	pt "github.com/activecm/rita-legacy/parser/parsetypes"
-	"github.com/activecm/rita-legacy/util"
 
 	jsoniter "github.com/json-iterator/go"
 	log "github.com/sirupsen/logrus"
 )
 
-// GatherLogFiles reads the files and directories looking for log and gz files
-func GatherLogFiles(paths []string, logger *log.Logger) []string {
-	var toReturn []string
-
-	for _, path := range paths {
-		if util.IsDir(path) {
-			toReturn = append(toReturn, gatherDir(path, logger)...)
-		} else if strings.HasSuffix(path, ".gz") ||
-			strings.HasSuffix(path, ".log") {
-			toReturn = append(toReturn, path)
-		} else {
-			logger.WithFields(log.Fields{
-				"path": path,
-			}).Warn("Ignoring non .log or .gz file")
-		}
-	}
-
-	return toReturn
-}
-
-// gatherDir reads the directory looking for log and .gz files
-func gatherDir(cpath string, logger *log.Logger) []string {
-	var toReturn []string
-	files, err := ioutil.ReadDir(cpath)
-	if err != nil {
-		logger.WithFields(log.Fields{
-			"error": err.Error(),
-			"path":  cpath,
-		}).Error("Error when reading directory")
-	}
-
-	for _, file := range files {
-		// Stop RITA from following symlinks
-		// In the case that RITA is pointed directly at Bro, it should not
-		// parse the "current" symlink which points to the spool.
-		// if file.IsDir() && file.Mode() != os.ModeSymlink {
-		// 	toReturn = append(toReturn, readDir(path.Join(cpath, file.Name()), logger)...)
-		// }
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".gz") ||
-			strings.HasSuffix(file.Name(), ".log") {
-			toReturn = append(toReturn, path.Join(cpath, file.Name()))
-		}
-	}
-	return toReturn
-}
-
 // GetFileScanner returns a buffered file scanner for a bro log file, a function to close the
 // underlying stream and any associated processors, as well as any error that may occur while
 // creating the scanner
@@ -77,12 +28,13 @@ func GetFileScanner(fileHandle *os.File) (scanner *bufio.Scanner, closer func()
 	// by default just close out the underlying file handle
 	closer = fileHandle.Close
 
-	ftype := fileHandle.Name()[len(fileHandle.Name())-3:]
-	if ftype != ".gz" && ftype != "log" {
+	name := fileHandle.Name()
+	if !strings.HasSuffix(name, ".gz") && !strings.HasSuffix(name, "log") &&
+		!strings.HasSuffix(name, ".json") {
 		return nil, closer, errors.New("filetype not recognized")
 	}
 
-	if ftype == ".gz" {
+	if strings.HasSuffix(name, ".gz") {
 		var gzipReader io.Reader
 		gzipReader, closer, err = newGzipReader(fileHandle)
 		if err != nil {
@@ -381,6 +333,8 @@ func parseTSVField(fieldText string, fieldType string, targetField reflect.Value
 	case pt.EnumSet:
 		fallthrough
 	case pt.StringVector:
+		fallthrough
+	case pt.AddrVector:
 		tokens := strings.Split(fieldText, ",")
 		tVal := reflect.ValueOf(tokens)
 		targetField.Set(tVal)
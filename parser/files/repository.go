@@ -41,6 +41,7 @@ type IndexedFile struct {
 	broDataFactory   func() pt.BroData
 	fieldMap         ZeekHeaderIndexMap
 	json             bool
+	suricata         bool
 }
 
 //The following functions are for interacting with the private data in
@@ -57,6 +58,16 @@ func (i *IndexedFile) SetJSON() {
 	i.json = true
 }
 
+// IsSuricata returns whether the file is a Suricata eve.json file
+func (i *IndexedFile) IsSuricata() bool {
+	return i.suricata
+}
+
+// SetSuricata sets the suricata flag
+func (i *IndexedFile) SetSuricata() {
+	i.suricata = true
+}
+
 // SetHeader sets the broHeader on the indexed file
 func (i *IndexedFile) SetHeader(header *BroHeader) {
 	i.header = header
@@ -0,0 +1,61 @@
+package files
+
+import (
+	"bufio"
+	"io"
+
+	pt "github.com/activecm/rita-legacy/parser/parsetypes"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// ScanStream reads newline delimited log entries from the reader and sends them to the lines
+// channel until the reader is exhausted. Each line is copied before it is sent so the receiver
+// may hold on to it.
+func ScanStream(reader io.Reader, lines chan<- []byte, logger *log.Logger) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		lines <- line
+	}
+
+	if scanner.Err() != nil {
+		logger.WithFields(log.Fields{
+			"error": scanner.Err().Error(),
+		}).Error("Could not read from the log stream")
+	}
+}
+
+// ParseStreamLine creates a new BroData from a line of a log stream. Since a stream
+// mixes several log types, Zeek entries must name their log type in the "_path" field
+// as done by https://github.com/corelight/json-streaming-logs. Suricata eve.json events
+// are supported as well. nil is returned for entries which cannot be mapped to a parse type.
+func ParseStreamLine(lineBuffer []byte, logger *log.Logger) pt.BroData {
+	if isSuricataEvent(lineBuffer) {
+		return ParseSuricataLine(lineBuffer, logger)
+	}
+
+	t := struct {
+		Path string `json:"_path"`
+	}{}
+	err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(lineBuffer, &t)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Encountered unparsable JSON in log stream")
+		return nil
+	}
+
+	broDataFactory := pt.NewBroDataFactory(t.Path)
+	if broDataFactory == nil {
+		return nil
+	}
+	return ParseJSONLine(lineBuffer, broDataFactory, logger)
+}
@@ -0,0 +1,339 @@
+package files
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	pt "github.com/activecm/rita-legacy/parser/parsetypes"
+
+	jsoniter "github.com/json-iterator/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// suricataTimeLayout is the layout of the timestamps in Suricata's eve.json
+// ex: 2023-09-14T10:32:11.123456+0000
+const suricataTimeLayout = "2006-01-02T15:04:05.999999-0700"
+
+type (
+	// eveEvent holds the fields RITA reads from a Suricata eve.json event
+	eveEvent struct {
+		Timestamp string   `json:"timestamp"`
+		FlowID    uint64   `json:"flow_id"`
+		EventType string   `json:"event_type"`
+		SrcIP     string   `json:"src_ip"`
+		SrcPort   int      `json:"src_port"`
+		DestIP    string   `json:"dest_ip"`
+		DestPort  int      `json:"dest_port"`
+		Proto     string   `json:"proto"`
+		AppProto  string   `json:"app_proto"`
+		Flow      *eveFlow `json:"flow"`
+		TCP       *eveTCP  `json:"tcp"`
+		DNS       *eveDNS  `json:"dns"`
+		HTTP      *eveHTTP `json:"http"`
+		TLS       *eveTLS  `json:"tls"`
+	}
+
+	eveFlow struct {
+		PktsToServer  int64  `json:"pkts_toserver"`
+		PktsToClient  int64  `json:"pkts_toclient"`
+		BytesToServer int64  `json:"bytes_toserver"`
+		BytesToClient int64  `json:"bytes_toclient"`
+		Start         string `json:"start"`
+		End           string `json:"end"`
+	}
+
+	eveTCP struct {
+		// FlagsToClient is the hex encoded union of the TCP flags sent by the server
+		FlagsToClient string `json:"tcp_flags_tc"`
+	}
+
+	eveDNSRecord struct {
+		RRName string      `json:"rrname"`
+		RRType string      `json:"rrtype"`
+		TTL    float64     `json:"ttl"`
+		RData  interface{} `json:"rdata"`
+	}
+
+	eveDNS struct {
+		Type   string      `json:"type"`
+		ID     int64       `json:"id"`
+		RCode  string      `json:"rcode"`
+		RRName string      `json:"rrname"`
+		RRType string      `json:"rrtype"`
+		RData  interface{} `json:"rdata"`
+		TTL    float64     `json:"ttl"`
+		AA     bool        `json:"aa"`
+		TC     bool        `json:"tc"`
+		RD     bool        `json:"rd"`
+		RA     bool        `json:"ra"`
+		// Queries is only present in the version 3 format
+		Queries []eveDNSRecord `json:"queries"`
+		// Answers is present in the detailed version 2 and 3 formats
+		Answers []eveDNSRecord `json:"answers"`
+		// Grouped is present in the grouped version 2 and 3 formats
+		Grouped map[string][]interface{} `json:"grouped"`
+	}
+
+	eveHTTP struct {
+		Hostname  string `json:"hostname"`
+		URL       string `json:"url"`
+		UserAgent string `json:"http_user_agent"`
+		Method    string `json:"http_method"`
+		Referrer  string `json:"http_refer"`
+		Protocol  string `json:"protocol"`
+		Status    int64  `json:"status"`
+		Length    int64  `json:"length"`
+	}
+
+	eveTLS struct {
+		Subject  string `json:"subject"`
+		IssuerDN string `json:"issuerdn"`
+		SNI      string `json:"sni"`
+		Version  string `json:"version"`
+		JA3      struct {
+			Hash string `json:"hash"`
+		} `json:"ja3"`
+		JA3S struct {
+			Hash string `json:"hash"`
+		} `json:"ja3s"`
+	}
+)
+
+// isSuricataEvent returns true if the given line is a Suricata eve.json event
+// rather than a Zeek JSON log entry
+func isSuricataEvent(line []byte) bool {
+	t := struct {
+		EventType string  `json:"event_type"`
+		Path      *string `json:"_path"`
+	}{}
+	err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(line, &t)
+	return err == nil && t.EventType != "" && t.Path == nil
+}
+
+// ParseSuricataLine creates a new BroData from a line of a Suricata eve.json file.
+// Flow, DNS, HTTP, and TLS events are mapped onto the Zeek conn, dns, http, and ssl
+// parse types. The flow_id stands in for the Zeek UID so that the events belonging
+// to the same flow can be linked. nil is returned for all other events.
+func ParseSuricataLine(lineBuffer []byte, logger *log.Logger) pt.BroData {
+	var event eveEvent
+	err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(lineBuffer, &event)
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Encountered unparsable JSON in log")
+		return nil
+	}
+
+	switch event.EventType {
+	case "flow":
+		if event.Flow == nil {
+			return nil
+		}
+		return suricataFlowToConn(&event)
+	case "dns":
+		// queries are logged again alongside their answers
+		if event.DNS == nil || (event.DNS.Type != "answer" && event.DNS.Type != "response") {
+			return nil
+		}
+		return suricataDNSToDNS(&event)
+	case "http":
+		if event.HTTP == nil {
+			return nil
+		}
+		return suricataHTTPToHTTP(&event)
+	case "tls":
+		if event.TLS == nil {
+			return nil
+		}
+		return suricataTLSToSSL(&event)
+	}
+	return nil
+}
+
+func suricataFlowToConn(event *eveEvent) *pt.Conn {
+	proto := suricataProto(event.Proto)
+
+	conn := &pt.Conn{
+		UID:             suricataUID(event.FlowID),
+		Source:          event.SrcIP,
+		SourcePort:      event.SrcPort,
+		Destination:     event.DestIP,
+		DestinationPort: event.DestPort,
+		Proto:           proto,
+		OrigPkts:        event.Flow.PktsToServer,
+		RespPkts:        event.Flow.PktsToClient,
+		// Suricata counts the full size of each packet
+		OrigIPBytes: event.Flow.BytesToServer,
+		RespIPBytes: event.Flow.BytesToClient,
+		OrigBytes:   event.Flow.BytesToServer,
+		RespBytes:   event.Flow.BytesToClient,
+	}
+
+	if event.AppProto != "failed" {
+		conn.Service = strings.ToLower(event.AppProto)
+	}
+
+	start, startErr := time.Parse(suricataTimeLayout, event.Flow.Start)
+	end, endErr := time.Parse(suricataTimeLayout, event.Flow.End)
+	if startErr != nil {
+		conn.TimeStamp = suricataTimestamp(event.Timestamp)
+	} else {
+		conn.TimeStamp = start.UTC().Unix()
+		if endErr == nil && end.After(start) {
+			conn.Duration = end.Sub(start).Seconds()
+		}
+	}
+
+	// approximate Zeek's connection states so scans can be detected
+	switch {
+	case event.Flow.PktsToClient == 0:
+		conn.ConnState = "S0"
+	case proto == "tcp" && event.TCP != nil && suricataRejected(event.TCP.FlagsToClient):
+		conn.ConnState = "REJ"
+	default:
+		conn.ConnState = "SF"
+	}
+
+	return conn
+}
+
+func suricataDNSToDNS(event *eveEvent) *pt.DNS {
+	dns := &pt.DNS{
+		TimeStamp:       suricataTimestamp(event.Timestamp),
+		UID:             suricataUID(event.FlowID),
+		Source:          event.SrcIP,
+		SourcePort:      event.SrcPort,
+		Destination:     event.DestIP,
+		DestinationPort: event.DestPort,
+		Proto:           suricataProto(event.Proto),
+		TransID:         event.DNS.ID,
+		Query:           event.DNS.RRName,
+		QTypeName:       event.DNS.RRType,
+		RCodeName:       event.DNS.RCode,
+		AA:              event.DNS.AA,
+		TC:              event.DNS.TC,
+		RD:              event.DNS.RD,
+		RA:              event.DNS.RA,
+	}
+
+	// version 3 moves the query into a list
+	if len(event.DNS.Queries) > 0 {
+		dns.Query = event.DNS.Queries[0].RRName
+		dns.QTypeName = event.DNS.Queries[0].RRType
+	}
+
+	// version 1 logs each answer in its own event
+	if rdata := suricataRData(event.DNS.RData); rdata != "" {
+		dns.Answers = append(dns.Answers, rdata)
+		dns.TTLs = append(dns.TTLs, event.DNS.TTL)
+	}
+
+	for _, answer := range event.DNS.Answers {
+		if rdata := suricataRData(answer.RData); rdata != "" {
+			dns.Answers = append(dns.Answers, rdata)
+			dns.TTLs = append(dns.TTLs, answer.TTL)
+		}
+	}
+
+	// the grouped format does not include the ttls
+	for _, rdatas := range event.DNS.Grouped {
+		for _, rdata := range rdatas {
+			if rdataStr := suricataRData(rdata); rdataStr != "" {
+				dns.Answers = append(dns.Answers, rdataStr)
+			}
+		}
+	}
+
+	return dns
+}
+
+func suricataHTTPToHTTP(event *eveEvent) *pt.HTTP {
+	return &pt.HTTP{
+		TimeStamp:       suricataTimestamp(event.Timestamp),
+		UID:             suricataUID(event.FlowID),
+		Source:          event.SrcIP,
+		SourcePort:      event.SrcPort,
+		Destination:     event.DestIP,
+		DestinationPort: event.DestPort,
+		Version:         strings.TrimPrefix(event.HTTP.Protocol, "HTTP/"),
+		Method:          event.HTTP.Method,
+		Host:            event.HTTP.Hostname,
+		URI:             event.HTTP.URL,
+		Referrer:        event.HTTP.Referrer,
+		UserAgent:       event.HTTP.UserAgent,
+		RespLen:         event.HTTP.Length,
+		StatusCode:      event.HTTP.Status,
+	}
+}
+
+func suricataTLSToSSL(event *eveEvent) *pt.SSL {
+	return &pt.SSL{
+		TimeStamp:       suricataTimestamp(event.Timestamp),
+		UID:             suricataUID(event.FlowID),
+		Source:          event.SrcIP,
+		SourcePort:      event.SrcPort,
+		Destination:     event.DestIP,
+		DestinationPort: event.DestPort,
+		Version:         suricataTLSVersion(event.TLS.Version),
+		ServerName:      event.TLS.SNI,
+		Subject:         event.TLS.Subject,
+		Issuer:          event.TLS.IssuerDN,
+		JA3:             event.TLS.JA3.Hash,
+		JA3S:            event.TLS.JA3S.Hash,
+	}
+}
+
+// suricataUID formats a Suricata flow id so it may be used in place of a Zeek UID
+func suricataUID(flowID uint64) string {
+	if flowID == 0 {
+		return ""
+	}
+	return strconv.FormatUint(flowID, 10)
+}
+
+// suricataTimestamp converts a Suricata timestamp to unix time
+func suricataTimestamp(timestamp string) int64 {
+	t, err := time.Parse(suricataTimeLayout, timestamp)
+	if err != nil {
+		return -1
+	}
+	return t.UTC().Unix()
+}
+
+// suricataProto converts Suricata's transport protocol names to Zeek's
+func suricataProto(proto string) string {
+	proto = strings.ToLower(proto)
+	if proto == "ipv6-icmp" {
+		return "icmp"
+	}
+	return proto
+}
+
+// suricataRejected returns true if the server answered with a RST without ever sending a SYN
+func suricataRejected(hexFlags string) bool {
+	flags, err := strconv.ParseUint(hexFlags, 16, 8)
+	if err != nil {
+		return false
+	}
+	const syn, rst = 0x02, 0x04
+	return flags&rst != 0 && flags&syn == 0
+}
+
+// suricataRData formats the rdata of a DNS answer. Records with structured rdata such as
+// SOA records are skipped.
+func suricataRData(rdata interface{}) string {
+	if rdataStr, ok := rdata.(string); ok {
+		return rdataStr
+	}
+	return ""
+}
+
+// suricataTLSVersion converts Suricata's TLS version names to Zeek's
+// ex: TLS 1.2 -> TLSv12
+func suricataTLSVersion(version string) string {
+	if strings.HasPrefix(version, "TLS ") {
+		return "TLSv" + strings.ReplaceAll(strings.TrimPrefix(version, "TLS "), ".", "")
+	}
+	return version
+}
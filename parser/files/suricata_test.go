@@ -0,0 +1,105 @@
+package files
+
+import (
+	"testing"
+
+	pt "github.com/activecm/rita-legacy/parser/parsetypes"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSuricataEvent(t *testing.T) {
+	assert.True(t, isSuricataEvent([]byte(`{"timestamp":"2023-09-14T10:32:11.123456+0000","event_type":"flow"}`)))
+	assert.False(t, isSuricataEvent([]byte(`{"_path":"conn","ts":1694687531.123,"uid":"C1"}`)))
+	assert.False(t, isSuricataEvent([]byte(`{"ts":1694687531.123,"uid":"C1"}`)))
+}
+
+func TestParseSuricataLine(t *testing.T) {
+	logger := log.New()
+
+	testCases := []struct {
+		line string
+		out  pt.BroData
+		msg  string
+	}{
+		{
+			line: `{"timestamp":"2023-09-14T10:32:41.000000+0000","flow_id":1234,"event_type":"flow",` +
+				`"src_ip":"10.0.0.1","src_port":51234,"dest_ip":"1.2.3.4","dest_port":443,"proto":"TCP","app_proto":"tls",` +
+				`"flow":{"pkts_toserver":10,"pkts_toclient":8,"bytes_toserver":1200,"bytes_toclient":5400,` +
+				`"start":"2023-09-14T10:32:11.000000+0000","end":"2023-09-14T10:32:41.500000+0000"},"tcp":{"tcp_flags_tc":"1b"}}`,
+			out: &pt.Conn{
+				TimeStamp: 1694687531, UID: "1234", Source: "10.0.0.1", SourcePort: 51234,
+				Destination: "1.2.3.4", DestinationPort: 443, Proto: "tcp", Service: "tls", Duration: 30.5,
+				OrigBytes: 1200, RespBytes: 5400, ConnState: "SF", OrigPkts: 10, OrigIPBytes: 1200,
+				RespPkts: 8, RespIPBytes: 5400,
+			},
+			msg: "flow events are mapped onto conn entries",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","flow_id":5,"event_type":"flow",` +
+				`"src_ip":"10.0.0.1","src_port":51234,"dest_ip":"10.0.0.2","dest_port":22,"proto":"TCP","app_proto":"failed",` +
+				`"flow":{"pkts_toserver":1,"pkts_toclient":1,"bytes_toserver":74,"bytes_toclient":60,` +
+				`"start":"2023-09-14T10:32:11.000000+0000","end":"2023-09-14T10:32:11.000000+0000"},"tcp":{"tcp_flags_tc":"14"}}`,
+			out: &pt.Conn{
+				TimeStamp: 1694687531, UID: "5", Source: "10.0.0.1", SourcePort: 51234,
+				Destination: "10.0.0.2", DestinationPort: 22, Proto: "tcp",
+				OrigBytes: 74, RespBytes: 60, ConnState: "REJ", OrigPkts: 1, OrigIPBytes: 74,
+				RespPkts: 1, RespIPBytes: 60,
+			},
+			msg: "flows answered with a reset are marked as rejected",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","flow_id":99,"event_type":"dns",` +
+				`"src_ip":"10.0.0.1","src_port":5353,"dest_ip":"8.8.8.8","dest_port":53,"proto":"UDP",` +
+				`"dns":{"version":2,"type":"answer","id":42,"rd":true,"ra":true,"rrname":"example.com","rrtype":"A","rcode":"NOERROR",` +
+				`"answers":[{"rrname":"example.com","rrtype":"A","ttl":300,"rdata":"93.184.216.34"}]}}`,
+			out: &pt.DNS{
+				TimeStamp: 1694687531, UID: "99", Source: "10.0.0.1", SourcePort: 5353,
+				Destination: "8.8.8.8", DestinationPort: 53, Proto: "udp", TransID: 42,
+				Query: "example.com", QTypeName: "A", RCodeName: "NOERROR", RD: true, RA: true,
+				Answers: []string{"93.184.216.34"}, TTLs: []float64{300},
+			},
+			msg: "dns answers are mapped onto dns entries",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","flow_id":99,"event_type":"dns",` +
+				`"src_ip":"10.0.0.1","src_port":5353,"dest_ip":"8.8.8.8","dest_port":53,"proto":"UDP",` +
+				`"dns":{"type":"query","id":42,"rrname":"example.com","rrtype":"A"}}`,
+			out: nil,
+			msg: "dns queries are skipped since they are repeated in the answers",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","flow_id":7,"event_type":"http",` +
+				`"src_ip":"10.0.0.1","src_port":50000,"dest_ip":"1.2.3.4","dest_port":80,"proto":"TCP",` +
+				`"http":{"hostname":"example.com","url":"/index.html","http_user_agent":"curl/8.0","http_method":"GET",` +
+				`"protocol":"HTTP/1.1","status":200,"length":512}}`,
+			out: &pt.HTTP{
+				TimeStamp: 1694687531, UID: "7", Source: "10.0.0.1", SourcePort: 50000,
+				Destination: "1.2.3.4", DestinationPort: 80, Version: "1.1", Method: "GET",
+				Host: "example.com", URI: "/index.html", UserAgent: "curl/8.0", RespLen: 512, StatusCode: 200,
+			},
+			msg: "http events are mapped onto http entries",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","flow_id":1234,"event_type":"tls",` +
+				`"src_ip":"10.0.0.1","src_port":51234,"dest_ip":"1.2.3.4","dest_port":443,"proto":"TCP",` +
+				`"tls":{"subject":"CN=example.com","issuerdn":"CN=Example CA","sni":"example.com","version":"TLS 1.2",` +
+				`"ja3":{"hash":"abc"},"ja3s":{"hash":"def"}}}`,
+			out: &pt.SSL{
+				TimeStamp: 1694687531, UID: "1234", Source: "10.0.0.1", SourcePort: 51234,
+				Destination: "1.2.3.4", DestinationPort: 443, Version: "TLSv12", ServerName: "example.com",
+				Subject: "CN=example.com", Issuer: "CN=Example CA", JA3: "abc", JA3S: "def",
+			},
+			msg: "tls events are mapped onto ssl entries",
+		},
+		{
+			line: `{"timestamp":"2023-09-14T10:32:11.000000+0000","event_type":"alert","src_ip":"10.0.0.1","dest_ip":"1.2.3.4"}`,
+			out:  nil,
+			msg:  "unsupported events are skipped",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.out, ParseSuricataLine([]byte(test.line), logger), test.msg)
+	}
+}
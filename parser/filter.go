@@ -18,6 +18,9 @@ type filter struct {
 	neverIncludedDomain  []string
 
 	filterExternalToInternal bool
+
+	lateralMovementEnabled bool
+	lateralMovementPorts   []int
 }
 
 func newFilter(conf *config.Config) (filter, error) {
@@ -43,6 +46,8 @@ func newFilter(conf *config.Config) (filter, error) {
 		alwaysIncludedDomain:     conf.S.Filtering.AlwaysIncludeDomain,
 		neverIncludedDomain:      conf.S.Filtering.NeverIncludeDomain,
 		filterExternalToInternal: conf.S.Filtering.FilterExternalToInternal,
+		lateralMovementEnabled:   conf.S.LateralMovement.Enabled,
+		lateralMovementPorts:     conf.S.LateralMovement.Ports,
 	}, nil
 }
 
@@ -155,6 +160,55 @@ func (fs *filter) filterDNSPair(srcIP net.IP, dstIP net.IP) bool {
 	return false
 }
 
+// filterLateralConn returns true if a connection is filtered/excluded from lateral movement analysis.
+// Lateral movement analysis looks at the internal to internal traffic which filterConnPair excludes.
+// This is determined by the following rules, in order:
+//  1. Filtered if lateral movement analysis is disabled in the configuration file
+//  2. Filtered if the destination port is not one of the configured administrative ports
+//  3. Filtered if either IP is on the NeverInclude list and neither IP is on the AlwaysInclude list
+//  4. Filtered if InternalSubnets is empty
+//  5. Filtered if either IP is external
+//  6. Not filtered in all other cases
+func (fs *filter) filterLateralConn(srcIP net.IP, dstIP net.IP, dstPort int) bool {
+	if !fs.lateralMovementEnabled {
+		return true
+	}
+
+	isAdminPort := false
+	for _, port := range fs.lateralMovementPorts {
+		if port == dstPort {
+			isAdminPort = true
+			break
+		}
+	}
+	if !isAdminPort {
+		return true
+	}
+
+	// check if on always included list
+	isIncluded := util.ContainsIP(fs.alwaysIncluded, srcIP) || util.ContainsIP(fs.alwaysIncluded, dstIP)
+
+	// check if on never included list
+	isExcluded := util.ContainsIP(fs.neverIncluded, srcIP) || util.ContainsIP(fs.neverIncluded, dstIP)
+
+	if isExcluded && !isIncluded {
+		return true
+	}
+
+	// without internal subnets, internal to internal traffic can't be identified
+	if len(fs.internal) == 0 {
+		return true
+	}
+
+	// both addresses must be internal
+	if !util.ContainsIP(fs.internal, srcIP) || !util.ContainsIP(fs.internal, dstIP) {
+		return true
+	}
+
+	// default to not filter the connection
+	return false
+}
+
 // filterSingleIP returns true if an IP is filtered/excluded.
 // This is determined by the following rules, in order:
 //  1. Not filtered IP is on the AlwaysInclude list
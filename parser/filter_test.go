@@ -158,6 +158,46 @@ func TestFilterConnPairExternalToInternal(t *testing.T) {
 	}
 }
 
+func TestFilterLateralConn(t *testing.T) {
+	internalNets, _ := util.ParseSubnets([]string{"10.0.0.0/8"})
+	alwaysInclude, _ := util.ParseSubnets([]string{"10.0.0.1/32", "10.0.0.3/32"})
+	neverInclude, _ := util.ParseSubnets([]string{"10.0.0.2/32", "10.0.0.3/32"})
+
+	fsTest := &filter{
+		internal:               internalNets,
+		alwaysIncluded:         alwaysInclude,
+		neverIncluded:          neverInclude,
+		lateralMovementEnabled: true,
+		lateralMovementPorts:   []int{445, 3389},
+	}
+
+	internal := "10.0.0.0"
+	internalAlways := "10.0.0.1"
+	internalNever := "10.0.0.2"
+	internalAlwaysNever := "10.0.0.3"
+	external := "1.1.1.0"
+
+	testCases := []testCase{
+		{internal, internal, false, "internal to internal should not be filtered"},
+		{internal, internalNever, true, "NeverInclude should be filtered"},
+		{internal, internalAlwaysNever, false, "AlwaysInclude should override NeverInclude when one IP is in both"},
+		{internalAlways, internalNever, false, "AlwaysInclude should override NeverInclude when src and dst conflict"},
+		{internal, external, true, "internal to external should be filtered"},
+		{external, internal, true, "external to internal should be filtered"},
+		{external, external, true, "external to external should be filtered"},
+	}
+
+	for _, test := range testCases {
+		output := fsTest.filterLateralConn(net.ParseIP(test.src), net.ParseIP(test.dst), 445)
+		assert.Equal(t, test.out, output, test.msg)
+	}
+
+	assert.True(t, fsTest.filterLateralConn(net.ParseIP(internal), net.ParseIP(internal), 80), "non-administrative ports should be filtered")
+
+	fsTest.lateralMovementEnabled = false
+	assert.True(t, fsTest.filterLateralConn(net.ParseIP(internal), net.ParseIP(internal), 445), "all connections should be filtered when disabled")
+}
+
 func TestFilterDomain(t *testing.T) {
 	internalNets, _ := util.ParseSubnets([]string{"10.0.0.0/8"})
 	alwaysInclude, _ := util.ParseSubnets([]string{"10.0.0.1/32", "10.0.0.3/32", "1.1.1.1/32", "1.1.1.3/32"})
@@ -13,17 +13,24 @@ import (
 	"github.com/activecm/rita-legacy/parser/files"
 	"github.com/activecm/rita-legacy/parser/parsetypes"
 	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/beacondns"
 	"github.com/activecm/rita-legacy/pkg/beaconproxy"
 	"github.com/activecm/rita-legacy/pkg/beaconsni"
 	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/pkg/certificate"
 	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
+	"github.com/activecm/rita-legacy/pkg/exfil"
 	"github.com/activecm/rita-legacy/pkg/explodeddns"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/hostname"
+	"github.com/activecm/rita-legacy/pkg/lateral"
 	"github.com/activecm/rita-legacy/pkg/remover"
+	"github.com/activecm/rita-legacy/pkg/scan"
 	"github.com/activecm/rita-legacy/pkg/sniconn"
+	"github.com/activecm/rita-legacy/pkg/threat"
 	"github.com/activecm/rita-legacy/pkg/uconn"
+	"github.com/activecm/rita-legacy/pkg/uconndns"
 	"github.com/activecm/rita-legacy/pkg/uconnproxy"
 	"github.com/activecm/rita-legacy/pkg/useragent"
 	"github.com/activecm/rita-legacy/resources"
@@ -84,10 +91,10 @@ func (fs *FSImporter) GetInternalSubnets() []*net.IPNet {
 	return fs.internal
 }
 
-// CollectFileDetails reads and hashes the files
-func (fs *FSImporter) CollectFileDetails(importFiles []string, threads int) []*files.IndexedFile {
+// CollectFileDetails reads and hashes the files selected by opts
+func (fs *FSImporter) CollectFileDetails(importFiles []string, opts files.GatherOptions, threads int) []*files.IndexedFile {
 	// find all of the potential bro log paths
-	logFiles := files.GatherLogFiles(importFiles, fs.log)
+	logFiles := files.GatherLogFiles(importFiles, opts, fs.log)
 
 	// hash the files and get their stats
 	return files.IndexFiles(
@@ -114,6 +121,62 @@ func (fs *FSImporter) Run(indexedFiles []*files.IndexedFile, threads int) {
 		return
 	}
 
+	if !fs.prepareChunk() {
+		return
+	}
+
+	// batch up the indexed files so as not to read too much in at one time
+	batchedIndexedFiles := batchFilesBySize(indexedFiles, fs.batchSizeBytes)
+
+	for i, indexedFileBatch := range batchedIndexedFiles {
+		fmt.Printf("\t[-] Processing batch %d of %d\n", i+1, len(batchedIndexedFiles))
+
+		// parse in those files!
+		retVals := fs.parseFiles(indexedFileBatch, threads, fs.log)
+
+		// Set chunk before we continue so if process dies, we still verify with a delete if
+		// any data was written out.
+		fs.metaDB.SetChunk(fs.config.S.Rolling.CurrentChunk, fs.database.GetSelectedDB(), true)
+
+		// analyze the parsed data
+		fs.analyze(retVals)
+
+		// record file+database name hash in metadabase to prevent duplicate content
+		fmt.Println("\t[-] Indexing log entries ... ")
+		err := fs.metaDB.AddNewFilesToIndex(indexedFileBatch)
+		if err != nil {
+			fs.log.Error("Could not update the list of parsed files")
+		}
+
+	}
+
+	// mark results as imported and analyzed
+	fmt.Println("\t[-] Updating metadatabase ... ")
+	fs.metaDB.MarkDBAnalyzed(fs.database.GetSelectedDB(), true)
+
+	progTime := time.Now()
+	fs.log.WithFields(
+		log.Fields{
+			"current_time": progTime.Format(util.TimeFormat),
+			"total_time":   progTime.Sub(start).String(),
+		},
+	).Info("Finished upload. Starting indexing")
+
+	progTime = time.Now()
+	fs.log.WithFields(
+		log.Fields{
+			"current_time": progTime.Format(util.TimeFormat),
+			"total_time":   progTime.Sub(start).String(),
+		},
+	).Info("Finished importing log files")
+
+	fmt.Println("\t[-] Done!")
+}
+
+// prepareChunk ensures the target database is recorded in the MetaDB and clears out
+// any data previously stored in the current chunk of a rolling database. Returns
+// false if the chunk could not be prepared.
+func (fs *FSImporter) prepareChunk() bool {
 	// Add new metadatabase record for db if doesn't already exist
 	dbExists, err := fs.metaDB.DBExists(fs.database.GetSelectedDB())
 	if err != nil {
@@ -148,7 +211,7 @@ func (fs *FSImporter) Run(indexedFiles []*files.IndexedFile, threads int) {
 		chunkSet, err := fs.metaDB.IsChunkSet(fs.config.S.Rolling.CurrentChunk, fs.database.GetSelectedDB())
 		if err != nil {
 			fmt.Println("\t[!] Could not find CID List entry in metadatabase")
-			return
+			return false
 		}
 
 		if chunkSet {
@@ -156,7 +219,7 @@ func (fs *FSImporter) Run(indexedFiles []*files.IndexedFile, threads int) {
 			err := fs.removeAnalysisChunk(fs.config.S.Rolling.CurrentChunk)
 			if err != nil {
 				fmt.Println("\t[!] Failed to remove outdata data from rolling dataset")
-				return
+				return false
 			}
 		}
 	}
@@ -166,88 +229,70 @@ func (fs *FSImporter) Run(indexedFiles []*files.IndexedFile, threads int) {
 		blacklist.BuildBlacklistedCollections(fs.database, fs.config, fs.log)
 	}
 
-	// batch up the indexed files so as not to read too much in at one time
-	batchedIndexedFiles := batchFilesBySize(indexedFiles, fs.batchSizeBytes)
-
-	for i, indexedFileBatch := range batchedIndexedFiles {
-		fmt.Printf("\t[-] Processing batch %d of %d\n", i+1, len(batchedIndexedFiles))
+	return true
+}
 
-		// parse in those files!
-		retVals := fs.parseFiles(indexedFileBatch, threads, fs.log)
+// analyze builds the analysis collections from the parsed data
+func (fs *FSImporter) analyze(retVals ParseResults) {
+	// build Hosts table.
+	fs.buildHosts(retVals.HostMap)
 
-		// Set chunk before we continue so if process dies, we still verify with a delete if
-		// any data was written out.
-		fs.metaDB.SetChunk(fs.config.S.Rolling.CurrentChunk, fs.database.GetSelectedDB(), true)
+	// build Uconns table. Must go before beacons.
+	fs.buildUconns(retVals.UniqueConnMap, retVals.HostMap)
 
-		// build Hosts table.
-		fs.buildHosts(retVals.HostMap)
+	// build uconnsProxy table. Must go before proxy beacons
+	fs.buildUconnsProxy(retVals.ProxyUniqueConnMap)
 
-		// build Uconns table. Must go before beacons.
-		fs.buildUconns(retVals.UniqueConnMap, retVals.HostMap)
+	// build SNIconns table. Must go before SNI beacons
+	fs.buildSNIConns(retVals.TLSConnMap, retVals.HTTPConnMap, retVals.ZeekUIDMap, retVals.HostMap, retVals.X509Map)
 
-		// build uconnsProxy table. Must go before proxy beacons
-		fs.buildUconnsProxy(retVals.ProxyUniqueConnMap)
+	// build or update the data exfiltration table
+	fs.buildExfil(retVals.UniqueConnMap, retVals.TLSConnMap, retVals.HTTPConnMap, retVals.ZeekUIDMap)
 
-		// build SNIconns table. Must go before SNI beacons
-		fs.buildSNIConns(retVals.TLSConnMap, retVals.HTTPConnMap, retVals.ZeekUIDMap, retVals.HostMap)
+	// update ts range for dataset (needs to be run before beacons)
+	minTimestamp, maxTimestamp := fs.updateTimestampRange()
 
-		// update ts range for dataset (needs to be run before beacons)
-		minTimestamp, maxTimestamp := fs.updateTimestampRange()
+	// build or update the exploded DNS table. Must go before hostnames
+	fs.buildExplodedDNS(retVals.ExplodedDNSMap)
 
-		// build or update the exploded DNS table. Must go before hostnames
-		fs.buildExplodedDNS(retVals.ExplodedDNSMap)
+	// build or update the exploded DNS table
+	fs.buildHostnames(retVals.HostnameMap)
 
-		// build or update the exploded DNS table
-		fs.buildHostnames(retVals.HostnameMap)
+	// build or update the DNS tunneling table
+	fs.buildDNSTunnels(retVals.DNSTunnelMap)
 
-		// build or update Beacons table
-		fs.buildBeacons(retVals.UniqueConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
+	// build uconnsDNS table. Must go before DNS beacons
+	fs.buildUconnsDNS(retVals.DNSUniqueConnMap)
 
-		// build or update the Proxy Beacons Table
-		fs.buildProxyBeacons(retVals.ProxyUniqueConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
+	// build or update Beacons table
+	fs.buildBeacons(retVals.UniqueConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
 
-		// build or update SNI Beacons Table
-		fs.buildSNIBeacons(retVals.TLSConnMap, retVals.HTTPConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
+	// build or update the Proxy Beacons Table
+	fs.buildProxyBeacons(retVals.ProxyUniqueConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
 
-		// build or update UserAgent table
-		fs.buildUserAgent(retVals.UseragentMap, retVals.HostMap)
+	// build or update the DNS Beacons Table
+	fs.buildDNSBeacons(retVals.DNSUniqueConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
 
-		// build or update Certificate table
-		fs.buildCertificates(retVals.CertificateMap)
+	// build or update SNI Beacons Table
+	fs.buildSNIBeacons(retVals.TLSConnMap, retVals.HTTPConnMap, retVals.HostMap, minTimestamp, maxTimestamp)
 
-		// update blacklisted peers in hosts collection
-		fs.markBlacklistedPeers(retVals.HostMap)
+	// build or update the port scan table
+	fs.buildScans(retVals.ScanMap)
 
-		// record file+database name hash in metadabase to prevent duplicate content
-		fmt.Println("\t[-] Indexing log entries ... ")
-		err := fs.metaDB.AddNewFilesToIndex(indexedFileBatch)
-		if err != nil {
-			fs.log.Error("Could not update the list of parsed files")
-		}
+	// build or update the lateral movement table
+	fs.buildLateral(retVals.LateralMap)
 
-	}
+	// build or update UserAgent table
+	fs.buildUserAgent(retVals.UseragentMap, retVals.HostMap)
 
-	// mark results as imported and analyzed
-	fmt.Println("\t[-] Updating metadatabase ... ")
-	fs.metaDB.MarkDBAnalyzed(fs.database.GetSelectedDB(), true)
+	// build or update Certificate table
+	fs.buildCertificates(retVals.CertificateMap, retVals.X509Map)
 
-	progTime := time.Now()
-	fs.log.WithFields(
-		log.Fields{
-			"current_time": progTime.Format(util.TimeFormat),
-			"total_time":   progTime.Sub(start).String(),
-		},
-	).Info("Finished upload. Starting indexing")
+	// update blacklisted peers in hosts collection
+	fs.markBlacklistedPeers(retVals.HostMap)
 
-	progTime = time.Now()
-	fs.log.WithFields(
-		log.Fields{
-			"current_time": progTime.Format(util.TimeFormat),
-			"total_time":   progTime.Sub(start).String(),
-		},
-	).Info("Finished importing log files")
-
-	fmt.Println("\t[-] Done!")
+	// score the internal hosts. Must go after every module which summarizes hosts
+	fs.scoreHosts(retVals.HostMap)
 }
 
 // batchFilesBySize takes in an slice of indexedFiles and splits the array into
@@ -361,7 +406,9 @@ func (fs *FSImporter) parseFiles(indexedFiles []*files.IndexedFile, parsingThrea
 
 					//parse the line
 					var entry parsetypes.BroData
-					if indexedFiles[j].IsJSON() {
+					if indexedFiles[j].IsSuricata() {
+						entry = files.ParseSuricataLine(fileScanner.Bytes(), logger)
+					} else if indexedFiles[j].IsJSON() {
 						entry = files.ParseJSONLine(fileScanner.Bytes(), indexedFiles[j].GetBroDataFactory(), logger)
 					} else {
 						// I've tried to increase performance by avoiding the allocations that result from
@@ -376,18 +423,7 @@ func (fs *FSImporter) parseFiles(indexedFiles []*files.IndexedFile, parsingThrea
 						continue
 					}
 
-					switch typedEntry := entry.(type) {
-					case *parsetypes.Conn:
-						parseConnEntry(typedEntry, fs.filter, retVals, logger)
-					case *parsetypes.DNS:
-						parseDNSEntry(typedEntry, fs.filter, retVals, logger)
-					case *parsetypes.HTTP:
-						parseHTTPEntry(typedEntry, fs.filter, retVals, logger)
-					case *parsetypes.OpenConn:
-						parseOpenConnEntry(typedEntry, fs.filter, retVals, logger)
-					case *parsetypes.SSL:
-						parseSSLEntry(typedEntry, fs.filter, retVals, logger)
-					}
+					fs.parseEntry(entry, retVals, logger)
 				}
 				indexedFiles[j].ParseTime = time.Now()
 				closeScanner() // handles closing the underlying fileHandle
@@ -416,6 +452,24 @@ func (fs *FSImporter) parseFiles(indexedFiles []*files.IndexedFile, parsingThrea
 	return retVals
 }
 
+// parseEntry hands a parsed log entry off to the parser for its type
+func (fs *FSImporter) parseEntry(entry parsetypes.BroData, retVals ParseResults, logger *log.Logger) {
+	switch typedEntry := entry.(type) {
+	case *parsetypes.Conn:
+		parseConnEntry(typedEntry, fs.filter, retVals, logger)
+	case *parsetypes.DNS:
+		parseDNSEntry(typedEntry, fs.filter, fs.config, retVals, logger)
+	case *parsetypes.HTTP:
+		parseHTTPEntry(typedEntry, fs.filter, retVals, logger)
+	case *parsetypes.OpenConn:
+		parseOpenConnEntry(typedEntry, fs.filter, retVals, logger)
+	case *parsetypes.SSL:
+		parseSSLEntry(typedEntry, fs.filter, retVals, logger)
+	case *parsetypes.X509:
+		parseX509Entry(typedEntry, retVals, logger)
+	}
+}
+
 // buildExplodedDNS .....
 func (fs *FSImporter) buildExplodedDNS(domainMap map[string]int) {
 
@@ -434,8 +488,81 @@ func (fs *FSImporter) buildExplodedDNS(domainMap map[string]int) {
 	}
 }
 
+// buildDNSTunnels .....
+func (fs *FSImporter) buildDNSTunnels(tunnelMap map[string]*dnstunnel.Input) {
+
+	if fs.config.S.DNSTunnel.Enabled {
+		if len(tunnelMap) > 0 {
+			// Set up the database
+			dnsTunnelRepo := dnstunnel.NewMongoRepository(fs.database, fs.config, fs.log)
+			err := dnsTunnelRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+			dnsTunnelRepo.Upsert(tunnelMap)
+		} else {
+			fmt.Println("\t[!] No DNS tunneling data to analyze")
+		}
+	}
+}
+
+// buildScans .....
+func (fs *FSImporter) buildScans(scanMap map[string]*scan.Input) {
+
+	if fs.config.S.Scan.Enabled {
+		if len(scanMap) > 0 {
+			// Set up the database
+			scanRepo := scan.NewMongoRepository(fs.database, fs.config, fs.log)
+			err := scanRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+			scanRepo.Upsert(scanMap)
+		} else {
+			fmt.Println("\t[!] No port scan data to analyze")
+		}
+	}
+}
+
+// buildExfil .....
+func (fs *FSImporter) buildExfil(uconnMap map[string]*uconn.Input, tlsMap map[string]*sniconn.TLSInput,
+	httpMap map[string]*sniconn.HTTPInput, zeekUIDMap map[string]*data.ZeekUIDRecord) {
+
+	if fs.config.S.Exfil.Enabled {
+		if len(uconnMap) > 0 || len(tlsMap) > 0 || len(httpMap) > 0 {
+			// Set up the database
+			exfilRepo := exfil.NewMongoRepository(fs.database, fs.config, fs.log)
+			err := exfilRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+			exfilRepo.Upsert(uconnMap, tlsMap, httpMap, zeekUIDMap)
+		} else {
+			fmt.Println("\t[!] No data exfiltration data to analyze")
+		}
+	}
+}
+
+// buildLateral .....
+func (fs *FSImporter) buildLateral(lateralMap map[string]*lateral.Input) {
+
+	if fs.config.S.LateralMovement.Enabled {
+		if len(lateralMap) > 0 {
+			// Set up the database
+			lateralRepo := lateral.NewMongoRepository(fs.database, fs.config, fs.log)
+			err := lateralRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+			lateralRepo.Upsert(lateralMap)
+		} else {
+			fmt.Println("\t[!] No lateral movement data to analyze")
+		}
+	}
+}
+
 // buildCertificates .....
-func (fs *FSImporter) buildCertificates(certMap map[string]*certificate.Input) {
+func (fs *FSImporter) buildCertificates(certMap map[string]*certificate.Input, x509Map map[string]*certificate.X509Input) {
 
 	if len(certMap) > 0 {
 		// Set up the database
@@ -444,9 +571,9 @@ func (fs *FSImporter) buildCertificates(certMap map[string]*certificate.Input) {
 		if err != nil {
 			fs.log.Error(err)
 		}
-		certificateRepo.Upsert(certMap)
+		certificateRepo.Upsert(certMap, x509Map)
 	} else {
-		fmt.Println("\t[!] No invalid certificate data to analyze")
+		fmt.Println("\t[!] No certificate data to analyze")
 	}
 
 }
@@ -486,9 +613,12 @@ func (fs *FSImporter) buildHostnames(hostnameMap map[string]*hostname.Input) {
 }
 
 func (fs *FSImporter) buildSNIConns(tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*sniconn.HTTPInput,
-	zeekUIDMap map[string]*data.ZeekUIDRecord, hostMap map[string]*host.Input) {
+	zeekUIDMap map[string]*data.ZeekUIDRecord, hostMap map[string]*host.Input, x509Map map[string]*certificate.X509Input) {
 	if fs.config.S.BeaconSNI.Enabled { // only enable SNIConns if a downstream analysis needs it
 		if len(tlsMap) != 0 || len(httpMap) != 0 {
+			// certificate fingerprints are checked against the certificate blacklists
+			linkTLSCertFingerprints(tlsMap, x509Map)
+
 			sniconnRepo := sniconn.NewMongoRepository(fs.database, fs.config, fs.log)
 
 			err := sniconnRepo.CreateIndexes()
@@ -521,6 +651,25 @@ func (fs *FSImporter) buildUconnsProxy(uconnProxyMap map[string]*uconnproxy.Inpu
 	}
 }
 
+func (fs *FSImporter) buildUconnsDNS(uconnDNSMap map[string]*uconndns.Input) {
+	// the DNS query timestamps are only needed for DNS beacon analysis
+	if fs.config.S.BeaconDNS.Enabled {
+		if len(uconnDNSMap) > 0 {
+			uconnDNSRepo := uconndns.NewMongoRepository(fs.database, fs.config, fs.log)
+
+			err := uconnDNSRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+
+			// send uconnDNSMap to uconnDNS analysis
+			uconnDNSRepo.Upsert(uconnDNSMap)
+		} else {
+			fmt.Println("\t[!] No DNS Uconn data to analyze")
+		}
+	}
+}
+
 func (fs *FSImporter) buildUconns(uconnMap map[string]*uconn.Input, hostMap map[string]*host.Input) {
 	// non-optional module
 	if len(uconnMap) > 0 {
@@ -575,6 +724,22 @@ func (fs *FSImporter) markBlacklistedPeers(hostMap map[string]*host.Input) {
 	}
 }
 
+func (fs *FSImporter) scoreHosts(hostMap map[string]*host.Input) {
+	if fs.config.S.ThreatScore.Enabled {
+		if len(hostMap) > 0 {
+			threatRepo := threat.NewMongoRepository(fs.database, fs.config, fs.log)
+
+			err := threatRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+
+			// send the hosts out for threat scoring
+			threatRepo.Upsert(hostMap)
+		}
+	}
+}
+
 func (fs *FSImporter) buildBeacons(uconnMap map[string]*uconn.Input, hostMap map[string]*host.Input, minTimestamp, maxTimestamp int64) {
 	if fs.config.S.Beacon.Enabled {
 		if len(uconnMap) > 0 {
@@ -613,6 +778,25 @@ func (fs *FSImporter) buildProxyBeacons(uconnProxyMap map[string]*uconnproxy.Inp
 
 }
 
+func (fs *FSImporter) buildDNSBeacons(uconnDNSMap map[string]*uconndns.Input, hostMap map[string]*host.Input, minTimestamp, maxTimestamp int64) {
+	if fs.config.S.BeaconDNS.Enabled {
+		if len(uconnDNSMap) > 0 {
+			beaconDNSRepo := beacondns.NewMongoRepository(fs.database, fs.config, fs.log)
+
+			err := beaconDNSRepo.CreateIndexes()
+			if err != nil {
+				fs.log.Error(err)
+			}
+
+			// send DNS uconns to beacon analysis
+			beaconDNSRepo.Upsert(uconnDNSMap, hostMap, minTimestamp, maxTimestamp)
+		} else {
+			fmt.Println("\t[!] No DNS Beacon data to analyze")
+		}
+	}
+
+}
+
 func (fs *FSImporter) buildSNIBeacons(tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*sniconn.HTTPInput, hostMap map[string]*host.Input, minTimestamp, maxTimestamp int64) {
 	if fs.config.S.BeaconSNI.Enabled {
 		if len(tlsMap) > 0 || len(httpMap) > 0 {
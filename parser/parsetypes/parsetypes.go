@@ -41,6 +41,10 @@ func NewBroDataFactory(fileType string) func() BroData {
 		return func() BroData {
 			return &SSL{}
 		}
+	} else if strings.HasPrefix(fileType, "x509") {
+		return func() BroData {
+			return &X509{}
+		}
 	}
 	return nil
 }
@@ -166,6 +170,9 @@ const (
 	// STRING_VECTOR is a VECTOR which contains STRINGs
 	StringVector = "vector[string]"
 
+	// ADDR_VECTOR is a VECTOR which contains ADDRs
+	AddrVector = "vector[addr]"
+
 	// INTERVAL_VECTOR is a VECTOR which contains INTERVALs
 	IntervalVector = "vector[interval]"
 
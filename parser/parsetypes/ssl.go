@@ -72,6 +72,9 @@ type SSL struct {
 	Logged bool `bson:"logged" bro:"logged" brotype:"bool" json:"logged"`
 	// CertChainFuids
 	CertChainFuids []string `bson:"cert_chain_fuids" bro:"cert_chain_fuids" brotype:"vector[string]" json:"cert_chain_fuids"`
+	// CertChainFps lists the fingerprints of the certificates offered by the server.
+	// Note: replaces cert_chain_fuids in zeek 6.0 and newer.
+	CertChainFps []string `bson:"cert_chain_fps" bro:"cert_chain_fps" brotype:"vector[string]" json:"cert_chain_fps"`
 	// ClientCertChainFuids
 	ClientCertChainFuids []string `bson:"client_cert_chain_fuids"  bro:"client_cert_chain_fuids" brotype:"vector[string]" json:"client_cert_chain_fuids"`
 	// Subject
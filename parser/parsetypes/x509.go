@@ -0,0 +1,82 @@
+package parsetypes
+
+import (
+	"github.com/activecm/rita-legacy/config"
+)
+
+// X509 provides a data structure for zeek's x509 certificate data
+type X509 struct {
+	// TimeStamp is the time when the certificate was seen
+	TimeStamp int64 `bson:"ts" bro:"ts" brotype:"time" json:"-"`
+	// TimeStampGeneric is used when reading from json files
+	TimeStampGeneric interface{} `bson:"-" json:"ts"`
+	// ID is the file id of the certificate. This matches the
+	// ids listed in the cert_chain_fuids field of the ssl log.
+	ID string `bson:"id" bro:"id" brotype:"string" json:"id"`
+	// Fingerprint is the hash of the certificate. This matches the
+	// hashes listed in the cert_chain_fps field of the ssl log.
+	// Note: only present in zeek 5.0 and newer.
+	Fingerprint string `bson:"fingerprint" bro:"fingerprint" brotype:"string" json:"fingerprint"`
+	// Version is the version number of the certificate
+	Version int `bson:"certificate_version" bro:"certificate.version" brotype:"count" json:"certificate.version"`
+	// Serial is the serial number of the certificate
+	Serial string `bson:"certificate_serial" bro:"certificate.serial" brotype:"string" json:"certificate.serial"`
+	// Subject is the subject of the certificate
+	Subject string `bson:"certificate_subject" bro:"certificate.subject" brotype:"string" json:"certificate.subject"`
+	// Issuer is the issuer of the certificate
+	Issuer string `bson:"certificate_issuer" bro:"certificate.issuer" brotype:"string" json:"certificate.issuer"`
+	// NotValidBefore is the timestamp before which the certificate is not valid
+	NotValidBefore int64 `bson:"certificate_not_valid_before" bro:"certificate.not_valid_before" brotype:"time" json:"-"`
+	// NotValidBeforeGeneric is used when reading from json files
+	NotValidBeforeGeneric interface{} `bson:"-" json:"certificate.not_valid_before"`
+	// NotValidAfter is the timestamp after which the certificate is not valid
+	NotValidAfter int64 `bson:"certificate_not_valid_after" bro:"certificate.not_valid_after" brotype:"time" json:"-"`
+	// NotValidAfterGeneric is used when reading from json files
+	NotValidAfterGeneric interface{} `bson:"-" json:"certificate.not_valid_after"`
+	// KeyAlg is the name of the key algorithm
+	KeyAlg string `bson:"certificate_key_alg" bro:"certificate.key_alg" brotype:"string" json:"certificate.key_alg"`
+	// SigAlg is the name of the signature algorithm
+	SigAlg string `bson:"certificate_sig_alg" bro:"certificate.sig_alg" brotype:"string" json:"certificate.sig_alg"`
+	// KeyType is the key type, if the key is parseable by openssl (rsa, dsa, or ecdsa)
+	KeyType string `bson:"certificate_key_type" bro:"certificate.key_type" brotype:"string" json:"certificate.key_type"`
+	// KeyLength is the key length in bits
+	KeyLength int `bson:"certificate_key_length" bro:"certificate.key_length" brotype:"count" json:"certificate.key_length"`
+	// Exponent is the exponent, if the key is an RSA key
+	Exponent string `bson:"certificate_exponent" bro:"certificate.exponent" brotype:"string" json:"certificate.exponent"`
+	// Curve is the curve, if the key is an EC key
+	Curve string `bson:"certificate_curve" bro:"certificate.curve" brotype:"string" json:"certificate.curve"`
+	// SANDNS lists the DNS entries in the subject alternative name extension
+	SANDNS []string `bson:"san_dns" bro:"san.dns" brotype:"vector[string]" json:"san.dns"`
+	// SANURI lists the URI entries in the subject alternative name extension
+	SANURI []string `bson:"san_uri" bro:"san.uri" brotype:"vector[string]" json:"san.uri"`
+	// SANEmail lists the email entries in the subject alternative name extension
+	SANEmail []string `bson:"san_email" bro:"san.email" brotype:"vector[string]" json:"san.email"`
+	// SANIP lists the IP entries in the subject alternative name extension
+	SANIP []string `bson:"san_ip" bro:"san.ip" brotype:"vector[addr]" json:"san.ip"`
+	// BasicConstraintsCA marks whether the certificate belongs to a certificate authority
+	BasicConstraintsCA bool `bson:"basic_constraints_ca" bro:"basic_constraints.ca" brotype:"bool" json:"basic_constraints.ca"`
+	// BasicConstraintsPathLen is the maximum path length of the certificate chain
+	BasicConstraintsPathLen int `bson:"basic_constraints_path_len" bro:"basic_constraints.path_len" brotype:"count" json:"basic_constraints.path_len"`
+	// HostCert marks whether the certificate was sent by the server.
+	// Note: only present in zeek 5.0 and newer.
+	HostCert bool `bson:"host_cert" bro:"host_cert" brotype:"bool" json:"host_cert"`
+	// ClientCert marks whether the certificate was sent by the client.
+	// Note: only present in zeek 5.0 and newer.
+	ClientCert bool `bson:"client_cert" bro:"client_cert" brotype:"bool" json:"client_cert"`
+	// AgentHostname names which sensor recorded this event. Only set when combining logs from multiple sensors.
+	AgentHostname string `bson:"agent_hostname" bro:"agent_hostname" brotype:"string" json:"agent_hostname"`
+	// AgentUUID identifies which sensor recorded this event. Only set when combining logs from multiple sensors.
+	AgentUUID string `bson:"agent_uuid" bro:"agent_uuid" brotype:"string" json:"agent_uuid"`
+}
+
+// TargetCollection returns the mongo collection this entry should be inserted
+func (line *X509) TargetCollection(config *config.StructureTableCfg) string {
+	return config.X509Table
+}
+
+// ConvertFromJSON performs any extra conversions necessary when reading from JSON
+func (line *X509) ConvertFromJSON() {
+	line.TimeStamp = convertTimestamp(line.TimeStampGeneric)
+	line.NotValidBefore = convertTimestamp(line.NotValidBeforeGeneric)
+	line.NotValidAfter = convertTimestamp(line.NotValidAfterGeneric)
+}
@@ -5,10 +5,14 @@ import (
 
 	"github.com/activecm/rita-legacy/pkg/certificate"
 	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/hostname"
+	"github.com/activecm/rita-legacy/pkg/lateral"
+	"github.com/activecm/rita-legacy/pkg/scan"
 	"github.com/activecm/rita-legacy/pkg/sniconn"
 	"github.com/activecm/rita-legacy/pkg/uconn"
+	"github.com/activecm/rita-legacy/pkg/uconndns"
 	"github.com/activecm/rita-legacy/pkg/uconnproxy"
 	"github.com/activecm/rita-legacy/pkg/useragent"
 )
@@ -21,6 +25,8 @@ type ParseResults struct {
 	UniqueConnLock      *sync.Mutex
 	ProxyUniqueConnMap  map[string]*uconnproxy.Input
 	ProxyUniqueConnLock *sync.Mutex
+	DNSUniqueConnMap    map[string]*uconndns.Input
+	DNSUniqueConnLock   *sync.Mutex
 	HostMap             map[string]*host.Input
 	HostLock            *sync.Mutex
 	HostnameMap         map[string]*hostname.Input
@@ -29,12 +35,20 @@ type ParseResults struct {
 	UseragentLock       *sync.Mutex
 	CertificateMap      map[string]*certificate.Input
 	CertificateLock     *sync.Mutex
+	X509Map             map[string]*certificate.X509Input
+	X509Lock            *sync.Mutex
 	ExplodedDNSMap      map[string]int
 	ExplodedDNSLock     *sync.Mutex
+	DNSTunnelMap        map[string]*dnstunnel.Input
+	DNSTunnelLock       *sync.Mutex
 	TLSConnMap          map[string]*sniconn.TLSInput
 	TLSConnLock         *sync.Mutex
 	HTTPConnMap         map[string]*sniconn.HTTPInput
 	HTTPConnLock        *sync.Mutex
+	ScanMap             map[string]*scan.Input
+	ScanLock            *sync.Mutex
+	LateralMap          map[string]*lateral.Input
+	LateralLock         *sync.Mutex
 	ZeekUIDMap          map[string]*data.ZeekUIDRecord
 	ZeekUIDLock         *sync.Mutex
 }
@@ -46,6 +60,8 @@ func newParseResults() ParseResults {
 		UniqueConnLock:      new(sync.Mutex),
 		ProxyUniqueConnMap:  make(map[string]*uconnproxy.Input),
 		ProxyUniqueConnLock: new(sync.Mutex),
+		DNSUniqueConnMap:    make(map[string]*uconndns.Input),
+		DNSUniqueConnLock:   new(sync.Mutex),
 		HostMap:             make(map[string]*host.Input),
 		HostLock:            new(sync.Mutex),
 		HostnameMap:         make(map[string]*hostname.Input),
@@ -54,12 +70,20 @@ func newParseResults() ParseResults {
 		UseragentLock:       new(sync.Mutex),
 		CertificateMap:      make(map[string]*certificate.Input),
 		CertificateLock:     new(sync.Mutex),
+		X509Map:             make(map[string]*certificate.X509Input),
+		X509Lock:            new(sync.Mutex),
 		ExplodedDNSMap:      make(map[string]int),
 		ExplodedDNSLock:     new(sync.Mutex),
+		DNSTunnelMap:        make(map[string]*dnstunnel.Input),
+		DNSTunnelLock:       new(sync.Mutex),
 		TLSConnMap:          make(map[string]*sniconn.TLSInput),
 		TLSConnLock:         new(sync.Mutex),
 		HTTPConnMap:         make(map[string]*sniconn.HTTPInput),
 		HTTPConnLock:        new(sync.Mutex),
+		ScanMap:             make(map[string]*scan.Input),
+		ScanLock:            new(sync.Mutex),
+		LateralMap:          make(map[string]*lateral.Input),
+		LateralLock:         new(sync.Mutex),
 		ZeekUIDMap:          make(map[string]*data.ZeekUIDRecord),
 		ZeekUIDLock:         new(sync.Mutex),
 	}
@@ -71,15 +71,20 @@ func parseSSLEntry(parseSSL *parsetypes.SSL, filter filter, retVals ParseResults
 		// the unique connection record may have been created before the certificate record was seen
 		copyServiceTuplesFromUconnToCerts(dstKey, srcDstKey, retVals)
 	}
+
+	updateLeafCertificatesBySSL(dstUniqIP, dstKey, parseSSL, retVals)
 }
 
+// noJA3Hash stands in for the JA3 hash of connections Zeek couldn't fingerprint
+const noJA3Hash = "No JA3 hash generated"
+
 func updateUseragentsBySSL(srcUniqIP data.UniqueIP, parseSSL *parsetypes.SSL, retVals ParseResults) {
 
 	retVals.UseragentLock.Lock()
 	defer retVals.UseragentLock.Unlock()
 
 	if parseSSL.JA3 == "" {
-		parseSSL.JA3 = "No JA3 hash generated"
+		parseSSL.JA3 = noJA3Hash
 	}
 
 	if _, ok := retVals.UseragentMap[parseSSL.JA3]; !ok {
@@ -122,6 +127,10 @@ func updateTLSConnectionsBySSL(srcIP net.IP, dstUniqIP data.UniqueIP, srcFQDNPai
 			Subjects: make(data.StringSet),
 			JA3s:     make(data.StringSet),
 			JA3Ss:    make(data.StringSet),
+
+			LeafCertIDs:      make(data.StringSet),
+			CertFingerprints: make(data.StringSet),
+			JA3Pairs:         make(map[sniconn.JA3Pair]int64),
 		}
 	}
 
@@ -157,6 +166,20 @@ func updateTLSConnectionsBySSL(srcIP net.IP, dstUniqIP data.UniqueIP, srcFQDNPai
 		retVals.TLSConnMap[srcFQDNKey].JA3Ss.Insert(parseSSL.JA3S)
 	}
 
+	// ///// INCREMENT THE CONNECTION COUNT FOR THE JA3/ JA3S PAIR /////
+	if len(parseSSL.JA3) > 0 && parseSSL.JA3 != noJA3Hash {
+		pair := sniconn.JA3Pair{JA3: parseSSL.JA3, JA3S: parseSSL.JA3S}
+		retVals.TLSConnMap[srcFQDNKey].JA3Pairs[pair]++
+	}
+
+	// ///// UNION LEAF CERTIFICATE INTO TLS LEAF CERTIFICATE SETS /////
+	// file ids are resolved to fingerprints via the x509 log during analysis
+	if len(parseSSL.CertChainFps) > 0 {
+		retVals.TLSConnMap[srcFQDNKey].CertFingerprints.Insert(parseSSL.CertChainFps[0])
+	} else if len(parseSSL.CertChainFuids) > 0 {
+		retVals.TLSConnMap[srcFQDNKey].LeafCertIDs.Insert(parseSSL.CertChainFuids[0])
+	}
+
 	// ///// APPEND ZEEK RECORD UID INTO TLS UID SET /////
 	// This allows us to link conn record information to this
 	// ip -> fqdn record such as data sizes.
@@ -241,12 +264,7 @@ func updateCertificatesBySSL(srcUniqIP data.UniqueIP, dstUniqIP data.UniqueIP, d
 
 	if _, ok := retVals.CertificateMap[dstKey]; !ok {
 		// create new uconn record if it does not exist
-		retVals.CertificateMap[dstKey] = &certificate.Input{
-			Host:         dstUniqIP,
-			OrigIps:      make(data.UniqueIPSet),
-			InvalidCerts: make(data.StringSet),
-			Tuples:       make(data.StringSet),
-		}
+		retVals.CertificateMap[dstKey] = newCertificateInput(dstUniqIP)
 	}
 
 	// ///// INCREMENT CONNECTION COUNTER FOR DESTINATION WITH INVALID CERTIFICATE /////
@@ -259,6 +277,54 @@ func updateCertificatesBySSL(srcUniqIP data.UniqueIP, dstUniqIP data.UniqueIP, d
 	retVals.CertificateMap[dstKey].OrigIps.Insert(srcUniqIP)
 }
 
+func updateLeafCertificatesBySSL(dstUniqIP data.UniqueIP, dstKey string, parseSSL *parsetypes.SSL, retVals ParseResults) {
+	// the first certificate in the chain is the certificate presented by the server
+	var leafCertID string
+	if len(parseSSL.CertChainFuids) > 0 {
+		leafCertID = parseSSL.CertChainFuids[0]
+	} else if len(parseSSL.CertChainFps) > 0 {
+		leafCertID = parseSSL.CertChainFps[0]
+	}
+
+	if len(leafCertID) == 0 {
+		return
+	}
+
+	retVals.CertificateLock.Lock()
+	defer retVals.CertificateLock.Unlock()
+
+	if _, ok := retVals.CertificateMap[dstKey]; !ok {
+		retVals.CertificateMap[dstKey] = newCertificateInput(dstUniqIP)
+	}
+
+	// ///// RECORD WHEN THE SERVER PRESENTED THE CERTIFICATE /////
+	// The certificate details are linked in from the x509 log during analysis
+	seen, ok := retVals.CertificateMap[dstKey].LeafCerts[leafCertID]
+	if !ok {
+		retVals.CertificateMap[dstKey].LeafCerts[leafCertID] = &certificate.TimeRange{
+			FirstSeen: parseSSL.TimeStamp,
+			LastSeen:  parseSSL.TimeStamp,
+		}
+		return
+	}
+	if parseSSL.TimeStamp < seen.FirstSeen {
+		seen.FirstSeen = parseSSL.TimeStamp
+	}
+	if parseSSL.TimeStamp > seen.LastSeen {
+		seen.LastSeen = parseSSL.TimeStamp
+	}
+}
+
+func newCertificateInput(dstUniqIP data.UniqueIP) *certificate.Input {
+	return &certificate.Input{
+		Host:         dstUniqIP,
+		OrigIps:      make(data.UniqueIPSet),
+		InvalidCerts: make(data.StringSet),
+		Tuples:       make(data.StringSet),
+		LeafCerts:    make(map[string]*certificate.TimeRange),
+	}
+}
+
 func copyServiceTuplesFromUconnToCerts(dstKey, srcDstKey string, retVals ParseResults) {
 	retVals.UniqueConnLock.Lock()
 	retVals.CertificateLock.Lock()
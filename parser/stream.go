@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/parser/files"
+	"github.com/activecm/rita-legacy/parser/parsetypes"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamWindow holds the data parsed from a log stream for a single chunk
+type streamWindow struct {
+	cid     int
+	retVals ParseResults
+	tsRange database.Range
+	entries int
+}
+
+// newStreamWindow creates an empty streamWindow for the given chunk
+func newStreamWindow(cid int) *streamWindow {
+	return &streamWindow{cid: cid, retVals: newParseResults()}
+}
+
+// add records the timestamp of an entry added to the window
+func (w *streamWindow) add(ts int64) {
+	if w.tsRange.Min == 0 || ts < w.tsRange.Min {
+		w.tsRange.Min = ts
+	}
+	if ts > w.tsRange.Max {
+		w.tsRange.Max = ts
+	}
+}
+
+// streamReader sorts the entries read from a log stream into windows, one per chunk.
+// Entries whose timestamps fall within the range of a chunk streamed by an earlier run
+// are skipped so that restarting a stream doesn't import entries twice. The windows cut
+// during this run aren't checked since Zeek writes conn.log entries when the connection
+// ends, so long connections regularly arrive after entries with later timestamps.
+type streamReader struct {
+	parse         func(entry parsetypes.BroData, retVals ParseResults)
+	earlierRanges map[int]database.Range
+	totalChunks   int
+	window        *streamWindow
+	skipped       int
+}
+
+// newStreamReader creates a streamReader which fills the current chunk first.
+// earlierRanges holds the time ranges of the chunks streamed by earlier runs.
+func newStreamReader(parse func(parsetypes.BroData, ParseResults), earlierRanges map[int]database.Range,
+	currentChunk, totalChunks int) *streamReader {
+	// the data in the current chunk will be replaced
+	delete(earlierRanges, currentChunk)
+	return &streamReader{
+		parse:         parse,
+		earlierRanges: earlierRanges,
+		totalChunks:   totalChunks,
+		window:        newStreamWindow(currentChunk),
+	}
+}
+
+// read adds a log entry to the current window unless an earlier run imported it
+func (r *streamReader) read(entry parsetypes.BroData) {
+	ts, timed := entryTimestamp(entry)
+	if timed && isPreviouslyImported(ts, r.earlierRanges) {
+		r.skipped++
+		return
+	}
+
+	r.parse(entry, r.window.retVals)
+	r.window.entries++
+	if timed {
+		r.window.add(ts)
+	}
+}
+
+// cut returns the current window and starts a new window for the next chunk.
+// Returns nil and keeps the current window if it is empty.
+func (r *streamReader) cut() *streamWindow {
+	if r.window.entries == 0 {
+		return nil
+	}
+
+	window := r.window
+	next := (window.cid + 1) % r.totalChunks
+	// the data in the next chunk will be replaced
+	delete(r.earlierRanges, next)
+	r.window = newStreamWindow(next)
+	return window
+}
+
+// RunStream imports log entries from the lines channel until the channel is closed or
+// the context is cancelled. The parsed data is flushed to a chunk of the rolling database
+// each time the wall clock crosses a multiple of the flush interval, advancing the chunk
+// after every flush. Rather than hashing files, entries whose timestamps fall within the
+// range of a chunk streamed by an earlier run are skipped.
+func (fs *FSImporter) RunStream(ctx context.Context, lines <-chan []byte, flushInterval time.Duration) error {
+	targetDB := fs.database.GetSelectedDB()
+
+	earlierRanges, err := fs.metaDB.GetChunkTSRanges(targetDB)
+	if err != nil {
+		return err
+	}
+
+	reader := newStreamReader(
+		func(entry parsetypes.BroData, retVals ParseResults) { fs.parseEntry(entry, retVals, fs.log) },
+		earlierRanges, fs.config.S.Rolling.CurrentChunk, fs.config.S.Rolling.TotalChunks,
+	)
+
+	// windows are analyzed in the background so that analysis doesn't hold up reading the
+	// stream. Reading only stops if a window is cut before the previous one was analyzed.
+	windows := make(chan *streamWindow)
+	analyzed := make(chan struct{})
+	go func() {
+		for window := range windows {
+			fs.flushStream(window)
+		}
+		close(analyzed)
+	}()
+
+	finish := func() {
+		if window := reader.cut(); window != nil {
+			windows <- window
+		}
+		close(windows)
+		<-analyzed
+	}
+
+	flushTimer := time.NewTimer(untilNextBoundary(time.Now(), flushInterval))
+	defer flushTimer.Stop()
+
+	fmt.Printf("\t[-] Streaming logs to: %s. Flushing every %s ...\n", targetDB, flushInterval)
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				finish()
+				return nil
+			}
+
+			entry := files.ParseStreamLine(line, fs.log)
+			if entry == nil {
+				continue
+			}
+			reader.read(entry)
+
+		case <-flushTimer.C:
+			if reader.skipped > 0 {
+				fs.log.WithFields(log.Fields{
+					"database": targetDB,
+					"skipped":  reader.skipped,
+				}).Warning("Skipped log entries which were previously imported into the database")
+				reader.skipped = 0
+			}
+			if window := reader.cut(); window != nil {
+				windows <- window
+			}
+			flushTimer.Reset(untilNextBoundary(time.Now(), flushInterval))
+
+		case <-ctx.Done():
+			finish()
+			return nil
+		}
+	}
+}
+
+// flushStream analyzes the data in the window into the window's chunk and records the
+// window's time range against the chunk. The window is dropped if the chunk can't be prepared.
+func (fs *FSImporter) flushStream(window *streamWindow) {
+	targetDB := fs.database.GetSelectedDB()
+	cid := window.cid
+	fs.config.S.Rolling.CurrentChunk = cid
+	fmt.Printf("\t[-] Flushing %d log entries to chunk %d of %s\n", window.entries, cid, targetDB)
+
+	if !fs.prepareChunk() {
+		fs.log.WithFields(log.Fields{
+			"database": targetDB,
+			"chunk":    cid,
+			"entries":  window.entries,
+		}).Error("Could not prepare the chunk for the streamed log entries")
+		return
+	}
+
+	fs.metaDB.SetChunk(cid, targetDB, true)
+	fs.analyze(window.retVals)
+
+	err := fs.metaDB.SetChunkTSRange(cid, targetDB, window.tsRange.Min, window.tsRange.Max)
+	if err != nil {
+		fs.log.Error("Could not record the time range of the streamed log entries")
+	}
+	fs.metaDB.MarkDBAnalyzed(targetDB, true)
+
+	fs.log.WithFields(log.Fields{
+		"database": targetDB,
+		"chunk":    cid,
+		"entries":  window.entries,
+		"min_ts":   window.tsRange.Min,
+		"max_ts":   window.tsRange.Max,
+	}).Info("Flushed streamed log entries")
+
+	fs.config.S.Rolling.CurrentChunk = (cid + 1) % fs.config.S.Rolling.TotalChunks
+
+	fmt.Println("\t[-] Done!")
+}
+
+// untilNextBoundary returns the time remaining until the wall clock crosses
+// the next multiple of the interval
+func untilNextBoundary(now time.Time, interval time.Duration) time.Duration {
+	return now.Truncate(interval).Add(interval).Sub(now)
+}
+
+// isPreviouslyImported returns true if the timestamp falls within the range of any chunk
+// which an earlier run streamed into the database
+func isPreviouslyImported(ts int64, importedRanges map[int]database.Range) bool {
+	for _, tsRange := range importedRanges {
+		if ts >= tsRange.Min && ts <= tsRange.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// entryTimestamp returns the time at which a log entry was recorded. The boolean
+// is false for open connections since each snapshot of an open connection shares
+// the start time of the connection.
+func entryTimestamp(entry parsetypes.BroData) (int64, bool) {
+	switch typedEntry := entry.(type) {
+	case *parsetypes.Conn:
+		return typedEntry.TimeStamp, true
+	case *parsetypes.DNS:
+		return typedEntry.TimeStamp, true
+	case *parsetypes.HTTP:
+		return typedEntry.TimeStamp, true
+	case *parsetypes.SSL:
+		return typedEntry.TimeStamp, true
+	case *parsetypes.X509:
+		return typedEntry.TimeStamp, true
+	}
+	return 0, false
+}
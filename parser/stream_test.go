@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/parser/parsetypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntilNextBoundary(t *testing.T) {
+	now := time.Date(2023, 9, 14, 10, 32, 0, 0, time.UTC)
+	assert.Equal(t, 28*time.Minute, untilNextBoundary(now, time.Hour))
+	assert.Equal(t, 3*time.Minute, untilNextBoundary(now, 5*time.Minute))
+
+	onBoundary := time.Date(2023, 9, 14, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Hour, untilNextBoundary(onBoundary, time.Hour), "a flush on the boundary waits for the next one")
+}
+
+func TestIsPreviouslyImported(t *testing.T) {
+	importedRanges := map[int]database.Range{
+		0: {Min: 100, Max: 200},
+		1: {Min: 300, Max: 400},
+	}
+
+	assert.True(t, isPreviouslyImported(100, importedRanges))
+	assert.True(t, isPreviouslyImported(350, importedRanges))
+	assert.False(t, isPreviouslyImported(250, importedRanges), "gaps between chunks are not imported")
+	assert.False(t, isPreviouslyImported(401, importedRanges))
+	assert.False(t, isPreviouslyImported(150, map[int]database.Range{}))
+}
+
+func TestStreamWindowRange(t *testing.T) {
+	window := newStreamWindow(0)
+	for _, ts := range []int64{250, 100, 300} {
+		window.add(ts)
+	}
+	assert.Equal(t, database.Range{Min: 100, Max: 300}, window.tsRange)
+}
+
+// readTimestamps feeds a conn.log entry with each timestamp to the reader
+func readTimestamps(reader *streamReader, timestamps ...int64) {
+	for _, ts := range timestamps {
+		reader.read(&parsetypes.Conn{TimeStamp: ts})
+	}
+}
+
+func TestStreamReaderOutOfOrder(t *testing.T) {
+	var parsed []int64
+	parse := func(entry parsetypes.BroData, retVals ParseResults) {
+		parsed = append(parsed, entry.(*parsetypes.Conn).TimeStamp)
+	}
+	earlierRanges := map[int]database.Range{
+		0: {Min: 100, Max: 200},
+		1: {Min: 300, Max: 400},
+		2: {Min: 10, Max: 20},
+	}
+	reader := newStreamReader(parse, earlierRanges, 2, 4)
+
+	// the current chunk will be replaced, so its entries are imported again
+	readTimestamps(reader, 15, 1000, 1100)
+	first := reader.cut()
+	assert.Equal(t, 2, first.cid)
+	assert.Equal(t, database.Range{Min: 15, Max: 1100}, first.tsRange)
+
+	// long connections are logged when they end, after the entries flushed in the previous window
+	readTimestamps(reader, 1050, 1200, 950)
+	// entries from the chunks of earlier runs are skipped
+	readTimestamps(reader, 150, 350)
+	assert.Equal(t, 2, reader.skipped)
+
+	second := reader.cut()
+	assert.Equal(t, 3, second.cid)
+	assert.Equal(t, 3, second.entries)
+	assert.Equal(t, database.Range{Min: 950, Max: 1200}, second.tsRange)
+
+	// chunk 0 is replaced by the next window, so its old entries are no longer skipped
+	readTimestamps(reader, 150, 350)
+	assert.Equal(t, 3, reader.skipped)
+	third := reader.cut()
+	assert.Equal(t, 0, third.cid)
+	assert.Equal(t, 1, third.entries)
+
+	assert.Equal(t, []int64{15, 1000, 1100, 1050, 1200, 950, 150}, parsed)
+}
+
+func TestStreamReaderCutEmpty(t *testing.T) {
+	reader := newStreamReader(func(parsetypes.BroData, ParseResults) {}, map[int]database.Range{}, 1, 2)
+	assert.Nil(t, reader.cut(), "empty windows are not flushed")
+
+	// open connections don't have a timestamp but are still flushed
+	reader.read(&parsetypes.OpenConn{TimeStamp: 123})
+	window := reader.cut()
+	if assert.NotNil(t, window) {
+		assert.Equal(t, 1, window.cid)
+		assert.Equal(t, database.Range{}, window.tsRange)
+	}
+
+	readTimestamps(reader, 500)
+	assert.Equal(t, 0, reader.cut().cid, "chunks wrap around")
+}
+
+func TestEntryTimestamp(t *testing.T) {
+	ts, timed := entryTimestamp(&parsetypes.Conn{TimeStamp: 123})
+	assert.Equal(t, int64(123), ts)
+	assert.True(t, timed)
+
+	_, timed = entryTimestamp(&parsetypes.OpenConn{TimeStamp: 123})
+	assert.False(t, timed, "open connection snapshots are not tied to a point in time")
+}
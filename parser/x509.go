@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"github.com/activecm/rita-legacy/parser/parsetypes"
+	"github.com/activecm/rita-legacy/pkg/certificate"
+	"github.com/activecm/rita-legacy/pkg/sniconn"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func parseX509Entry(parseX509 *parsetypes.X509, retVals ParseResults, logger *log.Logger) {
+	// client certificates are not presented by servers
+	if parseX509.ClientCert {
+		return
+	}
+
+	if parseX509.ID == "" && parseX509.Fingerprint == "" {
+		logger.WithFields(log.Fields{
+			"subject": parseX509.Subject,
+			"issuer":  parseX509.Issuer,
+		}).Error("Unable to link x509 log entry to ssl log entries without a file id or fingerprint, skipping entry.")
+		return
+	}
+
+	var sans []string
+	sans = append(sans, parseX509.SANDNS...)
+	sans = append(sans, parseX509.SANIP...)
+	sans = append(sans, parseX509.SANURI...)
+	sans = append(sans, parseX509.SANEmail...)
+
+	cert := &certificate.X509Input{
+		Fingerprint:    parseX509.Fingerprint,
+		Serial:         parseX509.Serial,
+		Subject:        parseX509.Subject,
+		Issuer:         parseX509.Issuer,
+		SANs:           sans,
+		NotValidBefore: parseX509.NotValidBefore,
+		NotValidAfter:  parseX509.NotValidAfter,
+		KeyType:        parseX509.KeyType,
+		KeyLength:      parseX509.KeyLength,
+	}
+
+	retVals.X509Lock.Lock()
+	defer retVals.X509Lock.Unlock()
+
+	// ssl logs reference certificates by file id in cert_chain_fuids, or
+	// by fingerprint in cert_chain_fps in newer versions of zeek
+	if len(parseX509.ID) > 0 {
+		retVals.X509Map[parseX509.ID] = cert
+	}
+	if len(parseX509.Fingerprint) > 0 {
+		retVals.X509Map[parseX509.Fingerprint] = cert
+	}
+}
+
+// linkTLSCertFingerprints looks up the fingerprints of the leaf certificates
+// which the ssl log references by file id
+func linkTLSCertFingerprints(tlsMap map[string]*sniconn.TLSInput, x509Map map[string]*certificate.X509Input) {
+	for _, tlsConn := range tlsMap {
+		for _, certID := range tlsConn.LeafCertIDs.Items() {
+			if cert, ok := x509Map[certID]; ok && len(cert.Fingerprint) > 0 {
+				tlsConn.CertFingerprints.Insert(cert.Fingerprint)
+			}
+		}
+	}
+}
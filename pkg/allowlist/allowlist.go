@@ -0,0 +1,205 @@
+package allowlist
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo"
+)
+
+// ja3Regex matches the md5 hex digest used for JA3 hashes
+var ja3Regex = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Allowlist matches hosts, domains, JA3 hashes, and user agents which the
+// user trusts. Findings involving allowlisted values are flagged as suppressed
+// when results are queried rather than being dropped at import time.
+type Allowlist struct {
+	subnets    []*net.IPNet
+	domains    []string
+	ja3s       data.StringSet
+	userAgents data.StringSet
+}
+
+// New creates an Allowlist from the given entries
+func New(entries []database.AllowlistEntry) (*Allowlist, error) {
+	allowlist := &Allowlist{
+		ja3s:       make(data.StringSet),
+		userAgents: make(data.StringSet),
+	}
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case database.AllowlistIP:
+			subnet, err := parseSubnet(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			allowlist.subnets = append(allowlist.subnets, subnet)
+		case database.AllowlistDomain:
+			allowlist.domains = append(allowlist.domains, entry.Value)
+		case database.AllowlistJA3:
+			allowlist.ja3s.Insert(entry.Value)
+		case database.AllowlistUserAgent:
+			allowlist.userAgents.Insert(entry.Value)
+		}
+	}
+	return allowlist, nil
+}
+
+// Load reads the allowlist from the MetaDB
+func Load(res *resources.Resources) (*Allowlist, error) {
+	entries, err := res.MetaDB.GetAllowlist()
+	if err != nil {
+		return nil, err
+	}
+	return New(entries)
+}
+
+// Normalize validates value as an allowlist entry of the given type and returns
+// the form in which it is stored
+func Normalize(entryType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("allowlist entries may not be empty")
+	}
+
+	switch entryType {
+	case database.AllowlistIP:
+		subnet, err := parseSubnet(value)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(value, "/") {
+			return subnet.String(), nil
+		}
+		return net.ParseIP(value).String(), nil
+	case database.AllowlistDomain:
+		return strings.TrimSuffix(strings.ToLower(value), "."), nil
+	case database.AllowlistJA3:
+		value = strings.ToLower(value)
+		if !ja3Regex.MatchString(value) {
+			return "", errors.New("JA3 hashes must be 32 hexadecimal characters")
+		}
+		return value, nil
+	case database.AllowlistUserAgent:
+		return value, nil
+	}
+	return "", errors.New("allowlist entry types are " + strings.Join(database.AllowlistTypes, ", "))
+}
+
+// parseSubnet parses an IP address or CIDR range into a subnet
+func parseSubnet(value string) (*net.IPNet, error) {
+	if _, subnet, err := net.ParseCIDR(value); err == nil {
+		return subnet, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, errors.New("invalid IP address or CIDR range: " + value)
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// ContainsIP returns true if any of the given IPs is allowlisted
+func (a *Allowlist) ContainsIP(ips ...string) bool {
+	if len(a.subnets) == 0 {
+		return false
+	}
+	for _, ipStr := range ips {
+		ip := net.ParseIP(ipStr)
+		if ip != nil && util.ContainsIP(a.subnets, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsDomain returns true if the domain is allowlisted. Wildcard entries
+// such as *.example.com match example.com and all of its subdomains.
+func (a *Allowlist) ContainsDomain(domain string) bool {
+	if len(a.domains) == 0 {
+		return false
+	}
+	return util.ContainsDomain(a.domains, strings.TrimSuffix(strings.ToLower(domain), "."))
+}
+
+// ContainsJA3 returns true if the JA3 hash is allowlisted
+func (a *Allowlist) ContainsJA3(hash string) bool {
+	return a.ja3s.Contains(strings.ToLower(hash))
+}
+
+// ContainsUserAgent returns true if the user agent is allowlisted
+func (a *Allowlist) ContainsUserAgent(userAgent string) bool {
+	return a.userAgents.Contains(userAgent)
+}
+
+// Collect reads the results from iter, marking each result which involves an allowlisted
+// value as suppressed. suppressed reports whether a result involves an allowlisted value,
+// and T must be a struct with a boolean Suppressed field. Suppressed results are dropped
+// as they are read unless showSuppressed is set, so limit only counts the results which
+// are kept. If noLimit is set, every result is read. iter is closed before returning.
+func Collect[T any](res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool,
+	suppressed func(allowed *Allowlist, result *T) bool) ([]T, error) {
+
+	allowed, err := Load(res)
+	if err != nil {
+		iter.Close()
+		return nil, err
+	}
+
+	results := collect(allowed, iter.Next, limit, noLimit, showSuppressed, suppressed)
+	return results, iter.Close()
+}
+
+// Filter marks each of the results which involves an allowlisted value as suppressed
+// in the same manner as Collect for results which have already been gathered
+func Filter[T any](res *resources.Resources, results []T, limit int, noLimit bool, showSuppressed bool,
+	suppressed func(allowed *Allowlist, result *T) bool) ([]T, error) {
+
+	allowed, err := Load(res)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := 0
+	next := func(result interface{}) bool {
+		if idx >= len(results) {
+			return false
+		}
+		*result.(*T) = results[idx]
+		idx++
+		return true
+	}
+	return collect(allowed, next, limit, noLimit, showSuppressed, suppressed), nil
+}
+
+// collect implements Collect and Filter. next fills in the next result and returns
+// false once there are no more results.
+func collect[T any](allowed *Allowlist, next func(result interface{}) bool, limit int, noLimit bool, showSuppressed bool,
+	suppressed func(allowed *Allowlist, result *T) bool) []T {
+
+	results := []T{}
+	for noLimit || len(results) < limit {
+		var result T
+		if !next(&result) {
+			break
+		}
+
+		isSuppressed := suppressed(allowed, &result)
+		if isSuppressed && !showSuppressed {
+			continue
+		}
+		reflect.ValueOf(&result).Elem().FieldByName("Suppressed").SetBool(isSuppressed)
+		results = append(results, result)
+	}
+	return results
+}
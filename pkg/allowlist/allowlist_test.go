@@ -0,0 +1,114 @@
+package allowlist
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlistContains(t *testing.T) {
+	allowed, err := New([]database.AllowlistEntry{
+		{Type: database.AllowlistIP, Value: "10.0.0.5"},
+		{Type: database.AllowlistIP, Value: "192.168.0.0/16"},
+		{Type: database.AllowlistIP, Value: "2001:db8::/32"},
+		{Type: database.AllowlistDomain, Value: "*.windowsupdate.com"},
+		{Type: database.AllowlistDomain, Value: "example.org"},
+		{Type: database.AllowlistJA3, Value: "e7d705a3286e19ea42f587b344ee6865"},
+		{Type: database.AllowlistUserAgent, Value: "Microsoft-Delivery-Optimization/10.0"},
+	})
+	require.NoError(t, err)
+
+	require.True(t, allowed.ContainsIP("10.0.0.5"))
+	require.True(t, allowed.ContainsIP("1.2.3.4", "192.168.44.2"))
+	require.True(t, allowed.ContainsIP("2001:db8::1"))
+	require.False(t, allowed.ContainsIP("10.0.0.6", "1.2.3.4"))
+	require.False(t, allowed.ContainsIP("not an ip"))
+
+	require.True(t, allowed.ContainsDomain("windowsupdate.com"))
+	require.True(t, allowed.ContainsDomain("Download.WindowsUpdate.com."))
+	require.True(t, allowed.ContainsDomain("example.org"))
+	require.False(t, allowed.ContainsDomain("www.example.org"))
+	require.False(t, allowed.ContainsDomain("evil.com"))
+
+	require.True(t, allowed.ContainsJA3("E7D705A3286E19EA42F587B344EE6865"))
+	require.False(t, allowed.ContainsJA3("00000000000000000000000000000000"))
+
+	require.True(t, allowed.ContainsUserAgent("Microsoft-Delivery-Optimization/10.0"))
+	require.False(t, allowed.ContainsUserAgent("curl/8.0"))
+}
+
+func TestEmptyAllowlist(t *testing.T) {
+	allowed, err := New(nil)
+	require.NoError(t, err)
+	require.False(t, allowed.ContainsIP("10.0.0.1"))
+	require.False(t, allowed.ContainsDomain("example.com"))
+	require.False(t, allowed.ContainsJA3("e7d705a3286e19ea42f587b344ee6865"))
+	require.False(t, allowed.ContainsUserAgent(""))
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		entryType string
+		value     string
+		expected  string
+		isErr     bool
+	}{
+		{database.AllowlistIP, " 10.0.0.1 ", "10.0.0.1", false},
+		{database.AllowlistIP, "10.1.2.3/8", "10.0.0.0/8", false},
+		{database.AllowlistIP, "2001:DB8::1", "2001:db8::1", false},
+		{database.AllowlistIP, "10.0.0.256", "", true},
+		{database.AllowlistDomain, "*.Example.COM.", "*.example.com", false},
+		{database.AllowlistJA3, "E7D705A3286E19EA42F587B344EE6865", "e7d705a3286e19ea42f587b344ee6865", false},
+		{database.AllowlistJA3, "e7d705a3", "", true},
+		{database.AllowlistUserAgent, "Mozilla/5.0 (Windows NT 10.0)", "Mozilla/5.0 (Windows NT 10.0)", false},
+		{"hash", "abc", "", true},
+		{database.AllowlistDomain, "  ", "", true},
+	}
+
+	for _, test := range testCases {
+		value, err := Normalize(test.entryType, test.value)
+		if test.isErr {
+			require.Error(t, err, test.value)
+			continue
+		}
+		require.NoError(t, err, test.value)
+		require.Equal(t, test.expected, value)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	type result struct {
+		IP         string
+		Suppressed bool
+	}
+
+	allowed, err := New([]database.AllowlistEntry{{Type: database.AllowlistIP, Value: "10.0.0.0/8"}})
+	require.NoError(t, err)
+
+	gather := func(limit int, noLimit, showSuppressed bool) []result {
+		// the results are read in order, the way an iterator over an aggregation would return them
+		source := []result{{IP: "10.0.0.1"}, {IP: "1.1.1.1"}, {IP: "10.0.0.2"}, {IP: "2.2.2.2"}, {IP: "3.3.3.3"}}
+		idx := 0
+		next := func(r interface{}) bool {
+			if idx >= len(source) {
+				return false
+			}
+			*r.(*result) = source[idx]
+			idx++
+			return true
+		}
+		return collect(allowed, next, limit, noLimit, showSuppressed, func(a *Allowlist, r *result) bool {
+			return a.ContainsIP(r.IP)
+		})
+	}
+
+	// suppressed results do not count towards the limit
+	require.Equal(t, []result{{IP: "1.1.1.1"}, {IP: "2.2.2.2"}}, gather(2, false, false))
+	require.Equal(t, []result{{IP: "1.1.1.1"}, {IP: "2.2.2.2"}, {IP: "3.3.3.3"}}, gather(2, true, false))
+
+	require.Equal(t, []result{{IP: "10.0.0.1", Suppressed: true}, {IP: "1.1.1.1"}, {IP: "10.0.0.2", Suppressed: true}},
+		gather(3, false, true))
+
+	require.Empty(t, gather(0, false, false))
+}
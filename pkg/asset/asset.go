@@ -0,0 +1,245 @@
+package asset
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+)
+
+var (
+	uniqueIPType    = reflect.TypeOf(data.UniqueIP{})
+	uniqueSrcIPType = reflect.TypeOf(data.UniqueSrcIP{})
+	uniqueDstIPType = reflect.TypeOf(data.UniqueDstIP{})
+)
+
+type (
+	// Inventory labels IP addresses with the details recorded in the asset inventory
+	Inventory struct {
+		entries []entry
+		cache   map[string]data.AssetLabel
+	}
+
+	// entry is a parsed asset from the inventory
+	entry struct {
+		subnet      *net.IPNet
+		prefixLen   int
+		networkUUID []byte // nil if the asset applies to every network
+		label       data.AssetLabel
+	}
+)
+
+// New creates an Inventory from the given assets
+func New(assets []database.Asset) (*Inventory, error) {
+	inventory := &Inventory{cache: make(map[string]data.AssetLabel)}
+
+	for _, asset := range assets {
+		subnet, err := ParseSubnet(asset.Subnet)
+		if err != nil {
+			return nil, err
+		}
+		prefixLen, _ := subnet.Mask.Size()
+
+		var networkUUID []byte
+		if asset.NetworkUUID != "" {
+			parsed, err := uuid.Parse(asset.NetworkUUID)
+			if err != nil {
+				return nil, errors.New("invalid network UUID: " + asset.NetworkUUID)
+			}
+			networkUUID = parsed[:]
+		}
+
+		inventory.entries = append(inventory.entries, entry{
+			subnet:      subnet,
+			prefixLen:   prefixLen,
+			networkUUID: networkUUID,
+			label:       asset.AssetLabel,
+		})
+	}
+	return inventory, nil
+}
+
+// Load reads the asset inventory from the MetaDB
+func Load(res *resources.Resources) (*Inventory, error) {
+	assets, err := res.MetaDB.GetAssets()
+	if err != nil {
+		return nil, err
+	}
+	return New(assets)
+}
+
+// ParseSubnet parses an IP address or CIDR range into a subnet
+func ParseSubnet(value string) (*net.IPNet, error) {
+	if _, subnet, err := net.ParseCIDR(value); err == nil {
+		return subnet, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, errors.New("invalid IP address or CIDR range: " + value)
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// Lookup returns the label of the asset containing the given IP on the given network.
+// The most specific subnet wins. Assets recorded for a specific network take precedence
+// over assets which apply to every network.
+func (inv *Inventory) Lookup(ip string, networkUUID bson.Binary) (data.AssetLabel, bool) {
+	if len(inv.entries) == 0 {
+		return data.AssetLabel{}, false
+	}
+
+	cacheKey := ip + "|" + string(networkUUID.Data)
+	if label, ok := inv.cache[cacheKey]; ok {
+		return label, label != data.AssetLabel{}
+	}
+
+	parsed := net.ParseIP(ip)
+	var best *entry
+	for idx := range inv.entries {
+		candidate := &inv.entries[idx]
+		if parsed == nil || !candidate.subnet.Contains(parsed) {
+			continue
+		}
+		if candidate.networkUUID != nil && !bytes.Equal(candidate.networkUUID, networkUUID.Data) {
+			continue
+		}
+		if best == nil || candidate.prefixLen > best.prefixLen ||
+			(candidate.prefixLen == best.prefixLen && candidate.networkUUID != nil) {
+			best = candidate
+		}
+	}
+
+	var label data.AssetLabel
+	if best != nil {
+		label = best.label
+	}
+	inv.cache[cacheKey] = label
+	return label, best != nil
+}
+
+// Label fills in the asset labels of every UniqueIP, UniqueSrcIP, and UniqueDstIP
+// within results, which must be a slice of structs
+func (inv *Inventory) Label(results interface{}) {
+	if len(inv.entries) == 0 {
+		return
+	}
+	inv.label(reflect.ValueOf(results))
+}
+
+func (inv *Inventory) label(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			inv.label(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < v.Len(); idx++ {
+			inv.label(v.Index(idx))
+		}
+	case reflect.Struct:
+		if !v.CanSet() {
+			return
+		}
+		switch v.Type() {
+		case uniqueIPType:
+			host := v.Addr().Interface().(*data.UniqueIP)
+			host.Asset, _ = inv.Lookup(host.IP, host.NetworkUUID)
+		case uniqueSrcIPType:
+			host := v.Addr().Interface().(*data.UniqueSrcIP)
+			host.SrcAsset, _ = inv.Lookup(host.SrcIP, host.SrcNetworkUUID)
+		case uniqueDstIPType:
+			host := v.Addr().Interface().(*data.UniqueDstIP)
+			host.DstAsset, _ = inv.Lookup(host.DstIP, host.DstNetworkUUID)
+		default:
+			for idx := 0; idx < v.NumField(); idx++ {
+				inv.label(v.Field(idx))
+			}
+		}
+	}
+}
+
+// Criticality returns the highest asset criticality of the hosts within a labeled result
+func Criticality(result interface{}) int {
+	return criticality(reflect.ValueOf(result))
+}
+
+func criticality(v reflect.Value) int {
+	max := 0
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			max = criticality(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < v.Len(); idx++ {
+			if crit := criticality(v.Index(idx)); crit > max {
+				max = crit
+			}
+		}
+	case reflect.Struct:
+		switch v.Type() {
+		case uniqueIPType:
+			max = v.Interface().(data.UniqueIP).Asset.Criticality
+		case uniqueSrcIPType:
+			max = v.Interface().(data.UniqueSrcIP).SrcAsset.Criticality
+		case uniqueDstIPType:
+			max = v.Interface().(data.UniqueDstIP).DstAsset.Criticality
+		default:
+			for idx := 0; idx < v.NumField(); idx++ {
+				if !v.Type().Field(idx).IsExported() {
+					continue
+				}
+				if crit := criticality(v.Field(idx)); crit > max {
+					max = crit
+				}
+			}
+		}
+	}
+	return max
+}
+
+// SortByCriticality sorts labeled results, which must be a slice of structs, by the
+// highest asset criticality of the hosts in each result. Results with the same
+// criticality keep their original order.
+func SortByCriticality(results interface{}) {
+	resultsVal := reflect.ValueOf(results)
+	crits := make([]int, resultsVal.Len())
+	order := make([]int, resultsVal.Len())
+	for idx := range crits {
+		crits[idx] = criticality(resultsVal.Index(idx))
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return crits[order[i]] > crits[order[j]]
+	})
+
+	sorted := reflect.MakeSlice(resultsVal.Type(), resultsVal.Len(), resultsVal.Len())
+	for idx, orig := range order {
+		sorted.Index(idx).Set(resultsVal.Index(orig))
+	}
+	reflect.Copy(resultsVal, sorted)
+}
+
+// normalizeSubnet returns the form in which an asset's IP address or CIDR range is stored
+func normalizeSubnet(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	subnet, err := ParseSubnet(value)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(value, "/") {
+		return subnet.String(), nil
+	}
+	return net.ParseIP(value).String(), nil
+}
@@ -0,0 +1,132 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetworkUUID = "a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d"
+
+func testInventory(t *testing.T) *Inventory {
+	inventory, err := New([]database.Asset{
+		{Subnet: "10.0.0.0/8", AssetLabel: data.AssetLabel{Role: "workstation", Criticality: 1}},
+		{Subnet: "10.0.0.5", AssetLabel: data.AssetLabel{Hostname: "dc01", Role: "domain controller", Criticality: 5}},
+		{Subnet: "10.0.0.0/8", NetworkUUID: testNetworkUUID, AssetLabel: data.AssetLabel{Role: "lab", Criticality: 2}},
+		{Subnet: "2001:db8::/32", AssetLabel: data.AssetLabel{Hostname: "v6host", Criticality: 3}},
+	})
+	require.NoError(t, err)
+	return inventory
+}
+
+func testNetwork() bson.Binary {
+	parsed := uuid.MustParse(testNetworkUUID)
+	return bson.Binary{Kind: bson.BinaryUUID, Data: parsed[:]}
+}
+
+func TestLookup(t *testing.T) {
+	inventory := testInventory(t)
+
+	label, ok := inventory.Lookup("10.0.0.5", bson.Binary{})
+	require.True(t, ok)
+	require.Equal(t, "dc01", label.Hostname)
+
+	label, ok = inventory.Lookup("10.1.2.3", bson.Binary{})
+	require.True(t, ok)
+	require.Equal(t, "workstation", label.Role)
+
+	// the network specific asset wins over the generic asset with the same range
+	label, ok = inventory.Lookup("10.1.2.3", testNetwork())
+	require.True(t, ok)
+	require.Equal(t, "lab", label.Role)
+
+	// the more specific range wins over the network specific asset
+	label, ok = inventory.Lookup("10.0.0.5", testNetwork())
+	require.True(t, ok)
+	require.Equal(t, "dc01", label.Hostname)
+
+	label, ok = inventory.Lookup("2001:db8::1", bson.Binary{})
+	require.True(t, ok)
+	require.Equal(t, "v6host", label.Hostname)
+
+	_, ok = inventory.Lookup("192.168.1.1", bson.Binary{})
+	require.False(t, ok)
+	_, ok = inventory.Lookup("not an ip", bson.Binary{})
+	require.False(t, ok)
+}
+
+func TestLabelAndSort(t *testing.T) {
+	inventory := testInventory(t)
+
+	results := []struct {
+		data.UniqueIPPair
+		Hosts []data.UniqueIP
+	}{
+		{UniqueIPPair: data.NewUniqueIPPair(data.UniqueIP{IP: "192.168.1.1"}, data.UniqueIP{IP: "8.8.8.8"})},
+		{UniqueIPPair: data.NewUniqueIPPair(data.UniqueIP{IP: "10.1.2.3"}, data.UniqueIP{IP: "8.8.8.8"})},
+		{
+			UniqueIPPair: data.NewUniqueIPPair(data.UniqueIP{IP: "8.8.4.4"}, data.UniqueIP{IP: "1.1.1.1"}),
+			Hosts:        []data.UniqueIP{{IP: "10.0.0.5"}},
+		},
+	}
+	inventory.Label(results)
+
+	require.Equal(t, "workstation", results[1].SrcAsset.Role)
+	require.Equal(t, "10.1.2.3", results[1].LabeledSrcIP())
+	require.Equal(t, "10.0.0.5 (dc01)", results[2].Hosts[0].LabeledIP())
+	require.Equal(t, data.AssetLabel{}, results[0].DstAsset)
+
+	require.Equal(t, 0, Criticality(results[0]))
+	require.Equal(t, 1, Criticality(results[1]))
+	require.Equal(t, 5, Criticality(results[2]))
+
+	SortByCriticality(results)
+	require.Equal(t, "8.8.4.4", results[0].SrcIP)
+	require.Equal(t, "10.1.2.3", results[1].SrcIP)
+	require.Equal(t, "192.168.1.1", results[2].SrcIP)
+}
+
+func TestParseCSV(t *testing.T) {
+	assets, err := ParseCSV(strings.NewReader(
+		"ip,hostname,owner,role,criticality,network_uuid\n" +
+			"10.0.0.5, dc01 ,IT,domain controller,5,\n" +
+			"10.1.2.3/16,,,workstation,," + testNetworkUUID + "\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	require.Equal(t, "10.0.0.5", assets[0].Subnet)
+	require.Equal(t, "dc01", assets[0].Hostname)
+	require.Equal(t, 5, assets[0].Criticality)
+	require.Equal(t, "10.1.0.0/16", assets[1].Subnet)
+	require.Equal(t, testNetworkUUID, assets[1].NetworkUUID)
+
+	_, err = ParseCSV(strings.NewReader("hostname\ndc01\n"))
+	require.Error(t, err)
+
+	_, err = ParseCSV(strings.NewReader("ip,criticality\n10.0.0.5,high\n"))
+	require.Error(t, err)
+
+	_, err = ParseCSV(strings.NewReader("ip\n10.0.0.256\n"))
+	require.Error(t, err)
+}
+
+func TestParseJSON(t *testing.T) {
+	assets, err := ParseJSON(strings.NewReader(
+		`[{"ip": "2001:DB8::1", "hostname": "v6host", "criticality": 3}]`,
+	))
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	require.Equal(t, "2001:db8::1", assets[0].Subnet)
+	require.Equal(t, 3, assets[0].Criticality)
+
+	_, err = ParseJSON(strings.NewReader(`[{"ip": "10.0.0.1", "network_uuid": "bogus"}]`))
+	require.Error(t, err)
+
+	_, err = ParseJSON(strings.NewReader(`[{"ip": "10.0.0.1", "criticality": -1}]`))
+	require.Error(t, err)
+}
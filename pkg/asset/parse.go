@@ -0,0 +1,136 @@
+package asset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/google/uuid"
+)
+
+// record holds a single asset as read from an inventory file
+type record struct {
+	IP          string `json:"ip"`
+	NetworkUUID string `json:"network_uuid"`
+	Hostname    string `json:"hostname"`
+	Owner       string `json:"owner"`
+	Role        string `json:"role"`
+	Criticality int    `json:"criticality"`
+}
+
+// ParseCSV reads assets from a csv file. The first row must name the columns.
+// The ip column is required and holds an IP address or CIDR range. The optional
+// network_uuid, hostname, owner, role, and criticality columns label the hosts.
+func ParseCSV(r io.Reader) ([]database.Asset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the csv file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	if _, ok := columns["ip"]; !ok {
+		return nil, errors.New("the csv file must have an ip column")
+	}
+
+	var assets []database.Asset
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(column string) string {
+			idx, ok := columns[column]
+			if !ok || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		rec := record{
+			IP:          get("ip"),
+			NetworkUUID: get("network_uuid"),
+			Hostname:    get("hostname"),
+			Owner:       get("owner"),
+			Role:        get("role"),
+		}
+		if crit := get("criticality"); crit != "" {
+			rec.Criticality, err = strconv.Atoi(crit)
+			if err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": criticality must be an integer")
+			}
+		}
+
+		asset, err := rec.toAsset()
+		if err != nil {
+			return nil, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// ParseJSON reads assets from a json file holding an array of objects
+// with the same fields as the columns of a csv inventory
+func ParseJSON(r io.Reader) ([]database.Asset, error) {
+	var records []record
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	assets := make([]database.Asset, 0, len(records))
+	for idx, rec := range records {
+		asset, err := rec.toAsset()
+		if err != nil {
+			return nil, errors.New("entry " + strconv.Itoa(idx+1) + ": " + err.Error())
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// toAsset validates the record and converts it into the form stored in the MetaDB
+func (r record) toAsset() (database.Asset, error) {
+	var asset database.Asset
+
+	subnet, err := normalizeSubnet(r.IP)
+	if err != nil {
+		return asset, err
+	}
+	asset.Subnet = subnet
+
+	if r.NetworkUUID != "" {
+		parsed, err := uuid.Parse(r.NetworkUUID)
+		if err != nil {
+			return asset, errors.New("invalid network UUID: " + r.NetworkUUID)
+		}
+		asset.NetworkUUID = parsed.String()
+	}
+
+	if r.Criticality < 0 {
+		return asset, errors.New("criticality may not be negative")
+	}
+
+	asset.Hostname = strings.TrimSpace(r.Hostname)
+	asset.Owner = strings.TrimSpace(r.Owner)
+	asset.Role = strings.TrimSpace(r.Role)
+	asset.Criticality = r.Criticality
+	return asset, nil
+}
@@ -0,0 +1,97 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/activecm/rita-legacy/pkg/beaconscore"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Explain recomputes the beacon score for the connections from src to dst along with
+// every intermediate value used to arrive at the score. If the pair was seen on
+// several networks, the pair with the most connections is explained.
+func Explain(res *resources.Resources, src string, dst string) (*Explanation, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	var pairs []struct {
+		data.UniqueIPPair `bson:",inline"`
+		Dat               []struct {
+			Ts     []int64 `bson:"ts"`
+			Bytes  []int64 `bson:"bytes"`
+			Count  int64   `bson:"count"`
+			TBytes int64   `bson:"tbytes"`
+		} `bson:"dat"`
+	}
+
+	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).
+		Find(bson.M{"src": src, "dst": dst}).All(&pairs)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("no connections from %s to %s were found", src, dst)
+	}
+
+	// gather the connection details across every chunk as the dissector does
+	var explanation *Explanation
+	for _, pair := range pairs {
+		candidate := &Explanation{UniqueIPPair: pair.UniqueIPPair}
+		for _, dat := range pair.Dat {
+			candidate.Connections += dat.Count
+			candidate.TotalBytes += dat.TBytes
+			candidate.TsList = append(candidate.TsList, dat.Ts...)
+			candidate.OrigBytesList = append(candidate.OrigBytesList, dat.Bytes...)
+		}
+		if explanation == nil || candidate.Connections > explanation.Connections {
+			explanation = candidate
+		}
+	}
+
+	if explanation.Connections <= int64(res.Config.S.Beacon.DefaultConnectionThresh) {
+		return nil, fmt.Errorf(
+			"%d connections were made from %s to %s. More than %d are required for beacon analysis",
+			explanation.Connections, src, dst, res.Config.S.Beacon.DefaultConnectionThresh,
+		)
+	}
+	if explanation.Connections > int64(res.Config.S.Strobe.ConnectionLimit) {
+		return nil, fmt.Errorf(
+			"%d connections were made from %s to %s. Pairs with more than %d connections are reported as strobes",
+			explanation.Connections, src, dst, res.Config.S.Strobe.ConnectionLimit,
+		)
+	}
+
+	sort.Sort(util.SortableInt64(explanation.TsList))
+	sort.Sort(util.SortableInt64(explanation.OrigBytesList))
+
+	if countUnique(explanation.TsList) <= 3 {
+		return nil, errors.New("more than 3 unique connection timestamps are required for beacon analysis")
+	}
+
+	explanation.TsMin, explanation.TsMax, err = res.MetaDB.GetTSRange(res.DB.GetSelectedDB())
+	if err != nil {
+		return nil, err
+	}
+
+	explanation.ScoreDetails = beaconscore.Score(
+		explanation.TsMin, explanation.TsMax, explanation.TsList, explanation.OrigBytesList,
+		beaconscore.BeaconParams(res.Config.S.Beacon),
+	)
+	return explanation, nil
+}
+
+// countUnique counts the distinct values in a sorted slice
+func countUnique(sorted []int64) int {
+	unique := 0
+	for i := range sorted {
+		if i == 0 || sorted[i] != sorted[i-1] {
+			unique++
+		}
+	}
+	return unique
+}
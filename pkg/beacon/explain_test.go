@@ -0,0 +1,12 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountUnique(t *testing.T) {
+	assert.Equal(t, 0, countUnique(nil))
+	assert.Equal(t, 3, countUnique([]int64{1, 1, 2, 3, 3, 3}))
+}
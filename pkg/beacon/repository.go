@@ -1,7 +1,9 @@
 package beacon
 
 import (
+	"github.com/activecm/rita-legacy/pkg/beaconscore"
 	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/uconn"
 )
@@ -32,18 +34,41 @@ type DSData struct {
 	ModeCount  int64   `bson:"mode_count"`
 }
 
+// PeriodogramData holds the dominant period found in a beacon's connections
+type PeriodogramData struct {
+	Score  float64 `bson:"score"`
+	Period int64   `bson:"period"`
+	Power  float64 `bson:"power"`
+}
+
 // Result represents a beacon between two hosts. Contains information
 // on connection delta times and the amount of data transferred
 type Result struct {
 	data.UniqueIPPair `bson:",inline"`
-	Connections       int64   `bson:"connection_count"`
-	AvgBytes          float64 `bson:"avg_bytes"`
-	TotalBytes        int64   `bson:"total_bytes"`
-	Ts                TSData  `bson:"ts"`
-	Ds                DSData  `bson:"ds"`
-	DurScore          float64 `bson:"duration_score"`
-	HistScore         float64 `bson:"hist_score"`
-	Score             float64 `bson:"score"`
+	Connections       int64           `bson:"connection_count"`
+	AvgBytes          float64         `bson:"avg_bytes"`
+	TotalBytes        int64           `bson:"total_bytes"`
+	Ts                TSData          `bson:"ts"`
+	Ds                DSData          `bson:"ds"`
+	DurScore          float64         `bson:"duration_score"`
+	HistScore         float64         `bson:"hist_score"`
+	Periodogram       PeriodogramData `bson:"periodogram"`
+	Score             float64         `bson:"score"`
+	DstGeo            geoip.Info      `bson:"dst_geo"`             // country and ASN of the destination, if GeoIP is enabled
+	Suppressed        bool            `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
+
+// Explanation recomputes the beacon score for a pair of hosts along with
+// every intermediate value
+type Explanation struct {
+	data.UniqueIPPair
+	Connections   int64
+	TotalBytes    int64
+	TsMin         int64 // first timestamp of the dataset
+	TsMax         int64 // last timestamp of the dataset
+	TsList        []int64
+	OrigBytesList []int64
+	beaconscore.ScoreDetails
 }
 
 // StrobeResult represents a unique connection with a large amount
@@ -51,4 +76,5 @@ type Result struct {
 type StrobeResult struct {
 	data.UniqueIPPair `bson:",inline"`
 	ConnectionCount   int64 `bson:"connection_count"`
+	Suppressed        bool  `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
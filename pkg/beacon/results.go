@@ -1,34 +1,117 @@
 package beacon
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
-// Results finds beacons in the database greater than a given cutoffScore
-func Results(res *resources.Resources, cutoffScore float64) ([]Result, error) {
+// Results finds beacons in the database greater than a given cutoffScore. Results
+// involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, cutoffScore float64, showSuppressed bool) ([]Result, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var beacons []Result
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Beacon.BeaconTable).Pipe(resultsQuery(res, cutoffScore)).AllowDiskUse().Iter()
+	return collectResults(res, iter, showSuppressed)
+}
 
-	beaconQuery := bson.M{"score": bson.M{"$gt": cutoffScore}}
+// ResultsPage returns a page of the beacons greater than a given cutoffScore along with
+// the total number of them. The first skip results are skipped and at most limit results
+// are returned. Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, cutoffScore float64, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Beacon.BeaconTable).Find(beaconQuery).Sort("-score").All(&beacons)
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Beacon.BeaconTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(res, cutoffScore), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	beacons, err := collectResults(res, iter, true)
+	return beacons, total, err
+}
 
-	return beacons, err
+// resultsQuery selects the beacons greater than a given cutoffScore, sorted by score,
+// and attaches the country and ASN of their destinations
+func resultsQuery(res *resources.Resources, cutoffScore float64) []bson.M {
+	return []bson.M{
+		{"$match": bson.M{"score": bson.M{"$gt": cutoffScore}}},
+		{"$sort": bson.M{"score": -1}},
+		// attach the country and ASN of the destination from the host collection
+		{"$lookup": bson.M{
+			"from": res.Config.T.Structure.HostTable,
+			"let":  bson.M{"ip": "$dst", "network_uuid": "$dst_network_uuid"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{"$expr": bson.M{
+					"$and": []bson.M{
+						{"$eq": []string{"$ip", "$$ip"}},
+						{"$eq": []string{"$network_uuid", "$$network_uuid"}},
+					},
+				}}},
+				{"$project": bson.M{"_id": 0, "geo": 1}},
+			},
+			"as": "dst_host",
+		}},
+		{"$addFields": bson.M{"dst_geo": bson.M{"$arrayElemAt": []interface{}{"$dst_host.geo", 0}}}},
+		{"$project": bson.M{"dst_host": 0}},
+	}
+}
+
+// collectResults reads the beacons from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, showSuppressed bool) ([]Result, error) {
+	beacons, err := allowlist.Collect(res, iter, 0, true, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP)
+	})
+	if err != nil {
+		return beacons, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return beacons, err
+	}
+	inventory.Label(beacons)
+
+	return beacons, nil
 }
 
 // StrobeResults finds strobes (beacons with an immense number of connections) in the database.
 // The results will be sorted by connection count ordered by sortDir (-1 or 1).
-// limit and noLimit control how many results are returned.
-func StrobeResults(res *resources.Resources, sortDir, limit int, noLimit bool) ([]StrobeResult, error) {
+// limit and noLimit control how many results are returned. Results involving allowlisted
+// values are only returned if showSuppressed is set.
+func StrobeResults(res *resources.Resources, sortDir, limit int, noLimit bool, showSuppressed bool) ([]StrobeResult, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var strobes []StrobeResult
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(strobeResultsQuery(sortDir)).AllowDiskUse().Iter()
+	return collectStrobeResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// StrobeResultsPage returns a page of the strobes sorted by connection count ordered by
+// sortDir (-1 or 1) along with the total number of them. The first skip results are skipped
+// and at most limit results are returned. Results involving allowlisted values are flagged
+// rather than dropped.
+func StrobeResultsPage(res *resources.Resources, sortDir, skip, limit int) ([]StrobeResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
 
-	strobeQuery := []bson.M{
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable)
+	iter, total, err := database.PipePage(collection, strobeResultsQuery(sortDir), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	strobes, err := collectStrobeResults(res, iter, 0, true, true)
+	return strobes, total, err
+}
+
+// strobeResultsQuery totals the connections of each strobe and sorts them by sortDir
+func strobeResultsQuery(sortDir int) []bson.M {
+	return []bson.M{
 		{"$match": bson.M{"strobe": true}},
 		{"$unwind": "$dat"},
 		{"$project": bson.M{
@@ -52,13 +135,23 @@ func StrobeResults(res *resources.Resources, sortDir, limit int, noLimit bool) (
 		}},
 		{"$sort": bson.M{"connection_count": sortDir}},
 	}
+}
 
-	if !noLimit {
-		strobeQuery = append(strobeQuery, bson.M{"$limit": limit})
+// collectStrobeResults reads the strobes from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectStrobeResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]StrobeResult, error) {
+	strobes, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *StrobeResult) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP)
+	})
+	if err != nil {
+		return strobes, err
 	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(strobeQuery).AllowDiskUse().All(&strobes)
-
-	return strobes, err
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return strobes, err
+	}
+	inventory.Label(strobes)
 
+	return strobes, nil
 }
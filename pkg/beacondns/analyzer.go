@@ -0,0 +1,107 @@
+package beacondns
+
+import (
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/beaconscore"
+	"github.com/activecm/rita-legacy/pkg/uconndns"
+
+	"github.com/globalsign/mgo/bson"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	//analyzer handles calculating statistical measures of the distribution of timestamps
+	//of the DNS queries each host made for a domain
+	analyzer struct {
+		tsMin            int64                      // min timestamp for the whole dataset
+		tsMax            int64                      // max timestamp for the whole dataset
+		chunk            int                        //current chunk (0 if not on rolling analysis)
+		db               *database.DB               // provides access to MongoDB
+		conf             *config.Config             // contains details needed to access MongoDB
+		log              *log.Logger                // main logger for RITA
+		analyzedCallback func(database.BulkChanges) // called on each analyzed result
+		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
+		analysisChannel  chan *uconndns.Input       // holds unanalyzed data
+		analysisWg       sync.WaitGroup             // wait for analysis to finish
+	}
+)
+
+// newAnalyzer creates a new analyzer for calculating the beacon statistics of DNS query series
+func newAnalyzer(min int64, max int64, chunk int, db *database.DB, conf *config.Config, log *log.Logger,
+	analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+	return &analyzer{
+		tsMin:            min,
+		tsMax:            max,
+		chunk:            chunk,
+		db:               db,
+		conf:             conf,
+		log:              log,
+		analyzedCallback: analyzedCallback,
+		closedCallback:   closedCallback,
+		analysisChannel:  make(chan *uconndns.Input),
+	}
+}
+
+// collect gathers the sorted query timestamps of a host and domain for analysis
+func (a *analyzer) collect(data *uconndns.Input) {
+	a.analysisChannel <- data
+}
+
+// close waits for the analyzer to finish
+func (a *analyzer) close() {
+	close(a.analysisChannel)
+	a.analysisWg.Wait()
+	a.closedCallback()
+}
+
+// start kicks off a new analysis thread
+func (a *analyzer) start() {
+	a.analysisWg.Add(1)
+	go func() {
+
+		for entry := range a.analysisChannel {
+
+			details := beaconscore.Score(a.tsMin, a.tsMax, entry.TsList, nil, beaconscore.BeaconDNSParams(a.conf.S.BeaconDNS))
+
+			// copy variables to be used by bulk callback to prevent capturing by reference
+			pairSelector := entry.Hosts.BSONKey()
+			dnsBeaconQuery := bson.M{
+				"$set": bson.M{
+					"connection_count":   entry.ConnectionCount,
+					"resolver":           entry.Resolver,
+					"src_network_name":   entry.Hosts.SrcNetworkName,
+					"ts.range":           details.Ts.Range,
+					"ts.mode":            details.Ts.Mode,
+					"ts.mode_count":      details.Ts.ModeCount,
+					"ts.intervals":       details.Ts.Intervals,
+					"ts.interval_counts": details.Ts.IntervalCounts,
+					"ts.dispersion":      details.Ts.Madm,
+					"ts.skew":            details.Ts.Skew,
+					"ts.score":           details.Ts.Score,
+					"duration_score":     details.DurScore,
+					"bucket_divs":        details.Hist.BucketDivs,
+					"freq_list":          details.Hist.FreqList,
+					"freq_count":         details.Hist.FreqCount,
+					"hist_score":         details.Hist.Score,
+					"score":              details.Score,
+					"cid":                a.chunk,
+				},
+			}
+
+			update := database.BulkChanges{
+				a.conf.T.BeaconDNS.BeaconDNSTable: []database.BulkChange{{
+					Selector: pairSelector,
+					Update:   dnsBeaconQuery,
+					Upsert:   true,
+				}},
+			}
+
+			a.analyzedCallback(update)
+		}
+
+		a.analysisWg.Done()
+	}()
+}
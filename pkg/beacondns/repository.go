@@ -0,0 +1,39 @@
+package beacondns
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/host"
+	"github.com/activecm/rita-legacy/pkg/uconndns"
+)
+
+type (
+
+	// Repository for beaconDNS collection
+	Repository interface {
+		CreateIndexes() error
+		Upsert(uconnDNSMap map[string]*uconndns.Input, hostMap map[string]*host.Input, minTimestamp, maxTimestamp int64)
+	}
+
+	//TSData ...
+	TSData struct {
+		Score      float64 `bson:"score"`
+		Range      int64   `bson:"range"`
+		Mode       int64   `bson:"mode"`
+		ModeCount  int64   `bson:"mode_count"`
+		Skew       float64 `bson:"skew"`
+		Dispersion int64   `bson:"dispersion"`
+	}
+
+	//Result represents a DNS beacon between a source IP and
+	// a queried domain.
+	Result struct {
+		data.UniqueSrcFQDNPair `bson:",inline"`
+		Connections            int64         `bson:"connection_count"`
+		Ts                     TSData        `bson:"ts"`
+		DurScore               float64       `bson:"duration_score"`
+		HistScore              float64       `bson:"hist_score"`
+		Score                  float64       `bson:"score"`
+		Resolver               data.UniqueIP `bson:"resolver"`
+		Suppressed             bool          `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+	}
+)
@@ -4,7 +4,6 @@ import (
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/uconnproxy"
-	"github.com/globalsign/mgo/bson"
 )
 
 type (
@@ -28,16 +27,14 @@ type (
 	//Result represents a beacon proxy between a source IP and
 	// an fqdn.
 	Result struct {
-		FQDN           string        `bson:"fqdn"`
-		SrcIP          string        `bson:"src"`
-		SrcNetworkName string        `bson:"src_network_name"`
-		SrcNetworkUUID bson.Binary   `bson:"src_network_uuid"`
-		Connections    int64         `bson:"connection_count"`
-		Ts             TSData        `bson:"ts"`
-		DurScore       float64       `bson:"duration_score"`
-		HistScore      float64       `bson:"hist_score"`
-		Score          float64       `bson:"score"`
-		Proxy          data.UniqueIP `bson:"proxy"`
+		data.UniqueSrcFQDNPair `bson:",inline"`
+		Connections            int64         `bson:"connection_count"`
+		Ts                     TSData        `bson:"ts"`
+		DurScore               float64       `bson:"duration_score"`
+		HistScore              float64       `bson:"hist_score"`
+		Score                  float64       `bson:"score"`
+		Proxy                  data.UniqueIP `bson:"proxy"`
+		Suppressed             bool          `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 	}
 
 	//StrobeResult represents a unique connection with a large amount
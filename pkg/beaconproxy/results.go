@@ -1,20 +1,60 @@
 package beaconproxy
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
-// Results finds beacons FQDN in the database greater than a given cutoffScore
-func Results(res *resources.Resources, cutoffScore float64) ([]Result, error) {
+// Results finds proxy beacons in the database greater than a given cutoffScore. Results
+// involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, cutoffScore float64, showSuppressed bool) ([]Result, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var beaconsProxy []Result
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconProxy.BeaconProxyTable).Find(resultsQuery(cutoffScore)).Sort("-score").Iter()
+	return collectResults(res, iter, showSuppressed)
+}
+
+// ResultsPage returns a page of the proxy beacons greater than a given cutoffScore along with
+// the total number of them. The first skip results are skipped and at most limit results
+// are returned. Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, cutoffScore float64, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconProxy.BeaconProxyTable)
+	iter, total, err := database.FindPage(collection, resultsQuery(cutoffScore), "-score", skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	beaconsProxy, err := collectResults(res, iter, true)
+	return beaconsProxy, total, err
+}
+
+// resultsQuery selects the proxy beacons greater than a given cutoffScore
+func resultsQuery(cutoffScore float64) bson.M {
+	return bson.M{"score": bson.M{"$gt": cutoffScore}}
+}
 
-	BeaconProxyQuery := bson.M{"score": bson.M{"$gt": cutoffScore}}
+// collectResults reads the proxy beacons from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, showSuppressed bool) ([]Result, error) {
+	beaconsProxy, err := allowlist.Collect(res, iter, 0, true, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP) || allowed.ContainsDomain(r.FQDN)
+	})
+	if err != nil {
+		return beaconsProxy, err
+	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconProxy.BeaconProxyTable).Find(BeaconProxyQuery).Sort("-score").All(&beaconsProxy)
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return beaconsProxy, err
+	}
+	inventory.Label(beaconsProxy)
 
-	return beaconsProxy, err
+	return beaconsProxy, nil
 }
@@ -0,0 +1,278 @@
+package beaconscore
+
+import (
+	"math"
+
+	"github.com/activecm/rita-legacy/util"
+)
+
+// HistogramScore calculates two potential scores based on the hourly histogram of the sorted
+// timestamps and takes the max of the two scores. min and max bound the timestamps of the
+// whole dataset.
+func HistogramScore(min int64, max int64, tsList []int64, params Params) HistogramDetails {
+	bimodalBucketSize := params.HistBimodalBucketSize
+	bimodalOutlierRemoval := params.HistBimodalOutlierRemoval
+	bimodalMinHoursSeen := params.HistBimodalMinHoursSeen
+
+	// get bucket list
+	// we currently look at a 24 hour period
+	bucketDivs := createBuckets(min, max, 24)
+
+	// use timestamps to get freqencies for buckets
+	freqList, freqCount, total, totalBars, longestRun := createHistogram(bucketDivs, tsList, bimodalBucketSize)
+
+	// calculate first potential score
+	// coefficient of variation will help score histograms that have jitter in the number of
+	// connections but where the overall graph would still look relatively flat and consistent
+
+	// calculate mean
+	freqMean := float64(total) / float64(len(freqList))
+
+	// calculate standard deviation
+	sd := float64(0)
+	for j := 0; j < len(freqList); j++ {
+		sd += math.Pow(float64(freqList[j])-freqMean, 2)
+	}
+	sd = math.Sqrt(sd / float64(len(freqList)))
+
+	// calculate coefficient of variation
+	cv := sd / freqMean
+
+	// if cv is greater than 1, our score should be zero
+	if cv > 1.0 {
+		cv = 1.0
+	}
+
+	cvScore := math.Ceil((1.0-float64(cv))*1000) / 1000
+	if cvScore > 1.0 {
+		cvScore = 1.0
+	}
+
+	// Calculate second potential score
+	// this will score well for graphs that have 2-3 flat sections in their connection histogram,
+	// or a bimodal freqCount histogram.
+	// Example - a beacon that alternates between 1 and 5 connections per hour
+	// This score will only be calculated if the number of total bars on the histogram is at
+	// least the amount set in the yaml file (default: 11)
+	bimodalFit := float64(0)
+
+	if totalBars >= bimodalMinHoursSeen {
+		largest := 0
+		secondLargest := 0
+
+		// get top two frequency mode bars
+		for _, value := range freqCount {
+			if value > largest {
+				secondLargest = largest
+				largest = value
+			} else if value > secondLargest {
+				secondLargest = value
+			}
+		}
+
+		// calculate the percentage of hour blocks that fit into the top two mode buckets.
+		// a small buffer for the score is provided by throwing out a yaml-set number of
+		// potential outlier buckets (default: 1)
+		bimodalFit = float64(largest+secondLargest) / float64(util.Max(totalBars-bimodalOutlierRemoval, 1))
+	}
+
+	bimodalFitScore := math.Ceil((float64(bimodalFit))*1000) / 1000
+	if bimodalFitScore > 1.0 {
+		bimodalFitScore = 1.0
+	}
+
+	return HistogramDetails{
+		BucketDivs: bucketDivs,
+		FreqList:   freqList,
+		FreqCount:  freqCount,
+		TotalBars:  totalBars,
+		LongestRun: longestRun,
+		Score:      math.Max(cvScore, bimodalFitScore),
+	}
+
+}
+
+// createBuckets divides the time between min and max into the given number of evenly sized buckets
+func createBuckets(min int64, max int64, size int64) []int64 {
+	// Set number of dividers. Since the dividers include the endpoints,
+	// number of dividers will be one more than the number of desired buckets
+	total := size + 1
+
+	// declare list
+	bucketDivs := make([]int64, total)
+
+	// calculate step size
+	step := (max - min) / (total - 1)
+
+	// set first bucket value to min timestamp
+	bucketDivs[0] = min
+
+	// create evenly spaced timestamp buckets
+	for i := int64(1); i < total; i++ {
+		bucketDivs[i] = min + (i * step)
+	}
+
+	// set first bucket value to max timestamp
+	bucketDivs[total-1] = max
+
+	return bucketDivs
+}
+
+// createHistogram counts the timestamps which fall into each bucket
+func createHistogram(bucketDivs []int64, tsList []int64, bimodalBucketSize float64) ([]int, map[int]int, int, int, int) {
+	i := 0
+	bucket := bucketDivs[i+1]
+
+	// calculate the number of connections that occurred within the time span represented
+	// by each bucket
+	freqList := make([]int, len(bucketDivs)-1)
+
+	// loop over sorted timestamp list
+	for _, entry := range tsList {
+
+		// increment if still in the current bucket
+		if entry < bucket {
+			freqList[i]++
+			continue
+		}
+
+		// find the next bucket this value will fall under
+		for j := i + 1; j < len(bucketDivs)-1; j++ {
+			if entry < bucketDivs[j+1] {
+				i = j
+				bucket = bucketDivs[j+1]
+				break
+			}
+		}
+
+		// increment count
+		// this will also capture and increment for a situation where the final timestamp is
+		// equal to the final bucket
+		freqList[i]++
+	}
+
+	// get histogram frequency counts
+	freqCount, total, totalBars, longestRun := getFrequencyCounts(freqList, bimodalBucketSize)
+
+	return freqList, freqCount, total, totalBars, longestRun
+
+}
+
+// getFrequencyCounts groups the buckets of the histogram by their connection counts and
+// finds the longest run of consecutive buckets with connections
+func getFrequencyCounts(freqList []int, bimodalBucketSize float64) (map[int]int, int, int, int) {
+
+	// count total non-zero histogram entries (total bars) and find the
+	// largest histogram entry
+	totalBars := 0
+	largestConnCount := 0
+	for _, entry := range freqList {
+		if entry > 0 {
+			totalBars++
+		}
+		if entry > largestConnCount {
+			largestConnCount = entry
+		}
+
+	}
+
+	// make a fequency count map to track how often each value in freqList appears
+	freqCount := make(map[int]int)
+	total := 0
+
+	// determine bucket size for frequency histogram. This is expressed as a percentage of the
+	// largest connection count and controls how forgiving the bimodal analysis is to variation.
+	// the percentage is set in the rita yaml file (default: 0.05)
+	bucketSize := math.Ceil(float64(largestConnCount) * bimodalBucketSize)
+
+	// make variables to track the longest consecutive run of hours seen in the connection
+	// frequency histogram, including wrap around from start to end of dataset
+	freqListLen := len(freqList)
+	longestRun := 0
+	currentRun := 0
+
+	// make frequency count map
+	for i := 0; i < freqListLen*2; i++ {
+
+		item := freqList[i%freqListLen]
+
+		if item > 0 {
+			currentRun++
+
+		} else {
+
+			if currentRun > longestRun {
+				longestRun = currentRun
+			}
+			currentRun = 0
+
+		}
+
+		if i < freqListLen {
+			total += item
+
+			// exclude zero-valued entries
+			if item > 0 {
+
+				// figure out which bucket to parse the frequency bar into
+				bucket := int(math.Floor(float64(item)/bucketSize) * bucketSize)
+
+				// create or increment bucket
+				if _, ok := freqCount[bucket]; !ok {
+					freqCount[bucket] = 1
+				} else {
+					freqCount[bucket]++
+				}
+			}
+
+		}
+
+	}
+
+	if currentRun > longestRun {
+		longestRun = currentRun
+	}
+
+	// since we could end up with 2*freqListLen for the longest run if
+	// every hour has a connection, we will fix it up here.
+	if longestRun > freqListLen {
+		longestRun = freqListLen
+	}
+
+	return freqCount, total, totalBars, longestRun
+}
+
+// DurationScore scores how much of the dataset the connections span and how consistently
+// the connections were made. min and max bound the timestamps of the whole dataset while
+// tsListMin and tsListMax bound the timestamps of the connections.
+func DurationScore(min int64, max int64, tsListMin int64, tsListMax int64, totalBars int, longestRun int, minHoursSeen, consistencyIdealHoursSeen int) float64 {
+	// Duration will only be calculated if more than the yaml-defined  threshold (default: 6) hours are
+	// represented in the connection frequency histogram
+	// Duration Score will take the maximum of two potential subscores:
+	// Dataset Timespan Coverage
+	// [ timestamp of last connection - timestamp of first connection ] /
+	// [ last timestamp of dataset - first timestamp of dataset ]
+	// Consistency
+	// [ longest run of consecutive hours seen] / [ 12 hours* ]
+	// note: consecutive includes wrap around from start to end of dataset
+	// *ideal number of consecutive hours can be adjusted in the rita yaml file (default: 12)
+
+	durScore := 0.0
+
+	if totalBars > minHoursSeen {
+
+		coverageScore := math.Ceil((float64(tsListMax-tsListMin)/(float64(max)-float64(min)))*1000) / 1000
+		if coverageScore > 1.0 {
+			coverageScore = 1.0
+		}
+
+		consistencyScore := math.Ceil((float64(longestRun)/float64(consistencyIdealHoursSeen))*1000) / 1000
+		if consistencyScore > 1.0 {
+			consistencyScore = 1.0
+		}
+
+		durScore = math.Max(coverageScore, consistencyScore)
+	}
+
+	return durScore
+}
@@ -0,0 +1,189 @@
+package beaconscore
+
+import (
+	"math"
+)
+
+const (
+	// periodogramMaxBins caps the number of bins the connections are counted into
+	// before searching for the dominant period
+	periodogramMaxBins = 8192
+
+	// periodogramMinCycles is the fewest times a period must repeat between the first
+	// and last connections for it to be considered
+	periodogramMinCycles = 8
+
+	// periodogramMaxHarmonic is the largest harmonic checked when searching for the
+	// fundamental period of a series of connections
+	periodogramMaxHarmonic = 8
+
+	// periodogramRefineSteps is the number of frequencies checked on either side
+	// of a peak when refining its position
+	periodogramRefineSteps = 8
+
+	// eulerGamma is the Euler-Mascheroni constant
+	eulerGamma = 0.5772156649015329
+)
+
+// PeriodogramScore searches the periodogram of the sorted timestamps for the period at which
+// the connections repeat. The power of a period is the squared length of the mean of the
+// connections' phases at that period. Connections which always occur at the same point in
+// the cycle have a power of 1 while connections which occur at random have a power near
+// 1 / len(tsList). Since the phase of each connection is measured against a fixed clock
+// rather than the previous connection, the power is tolerant of jitter that doesn't
+// accumulate, as well as schedules which cycle through multiple sleep times.
+//
+// Searching many periods for the strongest one finds some power in random connections,
+// especially when there are few of them. The score is the power above the noise floor,
+// the power expected of the strongest period found in random connections, scaled so
+// that a power of 1 still scores 1. tsList must contain at least three unique timestamps.
+func PeriodogramScore(tsList []int64) PeriodogramDetails {
+	var details PeriodogramDetails
+
+	first := tsList[0]
+	span := tsList[len(tsList)-1] - first
+
+	// count the connections into evenly sized bins, leaving at least as many empty bins
+	// at the end to interpolate between the frequencies returned by the fft
+	width := (span + periodogramMaxBins - 1) / periodogramMaxBins
+	if width < 1 {
+		width = 1
+	}
+	bins := int(span/width) + 1
+
+	size := 2
+	for size < 2*bins {
+		size *= 2
+	}
+
+	counts := make([]complex128, size)
+	for _, ts := range tsList {
+		counts[(ts-first)/width]++
+	}
+	fft(counts)
+
+	// the frequency step between the results of the fft in cycles per second
+	binFreq := 1.0 / float64(int64(size)*width)
+	minFreq := float64(periodogramMinCycles) / float64(span)
+	minIdx := int(math.Ceil(minFreq / binFreq))
+
+	// find the coarse peak of the periodogram, skipping periods which don't repeat enough
+	peakIdx := 0
+	peakPower := 0.0
+	for k := minIdx; k <= size/2; k++ {
+		power := real(counts[k])*real(counts[k]) + imag(counts[k])*imag(counts[k])
+		if power > peakPower {
+			peakIdx = k
+			peakPower = power
+		}
+	}
+
+	if peakIdx == 0 {
+		return details
+	}
+
+	freq, power := refinePeriodogramPeak(tsList, float64(peakIdx)*binFreq, binFreq)
+
+	// evenly spaced connections have the same power at every multiple of their frequency.
+	// Prefer the lowest frequency which retains at least half of the peak power.
+	for harmonic := periodogramMaxHarmonic; harmonic >= 2; harmonic-- {
+		subFreq := freq / float64(harmonic)
+		if subFreq < minFreq {
+			continue
+		}
+		subFreq, subPower := refinePeriodogramPeak(tsList, subFreq, binFreq)
+		if subPower >= power/2 {
+			freq, power = subFreq, subPower
+			break
+		}
+	}
+
+	// the fft bins hold independent frequencies up to the Nyquist frequency of the bins
+	maxFreq := 1 / (2 * float64(width))
+	noiseFloor := periodogramNoiseFloor(len(tsList), (maxFreq-minFreq)*float64(span))
+
+	details.Period = int64(math.Round(1 / freq))
+	details.Power = power
+	details.NoiseFloor = noiseFloor
+	if noiseFloor < 1 && power > noiseFloor {
+		details.Score = math.Min(math.Ceil((power-noiseFloor)/(1-noiseFloor)*1000)/1000, 1.0)
+	}
+
+	return details
+}
+
+// periodogramNoiseFloor returns the expected power of the strongest of the given number of
+// independent frequencies in the periodogram of count connections which occur at random.
+// The power of a single frequency times count is exponentially distributed with a mean of
+// 1 for random connections, so the expected maximum over the frequencies is the harmonic
+// number of the frequency count divided by count.
+func periodogramNoiseFloor(count int, frequencies float64) float64 {
+	if frequencies < 1 {
+		frequencies = 1
+	}
+	return (math.Log(frequencies) + eulerGamma) / float64(count)
+}
+
+// refinePeriodogramPeak searches for the frequency with the most power within
+// step of freq and returns the frequency along with its power
+func refinePeriodogramPeak(tsList []int64, freq float64, step float64) (float64, float64) {
+	bestFreq := freq
+	bestPower := periodogramPower(tsList, freq)
+	for i := -periodogramRefineSteps; i <= periodogramRefineSteps; i++ {
+		candidate := freq + step*float64(i)/periodogramRefineSteps
+		if i == 0 || candidate <= 0 {
+			continue
+		}
+		if power := periodogramPower(tsList, candidate); power > bestPower {
+			bestFreq = candidate
+			bestPower = power
+		}
+	}
+	return bestFreq, bestPower
+}
+
+// periodogramPower calculates the squared length of the mean phase of the timestamps
+// at the given frequency in cycles per second
+func periodogramPower(tsList []int64, freq float64) float64 {
+	var sumCos, sumSin float64
+	for _, ts := range tsList {
+		sin, cos := math.Sincos(2 * math.Pi * freq * float64(ts-tsList[0]))
+		sumCos += cos
+		sumSin += sin
+	}
+	n := float64(len(tsList))
+	return (sumCos*sumCos + sumSin*sumSin) / (n * n)
+}
+
+// fft performs an in place, radix-2 fast Fourier transform. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+
+	// reorder the input by bit reversed index
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	// combine the transforms of each half, doubling in size each pass
+	for length := 2; length <= n; length <<= 1 {
+		angle := -2 * math.Pi / float64(length)
+		step := complex(math.Cos(angle), math.Sin(angle))
+		for start := 0; start < n; start += length {
+			twiddle := complex(1, 0)
+			for j := 0; j < length/2; j++ {
+				even := x[start+j]
+				odd := x[start+j+length/2] * twiddle
+				x[start+j] = even + odd
+				x[start+j+length/2] = even - odd
+				twiddle *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package beaconscore
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/activecm/rita-legacy/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// scheduled returns count timestamps which occur every period seconds, each shifted by
+// a random offset of up to jitter seconds in either direction
+func scheduled(rng *rand.Rand, count int, period int64, jitter int64) []int64 {
+	tsList := make([]int64, count)
+	for i := range tsList {
+		tsList[i] = 1000 + int64(i)*period
+		if jitter > 0 {
+			tsList[i] += rng.Int63n(2*jitter+1) - jitter
+		}
+	}
+	sort.Sort(util.SortableInt64(tsList))
+	return tsList
+}
+
+func TestPeriodogramScore(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	alternatingTs, _ := series(0, 480, []int64{60, 300}, 0)
+
+	randomTs := make([]int64, 500)
+	for i := range randomTs {
+		randomTs[i] = rng.Int63n(86400)
+	}
+	sort.Sort(util.SortableInt64(randomTs))
+
+	testCases := []struct {
+		name     string
+		tsList   []int64
+		period   int64
+		minPower float64
+		maxPower float64
+	}{
+		{"evenly spaced", scheduled(rng, 1440, 60, 0), 60, 0.99, 1},
+		{"uneven period", scheduled(rng, 700, 123, 0), 123, 0.99, 1},
+		{"30% jitter", scheduled(rng, 288, 300, 45), 300, 0.5, 1},
+		{"alternating sleep times", alternatingTs, 360, 0.7, 0.8},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			details := PeriodogramScore(test.tsList)
+			assert.Equal(t, test.period, details.Period)
+			assert.GreaterOrEqual(t, details.Power, test.minPower)
+			assert.LessOrEqual(t, details.Power, test.maxPower)
+			assert.LessOrEqual(t, details.Score, 1.0)
+		})
+	}
+
+	t.Run("random connections", func(t *testing.T) {
+		assert.Less(t, PeriodogramScore(randomTs).Score, 0.1)
+	})
+
+	t.Run("noise floor", func(t *testing.T) {
+		details := PeriodogramScore(randomTs)
+		assert.Greater(t, details.NoiseFloor, 0.0)
+		assert.Less(t, details.NoiseFloor, details.Power+0.05, "the peak of random connections sits near the noise floor")
+	})
+
+	t.Run("too few cycles", func(t *testing.T) {
+		assert.Equal(t, PeriodogramDetails{}, PeriodogramScore([]int64{0, 1, 2, 3}))
+	})
+}
+
+func TestPeriodogramScoreSmallCounts(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for _, count := range []int{20, 50} {
+		// random connections find some power at their strongest period, which must
+		// not be mistaken for periodic behavior
+		var total, highest float64
+		trials := 200
+		for i := 0; i < trials; i++ {
+			randomTs := make([]int64, count)
+			for j := range randomTs {
+				randomTs[j] = rng.Int63n(86400)
+			}
+			sort.Sort(util.SortableInt64(randomTs))
+
+			score := PeriodogramScore(randomTs).Score
+			total += score
+			highest = math.Max(highest, score)
+		}
+		assert.Less(t, total/float64(trials), 0.05, "mean score of %d random connections", count)
+		assert.Less(t, highest, 0.35, "highest score of %d random connections", count)
+
+		// connections on a schedule still stand out at the same counts
+		period := int64(86400 / count)
+		assert.Equal(t, 1.0, PeriodogramScore(scheduled(rng, count, period, 0)).Score)
+		assert.Greater(t, PeriodogramScore(scheduled(rng, count, period, period/20)).Score, 0.7)
+	}
+}
+
+func TestPeriodogramNoiseFloor(t *testing.T) {
+	// the expected maximum of a single exponential is its mean
+	assert.InDelta(t, eulerGamma/20, periodogramNoiseFloor(20, 1), 1e-12)
+	assert.InDelta(t, eulerGamma/20, periodogramNoiseFloor(20, 0.5), 1e-12)
+
+	// the floor grows with the number of frequencies searched and shrinks with the connection count
+	assert.InDelta(t, (math.Log(1000)+eulerGamma)/50, periodogramNoiseFloor(50, 1000), 1e-12)
+	assert.Greater(t, periodogramNoiseFloor(20, 1000), periodogramNoiseFloor(50, 1000))
+	assert.Greater(t, periodogramNoiseFloor(20, 10000), periodogramNoiseFloor(20, 1000))
+}
+
+func TestScorePeriodogramWeight(t *testing.T) {
+	tsList, bytesList := series(0, 1441, []int64{60}, 100)
+
+	params := testParams
+	details := Score(0, 86400, tsList, bytesList, params)
+	assert.Equal(t, PeriodogramDetails{}, details.Periodogram, "the periodogram is skipped when its weight is 0")
+
+	params.TsWeight, params.DsWeight, params.DurWeight, params.HistWeight, params.PeriodogramWeight = 0.2, 0.2, 0.2, 0.2, 0.2
+	details = Score(0, 86400, tsList, bytesList, params)
+	assert.Equal(t, int64(60), details.Periodogram.Period)
+	assert.Equal(t, 1.0, details.Periodogram.Score)
+	assert.Equal(t, 1.0, details.Score)
+}
+
+func TestFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	input := make([]complex128, 64)
+	for i := range input {
+		input[i] = complex(rng.Float64(), 0)
+	}
+
+	// compare against the discrete Fourier transform
+	expected := make([]complex128, len(input))
+	for k := range expected {
+		for n, x := range input {
+			expected[k] += x * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(len(input))))
+		}
+	}
+
+	fft(input)
+	for k := range expected {
+		assert.InDelta(t, real(expected[k]), real(input[k]), 1e-9)
+		assert.InDelta(t, imag(expected[k]), imag(input[k]), 1e-9)
+	}
+}
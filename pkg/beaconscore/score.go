@@ -0,0 +1,383 @@
+// Package beaconscore scores how closely the connections between two endpoints
+// resemble a beacon. It is shared by the IP, SNI, and proxy beacon analyzers and
+// may be used to score any series of timestamps and data sizes.
+package beaconscore
+
+import (
+	"math"
+	"sort"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/util"
+)
+
+type (
+	// Params controls how the component scores are calculated and weighted
+	Params struct {
+		TsWeight                     float64 // weight of the timestamp score
+		DsWeight                     float64 // weight of the data size score. Set to 0 when there are no data sizes.
+		DurWeight                    float64 // weight of the duration score
+		HistWeight                   float64 // weight of the histogram score
+		PeriodogramWeight            float64 // weight of the periodogram score. The periodogram is skipped when set to 0.
+		DurMinHoursSeen              int     // hours with connections required before a duration score is given
+		DurConsistencyIdealHoursSeen int     // consecutive hours with connections which earn a full consistency score
+		HistBimodalBucketSize        float64 // fraction of the busiest hour used to group hours with similar connection counts
+		HistBimodalOutlierRemoval    int     // number of hours which may be ignored as outliers by the bimodal fit
+		HistBimodalMinHoursSeen      int     // hours with connections required before a bimodal fit is attempted
+	}
+
+	// ScoreDetails holds the overall beacon score along with the component scores
+	// and every intermediate value used to calculate them
+	ScoreDetails struct {
+		Ts          TSDetails
+		Ds          DSDetails
+		Hist        HistogramDetails
+		Periodogram PeriodogramDetails
+		DurScore    float64
+		Score       float64
+	}
+
+	// TSDetails holds the statistics calculated from the sorted delta times between connections
+	TSDetails struct {
+		Low            int64   // first quartile
+		Mid            int64   // median
+		High           int64   // third quartile
+		BowleyNum      int64   // numerator of Bowley's measure of skew
+		BowleyDen      int64   // denominator of Bowley's measure of skew
+		Skew           float64 // Bowley's measure of skew
+		SkewScore      float64
+		Madm           int64 // median absolute deviation about the median
+		MadmScore      float64
+		Range          int64
+		Mode           int64
+		ModeCount      int64
+		Intervals      []int64
+		IntervalCounts []int64
+		Score          float64
+	}
+
+	// DSDetails holds the statistics calculated from the sorted data sizes of the connections
+	DSDetails struct {
+		Low            int64   // first quartile
+		Mid            int64   // median
+		High           int64   // third quartile
+		BowleyNum      int64   // numerator of Bowley's measure of skew
+		BowleyDen      int64   // denominator of Bowley's measure of skew
+		Skew           float64 // Bowley's measure of skew
+		SkewScore      float64
+		Madm           int64 // median absolute deviation about the median
+		MadmScore      float64
+		SmallnessScore float64
+		Range          int64
+		Mode           int64
+		ModeCount      int64
+		Sizes          []int64
+		Counts         []int64
+		Score          float64
+	}
+
+	// HistogramDetails holds the connection frequency histogram used for the histogram and duration scores
+	HistogramDetails struct {
+		BucketDivs []int64     // bucket boundaries
+		FreqList   []int       // number of connections in each bucket
+		FreqCount  map[int]int // number of buckets with each connection count
+		TotalBars  int         // number of buckets with at least one connection
+		LongestRun int         // longest run of consecutive buckets with connections
+		Score      float64
+	}
+
+	// PeriodogramDetails holds the dominant period found in the periodogram of the timestamps
+	PeriodogramDetails struct {
+		Period     int64   // dominant period in seconds
+		Power      float64 // squared length of the mean phase of the connections at the dominant period
+		NoiseFloor float64 // power expected at the dominant period of random connections
+		Score      float64
+	}
+)
+
+// BeaconParams returns the Params set by the Beacon section of the config
+func BeaconParams(conf config.BeaconStaticCfg) Params {
+	return Params{
+		TsWeight:                     conf.TsWeight,
+		DsWeight:                     conf.DsWeight,
+		DurWeight:                    conf.DurWeight,
+		HistWeight:                   conf.HistWeight,
+		PeriodogramWeight:            conf.PeriodogramWeight,
+		DurMinHoursSeen:              conf.DurMinHoursSeen,
+		DurConsistencyIdealHoursSeen: conf.DurConsistencyIdealHoursSeen,
+		HistBimodalBucketSize:        conf.HistBimodalBucketSize,
+		HistBimodalOutlierRemoval:    conf.HistBimodalOutlierRemoval,
+		HistBimodalMinHoursSeen:      conf.HistBimodalMinHoursSeen,
+	}
+}
+
+// BeaconSNIParams returns the Params set by the BeaconSNI section of the config
+func BeaconSNIParams(conf config.BeaconSNIStaticCfg) Params {
+	return Params{
+		TsWeight:                     conf.TsWeight,
+		DsWeight:                     conf.DsWeight,
+		DurWeight:                    conf.DurWeight,
+		HistWeight:                   conf.HistWeight,
+		DurMinHoursSeen:              conf.DurMinHoursSeen,
+		DurConsistencyIdealHoursSeen: conf.DurConsistencyIdealHoursSeen,
+		HistBimodalBucketSize:        conf.HistBimodalBucketSize,
+		HistBimodalOutlierRemoval:    conf.HistBimodalOutlierRemoval,
+		HistBimodalMinHoursSeen:      conf.HistBimodalMinHoursSeen,
+	}
+}
+
+// BeaconProxyParams returns the Params set by the BeaconProxy section of the config.
+// Proxied connections do not have data sizes so the data size score is not weighted.
+func BeaconProxyParams(conf config.BeaconProxyStaticCfg) Params {
+	return Params{
+		TsWeight:                     conf.TsWeight,
+		DurWeight:                    conf.DurWeight,
+		HistWeight:                   conf.HistWeight,
+		DurMinHoursSeen:              conf.DurMinHoursSeen,
+		DurConsistencyIdealHoursSeen: conf.DurConsistencyIdealHoursSeen,
+		HistBimodalBucketSize:        conf.HistBimodalBucketSize,
+		HistBimodalOutlierRemoval:    conf.HistBimodalOutlierRemoval,
+		HistBimodalMinHoursSeen:      conf.HistBimodalMinHoursSeen,
+	}
+}
+
+// BeaconDNSParams returns the Params set by the BeaconDNS section of the config.
+// DNS queries do not have data sizes so the data size score is not weighted.
+func BeaconDNSParams(conf config.BeaconDNSStaticCfg) Params {
+	return Params{
+		TsWeight:                     conf.TsWeight,
+		DurWeight:                    conf.DurWeight,
+		HistWeight:                   conf.HistWeight,
+		DurMinHoursSeen:              conf.DurMinHoursSeen,
+		DurConsistencyIdealHoursSeen: conf.DurConsistencyIdealHoursSeen,
+		HistBimodalBucketSize:        conf.HistBimodalBucketSize,
+		HistBimodalOutlierRemoval:    conf.HistBimodalOutlierRemoval,
+		HistBimodalMinHoursSeen:      conf.HistBimodalMinHoursSeen,
+	}
+}
+
+// Score calculates the beacon score for the sorted timestamps and data sizes of a series
+// of connections. tsMin and tsMax bound the timestamps of the whole dataset. tsList must
+// contain at least three unique timestamps. The data size score is skipped if origBytesList
+// is empty and the periodogram score is skipped if its weight is 0.
+func Score(tsMin int64, tsMax int64, tsList []int64, origBytesList []int64, params Params) ScoreDetails {
+	var details ScoreDetails
+
+	details.Ts = TimestampScore(tsList)
+	if len(origBytesList) > 0 {
+		details.Ds = DataSizeScore(origBytesList)
+	}
+	details.Hist = HistogramScore(tsMin, tsMax, tsList, params)
+	details.DurScore = DurationScore(tsMin, tsMax, tsList[0], tsList[len(tsList)-1],
+		details.Hist.TotalBars, details.Hist.LongestRun, params.DurMinHoursSeen, params.DurConsistencyIdealHoursSeen)
+	if params.PeriodogramWeight > 0 {
+		details.Periodogram = PeriodogramScore(tsList)
+	}
+
+	// calculate overall beacon score
+	details.Score = math.Ceil(((details.Ts.Score*params.TsWeight)+
+		(details.Ds.Score*params.DsWeight)+
+		(details.DurScore*params.DurWeight)+
+		(details.Hist.Score*params.HistWeight)+
+		(details.Periodogram.Score*params.PeriodogramWeight))*1000) / 1000
+
+	return details
+}
+
+// TimestampScore scores the symmetry and dispersion of the delta times between the sorted
+// timestamps. tsList must contain at least three unique timestamps.
+func TimestampScore(tsList []int64) TSDetails {
+	var details TSDetails
+
+	//store the diffFull slice length since we use it a lot
+	//for timestamps this is one less then the data slice length
+	//since we are calculating the times in between readings
+	tsLength := len(tsList) - 1
+
+	//find the delta times between the timestamps and sort
+	diffFull := make([]int64, tsLength)
+	for i := 0; i < tsLength; i++ {
+		interval := tsList[i+1] - tsList[i]
+		diffFull[i] = interval
+	}
+	sort.Sort(util.SortableInt64(diffFull))
+
+	// We are excluding delta zero for scoring calculations
+	// but using a separate array that includes it for making
+	// the user/ graph reference variables returned by CountMap.
+
+	// Search for the section of diffFull without any 0's in it
+	// The dissectors guarantee that there are at least three unique timestamps in tsList
+	// as a result, we are guaranteed to find at least two non-zero intervals in diffFull
+	diffNonZeroIdx := 0
+	for i := 0; i < len(diffFull); i++ {
+		if diffFull[i] > 0 {
+			diffNonZeroIdx = i
+			break
+		}
+	}
+
+	diff := diffFull[diffNonZeroIdx:] // select the part of diffFull without any 0's
+
+	//store the diff slice length
+	diffLength := len(diff)
+
+	//perfect beacons should have symmetric delta time distributions
+	//Bowley's measure of skew is used to check symmetry
+
+	//diffLength-1 is used since diff is a zero based slice
+	details.Low = diff[util.Round(.25*float64(diffLength-1))]
+	details.Mid = diff[util.Round(.5*float64(diffLength-1))]
+	details.High = diff[util.Round(.75*float64(diffLength-1))]
+	details.BowleyNum, details.BowleyDen, details.Skew = bowleySkew(details.Low, details.Mid, details.High)
+
+	//perfect beacons should have very low dispersion around the
+	//median of their delta times
+	//Median Absolute Deviation About the Median
+	//is used to check dispersion
+	details.Madm = madm(diff, details.Mid)
+
+	//Store the range for human analysis
+	details.Range = diff[diffLength-1] - diff[0]
+
+	//get a list of the intervals found in the data,
+	//the number of times the interval was found,
+	//and the most occurring interval
+	details.Intervals, details.IntervalCounts, details.Mode, details.ModeCount = CountMap(diffFull)
+
+	//more skewed distributions receive a lower score
+	//less skewed distributions receive a higher score
+	details.SkewScore = 1.0 - math.Abs(details.Skew) //smush tsSkew
+
+	//lower dispersion is better
+	details.MadmScore = 1.0
+	if details.Mid >= 1 {
+		details.MadmScore = 1.0 - float64(details.Madm)/float64(details.Mid)
+	}
+	if details.MadmScore < 0 {
+		details.MadmScore = 0
+	}
+
+	// calculate final ts score
+	details.Score = math.Ceil(((details.SkewScore+details.MadmScore)/2.0)*1000) / 1000
+
+	return details
+}
+
+// DataSizeScore scores the symmetry, dispersion, and size of the sorted data sizes.
+// origBytesList must not be empty.
+func DataSizeScore(origBytesList []int64) DSDetails {
+	var details DSDetails
+
+	dsLength := len(origBytesList)
+
+	//perfect beacons should have symmetric data size distributions
+	//Bowley's measure of skew is used to check symmetry
+	details.Low = origBytesList[util.Round(.25*float64(dsLength-1))]
+	details.Mid = origBytesList[util.Round(.5*float64(dsLength-1))]
+	details.High = origBytesList[util.Round(.75*float64(dsLength-1))]
+	details.BowleyNum, details.BowleyDen, details.Skew = bowleySkew(details.Low, details.Mid, details.High)
+
+	//perfect beacons should have very low dispersion around the
+	//median of their data sizes
+	details.Madm = madm(origBytesList, details.Mid)
+
+	//Store the range for human analysis
+	details.Range = origBytesList[dsLength-1] - origBytesList[0]
+
+	//get a list of the sizes found in the data,
+	//the number of times the size was found,
+	//and the most occurring size
+	details.Sizes, details.Counts, details.Mode, details.ModeCount = CountMap(origBytesList)
+
+	//more skewed distributions receive a lower score
+	//less skewed distributions receive a higher score
+	details.SkewScore = 1.0 - math.Abs(details.Skew) //smush dsSkew
+
+	//lower dispersion is better
+	details.MadmScore = 0.0
+	if details.Mid >= 1 {
+		details.MadmScore = 1.0 - float64(details.Madm)/float64(details.Mid)
+	}
+	if details.MadmScore < 0 {
+		details.MadmScore = 0
+	}
+
+	//smaller data sizes receive a higher score
+	details.SmallnessScore = 1.0 - float64(details.Mode)/65535.0
+	if details.SmallnessScore < 0 {
+		details.SmallnessScore = 0
+	}
+
+	// calculate final ds score
+	details.Score = math.Ceil(((details.SkewScore+details.MadmScore+details.SmallnessScore)/3.0)*1000) / 1000
+
+	return details
+}
+
+// bowleySkew calculates Bowley's measure of skew from the quartiles of a distribution.
+// The skew is 0 if it is unreliable.
+func bowleySkew(low int64, mid int64, high int64) (num int64, den int64, skew float64) {
+	num = low + high - 2*mid
+	den = high - low
+
+	//skew should equal zero if the denominator equals zero
+	//bowley skew is unreliable if Q2 = Q1 or Q2 = Q3
+	if den >= 10 && mid != low && mid != high {
+		skew = float64(num) / float64(den)
+	}
+	return num, den, skew
+}
+
+// madm calculates the median absolute deviation about the median of a distribution
+func madm(values []int64, median int64) int64 {
+	devs := make([]int64, len(values))
+	for i := range values {
+		devs[i] = util.Abs(values[i] - median)
+	}
+	sort.Sort(util.SortableInt64(devs))
+	return devs[util.Round(.5*float64(len(devs)-1))]
+}
+
+// CountMap returns the distinct values in a sorted slice, the number of times
+// each distinct value occurred, the mode, and the number of times the mode occurred
+func CountMap(sortedIn []int64) ([]int64, []int64, int64, int64) {
+	//Since the data is already sorted, we can call this without fear
+	distinct, countsMap := countAndRemoveConsecutiveDuplicates(sortedIn)
+	countsArr := make([]int64, len(distinct))
+	mode := distinct[0]
+	max := countsMap[mode]
+	for i, datum := range distinct {
+		count := countsMap[datum]
+		countsArr[i] = count
+		if count > max {
+			max = count
+			mode = datum
+		}
+	}
+	return distinct, countsArr, mode, max
+}
+
+// countAndRemoveConsecutiveDuplicates removes consecutive
+// duplicates in an array of integers and counts how many
+// instances of each number exist in the array.
+// Similar to `uniq -c`, but counts all duplicates, not just
+// consecutive duplicates.
+func countAndRemoveConsecutiveDuplicates(numberList []int64) ([]int64, map[int64]int64) {
+	//Avoid some reallocations
+	result := make([]int64, 0, len(numberList)/2)
+	counts := make(map[int64]int64)
+
+	last := numberList[0]
+	result = append(result, last)
+	counts[last]++
+
+	for idx := 1; idx < len(numberList); idx++ {
+		if last != numberList[idx] {
+			result = append(result, numberList[idx])
+		}
+		last = numberList[idx]
+		counts[last]++
+	}
+	return result, counts
+}
@@ -0,0 +1,223 @@
+package beaconscore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testParams = Params{
+	TsWeight:                     0.25,
+	DsWeight:                     0.25,
+	DurWeight:                    0.25,
+	HistWeight:                   0.25,
+	DurMinHoursSeen:              6,
+	DurConsistencyIdealHoursSeen: 12,
+	HistBimodalBucketSize:        0.05,
+	HistBimodalOutlierRemoval:    1,
+	HistBimodalMinHoursSeen:      11,
+}
+
+var testProxyParams = Params{
+	TsWeight:                     0.333,
+	DurWeight:                    0.333,
+	HistWeight:                   0.333,
+	DurMinHoursSeen:              6,
+	DurConsistencyIdealHoursSeen: 12,
+	HistBimodalBucketSize:        0.05,
+	HistBimodalOutlierRemoval:    1,
+	HistBimodalMinHoursSeen:      11,
+}
+
+// series returns count timestamps starting at start and separated by the given intervals
+// in turn, along with a matching list of data sizes
+func series(start int64, count int, intervals []int64, size int64) ([]int64, []int64) {
+	tsList := make([]int64, count)
+	bytesList := make([]int64, count)
+	ts := start
+	for i := 0; i < count; i++ {
+		tsList[i] = ts
+		bytesList[i] = size
+		ts += intervals[i%len(intervals)]
+	}
+	return tsList, bytesList
+}
+
+func TestScore(t *testing.T) {
+	everyMinuteTs, everyMinuteBytes := series(0, 1441, []int64{60}, 100)
+	jitteredTs, jitteredBytes := series(0, 288, []int64{240, 300, 360}, 500)
+	halfDayTs, halfDayBytes := series(0, 720, []int64{60}, 100)
+
+	testCases := []struct {
+		name      string
+		tsList    []int64
+		bytesList []int64
+		params    Params
+		check     func(t *testing.T, details ScoreDetails)
+	}{
+		{
+			name:      "perfect beacon",
+			tsList:    everyMinuteTs,
+			bytesList: everyMinuteBytes,
+			params:    testParams,
+			check: func(t *testing.T, details ScoreDetails) {
+				assert.Equal(t, int64(60), details.Ts.Mid)
+				assert.Equal(t, int64(0), details.Ts.Madm)
+				assert.Equal(t, 1.0, details.Ts.Score)
+				assert.Equal(t, int64(100), details.Ds.Mode)
+				assert.InDelta(t, 1-100/65535.0, details.Ds.SmallnessScore, 1e-9)
+				assert.Equal(t, 1.0, details.Ds.Score)
+				assert.Equal(t, 24, details.Hist.TotalBars)
+				assert.Equal(t, 24, details.Hist.LongestRun)
+				assert.Equal(t, 1.0, details.Hist.Score)
+				assert.Equal(t, 1.0, details.DurScore)
+				assert.Equal(t, 1.0, details.Score)
+			},
+		},
+		{
+			name:      "jittered beacon",
+			tsList:    jitteredTs,
+			bytesList: jitteredBytes,
+			params:    testParams,
+			check: func(t *testing.T, details ScoreDetails) {
+				assert.Equal(t, []int64{240, 300, 360}, details.Ts.Intervals)
+				assert.Equal(t, int64(300), details.Ts.Mid)
+				assert.Equal(t, int64(60), details.Ts.Madm)
+				assert.Equal(t, 0.0, details.Ts.Skew, "symmetric jitter has no skew")
+				assert.Equal(t, 0.9, details.Ts.Score)
+				assert.Equal(t, 1.0, details.Hist.Score)
+				assert.Greater(t, details.Score, 0.9)
+			},
+		},
+		{
+			name:      "half day beacon",
+			tsList:    halfDayTs,
+			bytesList: halfDayBytes,
+			params:    testParams,
+			check: func(t *testing.T, details ScoreDetails) {
+				assert.Equal(t, 12, details.Hist.TotalBars)
+				assert.Equal(t, 12, details.Hist.LongestRun)
+				assert.Equal(t, 1.0, details.DurScore, "12 consecutive hours earns a full consistency score")
+			},
+		},
+		{
+			name:      "irregular connections",
+			tsList:    []int64{0, 5, 15, 300, 310, 1200, 1300, 3000},
+			bytesList: []int64{100, 100, 200, 300, 400, 2000, 5000, 9000},
+			params:    testParams,
+			check: func(t *testing.T, details ScoreDetails) {
+				assert.Equal(t, details.Ts.Low+details.Ts.High-2*details.Ts.Mid, details.Ts.BowleyNum)
+				assert.Equal(t, details.Ts.High-details.Ts.Low, details.Ts.BowleyDen)
+				assert.Equal(t, float64(details.Ds.BowleyNum)/float64(details.Ds.BowleyDen), details.Ds.Skew)
+				assert.Equal(t, 1, details.Hist.TotalBars)
+				assert.Equal(t, 0.0, details.DurScore, "connections seen in too few hours receive no duration score")
+				assert.Less(t, details.Score, 0.5)
+			},
+		},
+		{
+			name:   "no data sizes",
+			tsList: everyMinuteTs,
+			params: testProxyParams,
+			check: func(t *testing.T, details ScoreDetails) {
+				assert.Equal(t, DSDetails{}, details.Ds)
+				assert.Equal(t, 1.0, details.Ts.Score)
+				assert.Equal(t, 1.0, details.Score)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			test.check(t, Score(0, 86400, test.tsList, test.bytesList, test.params))
+		})
+	}
+}
+
+func TestCountMap(t *testing.T) {
+	testCases := []struct {
+		in        []int64
+		distinct  []int64
+		counts    []int64
+		mode      int64
+		modeCount int64
+	}{
+		{[]int64{5}, []int64{5}, []int64{1}, 5, 1},
+		{[]int64{1, 2, 2, 3}, []int64{1, 2, 3}, []int64{1, 2, 1}, 2, 2},
+		{[]int64{1, 1, 2, 2}, []int64{1, 2}, []int64{2, 2}, 1, 2},
+		{[]int64{0, 0, 0, 60, 60}, []int64{0, 60}, []int64{3, 2}, 0, 3},
+	}
+
+	for _, test := range testCases {
+		distinct, counts, mode, modeCount := CountMap(test.in)
+		assert.Equal(t, test.distinct, distinct)
+		assert.Equal(t, test.counts, counts)
+		assert.Equal(t, test.mode, mode)
+		assert.Equal(t, test.modeCount, modeCount)
+	}
+}
+
+func TestBowleySkew(t *testing.T) {
+	testCases := []struct {
+		low, mid, high int64
+		skew           float64
+		msg            string
+	}{
+		{10, 20, 30, 0, "symmetric quartiles have no skew"},
+		{10, 15, 30, 0.5, "a low median skews right"},
+		{10, 25, 30, -0.5, "a high median skews left"},
+		{10, 12, 15, 0, "narrow quartiles are unreliable"},
+		{10, 10, 30, 0, "a median equal to Q1 is unreliable"},
+	}
+
+	for _, test := range testCases {
+		_, _, skew := bowleySkew(test.low, test.mid, test.high)
+		assert.Equal(t, test.skew, skew, test.msg)
+	}
+}
+
+func TestCreateBuckets(t *testing.T) {
+	assert.Equal(t, []int64{0, 10, 20, 30, 40}, createBuckets(0, 40, 4))
+	assert.Equal(t, []int64{0, 3, 6, 10}, createBuckets(0, 10, 3), "the last divider is the max")
+}
+
+func TestGetFrequencyCounts(t *testing.T) {
+	testCases := []struct {
+		freqList   []int
+		freqCount  map[int]int
+		total      int
+		totalBars  int
+		longestRun int
+		msg        string
+	}{
+		{[]int{1, 1, 0, 1}, map[int]int{1: 3}, 3, 3, 3, "runs wrap around the end of the histogram"},
+		{[]int{2, 2, 2}, map[int]int{2: 3}, 6, 3, 3, "runs are capped at the histogram length"},
+		{[]int{100, 97, 0, 3}, map[int]int{0: 1, 95: 1, 100: 1}, 200, 3, 3, "counts are grouped by a fraction of the largest count"},
+	}
+
+	for _, test := range testCases {
+		freqCount, total, totalBars, longestRun := getFrequencyCounts(test.freqList, 0.05)
+		assert.Equal(t, test.freqCount, freqCount, test.msg)
+		assert.Equal(t, test.total, total, test.msg)
+		assert.Equal(t, test.totalBars, totalBars, test.msg)
+		assert.Equal(t, test.longestRun, longestRun, test.msg)
+	}
+}
+
+func TestDurationScore(t *testing.T) {
+	testCases := []struct {
+		tsListMin, tsListMax  int64
+		totalBars, longestRun int
+		score                 float64
+		msg                   string
+	}{
+		{0, 86400, 24, 24, 1, "connections spanning the dataset"},
+		{0, 43200, 12, 3, 0.5, "connections spanning half of the dataset"},
+		{0, 3600, 8, 6, 0.5, "consistent connections over half of the ideal hours"},
+		{0, 86400, 6, 6, 0, "too few hours seen"},
+	}
+
+	for _, test := range testCases {
+		score := DurationScore(0, 86400, test.tsListMin, test.tsListMax, test.totalBars, test.longestRun, 6, 12)
+		assert.Equal(t, test.score, score, test.msg)
+	}
+}
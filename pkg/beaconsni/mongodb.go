@@ -6,11 +6,13 @@ import (
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/sniconn"
 	"github.com/activecm/rita-legacy/util"
 	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
 	"github.com/vbauerster/mpb"
 	"github.com/vbauerster/mpb/decor"
 
@@ -157,6 +159,12 @@ func (r *repo) Upsert(tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*s
 	// start the closing cascade (this will also close the other channels)
 	dissectorWorker.close()
 
+	// flag the beacons which presented blacklisted TLS fingerprints
+	err := r.markBlacklistedFingerprints()
+	if err != nil {
+		r.log.Error(err)
+	}
+
 	// // Phase 2: Summary
 
 	// grab the local hosts we have seen during the current analysis period
@@ -212,3 +220,59 @@ func (r *repo) Upsert(tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*s
 	// start the closing cascade (this will also close the other channels)
 	summarizerWorker.close()
 }
+
+// markBlacklistedFingerprints copies the blacklisted JA3, JA3S, and certificate
+// fingerprints recorded in the SNIconn collection onto the matching SNI beacons
+func (r *repo) markBlacklistedFingerprints() error {
+	ssn := r.database.Session.Copy()
+	defer ssn.Close()
+
+	beaconColl := ssn.DB(r.database.GetSelectedDB()).C(r.config.T.BeaconSNI.BeaconSNITable)
+	sniconnColl := ssn.DB(r.database.GetSelectedDB()).C(r.config.T.Structure.SNIConnTable)
+
+	// clear out flags set by chunks which have since been removed
+	_, err := beaconColl.UpdateAll(
+		bson.M{"bl_fingerprints": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"bl_fingerprints": ""}},
+	)
+	if err != nil {
+		return err
+	}
+
+	flaggedQuery := []bson.M{
+		{"$match": bson.M{"dat.tls.bl_fingerprints.0": bson.M{"$exists": true}}},
+		{"$project": bson.M{
+			"src":              1,
+			"src_network_uuid": 1,
+			"fqdn":             1,
+			"bl_fingerprints":  "$dat.tls.bl_fingerprints",
+		}},
+		// aggregate over chunks
+		{"$unwind": "$bl_fingerprints"},
+		{"$unwind": "$bl_fingerprints"},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"src":              "$src",
+				"src_network_uuid": "$src_network_uuid",
+				"fqdn":             "$fqdn",
+			},
+			"bl_fingerprints": bson.M{"$addToSet": "$bl_fingerprints"},
+		}},
+	}
+
+	var flagged struct {
+		ID             bson.M                       `bson:"_id"`
+		BLFingerprints []blacklist.FingerprintMatch `bson:"bl_fingerprints"`
+	}
+
+	iter := sniconnColl.Pipe(flaggedQuery).AllowDiskUse().Iter()
+	for iter.Next(&flagged) {
+		err = beaconColl.Update(flagged.ID, bson.M{"$set": bson.M{"bl_fingerprints": flagged.BLFingerprints}})
+		// only some of the flagged connections are beacons
+		if err != nil && err != mgo.ErrNotFound {
+			iter.Close()
+			return err
+		}
+	}
+	return iter.Close()
+}
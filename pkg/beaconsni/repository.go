@@ -1,6 +1,7 @@
 package beaconsni
 
 import (
+	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/pkg/sniconn"
@@ -36,6 +37,8 @@ type Result struct {
 	HistScore              float64 `bson:"hist_score"`
 	Score                  float64 `bson:"score"`
 	// ResolvedIPs            []data.UniqueIP // Requires lookup on SNIconn collection
+	BLFingerprints []blacklist.FingerprintMatch `bson:"bl_fingerprints"`     // blacklisted JA3, JA3S, and certificate fingerprints
+	Suppressed     bool                         `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
 
 // TSData ...
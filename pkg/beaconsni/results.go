@@ -1,23 +1,60 @@
 package beaconsni
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
-// Results finds SNI beacons in the database greater than a given cutoffScore
-func Results(res *resources.Resources, cutoffScore float64) ([]Result, error) {
+// Results finds SNI beacons in the database greater than a given cutoffScore. Results
+// involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, cutoffScore float64, showSuppressed bool) ([]Result, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var beaconsSNI []Result
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconSNI.BeaconSNITable).Find(resultsQuery(cutoffScore)).Sort("-score").Iter()
+	return collectResults(res, iter, showSuppressed)
+}
+
+// ResultsPage returns a page of the SNI beacons greater than a given cutoffScore along with
+// the total number of them. The first skip results are skipped and at most limit results
+// are returned. Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, cutoffScore float64, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
 
-	beaconSNIQuery := bson.M{"score": bson.M{"$gt": cutoffScore}}
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconSNI.BeaconSNITable)
+	iter, total, err := database.FindPage(collection, resultsQuery(cutoffScore), "-score", skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	beaconsSNI, err := collectResults(res, iter, true)
+	return beaconsSNI, total, err
+}
+
+// resultsQuery selects the SNI beacons greater than a given cutoffScore
+func resultsQuery(cutoffScore float64) bson.M {
+	return bson.M{"score": bson.M{"$gt": cutoffScore}}
+}
+
+// collectResults reads the SNI beacons from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, showSuppressed bool) ([]Result, error) {
+	beaconsSNI, err := allowlist.Collect(res, iter, 0, true, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP) || allowed.ContainsDomain(r.FQDN)
+	})
+	if err != nil {
+		return beaconsSNI, err
+	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.BeaconSNI.BeaconSNITable).Find(beaconSNIQuery).Sort("-score").All(&beaconsSNI)
+	inventory, err := asset.Load(res)
 	if err != nil {
 		return beaconsSNI, err
 	}
+	inventory.Label(beaconsSNI)
 
-	return beaconsSNI, err
+	return beaconsSNI, nil
 }
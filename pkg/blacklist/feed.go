@@ -0,0 +1,439 @@
+package blacklist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-bl/list"
+)
+
+// feedIndicator is a single IP address or hostname read from a threat intel feed
+type feedIndicator struct {
+	Value      string
+	Type       list.BlacklistedEntryType
+	Source     string    // organization or list the feed credits for the indicator
+	Tags       []string  // free form labels such as malware families
+	Confidence int       // 0-100, 0 if the feed doesn't say
+	Expiry     time.Time // zero if the indicator doesn't expire
+}
+
+// feedList is a rita-bl list backed by a local STIX 2.1 bundle, MISP JSON export,
+// or CSV file. Unlike line separated lists, every entry carries the feed name,
+// source, tags, and confidence so blacklist hits can be traced back to them.
+type feedList struct {
+	meta list.Metadata
+	path string
+	now  func() time.Time
+}
+
+// newFeedList returns a list which reads the threat intel feed at path
+func newFeedList(path string) list.List {
+	return &feedList{
+		meta: list.Metadata{
+			Types:     []list.BlacklistedEntryType{list.BlacklistedIPType, list.BlacklistedHostnameType},
+			Name:      path,
+			CacheTime: 0, // Always reload the data
+		},
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// GetMetadata returns the Metadata associated with this list
+func (f *feedList) GetMetadata() list.Metadata {
+	return f.meta
+}
+
+// SetMetadata sets the Metadata associated with this list
+func (f *feedList) SetMetadata(meta list.Metadata) {
+	f.meta = meta
+}
+
+// FetchData reads the feed and sends its unexpired indicators to the channels in entryMap
+func (f *feedList) FetchData(entryMap list.BlacklistedEntryMap, errorsOut chan<- error) {
+	for _, entryType := range f.meta.Types {
+		defer close(entryMap[entryType])
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		errorsOut <- err
+		return
+	}
+	defer file.Close()
+
+	indicators, err := parseFeed(f.path, file)
+	if err != nil {
+		errorsOut <- errors.New(f.path + ": " + err.Error())
+		return
+	}
+
+	feedName := filepath.Base(f.path)
+	now := f.now()
+	for _, indicator := range indicators {
+		if !indicator.Expiry.IsZero() && indicator.Expiry.Before(now) {
+			continue
+		}
+		entry := list.NewBlacklistedEntry(indicator.Value, f)
+		entry.ExtraData["feed"] = feedName
+		entry.ExtraData["source"] = indicator.Source
+		entry.ExtraData["tags"] = indicator.Tags
+		entry.ExtraData["confidence"] = indicator.Confidence
+		entryMap[indicator.Type] <- entry
+	}
+}
+
+// parseFeed reads the indicators from a feed. CSV feeds are recognized by their
+// extension. JSON feeds are read as STIX bundles if they say so and as MISP
+// exports otherwise. Hostnames are lowercased to match the hostnames Zeek logs.
+func parseFeed(path string, r io.Reader) ([]feedIndicator, error) {
+	var indicators []feedIndicator
+	var err error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		indicators, err = parseCSVFeed(r)
+	case ".json":
+		raw, readErr := io.ReadAll(r)
+		if readErr != nil {
+			return nil, readErr
+		}
+		var probe struct {
+			Type string `json:"type"`
+		}
+		// MISP exports may be arrays, which fail to decode into the probe
+		if json.Unmarshal(raw, &probe) == nil && probe.Type == "bundle" {
+			indicators, err = parseSTIXFeed(raw)
+		} else {
+			indicators, err = parseMISPFeed(raw)
+		}
+	default:
+		return nil, errors.New("unsupported feed format, expected a .csv or .json file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range indicators {
+		if indicators[idx].Type == list.BlacklistedHostnameType {
+			indicators[idx].Value = strings.ToLower(indicators[idx].Value)
+		}
+	}
+	return indicators, nil
+}
+
+// parseCSVFeed reads a CSV feed with the columns indicator, type, source,
+// confidence, and expiry. Only the indicator column is required. A header
+// row is skipped if present.
+func parseCSVFeed(r io.Reader) ([]feedIndicator, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	var indicators []feedIndicator
+	for first := true; ; first = false {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		get := func(idx int) string {
+			if idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		if first && strings.EqualFold(get(0), "indicator") {
+			continue
+		}
+		if get(0) == "" {
+			continue
+		}
+
+		entryType, ok := indicatorType(get(1), get(0))
+		if !ok {
+			continue // feeds commonly mix in URLs and file hashes which RITA can't match
+		}
+
+		indicator := feedIndicator{
+			Value:  get(0),
+			Type:   entryType,
+			Source: get(2),
+		}
+		if conf := get(3); conf != "" {
+			indicator.Confidence, err = parseConfidence(conf)
+			if err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+			}
+		}
+		if expiry := get(4); expiry != "" {
+			indicator.Expiry, err = parseExpiry(expiry)
+			if err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+			}
+		}
+		indicators = append(indicators, indicator)
+	}
+	return indicators, nil
+}
+
+// stixObject holds the fields of STIX 2.1 objects needed to read indicators
+type stixObject struct {
+	Type               string   `json:"type"`
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	Pattern            string   `json:"pattern"`
+	PatternType        string   `json:"pattern_type"`
+	ValidUntil         string   `json:"valid_until"`
+	Revoked            bool     `json:"revoked"`
+	Confidence         int      `json:"confidence"`
+	Labels             []string `json:"labels"`
+	IndicatorTypes     []string `json:"indicator_types"`
+	CreatedByRef       string   `json:"created_by_ref"`
+	ExternalReferences []struct {
+		SourceName string `json:"source_name"`
+	} `json:"external_references"`
+}
+
+// stixComparison matches the comparisons in a STIX pattern which RITA can check
+var stixComparison = regexp.MustCompile(`(ipv4-addr|ipv6-addr|domain-name):value\s*=\s*'((?:[^'\\]|\\.)*)'`)
+
+// parseSTIXFeed reads the indicators from a STIX 2.1 bundle. Every IP address
+// and domain name compared against in an indicator's pattern is read, so
+// patterns joined with OR produce several indicators. Revoked indicators and
+// patterns in other languages are skipped. The source of each indicator is the
+// identity which created it.
+func parseSTIXFeed(raw []byte) ([]feedIndicator, error) {
+	var bundle struct {
+		Objects []stixObject `json:"objects"`
+	}
+	if err := json.Unmarshal(raw, &bundle); err != nil {
+		return nil, err
+	}
+
+	identities := make(map[string]string)
+	for _, obj := range bundle.Objects {
+		if obj.Type == "identity" {
+			identities[obj.ID] = obj.Name
+		}
+	}
+
+	unescape := strings.NewReplacer(`\'`, `'`, `\\`, `\`)
+
+	var indicators []feedIndicator
+	for _, obj := range bundle.Objects {
+		if obj.Type != "indicator" || obj.Revoked || (obj.PatternType != "" && obj.PatternType != "stix") {
+			continue
+		}
+
+		source := identities[obj.CreatedByRef]
+		if source == "" && len(obj.ExternalReferences) > 0 {
+			source = obj.ExternalReferences[0].SourceName
+		}
+
+		var expiry time.Time
+		if obj.ValidUntil != "" {
+			var err error
+			expiry, err = time.Parse(time.RFC3339, obj.ValidUntil)
+			if err != nil {
+				return nil, errors.New(obj.ID + ": invalid valid_until timestamp")
+			}
+		}
+
+		tags := append(append([]string{}, obj.IndicatorTypes...), obj.Labels...)
+
+		for _, match := range stixComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+			entryType := list.BlacklistedHostnameType
+			value := unescape.Replace(match[2])
+			if match[1] != "domain-name" {
+				entryType = list.BlacklistedIPType
+				value = strings.TrimSuffix(strings.TrimSuffix(value, "/32"), "/128")
+				if net.ParseIP(value) == nil {
+					continue // CIDR ranges can't be matched by rita-bl
+				}
+			}
+			indicators = append(indicators, feedIndicator{
+				Value:      value,
+				Type:       entryType,
+				Source:     source,
+				Tags:       tags,
+				Confidence: obj.Confidence,
+				Expiry:     expiry,
+			})
+		}
+	}
+	return indicators, nil
+}
+
+// mispTag is a tag attached to a MISP event or attribute
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+// mispAttribute is a single value recorded in a MISP event
+type mispAttribute struct {
+	Type  string    `json:"type"`
+	Value string    `json:"value"`
+	ToIDS bool      `json:"to_ids"`
+	Tag   []mispTag `json:"Tag"`
+}
+
+// mispEvent holds the fields of a MISP event needed to read indicators
+type mispEvent struct {
+	Info          string `json:"info"`
+	ThreatLevelID string `json:"threat_level_id"`
+	Orgc          struct {
+		Name string `json:"name"`
+	} `json:"Orgc"`
+	Tag       []mispTag       `json:"Tag"`
+	Attribute []mispAttribute `json:"Attribute"`
+	Object    []struct {
+		Attribute []mispAttribute `json:"Attribute"`
+	} `json:"Object"`
+}
+
+// mispConfidence maps MISP threat levels onto the 0-100 confidence scale
+// since MISP events don't record a confidence of their own
+var mispConfidence = map[string]int{
+	"1": 90, // high
+	"2": 60, // medium
+	"3": 30, // low
+}
+
+// parseMISPFeed reads the indicators from a MISP JSON export. A single event,
+// an array of events, and the response of the MISP REST API are accepted.
+// Only attributes flagged for IDS use are read. The source of each indicator
+// is the organization which created the event.
+func parseMISPFeed(raw []byte) ([]feedIndicator, error) {
+	type wrapper struct {
+		Event mispEvent `json:"Event"`
+	}
+
+	var events []mispEvent
+	var single wrapper
+	var multiple []wrapper
+	var response struct {
+		Response []wrapper `json:"response"`
+	}
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		for _, w := range multiple {
+			events = append(events, w.Event)
+		}
+	} else if err := json.Unmarshal(raw, &response); err == nil && len(response.Response) > 0 {
+		for _, w := range response.Response {
+			events = append(events, w.Event)
+		}
+	} else if err := json.Unmarshal(raw, &single); err == nil {
+		events = append(events, single.Event)
+	} else {
+		return nil, err
+	}
+
+	var indicators []feedIndicator
+	for _, event := range events {
+		source := event.Orgc.Name
+		if source == "" {
+			source = event.Info
+		}
+
+		attributes := event.Attribute
+		for _, obj := range event.Object {
+			attributes = append(attributes, obj.Attribute...)
+		}
+
+		for _, attr := range attributes {
+			if !attr.ToIDS {
+				continue
+			}
+
+			var tags []string
+			for _, tag := range append(append([]mispTag{}, event.Tag...), attr.Tag...) {
+				tags = append(tags, tag.Name)
+			}
+
+			for _, value := range mispValues(attr) {
+				entryType, ok := indicatorType("", value)
+				if !ok {
+					continue
+				}
+				indicators = append(indicators, feedIndicator{
+					Value:      value,
+					Type:       entryType,
+					Source:     source,
+					Tags:       tags,
+					Confidence: mispConfidence[event.ThreatLevelID],
+				})
+			}
+		}
+	}
+	return indicators, nil
+}
+
+// mispValues returns the IP addresses and hostnames held by a MISP attribute
+func mispValues(attr mispAttribute) []string {
+	switch attr.Type {
+	case "ip-src", "ip-dst", "domain", "hostname":
+		return []string{attr.Value}
+	case "ip-src|port", "ip-dst|port", "hostname|port":
+		return []string{strings.SplitN(attr.Value, "|", 2)[0]}
+	case "domain|ip":
+		return strings.SplitN(attr.Value, "|", 2)
+	}
+	return nil
+}
+
+// indicatorType maps the type named by a feed onto a rita-bl entry type. If the
+// feed doesn't name a type, it is inferred from the value. ok is false for
+// indicators RITA can't match such as URLs and file hashes.
+func indicatorType(name, value string) (entryType list.BlacklistedEntryType, ok bool) {
+	switch strings.ToLower(name) {
+	case "ip", "ipv4", "ipv6", "ip-src", "ip-dst", "ipv4-addr", "ipv6-addr":
+		return list.BlacklistedIPType, true
+	case "domain", "hostname", "fqdn", "domain-name":
+		return list.BlacklistedHostnameType, true
+	case "":
+		if net.ParseIP(value) != nil {
+			return list.BlacklistedIPType, true
+		}
+		if strings.ContainsAny(value, "/:") {
+			return "", false
+		}
+		return list.BlacklistedHostnameType, true
+	}
+	return "", false
+}
+
+// parseConfidence reads a 0-100 confidence
+func parseConfidence(value string) (int, error) {
+	conf, err := strconv.Atoi(value)
+	if err != nil || conf < 0 || conf > 100 {
+		return 0, errors.New("confidence must be an integer between 0 and 100")
+	}
+	return conf, nil
+}
+
+// parseExpiry reads an RFC 3339 timestamp or a date
+func parseExpiry(value string) (time.Time, error) {
+	if expiry, err := time.Parse(time.RFC3339, value); err == nil {
+		return expiry, nil
+	}
+	if expiry, err := time.Parse("2006-01-02", value); err == nil {
+		return expiry, nil
+	}
+	return time.Time{}, errors.New("expiry must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
@@ -0,0 +1,196 @@
+package blacklist
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	ritaBLdb "github.com/activecm/rita-bl/database"
+	"github.com/activecm/rita-bl/list"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSVFeed(t *testing.T) {
+	feed := `indicator,type,source,confidence,expiry
+1.2.3.4,ip,abuse.ch,80,2030-01-01
+# comment
+evil.com,domain,,,2021-06-01T00:00:00Z
+http://evil.com/payload,url,abuse.ch,90,
+5.6.7.8
+`
+	indicators, err := parseFeed("feed.csv", strings.NewReader(feed))
+	require.NoError(t, err)
+	require.Equal(t, []feedIndicator{
+		{
+			Value:      "1.2.3.4",
+			Type:       list.BlacklistedIPType,
+			Source:     "abuse.ch",
+			Confidence: 80,
+			Expiry:     time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Value:  "evil.com",
+			Type:   list.BlacklistedHostnameType,
+			Expiry: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{Value: "5.6.7.8", Type: list.BlacklistedIPType},
+	}, indicators)
+
+	_, err = parseFeed("feed.csv", strings.NewReader("1.2.3.4,ip,abuse.ch,high\n"))
+	require.EqualError(t, err, "line 1: confidence must be an integer between 0 and 100")
+}
+
+func TestParseSTIXFeed(t *testing.T) {
+	feed := `{
+  "type": "bundle",
+  "id": "bundle--1",
+  "objects": [
+    {"type": "identity", "id": "identity--1", "name": "ACME CERT"},
+    {
+      "type": "indicator",
+      "id": "indicator--1",
+      "created_by_ref": "identity--1",
+      "pattern": "[ipv4-addr:value = '1.2.3.4/32'] OR [domain-name:value = 'evil.com'] OR [ipv4-addr:value = '10.0.0.0/8']",
+      "pattern_type": "stix",
+      "indicator_types": ["malicious-activity"],
+      "labels": ["emotet"],
+      "confidence": 75,
+      "valid_until": "2030-01-01T00:00:00Z"
+    },
+    {
+      "type": "indicator",
+      "id": "indicator--2",
+      "pattern": "[ipv4-addr:value = '5.6.7.8']",
+      "pattern_type": "stix",
+      "revoked": true
+    },
+    {
+      "type": "indicator",
+      "id": "indicator--3",
+      "pattern": "alert ip 5.6.7.8 any -> any any",
+      "pattern_type": "snort"
+    }
+  ]
+}`
+	indicators, err := parseFeed("bundle.json", strings.NewReader(feed))
+	require.NoError(t, err)
+
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []string{"malicious-activity", "emotet"}
+	require.Equal(t, []feedIndicator{
+		{Value: "1.2.3.4", Type: list.BlacklistedIPType, Source: "ACME CERT", Tags: tags, Confidence: 75, Expiry: expiry},
+		{Value: "evil.com", Type: list.BlacklistedHostnameType, Source: "ACME CERT", Tags: tags, Confidence: 75, Expiry: expiry},
+	}, indicators)
+}
+
+func TestParseMISPFeed(t *testing.T) {
+	event := `{"Event": {
+  "info": "Phishing campaign",
+  "threat_level_id": "2",
+  "Orgc": {"name": "CIRCL"},
+  "Tag": [{"name": "tlp:green"}],
+  "Attribute": [
+    {"type": "ip-dst|port", "value": "1.2.3.4|443", "to_ids": true, "Tag": [{"name": "c2"}]},
+    {"type": "domain", "value": "benign.com", "to_ids": false},
+    {"type": "md5", "value": "d41d8cd98f00b204e9800998ecf8427e", "to_ids": true}
+  ],
+  "Object": [
+    {"Attribute": [{"type": "domain|ip", "value": "evil.com|5.6.7.8", "to_ids": true}]}
+  ]
+}}`
+	expected := []feedIndicator{
+		{Value: "1.2.3.4", Type: list.BlacklistedIPType, Source: "CIRCL", Tags: []string{"tlp:green", "c2"}, Confidence: 60},
+		{Value: "evil.com", Type: list.BlacklistedHostnameType, Source: "CIRCL", Tags: []string{"tlp:green"}, Confidence: 60},
+		{Value: "5.6.7.8", Type: list.BlacklistedIPType, Source: "CIRCL", Tags: []string{"tlp:green"}, Confidence: 60},
+	}
+
+	indicators, err := parseFeed("event.json", strings.NewReader(event))
+	require.NoError(t, err)
+	require.Equal(t, expected, indicators)
+
+	indicators, err = parseFeed("events.json", strings.NewReader("["+event+"]"))
+	require.NoError(t, err)
+	require.Equal(t, expected, indicators)
+
+	indicators, err = parseFeed("response.json", strings.NewReader(`{"response": [`+event+`]}`))
+	require.NoError(t, err)
+	require.Equal(t, expected, indicators)
+}
+
+func TestParseFeedLowercasesHostnames(t *testing.T) {
+	feed := "Evil.COM,domain\nC2.Example.org\n2001:DB8::1,ipv6\n"
+	indicators, err := parseFeed("feed.csv", strings.NewReader(feed))
+	require.NoError(t, err)
+	require.Equal(t, []feedIndicator{
+		{Value: "evil.com", Type: list.BlacklistedHostnameType},
+		{Value: "c2.example.org", Type: list.BlacklistedHostnameType},
+		{Value: "2001:DB8::1", Type: list.BlacklistedIPType},
+	}, indicators)
+
+	bundle := `{"type": "bundle", "objects": [{"type": "indicator", "id": "indicator--1",
+		"pattern": "[domain-name:value = 'Mixed.Case.Example']", "pattern_type": "stix"}]}`
+	indicators, err = parseFeed("bundle.json", strings.NewReader(bundle))
+	require.NoError(t, err)
+	require.Len(t, indicators, 1)
+	require.Equal(t, "mixed.case.example", indicators[0].Value)
+}
+
+func TestParseFeedUnsupported(t *testing.T) {
+	_, err := parseFeed("feed.txt", strings.NewReader("1.2.3.4\n"))
+	require.Error(t, err)
+}
+
+func TestFeedListSkipsExpired(t *testing.T) {
+	path := t.TempDir() + "/feed.csv"
+	require.NoError(t, os.WriteFile(path, []byte("1.2.3.4,ip,,,2020-01-01\nevil.com,domain,abuse.ch,50,2030-01-01\n"), 0644))
+
+	feed := newFeedList(path).(*feedList)
+	feed.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	entryMap := list.NewBlacklistedEntryMap(feed.GetMetadata().Types...)
+	errs := make(chan error, 1)
+	go feed.FetchData(entryMap, errs)
+
+	var ips, hostnames []list.BlacklistedEntry
+	done := make(chan struct{})
+	go func() {
+		for entry := range entryMap[list.BlacklistedIPType] {
+			ips = append(ips, entry)
+		}
+		close(done)
+	}()
+	for entry := range entryMap[list.BlacklistedHostnameType] {
+		hostnames = append(hostnames, entry)
+	}
+	<-done
+
+	require.Empty(t, ips)
+	require.Len(t, hostnames, 1)
+	require.Equal(t, "evil.com", hostnames[0].Index)
+	require.Equal(t, "feed.csv", hostnames[0].ExtraData["feed"])
+	require.Equal(t, "abuse.ch", hostnames[0].ExtraData["source"])
+	require.Equal(t, 50, hostnames[0].ExtraData["confidence"])
+	require.Empty(t, errs)
+}
+
+func TestSourceFromEntry(t *testing.T) {
+	// entries from line separated lists carry no extra data
+	source := sourceFromEntry(ritaBLdb.BlacklistResult{List: "feodo tracker", ExtraData: map[string]interface{}{}})
+	require.Equal(t, Source{Feed: "feodo tracker"}, source)
+	require.Equal(t, "feodo tracker", source.String())
+
+	// values read back from MongoDB
+	source = sourceFromEntry(ritaBLdb.BlacklistResult{
+		List: "/opt/feeds/feed.csv",
+		ExtraData: map[string]interface{}{
+			"feed":       "feed.csv",
+			"source":     "abuse.ch",
+			"confidence": 80,
+			"tags":       []interface{}{"emotet", "c2"},
+		},
+	})
+	require.Equal(t, Source{Feed: "feed.csv", Source: "abuse.ch", Confidence: 80, Tags: []string{"emotet", "c2"}}, source)
+	require.Equal(t, "feed.csv/abuse.ch conf=80 tags=emotet|c2", source.String())
+	require.Equal(t, "feed.csv/abuse.ch conf=80 tags=emotet|c2; feodo tracker", FormatSources([]Source{source, {Feed: "feodo tracker"}}))
+}
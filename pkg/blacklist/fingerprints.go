@@ -0,0 +1,192 @@
+package blacklist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/activecm/rita-legacy/config"
+)
+
+// Types of TLS fingerprints which may be blacklisted
+const (
+	JA3Fingerprint      = "ja3"       // MD5 hash of the client hello
+	JA3SFingerprint     = "ja3s"      // MD5 hash of the server hello
+	CertSHA1Fingerprint = "cert_sha1" // SHA1 fingerprint of the server's leaf certificate
+)
+
+// FingerprintMatch records a TLS fingerprint which appears on a fingerprint blacklist
+type FingerprintMatch struct {
+	Type   string `bson:"type"`   // one of JA3Fingerprint, JA3SFingerprint, or CertSHA1Fingerprint
+	Hash   string `bson:"hash"`   // lowercase hex encoded fingerprint
+	List   string `bson:"list"`   // file path or url of the list containing the fingerprint
+	Reason string `bson:"reason"` // listing reason given by the list, if any
+}
+
+// String formats the match without commas so it fits in a CSV column
+func (m FingerprintMatch) String() string {
+	out := m.Type + ":" + m.Hash
+	if m.Reason != "" {
+		out += " (" + strings.ReplaceAll(m.Reason, ",", " ") + ")"
+	}
+	return out
+}
+
+// Fingerprints holds the entries of the JA3, JA3S, and certificate SHA1 blacklists
+type Fingerprints struct {
+	entries map[string]map[string]FingerprintMatch // fingerprint type -> hash -> entry
+}
+
+// LoadFingerprints reads the fingerprint blacklists named in the configuration.
+// Lists which can't be read are reported in the returned error while the
+// entries of the remaining lists are still returned.
+func LoadFingerprints(conf *config.Config) (*Fingerprints, error) {
+	fingerprints := &Fingerprints{entries: make(map[string]map[string]FingerprintMatch)}
+	if !conf.S.Blacklisted.Enabled {
+		return fingerprints, nil
+	}
+
+	var failed []string
+	lists := []struct {
+		fpType string
+		paths  []string
+	}{
+		{JA3Fingerprint, conf.S.Blacklisted.JA3Blacklists},
+		{JA3SFingerprint, conf.S.Blacklisted.JA3SBlacklists},
+		{CertSHA1Fingerprint, conf.S.Blacklisted.CertBlacklists},
+	}
+	for _, fpList := range lists {
+		for _, path := range fpList.paths {
+			err := fingerprints.loadList(fpList.fpType, path)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fingerprints, fmt.Errorf("could not read fingerprint blacklists: %s", strings.Join(failed, "; "))
+	}
+	return fingerprints, nil
+}
+
+// loadList reads a single fingerprint blacklist from a file path or url
+func (f *Fingerprints) loadList(fpType string, path string) error {
+	reader, err := tryOpenFileThenURL(path)()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	matches, err := parseFingerprintList(reader, fpType, path)
+	if err != nil {
+		return err
+	}
+	f.add(matches...)
+	return nil
+}
+
+// add records the given blacklist entries. The first list to name a fingerprint wins.
+func (f *Fingerprints) add(matches ...FingerprintMatch) {
+	for _, match := range matches {
+		if _, ok := f.entries[match.Type]; !ok {
+			f.entries[match.Type] = make(map[string]FingerprintMatch)
+		}
+		if _, ok := f.entries[match.Type][match.Hash]; !ok {
+			f.entries[match.Type][match.Hash] = match
+		}
+	}
+}
+
+// Len returns the number of blacklisted fingerprints
+func (f *Fingerprints) Len() int {
+	total := 0
+	for _, hashes := range f.entries {
+		total += len(hashes)
+	}
+	return total
+}
+
+// Match returns the blacklist entries for the given client JA3 hashes,
+// server JA3S hashes, and leaf certificate fingerprints
+func (f *Fingerprints) Match(ja3s []string, ja3ss []string, certFingerprints []string) []FingerprintMatch {
+	matches := []FingerprintMatch{}
+	if f.Len() == 0 {
+		return matches
+	}
+
+	candidates := []struct {
+		fpType string
+		hashes []string
+	}{
+		{JA3Fingerprint, ja3s},
+		{JA3SFingerprint, ja3ss},
+		{CertSHA1Fingerprint, certFingerprints},
+	}
+	for _, candidate := range candidates {
+		for _, hash := range candidate.hashes {
+			if match, ok := f.entries[candidate.fpType][normalizeFingerprint(hash)]; ok {
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// parseFingerprintList reads a line separated or CSV list of fingerprints of type fpType.
+// The fingerprint is taken from the first column holding a hex string of the right
+// length, so lists such as the abuse.ch SSLBL exports may be used as is. If the
+// line has other columns, the last one is kept as the listing reason.
+// Comments, headers, and lines without a fingerprint are skipped.
+func parseFingerprintList(r io.Reader, fpType string, listName string) ([]FingerprintMatch, error) {
+	hashLength := 32 // JA3 and JA3S are MD5 hashes
+	if fpType == CertSHA1Fingerprint {
+		hashLength = 40
+	}
+
+	var matches []FingerprintMatch
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		for idx, field := range fields {
+			hash := normalizeFingerprint(field)
+			if !isHex(hash, hashLength) {
+				continue
+			}
+
+			match := FingerprintMatch{Type: fpType, Hash: hash, List: listName}
+			if last := len(fields) - 1; last != idx {
+				match.Reason = strings.Trim(strings.TrimSpace(fields[last]), `"`)
+			}
+			matches = append(matches, match)
+			break
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// normalizeFingerprint lowercases a hex fingerprint and strips the colons
+// and quotes some tools include
+func normalizeFingerprint(value string) string {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	return strings.ToLower(strings.ReplaceAll(value, ":", ""))
+}
+
+// isHex returns true if value is a hex string of the given length
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, char := range value {
+		if !strings.ContainsRune("0123456789abcdef", char) {
+			return false
+		}
+	}
+	return true
+}
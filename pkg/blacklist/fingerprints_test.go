@@ -0,0 +1,75 @@
+package blacklist
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFingerprintList(t *testing.T) {
+	// abuse.ch SSLBL JA3 export
+	ja3List := `# ja3_md5,Firstseen,Lastseen,Listingreason
+B386946A5A44D1DDCC843BC75336DFCE,2017-07-14 18:08:15,2019-07-27 20:42:54,Dridex
+e7d705a3286e19ea42f587b344ee6865
+not a fingerprint
+`
+	matches, err := parseFingerprintList(strings.NewReader(ja3List), JA3Fingerprint, "ja3.csv")
+	require.NoError(t, err)
+	require.Equal(t, []FingerprintMatch{
+		{Type: JA3Fingerprint, Hash: "b386946a5a44d1ddcc843bc75336dfce", List: "ja3.csv", Reason: "Dridex"},
+		{Type: JA3Fingerprint, Hash: "e7d705a3286e19ea42f587b344ee6865", List: "ja3.csv"},
+	}, matches)
+
+	// abuse.ch SSLBL certificate export and colon separated fingerprints
+	certList := `# Listingdate,SHA1,Listingreason
+2021-01-01 00:00:00,0b6ab1e8d2b3b9b3a3c7f28a0f4e1e0c2c1d3f4a,"AsyncRAT C&C"
+0B:6A:B1:E8:D2:B3:B9:B3:A3:C7:F2:8A:0F:4E:1E:0C:2C:1D:3F:4B
+b386946a5a44d1ddcc843bc75336dfce
+`
+	matches, err = parseFingerprintList(strings.NewReader(certList), CertSHA1Fingerprint, "sslbl.csv")
+	require.NoError(t, err)
+	require.Equal(t, []FingerprintMatch{
+		{Type: CertSHA1Fingerprint, Hash: "0b6ab1e8d2b3b9b3a3c7f28a0f4e1e0c2c1d3f4a", List: "sslbl.csv", Reason: "AsyncRAT C&C"},
+		{Type: CertSHA1Fingerprint, Hash: "0b6ab1e8d2b3b9b3a3c7f28a0f4e1e0c2c1d3f4b", List: "sslbl.csv"},
+	}, matches)
+}
+
+func TestFingerprintsMatch(t *testing.T) {
+	dir := t.TempDir()
+	ja3Path := dir + "/ja3.txt"
+	ja3sPath := dir + "/ja3s.txt"
+	require.NoError(t, os.WriteFile(ja3Path, []byte("b386946a5a44d1ddcc843bc75336dfce,Dridex\n"), 0644))
+	require.NoError(t, os.WriteFile(ja3sPath, []byte("e7d705a3286e19ea42f587b344ee6865\n"), 0644))
+
+	conf := &config.Config{}
+	conf.S.Blacklisted.Enabled = true
+	conf.S.Blacklisted.JA3Blacklists = []string{ja3Path}
+	conf.S.Blacklisted.JA3SBlacklists = []string{ja3sPath, dir + "/missing.txt"}
+
+	fingerprints, err := LoadFingerprints(conf)
+	require.Error(t, err)
+	require.Equal(t, 2, fingerprints.Len())
+
+	// a JA3S hash is not matched against the JA3 lists
+	matches := fingerprints.Match(
+		[]string{"B386946A5A44D1DDCC843BC75336DFCE", "e7d705a3286e19ea42f587b344ee6865"},
+		[]string{"e7d705a3286e19ea42f587b344ee6865"},
+		nil,
+	)
+	require.Equal(t, []FingerprintMatch{
+		{Type: JA3Fingerprint, Hash: "b386946a5a44d1ddcc843bc75336dfce", List: ja3Path, Reason: "Dridex"},
+		{Type: JA3SFingerprint, Hash: "e7d705a3286e19ea42f587b344ee6865", List: ja3sPath},
+	}, matches)
+
+	require.Equal(t, "ja3:b386946a5a44d1ddcc843bc75336dfce (Dridex)", matches[0].String())
+	require.Empty(t, fingerprints.Match([]string{"00000000000000000000000000000000"}, nil, nil))
+
+	// disabling the blacklist module disables the fingerprint lists
+	conf.S.Blacklisted.Enabled = false
+	fingerprints, err = LoadFingerprints(conf)
+	require.NoError(t, err)
+	require.Zero(t, fingerprints.Len())
+}
@@ -2,6 +2,7 @@ package blacklist
 
 import (
 	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 )
 
 // Repository for blacklist results in host collection
@@ -25,6 +26,9 @@ type IPResult struct {
 	UniqueConnections int             `bson:"uconn_count"`
 	TotalBytes        int             `bson:"total_bytes"`
 	Peers             []data.UniqueIP `bson:"peers"`
+	Geo               geoip.Info      `bson:"geo"`                 // country and ASN of the blacklisted IP, if GeoIP is enabled
+	Sources           []Source        `bson:"bl_sources"`          // threat intel lists and feeds which flagged the IP
+	Suppressed        bool            `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
 
 // HostnameResult represents a blacklisted hostname and summary
@@ -35,4 +39,17 @@ type HostnameResult struct {
 	UniqueConnections int             `bson:"uconn_count"`
 	TotalBytes        int             `bson:"total_bytes"`
 	ConnectedHosts    []data.UniqueIP `bson:"sources,omitempty"`
+	Sources           []Source        `bson:"bl_sources"`          // threat intel lists and feeds which flagged the hostname
+	Suppressed        bool            `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
+
+// FingerprintResult represents a blacklisted JA3, JA3S, or certificate fingerprint
+// and summary data about the TLS connections which presented it
+type FingerprintResult struct {
+	FingerprintMatch `bson:",inline"`
+	Connections      int             `bson:"conn_count"`
+	Hosts            []data.UniqueIP `bson:"sources"` // internal hosts which made the connections
+	FQDNs            []string        `bson:"fqdns"`   // server names the hosts connected to
+	RespondingIPs    []data.UniqueIP `bson:"dst_ips"`
+	Suppressed       bool            `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
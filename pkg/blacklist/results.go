@@ -1,7 +1,11 @@
 package blacklist
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
@@ -9,16 +13,42 @@ import (
 // hosts which connected to the blacklisted hostnames. The results will be sorted in
 // descending order keyed on of {uconn_count, conn_count, total_bytes} depending on the value
 // of sort. limit and noLimit control how many results are returned.
-func HostnameResults(res *resources.Resources, sort string, limit int, noLimit bool) ([]HostnameResult, error) {
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func HostnameResults(res *resources.Resources, sort string, limit int, noLimit bool, showSuppressed bool) ([]HostnameResult, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	blHostsQuery := []bson.M{
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(hostnameResultsQuery(sort)).AllowDiskUse().Iter()
+	return collectHostnameResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// HostnameResultsPage returns a page of the blacklisted hostnames sorted in descending order
+// keyed on sort along with the total number of them. The first skip results are skipped and
+// at most limit results are returned. Results involving allowlisted values are flagged rather
+// than dropped.
+func HostnameResultsPage(res *resources.Resources, sort string, skip, limit int) ([]HostnameResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable)
+	iter, total, err := database.PipePage(collection, hostnameResultsQuery(sort), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	blHosts, err := collectHostnameResults(res, iter, 0, true, true)
+	return blHosts, total, err
+}
+
+// hostnameResultsQuery sums the connections from internal hosts to each blacklisted hostname,
+// sorted in descending order keyed on sort
+func hostnameResultsQuery(sort string) []bson.M {
+	return []bson.M{
 		// find blacklisted hostnames and the IPs associated with them
 		{"$match": bson.M{"blacklisted": true}},
 		{"$project": bson.M{
-			"host":    1,
-			"dat.ips": 1,
+			"host":       1,
+			"dat.ips":    1,
+			"bl_sources": 1,
 		}},
 		// aggregate over time/ chunks
 		{"$unwind": "$dat"},
@@ -28,8 +58,9 @@ func HostnameResults(res *resources.Resources, sort string, limit int, noLimit b
 		// network_uuid and we don't need to display it
 		{"$project": bson.M{"dat.ips.network_name": 0}},
 		{"$group": bson.M{
-			"_id": "$host",
-			"ips": bson.M{"$addToSet": "$dat.ips"},
+			"_id":        "$host",
+			"ips":        bson.M{"$addToSet": "$dat.ips"},
+			"bl_sources": bson.M{"$first": "$bl_sources"},
 		}},
 		{"$unwind": "$ips"},
 		// find out which IPs connected to each hostname via uconn
@@ -53,6 +84,7 @@ func HostnameResults(res *resources.Resources, sort string, limit int, noLimit b
 			"src_network_name": "$uconn.src_network_name",
 			"conns":            "$uconn.dat.count",
 			"tbytes":           "$uconn.dat.tbytes",
+			"bl_sources":       1,
 		}},
 		// remove duplicate source for each host and sum bytes
 		// and connections per blacklisted hostname.
@@ -67,12 +99,14 @@ func HostnameResults(res *resources.Resources, sort string, limit int, noLimit b
 			"src_network_name": bson.M{"$last": "$src_network_name"},
 			"conns":            bson.M{"$sum": "$conns"},
 			"tbytes":           bson.M{"$sum": "$tbytes"},
+			"bl_sources":       bson.M{"$first": "$bl_sources"},
 		}},
 		{"$project": bson.M{
-			"_id":    0,
-			"host":   "$_id.host",
-			"conns":  1,
-			"tbytes": 1,
+			"_id":        0,
+			"host":       "$_id.host",
+			"conns":      1,
+			"tbytes":     1,
+			"bl_sources": 1,
 			"src": bson.M{
 				"ip":           "$_id.src_ip",
 				"network_uuid": "$_id.src_network_uuid",
@@ -81,10 +115,11 @@ func HostnameResults(res *resources.Resources, sort string, limit int, noLimit b
 		}},
 
 		{"$group": bson.M{
-			"_id":     "$host",
-			"conns":   bson.M{"$sum": "$conns"},
-			"tbytes":  bson.M{"$sum": "$tbytes"},
-			"sources": bson.M{"$addToSet": "$src"},
+			"_id":        "$host",
+			"conns":      bson.M{"$sum": "$conns"},
+			"tbytes":     bson.M{"$sum": "$tbytes"},
+			"sources":    bson.M{"$addToSet": "$src"},
+			"bl_sources": bson.M{"$first": "$bl_sources"},
 		}},
 		{"$project": bson.M{
 			"_id":         0,
@@ -93,44 +128,124 @@ func HostnameResults(res *resources.Resources, sort string, limit int, noLimit b
 			"conn_count":  "$conns",
 			"total_bytes": "$tbytes",
 			"sources":     1,
+			"bl_sources":  1,
 		}},
 		{"$sort": bson.M{sort: -1}},
 	}
+}
 
-	if !noLimit {
-		blHostsQuery = append(blHostsQuery, bson.M{"$limit": limit})
+// collectHostnameResults reads the blacklisted hostnames from iter and labels the connected hosts
+// with the asset inventory. Results involving allowlisted values are only returned if
+// showSuppressed is set.
+func collectHostnameResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]HostnameResult, error) {
+	blHosts, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *HostnameResult) bool {
+		return allowed.ContainsDomain(r.Host)
+	})
+	if err != nil {
+		return blHosts, err
 	}
 
-	var blHosts []HostnameResult
-
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(blHostsQuery).AllowDiskUse().All(&blHosts)
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return blHosts, err
+	}
+	inventory.Label(blHosts)
 
-	return blHosts, err
+	return blHosts, nil
 }
 
 // SrcIPResults finds blacklisted source IPs in the database and the IPs of the
 // hosts which the blacklisted IP connected to. The results will be sorted in
 // descending order keyed on of {uconn_count, conn_count, total_bytes} depending on the value
 // of sort. limit and noLimit control how many results are returned.
-func SrcIPResults(res *resources.Resources, sort string, limit int, noLimit bool) ([]IPResult, error) {
-	return ipResults(res, sort, limit, noLimit, true)
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func SrcIPResults(res *resources.Resources, sort string, limit int, noLimit bool, showSuppressed bool) ([]IPResult, error) {
+	return ipResults(res, srcIPResultsQuery(sort), limit, noLimit, showSuppressed)
+}
+
+// SrcIPResultsPage returns a page of the blacklisted source IPs sorted in descending order
+// keyed on sort along with the total number of them. The first skip results are skipped and
+// at most limit results are returned. Results involving allowlisted values are flagged rather
+// than dropped.
+func SrcIPResultsPage(res *resources.Resources, sort string, skip, limit int) ([]IPResult, int, error) {
+	return ipResultsPage(res, srcIPResultsQuery(sort), skip, limit)
 }
 
 // DstIPResults finds blacklisted destination IPs in the database and the IPs of the
 // hosts which connected to the blacklisted IP. The results will be sorted in
 // descending order keyed on of {uconn_count, conn_count, total_bytes} depending on the value
 // of sort. limit and noLimit control how many results are returned.
-func DstIPResults(res *resources.Resources, sort string, limit int, noLimit bool) ([]IPResult, error) {
-	return ipResults(res, sort, limit, false, noLimit)
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func DstIPResults(res *resources.Resources, sort string, limit int, noLimit bool, showSuppressed bool) ([]IPResult, error) {
+	return ipResults(res, dstIPResultsQuery(sort), limit, noLimit, showSuppressed)
 }
 
-// ipResults implements SrcIPResults and DstIPResults. Set sourceDestFlag to true
-// to find blacklisted source IPs. Set sourceDestFlag to false to find blacklisted
-// destination IPs.
-func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, sourceDestFlag bool) ([]IPResult, error) {
+// DstIPResultsPage returns a page of the blacklisted destination IPs sorted in descending order
+// keyed on sort along with the total number of them. The first skip results are skipped and
+// at most limit results are returned. Results involving allowlisted values are flagged rather
+// than dropped.
+func DstIPResultsPage(res *resources.Resources, sort string, skip, limit int) ([]IPResult, int, error) {
+	return ipResultsPage(res, dstIPResultsQuery(sort), skip, limit)
+}
+
+// srcIPResultsQuery builds the hosts collection pipeline for SrcIPResults
+func srcIPResultsQuery(sort string) []bson.M {
+	return ipResultsQuery(sort, true)
+}
+
+// dstIPResultsQuery builds the hosts collection pipeline for DstIPResults
+func dstIPResultsQuery(sort string) []bson.M {
+	return ipResultsQuery(sort, false)
+}
+
+// ipResults implements SrcIPResults and DstIPResults by running blIPQuery against
+// the hosts collection
+func ipResults(res *resources.Resources, blIPQuery []bson.M, limit int, noLimit bool, showSuppressed bool) ([]IPResult, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable).Pipe(blIPQuery).AllowDiskUse().Iter()
+	return collectIPResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ipResultsPage implements SrcIPResultsPage and DstIPResultsPage by running blIPQuery
+// against the hosts collection
+func ipResultsPage(res *resources.Resources, blIPQuery []bson.M, skip, limit int) ([]IPResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable)
+	iter, total, err := database.PipePage(collection, blIPQuery, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	blIPs, err := collectIPResults(res, iter, 0, true, true)
+	return blIPs, total, err
+}
+
+// collectIPResults reads the blacklisted IPs from iter and labels them and their peers with
+// the asset inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectIPResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]IPResult, error) {
+	blIPs, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *IPResult) bool {
+		return allowed.ContainsIP(r.Host.IP)
+	})
+	if err != nil {
+		return blIPs, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return blIPs, err
+	}
+	inventory.Label(blIPs)
+
+	return blIPs, nil
+}
+
+// ipResultsQuery builds the pipeline for SrcIPResults and DstIPResults. Set sourceDestFlag
+// to true to find blacklisted source IPs. Set sourceDestFlag to false to find blacklisted
+// destination IPs.
+func ipResultsQuery(sort string, sourceDestFlag bool) []bson.M {
 	var hostMatch bson.M
 	var blHostField string
 	var blPeerField string
@@ -153,8 +268,6 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			}}
 	}
 
-	var blIPs []IPResult
-
 	blIPQuery := []bson.M{
 		// find blacklisted source/ destination hosts
 		{"$match": hostMatch},
@@ -163,6 +276,8 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			"ip":           1,
 			"network_uuid": 1,
 			"network_name": 1,
+			"geo":          1,
+			"bl_sources":   1,
 		}},
 		// join on both src/dst and src/dst_network_uuid
 		{"$lookup": bson.M{
@@ -185,6 +300,8 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			"ip":                1,
 			"network_uuid":      1,
 			"network_name":      1,
+			"geo":               1,
+			"bl_sources":        1,
 			"peer_ip":           "$uconn." + blPeerField,
 			"peer_network_uuid": "$uconn." + blPeerField + "_network_uuid",
 			"peer_network_name": "$uconn." + blPeerField + "_network_name",
@@ -210,6 +327,8 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			// there should only be one network_name in each record
 			// as it comes from the hosts collection
 			"network_name": bson.M{"$last": "$network_name"},
+			"geo":          bson.M{"$last": "$geo"},
+			"bl_sources":   bson.M{"$last": "$bl_sources"},
 			// use one of the network names associated with the network_uuid
 			// for this partial result
 			"peer_network_name": bson.M{"$last": "$peer_network_name"},
@@ -223,6 +342,8 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			"ip":           "$_id.ip",
 			"network_uuid": "$_id.network_uuid",
 			"network_name": "$network_name",
+			"geo":          "$geo",
+			"bl_sources":   "$bl_sources",
 			"peer": bson.M{
 				"ip":           "$_id.peer_ip",
 				"network_uuid": "$_id.peer_network_uuid",
@@ -239,9 +360,11 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 				"network_uuid": "$network_uuid",
 				"network_name": "$network_name",
 			},
-			"peers":  bson.M{"$addToSet": "$peer"},
-			"conns":  bson.M{"$sum": "$conns"},
-			"tbytes": bson.M{"$sum": "$tbytes"},
+			"geo":        bson.M{"$last": "$geo"},
+			"bl_sources": bson.M{"$last": "$bl_sources"},
+			"peers":      bson.M{"$addToSet": "$peer"},
+			"conns":      bson.M{"$sum": "$conns"},
+			"tbytes":     bson.M{"$sum": "$tbytes"},
 		}},
 		// move the id fields back out and add uconn_count
 		{"$project": bson.M{
@@ -249,6 +372,8 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 			"ip":           "$_id.ip",
 			"network_uuid": "$_id.network_uuid",
 			"network_name": "$_id.network_name",
+			"geo":          1,
+			"bl_sources":   1,
 			"peers":        1,
 			"conn_count":   "$conns",
 			"uconn_count":  bson.M{"$size": bson.M{"$ifNull": []interface{}{"$peers", []interface{}{}}}},
@@ -257,12 +382,74 @@ func ipResults(res *resources.Resources, sort string, limit int, noLimit bool, s
 		{"$sort": bson.M{sort: -1}},
 	}
 
-	if !noLimit {
-		blIPQuery = append(blIPQuery, bson.M{"$limit": limit})
+	return blIPQuery
+}
+
+// FingerprintResults finds the blacklisted JA3, JA3S, and certificate fingerprints
+// presented in TLS connections along with the hosts and servers involved. The results
+// are sorted in descending order by connection count. limit and noLimit control how many
+// results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func FingerprintResults(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]FingerprintResult, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	blFingerprintQuery := []bson.M{
+		// find TLS connections which presented a blacklisted fingerprint
+		{"$match": bson.M{"dat.tls.bl_fingerprints.0": bson.M{"$exists": true}}},
+		{"$project": bson.M{
+			"src":              1,
+			"src_network_uuid": 1,
+			"src_network_name": 1,
+			"fqdn":             1,
+			"dat.tls":          1,
+		}},
+		// aggregate over time/ chunks
+		{"$unwind": "$dat"},
+		{"$unwind": "$dat.tls.bl_fingerprints"},
+		{"$group": bson.M{
+			"_id":        "$dat.tls.bl_fingerprints",
+			"conn_count": bson.M{"$sum": "$dat.tls.count"},
+			"sources": bson.M{"$addToSet": bson.M{
+				"ip":           "$src",
+				"network_uuid": "$src_network_uuid",
+				"network_name": "$src_network_name",
+			}},
+			"fqdns":   bson.M{"$addToSet": "$fqdn"},
+			"dst_ips": bson.M{"$push": "$dat.tls.dst_ips"},
+		}},
+		{"$project": bson.M{
+			"_id":        0,
+			"type":       "$_id.type",
+			"hash":       "$_id.hash",
+			"list":       "$_id.list",
+			"reason":     "$_id.reason",
+			"conn_count": 1,
+			"sources":    1,
+			"fqdns":      1,
+			// flatten the destination IPs recorded in each chunk
+			"dst_ips": bson.M{"$reduce": bson.M{
+				"input":        "$dst_ips",
+				"initialValue": []interface{}{},
+				"in":           bson.M{"$setUnion": []string{"$$value", "$$this"}},
+			}},
+		}},
+		{"$sort": bson.M{"conn_count": -1}},
 	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable).Pipe(blIPQuery).AllowDiskUse().All(&blIPs)
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.SNIConnTable).Pipe(blFingerprintQuery).AllowDiskUse().Iter()
+	blFingerprints, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *FingerprintResult) bool {
+		return r.Type != CertSHA1Fingerprint && allowed.ContainsJA3(r.Hash)
+	})
+	if err != nil {
+		return blFingerprints, err
+	}
 
-	return blIPs, err
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return blFingerprints, err
+	}
+	inventory.Label(blFingerprints)
 
+	return blFingerprints, nil
 }
@@ -0,0 +1,45 @@
+package blacklist
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+// pipelineStage returns the first stage of the pipeline using the given operator
+func pipelineStage(pipeline []bson.M, operator string) (interface{}, bool) {
+	for _, stage := range pipeline {
+		if value, ok := stage[operator]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func TestIPResultsQueryDirection(t *testing.T) {
+	srcMatch, ok := pipelineStage(srcIPResultsQuery("conn_count"), "$match")
+	require.True(t, ok)
+	require.Contains(t, srcMatch.(bson.M)["$and"], bson.M{"dat.count_src": bson.M{"$gt": 0}})
+
+	dstMatch, ok := pipelineStage(dstIPResultsQuery("conn_count"), "$match")
+	require.True(t, ok)
+	require.Contains(t, dstMatch.(bson.M)["$and"], bson.M{"dat.count_dst": bson.M{"$gt": 0}})
+
+	// the peers of blacklisted destinations are the sources which connected to them
+	project, ok := pipelineStage(dstIPResultsQuery("conn_count")[3:], "$project")
+	require.True(t, ok)
+	require.Equal(t, "$uconn.src", project.(bson.M)["peer_ip"])
+}
+
+func TestIPResultsQuerySort(t *testing.T) {
+	for _, query := range []func(string) []bson.M{srcIPResultsQuery, dstIPResultsQuery} {
+		// the results are limited as they are read so suppressed results don't count towards the limit
+		_, ok := pipelineStage(query("conn_count"), "$limit")
+		require.False(t, ok)
+
+		sort, ok := pipelineStage(query("uconn_count"), "$sort")
+		require.True(t, ok)
+		require.Equal(t, bson.M{"uconn_count": -1}, sort)
+	}
+}
@@ -113,6 +113,11 @@ func getSourceLists(conf *config.Config) []list.List {
 	blacklists = append(blacklists, ipLists...)
 	blacklists = append(blacklists, hostLists...)
 
+	//use threat intel feeds which record where each entry came from
+	for _, path := range conf.S.Blacklisted.ThreatFeeds {
+		blacklists = append(blacklists, newFeedList(path))
+	}
+
 	return blacklists
 }
 
@@ -0,0 +1,105 @@
+package blacklist
+
+import (
+	"strconv"
+	"strings"
+
+	ritaBLdb "github.com/activecm/rita-bl/database"
+	"github.com/activecm/rita-bl/list"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Source records which threat intel list or feed flagged a host and why.
+// Line separated lists and Feodo Tracker only fill in Feed.
+type Source struct {
+	Feed       string   `bson:"feed" json:"feed"`             // name of the list or feed file
+	Source     string   `bson:"source" json:"source"`         // organization or list the feed credits
+	Tags       []string `bson:"tags" json:"tags"`             // labels such as malware families
+	Confidence int      `bson:"confidence" json:"confidence"` // 0-100, 0 if the feed doesn't say
+}
+
+// String formats the source without commas so it fits in a CSV column
+func (s Source) String() string {
+	out := s.Feed
+	if s.Source != "" && s.Source != s.Feed {
+		out += "/" + s.Source
+	}
+	if s.Confidence > 0 {
+		out += " conf=" + strconv.Itoa(s.Confidence)
+	}
+	if len(s.Tags) > 0 {
+		out += " tags=" + strings.Join(s.Tags, "|")
+	}
+	return out
+}
+
+// FormatSources joins the descriptions of several sources
+func FormatSources(sources []Source) string {
+	var formatted []string
+	for _, source := range sources {
+		formatted = append(formatted, source.String())
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// IPSources returns the threat intel entries in the blacklist database blDB which match an IP address
+func IPSources(ssn *mgo.Session, blDB string, ip string) ([]Source, error) {
+	return findSources(ssn, blDB, list.BlacklistedIPType, ip)
+}
+
+// HostnameSources returns the threat intel entries in the blacklist database blDB which match a hostname
+func HostnameSources(ssn *mgo.Session, blDB string, hostname string) ([]Source, error) {
+	return findSources(ssn, blDB, list.BlacklistedHostnameType, hostname)
+}
+
+// findSources reads the rita-bl entries for the given index. Feeds may list an
+// indicator more than once, so duplicate sources are dropped.
+func findSources(ssn *mgo.Session, blDB string, entryType list.BlacklistedEntryType, index string) ([]Source, error) {
+	var entries []ritaBLdb.BlacklistResult
+	err := ssn.DB(blDB).C(string(entryType)).Find(bson.M{"index": index}).All(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []Source{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		source := sourceFromEntry(entry)
+		key := source.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// sourceFromEntry converts the provenance recorded by a feedList into a Source.
+// Entries from other lists are attributed to the list name alone.
+func sourceFromEntry(entry ritaBLdb.BlacklistResult) Source {
+	source := Source{Feed: entry.List}
+	if feed, ok := entry.ExtraData["feed"].(string); ok && feed != "" {
+		source.Feed = feed
+	}
+	if name, ok := entry.ExtraData["source"].(string); ok {
+		source.Source = name
+	}
+	switch conf := entry.ExtraData["confidence"].(type) {
+	case int:
+		source.Confidence = conf
+	case int64:
+		source.Confidence = int(conf)
+	case float64:
+		source.Confidence = int(conf)
+	}
+	if tags, ok := entry.ExtraData["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if str, ok := tag.(string); ok {
+				source.Tags = append(source.Tags, str)
+			}
+		}
+	}
+	return source
+}
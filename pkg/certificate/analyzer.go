@@ -1,6 +1,7 @@
 package certificate
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/activecm/rita-legacy/config"
@@ -8,10 +9,23 @@ import (
 	"github.com/globalsign/mgo/bson"
 )
 
+// shortLivedCertDuration is the validity window (in seconds) under which a certificate
+// is considered to be short lived
+const shortLivedCertDuration int64 = 14 * 24 * 60 * 60
+
+// freshCertDuration is how long (in seconds) after its issuance a certificate is
+// considered to be freshly issued when it is first presented by a server
+const freshCertDuration int64 = 7 * 24 * 60 * 60
+
+// maxCertsPerChunk caps the number of distinct flagged certificates recorded for a server
+// in each chunk to keep the documents in the cert collection small
+const maxCertsPerChunk = 10
+
 type (
-	//analyzer is a structure for invalid certificate analysis
+	//analyzer is a structure for certificate analysis
 	analyzer struct {
 		chunk            int                        // current chunk (0 if not on rolling analysis)
+		x509Map          map[string]*X509Input      // certificate details keyed by file id and fingerprint
 		db               *database.DB               // provides access to MongoDB
 		conf             *config.Config             // contains details needed to access MongoDB
 		analyzedCallback func(database.BulkChanges) // called on each analyzed result
@@ -22,10 +36,11 @@ type (
 )
 
 // newAnalyzer creates a new analyzer for recording connections that were made
-// with invalid certificates
-func newAnalyzer(chunk int, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+// with invalid or suspicious certificates
+func newAnalyzer(chunk int, x509Map map[string]*X509Input, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
 	return &analyzer{
 		chunk:            chunk,
+		x509Map:          x509Map,
 		db:               db,
 		conf:             conf,
 		analyzedCallback: analyzedCallback,
@@ -54,34 +69,48 @@ func (a *analyzer) start() {
 		defer ssn.Close()
 
 		for datum := range a.analysisChannel {
-			// cap the list to an arbitrary amount (hopefully smaller than the 16 MB document size cap)
-			// anything approaching this limit will cause performance issues in software that depends on rita
-			// anything tuncated over this limit won't be visible as an IP connecting to an invalid cert
-			origIPs := datum.OrigIps.Items()
-			if len(origIPs) > 200003 {
-				origIPs = origIPs[:200003]
+			datSubdoc := bson.M{"cid": a.chunk}
+
+			if datum.Seen > 0 {
+				// cap the list to an arbitrary amount (hopefully smaller than the 16 MB document size cap)
+				// anything approaching this limit will cause performance issues in software that depends on rita
+				// anything tuncated over this limit won't be visible as an IP connecting to an invalid cert
+				origIPs := datum.OrigIps.Items()
+				if len(origIPs) > 200003 {
+					origIPs = origIPs[:200003]
+				}
+
+				tuples := datum.Tuples.Items()
+				if len(tuples) > 20 {
+					tuples = tuples[:20]
+				}
+
+				invalidCerts := datum.InvalidCerts.Items()
+				if len(invalidCerts) > 10 {
+					invalidCerts = invalidCerts[:10]
+				}
+
+				datSubdoc["seen"] = datum.Seen
+				datSubdoc["orig_ips"] = origIPs
+				datSubdoc["tuples"] = tuples
+				datSubdoc["icodes"] = invalidCerts
 			}
 
-			tuples := datum.Tuples.Items()
-			if len(tuples) > 20 {
-				tuples = tuples[:20]
+			certs := linkCertificates(datum.LeafCerts, a.x509Map)
+			if len(certs) > 0 {
+				datSubdoc["certs"] = certs
 			}
 
-			invalidCerts := datum.InvalidCerts.Items()
-			if len(invalidCerts) > 10 {
-				invalidCerts = invalidCerts[:10]
+			// nothing to record if the certificates presented by this server
+			// were valid and none of them were flagged
+			if len(datSubdoc) == 1 {
+				continue
 			}
 
 			// create certificateQuery
 			certificateQuery := bson.M{
 				"$push": bson.M{
-					"dat": bson.M{
-						"seen":     datum.Seen,
-						"orig_ips": origIPs,
-						"tuples":   tuples,
-						"icodes":   invalidCerts,
-						"cid":      a.chunk,
-					},
+					"dat": datSubdoc,
 				},
 				"$set": bson.M{
 					"cid":          a.chunk,
@@ -102,3 +131,101 @@ func (a *analyzer) start() {
 		a.analysisWg.Done()
 	}()
 }
+
+// linkCertificates looks up the x509 details of each certificate presented by a server,
+// combining the records for certificates which were presented more than once.
+// Only the certificates which were flagged are returned.
+func linkCertificates(leafCerts map[string]*TimeRange, x509Map map[string]*X509Input) []Details {
+	certs := make(map[string]*Details)
+	for certID, seen := range leafCerts {
+		x509, ok := x509Map[certID]
+		if !ok {
+			continue
+		}
+
+		// zeek assigns a new file id each time a certificate is transferred,
+		// so the same certificate may be referenced by several ids
+		key := x509.Fingerprint
+		if key == "" {
+			key = x509.Issuer + "\x00" + x509.Serial
+		}
+
+		if existing, ok := certs[key]; ok {
+			if seen.FirstSeen < existing.FirstSeen {
+				existing.FirstSeen = seen.FirstSeen
+			}
+			if seen.LastSeen > existing.LastSeen {
+				existing.LastSeen = seen.LastSeen
+			}
+			continue
+		}
+
+		certs[key] = &Details{
+			Serial:         x509.Serial,
+			Subject:        x509.Subject,
+			Issuer:         x509.Issuer,
+			SANs:           x509.SANs,
+			NotValidBefore: x509.NotValidBefore,
+			NotValidAfter:  x509.NotValidAfter,
+			KeyType:        x509.KeyType,
+			KeyLength:      x509.KeyLength,
+			FirstSeen:      seen.FirstSeen,
+			LastSeen:       seen.LastSeen,
+		}
+	}
+
+	var results []Details
+	for _, cert := range certs {
+		flagCertificate(cert)
+		if cert.flagged() {
+			results = append(results, *cert)
+		}
+	}
+
+	// keep the most recently seen certificates when truncating
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].LastSeen > results[j].LastSeen
+	})
+	if len(results) > maxCertsPerChunk {
+		results = results[:maxCertsPerChunk]
+	}
+	return results
+}
+
+// flagCertificate marks certificates which are expired, self-signed, short lived,
+// or were freshly issued when they were first presented
+func flagCertificate(cert *Details) {
+	cert.SelfSigned = cert.Subject != "" && cert.Subject == cert.Issuer
+	cert.Expired = cert.NotValidAfter > 0 && cert.LastSeen > cert.NotValidAfter
+
+	if cert.NotValidBefore > 0 && cert.NotValidAfter > 0 {
+		cert.ShortLived = cert.NotValidAfter-cert.NotValidBefore < shortLivedCertDuration
+	}
+
+	if cert.NotValidBefore > 0 && cert.FirstSeen >= cert.NotValidBefore {
+		cert.FreshlyIssued = cert.FirstSeen-cert.NotValidBefore < freshCertDuration
+	}
+}
+
+// Flags lists the reasons a certificate was flagged
+func (d Details) Flags() []string {
+	var flags []string
+	if d.Expired {
+		flags = append(flags, "expired")
+	}
+	if d.SelfSigned {
+		flags = append(flags, "self-signed")
+	}
+	if d.ShortLived {
+		flags = append(flags, "short-lived")
+	}
+	if d.FreshlyIssued {
+		flags = append(flags, "freshly-issued")
+	}
+	return flags
+}
+
+// flagged returns true if any of the certificate flags are set
+func (d Details) flagged() bool {
+	return d.SelfSigned || d.Expired || d.ShortLived || d.FreshlyIssued
+}
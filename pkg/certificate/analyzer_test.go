@@ -0,0 +1,70 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCertificates(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	now := int64(1650000000)
+
+	x509Map := map[string]*X509Input{
+		"Fexpired":    {Serial: "01", Subject: "CN=a", Issuer: "CN=ca", NotValidBefore: now - 365*day, NotValidAfter: now - day},
+		"Fselfsigned": {Serial: "02", Subject: "CN=b", Issuer: "CN=b", NotValidBefore: now - 365*day, NotValidAfter: now + 365*day},
+		"Fshortlived": {Serial: "03", Subject: "CN=c", Issuer: "CN=ca", NotValidBefore: now - 300*day, NotValidAfter: now - 300*day + 10*day},
+		"Ffresh":      {Serial: "04", Subject: "CN=d", Issuer: "CN=ca", NotValidBefore: now - day, NotValidAfter: now + 90*day},
+		"Fbenign":     {Serial: "05", Subject: "CN=e", Issuer: "CN=ca", NotValidBefore: now - 365*day, NotValidAfter: now + 365*day},
+		"Fbenign2":    {Serial: "05", Subject: "CN=e", Issuer: "CN=ca", NotValidBefore: now - 365*day, NotValidAfter: now + 365*day},
+	}
+
+	leafCerts := map[string]*TimeRange{
+		"Fexpired":    {FirstSeen: now, LastSeen: now},
+		"Fselfsigned": {FirstSeen: now, LastSeen: now},
+		"Fshortlived": {FirstSeen: now - 295*day, LastSeen: now - 295*day},
+		"Ffresh":      {FirstSeen: now, LastSeen: now},
+		"Fbenign":     {FirstSeen: now, LastSeen: now},
+		"Fbenign2":    {FirstSeen: now - day, LastSeen: now + day},
+		"Fmissing":    {FirstSeen: now, LastSeen: now},
+	}
+
+	certs := linkCertificates(leafCerts, x509Map)
+	require.Len(t, certs, 4)
+
+	bySerial := make(map[string]Details)
+	for _, cert := range certs {
+		bySerial[cert.Serial] = cert
+	}
+
+	require.True(t, bySerial["01"].Expired)
+	require.True(t, bySerial["02"].SelfSigned)
+	require.True(t, bySerial["03"].ShortLived)
+	require.True(t, bySerial["04"].FreshlyIssued)
+
+	// certificates which were not flagged are not recorded
+	require.NotContains(t, bySerial, "05")
+
+	// the most recently seen certificates are sorted first
+	require.Equal(t, "03", certs[len(certs)-1].Serial)
+}
+
+func TestLinkCertificatesCombinesFileIDs(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	now := int64(1650000000)
+
+	x509Map := map[string]*X509Input{
+		"F1": {Fingerprint: "ab", Serial: "01", Subject: "CN=a", Issuer: "CN=a", NotValidBefore: now - 365*day, NotValidAfter: now + 365*day},
+		"F2": {Fingerprint: "ab", Serial: "01", Subject: "CN=a", Issuer: "CN=a", NotValidBefore: now - 365*day, NotValidAfter: now + 365*day},
+	}
+	leafCerts := map[string]*TimeRange{
+		"F1": {FirstSeen: now, LastSeen: now},
+		"F2": {FirstSeen: now - day, LastSeen: now + day},
+	}
+
+	certs := linkCertificates(leafCerts, x509Map)
+	require.Len(t, certs, 1)
+	require.True(t, certs[0].SelfSigned)
+	require.Equal(t, now-day, certs[0].FirstSeen)
+	require.Equal(t, now+day, certs[0].LastSeen)
+}
@@ -61,12 +61,13 @@ func (r *repo) CreateIndexes() error {
 }
 
 // Upsert records the given certificate data in MongoDB
-func (r *repo) Upsert(certMap map[string]*Input) {
+func (r *repo) Upsert(certMap map[string]*Input, x509Map map[string]*X509Input) {
 	// Create the workers
 	writerWorker := database.NewBulkWriter(r.database, r.config, r.log, true, "certificate")
 
 	analyzerWorker := newAnalyzer(
 		r.config.S.Rolling.CurrentChunk,
+		x509Map,
 		r.database,
 		r.config,
 		writerWorker.Collect,
@@ -83,7 +84,7 @@ func (r *repo) Upsert(certMap map[string]*Input) {
 	p := mpb.New(mpb.WithWidth(20))
 	bar := p.AddBar(int64(len(certMap)),
 		mpb.PrependDecorators(
-			decor.Name("\t[-] Invalid Cert Analysis:", decor.WC{W: 30, C: decor.DidentRight}),
+			decor.Name("\t[-] Certificate Analysis:", decor.WC{W: 30, C: decor.DidentRight}),
 			decor.CountersNoUnit(" %d / %d ", decor.WCSyncWidth),
 		),
 		mpb.AppendDecorators(decor.Percentage()),
@@ -52,7 +52,7 @@ func init() {
 }
 
 func TestUpsert(t *testing.T) {
-	testRepo.Upsert(testCertificate)
+	testRepo.Upsert(testCertificate, map[string]*X509Input{})
 
 }
 
@@ -7,7 +7,7 @@ import (
 // Repository for uconn collection
 type Repository interface {
 	CreateIndexes() error
-	Upsert(useragentMap map[string]*Input)
+	Upsert(certMap map[string]*Input, x509Map map[string]*X509Input)
 }
 
 // Input ....
@@ -17,6 +17,57 @@ type Input struct {
 	OrigIps      data.UniqueIPSet
 	InvalidCerts data.StringSet
 	Tuples       data.StringSet
+
+	// LeafCerts maps the file id or fingerprint of each certificate presented by the
+	// server to the first and last times the certificate was presented
+	LeafCerts map[string]*TimeRange
+}
+
+// TimeRange records the first and last time an event was seen
+type TimeRange struct {
+	FirstSeen int64
+	LastSeen  int64
+}
+
+// X509Input holds the details of a certificate recorded in zeek's x509 log
+type X509Input struct {
+	Fingerprint    string
+	Serial         string
+	Subject        string
+	Issuer         string
+	SANs           []string
+	NotValidBefore int64
+	NotValidAfter  int64
+	KeyType        string
+	KeyLength      int
+}
+
+// Details represents a certificate presented by a server along
+// with the flags raised for that certificate
+type Details struct {
+	Serial         string   `bson:"serial"`
+	Subject        string   `bson:"subject"`
+	Issuer         string   `bson:"issuer"`
+	SANs           []string `bson:"sans"`
+	NotValidBefore int64    `bson:"not_valid_before"`
+	NotValidAfter  int64    `bson:"not_valid_after"`
+	KeyType        string   `bson:"key_type"`
+	KeyLength      int      `bson:"key_length"`
+	FirstSeen      int64    `bson:"first_seen"`
+	LastSeen       int64    `bson:"last_seen"`
+	SelfSigned     bool     `bson:"self_signed"`
+	Expired        bool     `bson:"expired"`
+	ShortLived     bool     `bson:"short_lived"`
+	FreshlyIssued  bool     `bson:"freshly_issued"`
+}
+
+// Result represents a server which presented a suspicious certificate
+// and the highest beacon score of any connections made to that server
+type Result struct {
+	Host        data.UniqueIP `bson:",inline"`
+	Certificate Details       `bson:"cert"`
+	BeaconScore float64       `bson:"beacon_score"`
+	Suppressed  bool          `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
 
 // AnalysisView (for reporting)
@@ -0,0 +1,124 @@
+package certificate
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the certificates which were flagged as expired, self-signed,
+// short lived, or freshly issued along with the servers which presented them.
+// The results are sorted by the highest beacon score of any connection to the
+// presenting server. limit and noLimit control how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Cert.CertificateTable).Pipe(resultsQuery(res)).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the flagged certificates along with the total number of them.
+// The first skip results are skipped and at most limit results are returned. Results
+// involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Cert.CertificateTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(res), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	certResults, err := collectResults(res, iter, 0, true, true)
+	return certResults, total, err
+}
+
+// resultsQuery gathers each flagged certificate along with the highest beacon score of any
+// connection to the server which presented it
+func resultsQuery(res *resources.Resources) []bson.M {
+	flaggedMatch := bson.M{"$or": []bson.M{
+		{"dat.certs.self_signed": true},
+		{"dat.certs.expired": true},
+		{"dat.certs.short_lived": true},
+		{"dat.certs.freshly_issued": true},
+	}}
+
+	certQuery := []bson.M{
+		{"$match": flaggedMatch},
+		{"$unwind": "$dat"},
+		{"$unwind": "$dat.certs"},
+		{"$match": flaggedMatch},
+		// a certificate may be recorded in multiple chunks
+		{"$group": bson.M{
+			"_id": bson.M{
+				"ip":           "$ip",
+				"network_uuid": "$network_uuid",
+				"issuer":       "$dat.certs.issuer",
+				"serial":       "$dat.certs.serial",
+			},
+			"network_name": bson.M{"$last": "$network_name"},
+			"cert":         bson.M{"$last": "$dat.certs"},
+			"first_seen":   bson.M{"$min": "$dat.certs.first_seen"},
+			"last_seen":    bson.M{"$max": "$dat.certs.last_seen"},
+			"expired":      bson.M{"$max": "$dat.certs.expired"},
+			"fresh":        bson.M{"$max": "$dat.certs.freshly_issued"},
+		}},
+		// find any beacons to the server presenting the certificate
+		{"$lookup": bson.M{
+			"from": res.Config.T.Beacon.BeaconTable,
+			"let":  bson.M{"ip": "$_id.ip", "network_uuid": "$_id.network_uuid"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{"$expr": bson.M{
+					"$and": []bson.M{
+						{"$eq": []string{"$dst", "$$ip"}},
+						{"$eq": []string{"$dst_network_uuid", "$$network_uuid"}},
+					},
+				}}},
+				{"$project": bson.M{"score": 1}},
+			},
+			"as": "beacons",
+		}},
+		{"$addFields": bson.M{
+			"cert.first_seen":     "$first_seen",
+			"cert.last_seen":      "$last_seen",
+			"cert.expired":        "$expired",
+			"cert.freshly_issued": "$fresh",
+			"beacon_score":        bson.M{"$ifNull": []interface{}{bson.M{"$max": "$beacons.score"}, 0}},
+		}},
+		{"$project": bson.M{
+			"_id":          0,
+			"ip":           "$_id.ip",
+			"network_uuid": "$_id.network_uuid",
+			"network_name": 1,
+			"cert":         1,
+			"beacon_score": 1,
+		}},
+		{"$sort": bson.D{{Name: "beacon_score", Value: -1}, {Name: "cert.last_seen", Value: -1}}},
+	}
+
+	return certQuery
+}
+
+// collectResults reads the certificates from iter and labels the servers with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	certResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.Host.IP)
+	})
+	if err != nil {
+		return certResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return certResults, err
+	}
+	inventory.Label(certResults)
+
+	return certResults, nil
+}
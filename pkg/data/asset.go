@@ -0,0 +1,55 @@
+package data
+
+import "strconv"
+
+// AssetLabel describes the internal host behind an IP address as recorded
+// in the asset inventory. The zero value means the IP isn't in the inventory.
+type AssetLabel struct {
+	Hostname    string `bson:"hostname" json:"hostname"`
+	Owner       string `bson:"owner" json:"owner"`
+	Role        string `bson:"role" json:"role"`
+	Criticality int    `bson:"criticality" json:"criticality"` // higher values are more critical
+}
+
+// Details summarizes the owner, role, and criticality of the asset
+func (a AssetLabel) Details() string {
+	var details string
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if details != "" {
+			details += ", "
+		}
+		details += name + ": " + value
+	}
+	add("owner", a.Owner)
+	add("role", a.Role)
+	if a.Criticality != 0 {
+		add("criticality", strconv.Itoa(a.Criticality))
+	}
+	return details
+}
+
+// labelIP appends the asset's hostname to the given IP address for display
+func labelIP(ip string, asset AssetLabel) string {
+	if asset.Hostname == "" {
+		return ip
+	}
+	return ip + " (" + asset.Hostname + ")"
+}
+
+// LabeledIP returns the IP address followed by its hostname from the asset inventory
+func (u UniqueIP) LabeledIP() string {
+	return labelIP(u.IP, u.Asset)
+}
+
+// LabeledSrcIP returns the source IP address followed by its hostname from the asset inventory
+func (u UniqueSrcIP) LabeledSrcIP() string {
+	return labelIP(u.SrcIP, u.SrcAsset)
+}
+
+// LabeledDstIP returns the destination IP address followed by its hostname from the asset inventory
+func (u UniqueDstIP) LabeledDstIP() string {
+	return labelIP(u.DstIP, u.DstAsset)
+}
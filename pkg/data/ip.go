@@ -18,6 +18,7 @@ type UniqueIP struct {
 	IP          string      `bson:"ip"`
 	NetworkUUID bson.Binary `bson:"network_uuid"`
 	NetworkName string      `bson:"network_name"`
+	Asset       AssetLabel  `bson:"-" json:"asset"` // filled in from the asset inventory when results are queried
 }
 
 // NewUniqueIP returns a new UniqueIP. If the given ip is publicly routable, the resulting UniqueIP's
@@ -99,6 +100,7 @@ type UniqueSrcIP struct {
 	SrcIP          string      `bson:"src"`
 	SrcNetworkUUID bson.Binary `bson:"src_network_uuid"`
 	SrcNetworkName string      `bson:"src_network_name"`
+	SrcAsset       AssetLabel  `bson:"-" json:"src_asset"` // filled in from the asset inventory when results are queried
 }
 
 // AsSrc returns the UniqueIP in the UniqueSrcIP format
@@ -107,6 +109,7 @@ func (u UniqueIP) AsSrc() UniqueSrcIP {
 		SrcIP:          u.IP,
 		SrcNetworkUUID: u.NetworkUUID,
 		SrcNetworkName: u.NetworkName,
+		SrcAsset:       u.Asset,
 	}
 }
 
@@ -116,6 +119,7 @@ func (u UniqueSrcIP) Unpair() UniqueIP {
 		IP:          u.SrcIP,
 		NetworkUUID: u.SrcNetworkUUID,
 		NetworkName: u.SrcNetworkName,
+		Asset:       u.SrcAsset,
 	}
 }
 
@@ -134,6 +138,7 @@ type UniqueDstIP struct {
 	DstIP          string      `bson:"dst"`
 	DstNetworkUUID bson.Binary `bson:"dst_network_uuid"`
 	DstNetworkName string      `bson:"dst_network_name"`
+	DstAsset       AssetLabel  `bson:"-" json:"dst_asset"` // filled in from the asset inventory when results are queried
 }
 
 // AsDst returns the UniqueIP in the UniqueDstIP format
@@ -142,6 +147,7 @@ func (u UniqueIP) AsDst() UniqueDstIP {
 		DstIP:          u.IP,
 		DstNetworkUUID: u.NetworkUUID,
 		DstNetworkName: u.NetworkName,
+		DstAsset:       u.Asset,
 	}
 }
 
@@ -151,6 +157,7 @@ func (u UniqueDstIP) Unpair() UniqueIP {
 		IP:          u.DstIP,
 		NetworkUUID: u.DstNetworkUUID,
 		NetworkName: u.DstNetworkName,
+		Asset:       u.DstAsset,
 	}
 }
 
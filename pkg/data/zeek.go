@@ -2,8 +2,10 @@ package data
 
 type ZeekUIDRecord struct {
 	Conn struct {
-		OrigBytes int64
-		RespBytes int64
-		Duration  float64
+		Ts          int64
+		OrigBytes   int64
+		RespBytes   int64
+		RespIPBytes int64 // counts headers like OrigBytes does, used to judge the direction of transfers
+		Duration    float64
 	}
 }
@@ -0,0 +1,186 @@
+package dnstunnel
+
+import (
+	"math"
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	// entropyLow and entropyHigh bound the average character entropy (in bits) of
+	// queried subdomains. Dictionary words and short labels tend to fall below the
+	// lower bound while base32/base64 encoded data approaches the upper bound.
+	entropyLow  = 2.5
+	entropyHigh = 4.0
+
+	// queryLengthLow and queryLengthHigh bound the average length of the queries.
+	// Tunneling tools pack as much data as possible into each query.
+	queryLengthLow  = 30.0
+	queryLengthHigh = 90.0
+)
+
+type (
+	//analyzer : structure for dns tunneling analysis
+	analyzer struct {
+		chunk            int                        //current chunk (0 if not on rolling analysis)
+		db               *database.DB               // provides access to MongoDB
+		conf             *config.Config             // contains details needed to access MongoDB
+		analyzedCallback func(database.BulkChanges) // called on each analyzed result
+		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
+		analysisChannel  chan *Input                // holds unanalyzed data
+		analysisWg       sync.WaitGroup             // wait for analysis to finish
+	}
+)
+
+// newAnalyzer creates a new analyzer for scoring client and registered domain pairs
+func newAnalyzer(chunk int, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+	return &analyzer{
+		chunk:            chunk,
+		db:               db,
+		conf:             conf,
+		analyzedCallback: analyzedCallback,
+		closedCallback:   closedCallback,
+		analysisChannel:  make(chan *Input),
+	}
+}
+
+// collect sends a client and registered domain pair to be analyzed
+func (a *analyzer) collect(datum *Input) {
+	a.analysisChannel <- datum
+}
+
+// close waits for the analyzer to finish
+func (a *analyzer) close() {
+	close(a.analysisChannel)
+	a.analysisWg.Wait()
+	a.closedCallback()
+}
+
+// start kicks off a new analysis thread
+func (a *analyzer) start() {
+	a.analysisWg.Add(1)
+	go func() {
+		ssn := a.db.Session.Copy()
+		defer ssn.Close()
+
+		for datum := range a.analysisChannel {
+			current := chunkStats{
+				CID:                     a.chunk,
+				QueryCount:              datum.QueryCount,
+				UniqueSubdomains:        int64(len(datum.Subdomains)),
+				QueryLengthTotal:        datum.QueryLengthTotal,
+				QueryLengthSquaresTotal: datum.QueryLengthSquaresTotal,
+				MaxQueryLength:          datum.MaxQueryLength,
+				SubdomainQueryCount:     datum.SubdomainQueryCount,
+				EntropyTotal:            datum.EntropyTotal,
+				TXTNullCount:            datum.TXTNullCount,
+				NXDomainCount:           datum.NXDomainCount,
+			}
+
+			// combine the statistics gathered in previous imports with the current import
+			// so the score reflects every chunk in the dataset
+			var existing struct {
+				Dat []chunkStats `bson:"dat"`
+			}
+			_ = ssn.DB(a.db.GetSelectedDB()).C(a.conf.T.DNS.DNSTunnelTable).
+				Find(datum.Hosts.BSONKey()).Select(bson.M{"dat": 1}).One(&existing)
+
+			total := current
+			for _, prev := range existing.Dat {
+				total = total.merge(prev)
+			}
+
+			result := scoreTunnel(total, a.conf.S.DNSTunnel)
+
+			tunnelQuery := bson.M{
+				"$push": bson.M{"dat": current},
+				"$set": bson.M{
+					"cid":                    a.chunk,
+					"src_network_name":       datum.Hosts.SrcNetworkName,
+					"score":                  result.Score,
+					"query_count":            result.QueryCount,
+					"entropy":                result.Entropy,
+					"query_length_mean":      result.QueryLengthMean,
+					"query_length_stddev":    result.QueryLengthStdDev,
+					"query_length_max":       result.QueryLengthMax,
+					"unique_subdomain_ratio": result.UniqueSubdomainRatio,
+					"txt_null_share":         result.TXTNullShare,
+					"nxdomain_rate":          result.NXDomainRate,
+				},
+			}
+
+			a.analyzedCallback(database.BulkChanges{
+				a.conf.T.DNS.DNSTunnelTable: []database.BulkChange{{
+					Selector: datum.Hosts.BSONKey(),
+					Update:   tunnelQuery,
+					Upsert:   true,
+				}},
+			})
+		}
+		a.analysisWg.Done()
+	}()
+}
+
+// merge combines the statistics gathered in two chunks
+func (s chunkStats) merge(other chunkStats) chunkStats {
+	s.QueryCount += other.QueryCount
+	// subdomains may repeat across chunks, so this overestimates the number of unique subdomains
+	s.UniqueSubdomains += other.UniqueSubdomains
+	s.QueryLengthTotal += other.QueryLengthTotal
+	s.QueryLengthSquaresTotal += other.QueryLengthSquaresTotal
+	if other.MaxQueryLength > s.MaxQueryLength {
+		s.MaxQueryLength = other.MaxQueryLength
+	}
+	s.SubdomainQueryCount += other.SubdomainQueryCount
+	s.EntropyTotal += other.EntropyTotal
+	s.TXTNullCount += other.TXTNullCount
+	s.NXDomainCount += other.NXDomainCount
+	return s
+}
+
+// scoreTunnel computes the measurements and overall score for a client and registered domain pair
+func scoreTunnel(stats chunkStats, conf config.DNSTunnelStaticCfg) Result {
+	var result Result
+	if stats.QueryCount == 0 {
+		return result
+	}
+
+	count := float64(stats.QueryCount)
+
+	result.QueryCount = stats.QueryCount
+	// entropy is only measured for queries which had a subdomain
+	if stats.SubdomainQueryCount > 0 {
+		result.Entropy = stats.EntropyTotal / float64(stats.SubdomainQueryCount)
+	}
+	result.QueryLengthMean = float64(stats.QueryLengthTotal) / count
+	result.QueryLengthStdDev = math.Sqrt(math.Max(0, float64(stats.QueryLengthSquaresTotal)/count-result.QueryLengthMean*result.QueryLengthMean))
+	result.QueryLengthMax = stats.MaxQueryLength
+	result.UniqueSubdomainRatio = math.Min(1, float64(stats.UniqueSubdomains)/count)
+	result.TXTNullShare = float64(stats.TXTNullCount) / count
+	result.NXDomainRate = float64(stats.NXDomainCount) / count
+
+	entropyScore := scaleScore(result.Entropy, entropyLow, entropyHigh)
+	lengthScore := scaleScore(result.QueryLengthMean, queryLengthLow, queryLengthHigh)
+
+	result.Score = math.Ceil(((entropyScore*conf.EntropyWeight)+
+		(lengthScore*conf.QueryLengthWeight)+
+		(result.UniqueSubdomainRatio*conf.UniqueSubdomainWeight)+
+		(result.TXTNullShare*conf.TXTNullWeight)+
+		(result.NXDomainRate*conf.NXDomainWeight))*1000) / 1000
+
+	return result
+}
+
+// scaleScore linearly maps a value between low and high onto the range [0, 1]
+func scaleScore(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	}
+	if value >= high {
+		return 1
+	}
+	return (value - low) / (high - low)
+}
@@ -0,0 +1,76 @@
+package dnstunnel
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/stretchr/testify/require"
+)
+
+var testTunnelConfig = config.DNSTunnelStaticCfg{
+	EntropyWeight:         0.2,
+	QueryLengthWeight:     0.2,
+	UniqueSubdomainWeight: 0.2,
+	TXTNullWeight:         0.2,
+	NXDomainWeight:        0.2,
+}
+
+func TestScoreTunnel(t *testing.T) {
+	// 100 unique, long, random looking TXT queries
+	tunnel := chunkStats{
+		QueryCount:              100,
+		UniqueSubdomains:        100,
+		QueryLengthTotal:        100 * 120,
+		QueryLengthSquaresTotal: 100 * 120 * 120,
+		MaxQueryLength:          120,
+		SubdomainQueryCount:     100,
+		EntropyTotal:            100 * 4.5,
+		TXTNullCount:            100,
+	}
+	result := scoreTunnel(tunnel, testTunnelConfig)
+	require.Equal(t, 0.8, result.Score)
+	require.Equal(t, 120.0, result.QueryLengthMean)
+	require.Equal(t, 0.0, result.QueryLengthStdDev)
+	require.Equal(t, 1.0, result.UniqueSubdomainRatio)
+
+	// 100 repeated lookups of a short hostname
+	benign := chunkStats{
+		QueryCount:              100,
+		UniqueSubdomains:        1,
+		QueryLengthTotal:        100 * 15,
+		QueryLengthSquaresTotal: 100 * 15 * 15,
+		MaxQueryLength:          15,
+		SubdomainQueryCount:     100,
+		EntropyTotal:            100 * 1.5,
+	}
+	result = scoreTunnel(benign, testTunnelConfig)
+	require.Equal(t, 0.002, result.Score)
+
+	// the statistics from separate chunks are summed
+	merged := benign.merge(tunnel)
+	require.Equal(t, int64(200), merged.QueryCount)
+	require.Equal(t, int64(120), merged.MaxQueryLength)
+	result = scoreTunnel(merged, testTunnelConfig)
+	require.InDelta(t, 52.5, result.QueryLengthStdDev, 0.001)
+
+	require.Equal(t, Result{}, scoreTunnel(chunkStats{}, testTunnelConfig))
+}
+
+func TestScoreTunnelEntropyIgnoresBareDomainQueries(t *testing.T) {
+	// half of the queries were for the registered domain itself
+	tunnel := chunkStats{
+		QueryCount:          100,
+		SubdomainQueryCount: 50,
+		EntropyTotal:        50 * 4.0,
+	}
+	result := scoreTunnel(tunnel, testTunnelConfig)
+	require.Equal(t, 4.0, result.Entropy)
+
+	// no entropy is measured if none of the queries had a subdomain
+	result = scoreTunnel(chunkStats{QueryCount: 100}, testTunnelConfig)
+	require.Equal(t, 0.0, result.Entropy)
+
+	merged := tunnel.merge(chunkStats{QueryCount: 10, SubdomainQueryCount: 10, EntropyTotal: 10 * 1.0})
+	require.Equal(t, int64(60), merged.SubdomainQueryCount)
+	require.InDelta(t, 210.0/60, scoreTunnel(merged, testTunnelConfig).Entropy, 0.0001)
+}
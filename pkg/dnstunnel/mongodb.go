@@ -0,0 +1,105 @@
+package dnstunnel
+
+import (
+	"runtime"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo"
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type repo struct {
+	database *database.DB
+	config   *config.Config
+	log      *log.Logger
+}
+
+// NewMongoRepository bundles the given resources for updating MongoDB with DNS tunneling data
+func NewMongoRepository(db *database.DB, conf *config.Config, logger *log.Logger) Repository {
+	return &repo{
+		database: db,
+		config:   conf,
+		log:      logger,
+	}
+}
+
+// CreateIndexes creates indexes for the dnsTunnel collection
+func (r *repo) CreateIndexes() error {
+	session := r.database.Session.Copy()
+	defer session.Close()
+
+	// set collection name
+	collectionName := r.config.T.DNS.DNSTunnelTable
+
+	// check if collection already exists
+	names, _ := session.DB(r.database.GetSelectedDB()).CollectionNames()
+
+	// if collection exists, we don't need to do anything else
+	for _, name := range names {
+		if name == collectionName {
+			return nil
+		}
+	}
+
+	// set desired indexes
+	indexes := []mgo.Index{
+		{Key: []string{"src", "src_network_uuid", "fqdn"}, Unique: true},
+		{Key: []string{"fqdn"}},
+		{Key: []string{"-score"}},
+	}
+
+	// create collection
+	err := r.database.CreateCollection(collectionName, indexes)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Upsert scores the given DNS query data and records the results in MongoDB
+func (r *repo) Upsert(tunnelMap map[string]*Input) {
+
+	//Create the workers
+	writerWorker := database.NewBulkWriter(r.database, r.config, r.log, true, "dns_tunnel")
+
+	analyzerWorker := newAnalyzer(
+		r.config.S.Rolling.CurrentChunk,
+		r.database,
+		r.config,
+		writerWorker.Collect,
+		writerWorker.Close,
+	)
+
+	//kick off the threaded goroutines
+	for i := 0; i < util.Max(1, runtime.NumCPU()/2); i++ {
+		analyzerWorker.start()
+		writerWorker.Start()
+	}
+
+	// progress bar for troubleshooting
+	p := mpb.New(mpb.WithWidth(20))
+	bar := p.AddBar(int64(len(tunnelMap)),
+		mpb.PrependDecorators(
+			decor.Name("\t[-] DNS Tunnel Analysis:", decor.WC{W: 30, C: decor.DidentRight}),
+			decor.CountersNoUnit(" %d / %d ", decor.WCSyncWidth),
+		),
+		mpb.AppendDecorators(decor.Percentage()),
+	)
+
+	// loop over map entries
+	for _, entry := range tunnelMap {
+		analyzerWorker.collect(entry)
+		bar.IncrBy(1)
+	}
+
+	p.Wait()
+
+	// start the closing cascade (this will also close the other channels)
+	analyzerWorker.close()
+}
@@ -0,0 +1,57 @@
+package dnstunnel
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+// Repository for dnsTunnel collection
+type Repository interface {
+	CreateIndexes() error
+	Upsert(tunnelMap map[string]*Input)
+}
+
+// Input holds the DNS queries made by a single client for subdomains
+// of a single registered domain
+type Input struct {
+	Hosts                   data.UniqueSrcFQDNPair // the client and the registered domain
+	QueryCount              int64                  // number of queries made
+	QueryLengthTotal        int64                  // sum of the lengths of the queries
+	QueryLengthSquaresTotal int64                  // sum of the squared lengths of the queries
+	MaxQueryLength          int64                  // length of the longest query
+	SubdomainQueryCount     int64                  // number of queries made for a subdomain of the registered domain
+	EntropyTotal            float64                // sum of the character entropy of the queried subdomains
+	TXTNullCount            int64                  // number of queries for TXT or NULL records
+	NXDomainCount           int64                  // number of queries which resulted in NXDOMAIN
+	Subdomains              data.StringSet         // distinct subdomains queried
+}
+
+// chunkStats holds the query statistics for a single chunk. The statistics
+// are sums so that they may be combined across chunks.
+type chunkStats struct {
+	CID                     int     `bson:"cid"`
+	QueryCount              int64   `bson:"queries"`
+	UniqueSubdomains        int64   `bson:"unique_subdomains"`
+	QueryLengthTotal        int64   `bson:"query_length_total"`
+	QueryLengthSquaresTotal int64   `bson:"query_length_squares_total"`
+	MaxQueryLength          int64   `bson:"max_query_length"`
+	SubdomainQueryCount     int64   `bson:"subdomain_queries"`
+	EntropyTotal            float64 `bson:"entropy_total"`
+	TXTNullCount            int64   `bson:"txt_null"`
+	NXDomainCount           int64   `bson:"nxdomain"`
+}
+
+// Result represents a client and registered domain pair along with the
+// measurements used to determine whether the pair is being used for DNS tunneling
+type Result struct {
+	data.UniqueSrcFQDNPair `bson:",inline"`
+	Score                  float64 `bson:"score"`
+	QueryCount             int64   `bson:"query_count"`
+	Entropy                float64 `bson:"entropy"`
+	QueryLengthMean        float64 `bson:"query_length_mean"`
+	QueryLengthStdDev      float64 `bson:"query_length_stddev"`
+	QueryLengthMax         int64   `bson:"query_length_max"`
+	UniqueSubdomainRatio   float64 `bson:"unique_subdomain_ratio"`
+	TXTNullShare           float64 `bson:"txt_null_share"`
+	NXDomainRate           float64 `bson:"nxdomain_rate"`
+	Suppressed             bool    `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
@@ -0,0 +1,62 @@
+package dnstunnel
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the client and registered domain pairs which made enough queries
+// to be considered for DNS tunneling, sorted by score. limit and noLimit control
+// how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.DNSTunnelTable).Find(resultsQuery(res)).Sort("-score").Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the DNS tunneling results along with the total number
+// of them. The first skip results are skipped and at most limit results are returned.
+// Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.DNSTunnelTable)
+	iter, total, err := database.FindPage(collection, resultsQuery(res), "-score", skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	tunnelResults, err := collectResults(res, iter, 0, true, true)
+	return tunnelResults, total, err
+}
+
+// resultsQuery selects the pairs which made enough queries to be considered for DNS tunneling
+func resultsQuery(res *resources.Resources) bson.M {
+	return bson.M{"query_count": bson.M{"$gte": res.Config.S.DNSTunnel.MinQueryCount}}
+}
+
+// collectResults reads the DNS tunneling results from iter and labels them with the asset
+// inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	tunnelResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP) || allowed.ContainsDomain(r.FQDN)
+	})
+	if err != nil {
+		return tunnelResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return tunnelResults, err
+	}
+	inventory.Label(tunnelResults)
+
+	return tunnelResults, nil
+}
@@ -0,0 +1,225 @@
+package exfil
+
+import (
+	"math"
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/globalsign/mgo/bson"
+)
+
+// volumeHigh is the number of uploaded bytes at which the volume subscore
+// saturates. The subscore grows logarithmically from the MinUploadBytes
+// threshold up to this value.
+const volumeHigh = 1e9
+
+type (
+	//analyzer : structure for data exfiltration analysis
+	analyzer struct {
+		chunk            int                        //current chunk (0 if not on rolling analysis)
+		db               *database.DB               // provides access to MongoDB
+		conf             *config.Config             // contains details needed to access MongoDB
+		analyzedCallback func(database.BulkChanges) // called on each analyzed result
+		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
+		analysisChannel  chan *Input                // holds unanalyzed data
+		analysisWg       sync.WaitGroup             // wait for analysis to finish
+	}
+
+	// peerScore holds the subscores and overall score of a single peer
+	peerScore struct {
+		Score     float64
+		Asymmetry float64
+		Volume    float64
+		TimeOfDay float64
+	}
+)
+
+// newAnalyzer creates a new analyzer for scoring the outbound traffic of internal hosts
+func newAnalyzer(chunk int, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+	return &analyzer{
+		chunk:            chunk,
+		db:               db,
+		conf:             conf,
+		analyzedCallback: analyzedCallback,
+		closedCallback:   closedCallback,
+		analysisChannel:  make(chan *Input),
+	}
+}
+
+// collect sends an internal host's outbound traffic to be analyzed
+func (a *analyzer) collect(datum *Input) {
+	a.analysisChannel <- datum
+}
+
+// close waits for the analyzer to finish
+func (a *analyzer) close() {
+	close(a.analysisChannel)
+	a.analysisWg.Wait()
+	a.closedCallback()
+}
+
+// start kicks off a new analysis thread
+func (a *analyzer) start() {
+	a.analysisWg.Add(1)
+	go func() {
+		ssn := a.db.Session.Copy()
+		defer ssn.Close()
+
+		for datum := range a.analysisChannel {
+			// gather the hours in which the host was active during previous imports.
+			// these are summarized onto the host document after each import.
+			var existing struct {
+				Dat []struct {
+					Hours []int64 `bson:"exfil_hours"`
+				} `bson:"dat"`
+			}
+			_ = ssn.DB(a.db.GetSelectedDB()).C(a.conf.T.Structure.HostTable).
+				Find(datum.Host.BSONKey()).Select(bson.M{"dat.exfil_hours": 1}).One(&existing)
+
+			hostHours := datum.Hours
+			for _, prev := range existing.Dat {
+				for hour := 0; hour < len(prev.Hours) && hour < 24; hour++ {
+					hostHours[hour] += prev.Hours[hour]
+				}
+			}
+
+			var exfilUpdates []database.BulkChange
+			for _, peer := range datum.Peers {
+				if peer.OrigBytes < a.conf.S.Exfil.MinUploadBytes {
+					continue
+				}
+
+				score := scorePeer(peer, baselineHours(hostHours, peer), a.conf.S.Exfil)
+
+				exfilUpdates = append(exfilUpdates, database.BulkChange{
+					Selector: peerSelector(datum, peer),
+					Update: bson.M{
+						"$push": bson.M{"dat": bson.M{
+							"cid":               a.chunk,
+							"score":             score.Score,
+							"asymmetry_score":   score.Asymmetry,
+							"volume_score":      score.Volume,
+							"time_of_day_score": score.TimeOfDay,
+							"orig_bytes":        peer.OrigBytes,
+							"resp_bytes":        peer.RespBytes,
+							"connections":       peer.Connections,
+						}},
+						"$set": peerNames(datum, peer, a.chunk),
+					},
+					Upsert: true,
+				})
+			}
+
+			if len(exfilUpdates) > 0 {
+				a.analyzedCallback(database.BulkChanges{
+					a.conf.T.Exfil.ExfilTable: exfilUpdates,
+				})
+			}
+		}
+		a.analysisWg.Done()
+	}()
+}
+
+// peerSelector returns the selector for the exfil document tracking the
+// traffic between an internal host and a peer
+func peerSelector(datum *Input, peer *Peer) bson.M {
+	selector := datum.Host.AsSrc().BSONKey()
+	selector["type"] = peer.Type
+	if peer.Type == IPPeer {
+		selector["dst"] = peer.Dst.IP
+		selector["dst_network_uuid"] = peer.Dst.NetworkUUID
+	} else {
+		selector["fqdn"] = peer.FQDN
+	}
+	return selector
+}
+
+// peerNames returns the top level fields which are updated on every import
+func peerNames(datum *Input, peer *Peer, chunk int) bson.M {
+	names := bson.M{
+		"cid":              chunk,
+		"src_network_name": datum.Host.NetworkName,
+	}
+	if peer.Type == IPPeer {
+		names["dst_network_name"] = peer.Dst.NetworkName
+	}
+	return names
+}
+
+// baselineHours returns the number of connections the host made in each hour
+// of the day excluding the connections made to the given peer
+func baselineHours(hostHours [24]int64, peer *Peer) [24]int64 {
+	var baseline [24]int64
+	for hour := range hostHours {
+		baseline[hour] = hostHours[hour] - peer.Hours[hour]
+		if baseline[hour] < 0 {
+			baseline[hour] = 0
+		}
+	}
+	return baseline
+}
+
+// scorePeer computes the subscores and overall score for the traffic between
+// an internal host and a peer. baseline holds the number of connections the
+// host made to other peers in each hour of the day.
+func scorePeer(peer *Peer, baseline [24]int64, conf config.ExfilStaticCfg) peerScore {
+	var score peerScore
+
+	// the producer consumer ratio ranges from -1 (download only) to 1 (upload only)
+	totalBytes := peer.OrigBytes + peer.RespBytes
+	if totalBytes > 0 {
+		score.Asymmetry = math.Max(0, float64(peer.OrigBytes-peer.RespBytes)/float64(totalBytes))
+	}
+
+	if peer.OrigBytes > 0 {
+		volumeLow := math.Log10(math.Max(1, float64(conf.MinUploadBytes)))
+		score.Volume = scaleScore(math.Log10(float64(peer.OrigBytes)), volumeLow, math.Log10(volumeHigh))
+	}
+
+	score.TimeOfDay = timeOfDayScore(peer, baseline)
+
+	score.Score = math.Ceil(((score.Asymmetry*conf.AsymmetryWeight)+
+		(score.Volume*conf.VolumeWeight)+
+		(score.TimeOfDay*conf.TimeOfDayWeight))*1000) / 1000
+
+	return score
+}
+
+// timeOfDayScore returns the share of the bytes uploaded to the peer during hours
+// in which the host is otherwise quiet. Each hour is weighted by how far the host's
+// activity during that hour falls below its average hourly activity.
+func timeOfDayScore(peer *Peer, baseline [24]int64) float64 {
+	if peer.OrigBytes <= 0 {
+		return 0
+	}
+
+	var baselineTotal int64
+	for _, count := range baseline {
+		baselineTotal += count
+	}
+	// without any other activity there is nothing to compare against
+	if baselineTotal == 0 {
+		return 0
+	}
+	hourlyMean := float64(baselineTotal) / 24
+
+	var offHoursBytes float64
+	for hour, bytes := range peer.HourlyOrigBytes {
+		quietness := 1 - math.Min(1, float64(baseline[hour])/hourlyMean)
+		offHoursBytes += float64(bytes) * quietness
+	}
+
+	return math.Min(1, offHoursBytes/float64(peer.OrigBytes))
+}
+
+// scaleScore linearly maps a value between low and high onto the range [0, 1]
+func scaleScore(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	}
+	if value >= high {
+		return 1
+	}
+	return (value - low) / (high - low)
+}
@@ -0,0 +1,115 @@
+package exfil
+
+import (
+	"net"
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/sniconn"
+	"github.com/activecm/rita-legacy/pkg/uconn"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.ExfilStaticCfg{
+	MinUploadBytes:  1000000,
+	AsymmetryWeight: 0.4,
+	VolumeWeight:    0.4,
+	TimeOfDayWeight: 0.2,
+}
+
+func TestScorePeer(t *testing.T) {
+	// the host is busy during business hours (UTC)
+	var baseline [24]int64
+	for hour := 9; hour < 17; hour++ {
+		baseline[hour] = 100
+	}
+
+	// a backup job uploading 1 GB overnight with little coming back
+	nightly := &Peer{OrigBytes: 1e9, RespBytes: 1e6}
+	nightly.HourlyOrigBytes[2] = 1e9
+	score := scorePeer(nightly, baseline, testConfig)
+	require.InDelta(t, 0.998, score.Asymmetry, 0.001)
+	require.Equal(t, 1.0, score.Volume)
+	require.Equal(t, 1.0, score.TimeOfDay)
+	require.Equal(t, 1.0, score.Score)
+
+	// a video call sending and receiving similar amounts during the day
+	call := &Peer{OrigBytes: 5e7, RespBytes: 6e7}
+	call.HourlyOrigBytes[10] = 5e7
+	score = scorePeer(call, baseline, testConfig)
+	require.Equal(t, 0.0, score.Asymmetry)
+	require.Equal(t, 0.0, score.TimeOfDay)
+	require.InDelta(t, 0.566, score.Volume, 0.001)
+
+	// without any other activity the time of day can't be judged
+	score = scorePeer(nightly, [24]int64{}, testConfig)
+	require.Equal(t, 0.0, score.TimeOfDay)
+}
+
+func TestLinkInputMaps(t *testing.T) {
+	src := data.NewUniqueIP(net.ParseIP("10.0.0.1"), "", "")
+	dst := data.NewUniqueIP(net.ParseIP("1.2.3.4"), "", "")
+
+	uconnMap := map[string]*uconn.Input{
+		"pair": {
+			Hosts:           data.NewUniqueIPPair(src, dst),
+			IsLocalSrc:      true,
+			ConnectionCount: 2,
+			TotalBytes:      1500,
+			TsList:          []int64{3600, 7200},
+			OrigBytesList:   []int64{1000, 300},
+		},
+		"inbound": {
+			Hosts:           data.NewUniqueIPPair(dst, src),
+			IsLocalDst:      true,
+			ConnectionCount: 1,
+			TsList:          []int64{3600},
+			OrigBytesList:   []int64{100},
+		},
+	}
+
+	fqdnPair := data.NewUniqueSrcFQDNPair(src, "example.com")
+	tlsMap := map[string]*sniconn.TLSInput{
+		"tls": {Hosts: fqdnPair, IsLocalSrc: true, ConnectionCount: 1, ZeekUIDs: []string{"a"}},
+	}
+	httpMap := map[string]*sniconn.HTTPInput{
+		"http": {Hosts: fqdnPair, IsLocalSrc: true, ConnectionCount: 2, ZeekUIDs: []string{"a", "b"}},
+	}
+
+	zeekUIDMap := map[string]*data.ZeekUIDRecord{
+		"a": {},
+		"b": {},
+	}
+	zeekUIDMap["a"].Conn.Ts = 3600
+	zeekUIDMap["a"].Conn.OrigBytes = 50
+	zeekUIDMap["a"].Conn.RespIPBytes = 10
+	zeekUIDMap["b"].Conn.Ts = 3600
+	zeekUIDMap["b"].Conn.OrigBytes = 70
+	zeekUIDMap["b"].Conn.RespIPBytes = 20
+
+	exfilMap := linkInputMaps(uconnMap, tlsMap, httpMap, zeekUIDMap)
+	require.Len(t, exfilMap, 1)
+
+	input := exfilMap[src.MapKey()]
+	require.Equal(t, int64(1), input.Hours[1])
+	require.Equal(t, int64(1), input.Hours[2])
+	require.Len(t, input.Peers, 2)
+
+	for _, peer := range input.Peers {
+		switch peer.Type {
+		case IPPeer:
+			require.Equal(t, dst.IP, peer.Dst.IP)
+			require.Equal(t, int64(1300), peer.OrigBytes)
+			require.Equal(t, int64(200), peer.RespBytes)
+			require.Equal(t, int64(1000), peer.HourlyOrigBytes[1])
+		case FQDNPeer:
+			// the connection logged by both the TLS and HTTP records is only counted once
+			require.Equal(t, "example.com", peer.FQDN)
+			require.Equal(t, int64(3), peer.Connections)
+			require.Equal(t, int64(120), peer.OrigBytes)
+			require.Equal(t, int64(30), peer.RespBytes)
+			require.Equal(t, int64(2), peer.Hours[1])
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package exfil
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/sniconn"
+	"github.com/activecm/rita-legacy/pkg/uconn"
+	"github.com/activecm/rita-legacy/util"
+
+	"github.com/globalsign/mgo"
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type repo struct {
+	database *database.DB
+	config   *config.Config
+	log      *log.Logger
+}
+
+// NewMongoRepository bundles the given resources for updating MongoDB with data exfiltration data
+func NewMongoRepository(db *database.DB, conf *config.Config, logger *log.Logger) Repository {
+	return &repo{
+		database: db,
+		config:   conf,
+		log:      logger,
+	}
+}
+
+// CreateIndexes creates indexes for the exfil collection
+func (r *repo) CreateIndexes() error {
+	session := r.database.Session.Copy()
+	defer session.Close()
+
+	// set collection name
+	collectionName := r.config.T.Exfil.ExfilTable
+
+	// check if collection already exists
+	names, _ := session.DB(r.database.GetSelectedDB()).CollectionNames()
+
+	// if collection exists, we don't need to do anything else
+	for _, name := range names {
+		if name == collectionName {
+			return nil
+		}
+	}
+
+	// set desired indexes
+	indexes := []mgo.Index{
+		{Key: []string{"type", "src", "src_network_uuid", "dst", "dst_network_uuid", "fqdn"}, Unique: true},
+		{Key: []string{"src", "src_network_uuid"}},
+		{Key: []string{"dat.score"}},
+	}
+
+	// create collection
+	err := r.database.CreateCollection(collectionName, indexes)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Upsert scores the outbound traffic of internal hosts and records the results in MongoDB.
+// Summaries are created for each internal host in MongoDB.
+func (r *repo) Upsert(uconnMap map[string]*uconn.Input, tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*sniconn.HTTPInput,
+	zeekUIDMap map[string]*data.ZeekUIDRecord) {
+
+	exfilMap := linkInputMaps(uconnMap, tlsMap, httpMap, zeekUIDMap)
+
+	// skip the analysis if there are no internal hosts with outbound traffic
+	if len(exfilMap) == 0 {
+		fmt.Println("\t[!] No data exfiltration data to analyze")
+		return
+	}
+
+	// Phase 1: Analysis
+
+	//Create the workers
+	writerWorker := database.NewBulkWriter(r.database, r.config, r.log, true, "exfil")
+
+	analyzerWorker := newAnalyzer(
+		r.config.S.Rolling.CurrentChunk,
+		r.database,
+		r.config,
+		writerWorker.Collect,
+		writerWorker.Close,
+	)
+
+	//kick off the threaded goroutines
+	for i := 0; i < util.Max(1, runtime.NumCPU()/2); i++ {
+		analyzerWorker.start()
+		writerWorker.Start()
+	}
+
+	// progress bar for troubleshooting
+	p := mpb.New(mpb.WithWidth(20))
+	bar := p.AddBar(int64(len(exfilMap)),
+		mpb.PrependDecorators(
+			decor.Name("\t[-] Exfiltration Analysis:", decor.WC{W: 30, C: decor.DidentRight}),
+			decor.CountersNoUnit(" %d / %d ", decor.WCSyncWidth),
+		),
+		mpb.AppendDecorators(decor.Percentage()),
+	)
+
+	// loop over map entries
+	for _, entry := range exfilMap {
+		analyzerWorker.collect(entry)
+		bar.IncrBy(1)
+	}
+
+	p.Wait()
+
+	// start the closing cascade (this will also close the other channels)
+	analyzerWorker.close()
+
+	// Phase 2: Summary
+
+	// initialize a new writer for the summarizer
+	writerWorker = database.NewBulkWriter(r.database, r.config, r.log, true, "exfil")
+	summarizerWorker := newSummarizer(
+		r.config.S.Rolling.CurrentChunk,
+		r.database,
+		r.config,
+		r.log,
+		writerWorker.Collect,
+		writerWorker.Close,
+	)
+
+	// kick off the threaded goroutines
+	for i := 0; i < util.Max(1, runtime.NumCPU()/2); i++ {
+		summarizerWorker.start()
+		writerWorker.Start()
+	}
+
+	// add a progress bar for troubleshooting
+	p = mpb.New(mpb.WithWidth(20))
+	bar = p.AddBar(int64(len(exfilMap)),
+		mpb.PrependDecorators(
+			decor.Name("\t[-] Exfiltration Aggregation:", decor.WC{W: 30, C: decor.DidentRight}),
+			decor.CountersNoUnit(" %d / %d ", decor.WCSyncWidth),
+		),
+		mpb.AppendDecorators(decor.Percentage()),
+	)
+
+	// loop over the internal hosts that need to be summarized
+	for _, entry := range exfilMap {
+		summarizerWorker.collect(entry)
+		bar.IncrBy(1)
+	}
+
+	p.Wait()
+
+	// start the closing cascade (this will also close the other channels)
+	summarizerWorker.close()
+}
+
+// linkInputMaps groups the internal to external unique connections and the
+// internal host to FQDN connections by the internal host
+func linkInputMaps(uconnMap map[string]*uconn.Input, tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*sniconn.HTTPInput,
+	zeekUIDMap map[string]*data.ZeekUIDRecord) map[string]*Input {
+
+	exfilMap := make(map[string]*Input)
+
+	getInput := func(host data.UniqueIP) *Input {
+		hostKey := host.MapKey()
+		if _, ok := exfilMap[hostKey]; !ok {
+			exfilMap[hostKey] = &Input{Host: host}
+		}
+		return exfilMap[hostKey]
+	}
+
+	for _, uconnInput := range uconnMap {
+		if !uconnInput.IsLocalSrc || uconnInput.IsLocalDst {
+			continue
+		}
+
+		peer := &Peer{
+			Type:        IPPeer,
+			Dst:         uconnInput.Hosts.UniqueDstIP.Unpair(),
+			Connections: uconnInput.ConnectionCount,
+		}
+
+		// the timestamps and originator bytes are recorded together for each connection
+		for i := 0; i < len(uconnInput.TsList) && i < len(uconnInput.OrigBytesList); i++ {
+			hour := hourOfDay(uconnInput.TsList[i])
+			peer.Hours[hour]++
+			peer.HourlyOrigBytes[hour] += uconnInput.OrigBytesList[i]
+			peer.OrigBytes += uconnInput.OrigBytesList[i]
+		}
+		peer.RespBytes = uconnInput.TotalBytes - peer.OrigBytes
+		if peer.RespBytes < 0 {
+			peer.RespBytes = 0
+		}
+
+		input := getInput(uconnInput.Hosts.UniqueSrcIP.Unpair())
+		for hour, count := range peer.Hours {
+			input.Hours[hour] += count
+		}
+		input.Peers = append(input.Peers, peer)
+	}
+
+	// TLS and HTTP connections to the same FQDN are combined
+	fqdnPeers := make(map[string]*Peer)
+	fqdnUIDs := make(map[string]data.StringSet)
+	addFQDNConns := func(hosts data.UniqueSrcFQDNPair, connCount int64, zeekUIDs []string) {
+		hostsKey := hosts.MapKey()
+		if _, ok := fqdnPeers[hostsKey]; !ok {
+			fqdnPeers[hostsKey] = &Peer{Type: FQDNPeer, FQDN: hosts.FQDN}
+			fqdnUIDs[hostsKey] = make(data.StringSet)
+
+			input := getInput(hosts.UniqueSrcIP.Unpair())
+			input.Peers = append(input.Peers, fqdnPeers[hostsKey])
+		}
+		peer := fqdnPeers[hostsKey]
+		peer.Connections += connCount
+
+		for _, zeekUID := range zeekUIDs {
+			// skip connections already recorded and connections without a conn record
+			zeekRecord, ok := zeekUIDMap[zeekUID]
+			if fqdnUIDs[hostsKey].Contains(zeekUID) || !ok {
+				continue
+			}
+			fqdnUIDs[hostsKey].Insert(zeekUID)
+
+			hour := hourOfDay(zeekRecord.Conn.Ts)
+			peer.Hours[hour]++
+			peer.HourlyOrigBytes[hour] += zeekRecord.Conn.OrigBytes
+			peer.OrigBytes += zeekRecord.Conn.OrigBytes
+			peer.RespBytes += zeekRecord.Conn.RespIPBytes
+		}
+	}
+
+	for _, tlsInput := range tlsMap {
+		if tlsInput.IsLocalSrc {
+			addFQDNConns(tlsInput.Hosts, tlsInput.ConnectionCount, tlsInput.ZeekUIDs)
+		}
+	}
+	for _, httpInput := range httpMap {
+		if httpInput.IsLocalSrc {
+			addFQDNConns(httpInput.Hosts, httpInput.ConnectionCount, httpInput.ZeekUIDs)
+		}
+	}
+
+	return exfilMap
+}
+
+// hourOfDay returns the hour of the day (UTC) in which the given timestamp falls
+func hourOfDay(ts int64) int {
+	return time.Unix(ts, 0).UTC().Hour()
+}
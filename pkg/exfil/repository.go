@@ -0,0 +1,58 @@
+package exfil
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/sniconn"
+	"github.com/activecm/rita-legacy/pkg/uconn"
+)
+
+const (
+	// IPPeer is an external IP address contacted by an internal host
+	IPPeer = "ip"
+	// FQDNPeer is a fully qualified domain name contacted by an internal host over TLS or HTTP
+	FQDNPeer = "fqdn"
+)
+
+// Repository for exfil collection
+type Repository interface {
+	CreateIndexes() error
+	Upsert(uconnMap map[string]*uconn.Input, tlsMap map[string]*sniconn.TLSInput, httpMap map[string]*sniconn.HTTPInput,
+		zeekUIDMap map[string]*data.ZeekUIDRecord)
+}
+
+// Input holds the outbound traffic of an internal host during the current chunk
+type Input struct {
+	Host  data.UniqueIP // the internal host
+	Hours [24]int64     // number of outbound connections started in each hour of the day (UTC)
+	Peers []*Peer       // external hosts and FQDNs contacted by the internal host
+}
+
+// Peer holds the traffic between an internal host and an external IP address or FQDN
+type Peer struct {
+	Type            string        // IPPeer or FQDNPeer
+	Dst             data.UniqueIP // the external host (IPPeer only)
+	FQDN            string        // the fully qualified domain name (FQDNPeer only)
+	Connections     int64         // number of connections made to the peer
+	OrigBytes       int64         // bytes sent by the internal host
+	RespBytes       int64         // bytes sent by the peer
+	Hours           [24]int64     // number of connections started in each hour of the day (UTC)
+	HourlyOrigBytes [24]int64     // bytes sent by the internal host in each hour of the day (UTC)
+}
+
+// Result represents the upload heavy traffic from an internal host to
+// an external IP address or FQDN during a single chunk
+type Result struct {
+	data.UniqueSrcIP `bson:",inline"`
+	data.UniqueDstIP `bson:",inline"`
+	Type             string  `bson:"type"`
+	FQDN             string  `bson:"fqdn"`
+	CID              int     `bson:"cid"`
+	Score            float64 `bson:"score"`
+	AsymmetryScore   float64 `bson:"asymmetry_score"`
+	VolumeScore      float64 `bson:"volume_score"`
+	TimeOfDayScore   float64 `bson:"time_of_day_score"`
+	OrigBytes        int64   `bson:"orig_bytes"`
+	RespBytes        int64   `bson:"resp_bytes"`
+	Connections      int64   `bson:"connections"`
+	Suppressed       bool    `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
@@ -0,0 +1,83 @@
+package exfil
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the scored outbound traffic from internal hosts to external IP addresses
+// and FQDNs in each chunk, sorted by score. limit and noLimit control how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Exfil.ExfilTable).Pipe(resultsQuery()).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the scored outbound traffic, sorted by score, along with the
+// total number of results. The first skip results are skipped and at most limit results are
+// returned. Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Exfil.ExfilTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	exfilResults, err := collectResults(res, iter, 0, true, true)
+	return exfilResults, total, err
+}
+
+// resultsQuery flattens the scores of each chunk, sorted by score
+func resultsQuery() []bson.M {
+	return []bson.M{
+		{"$unwind": "$dat"},
+		{"$project": bson.M{
+			"_id":               0,
+			"type":              1,
+			"src":               1,
+			"src_network_uuid":  1,
+			"src_network_name":  1,
+			"dst":               1,
+			"dst_network_uuid":  1,
+			"dst_network_name":  1,
+			"fqdn":              1,
+			"cid":               "$dat.cid",
+			"score":             "$dat.score",
+			"asymmetry_score":   "$dat.asymmetry_score",
+			"volume_score":      "$dat.volume_score",
+			"time_of_day_score": "$dat.time_of_day_score",
+			"orig_bytes":        "$dat.orig_bytes",
+			"resp_bytes":        "$dat.resp_bytes",
+			"connections":       "$dat.connections",
+		}},
+		{"$sort": bson.D{{Name: "score", Value: -1}, {Name: "orig_bytes", Value: -1}}},
+	}
+}
+
+// collectResults reads the exfil results from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	exfilResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP) || allowed.ContainsDomain(r.FQDN)
+	})
+	if err != nil {
+		return exfilResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return exfilResults, err
+	}
+	inventory.Label(exfilResults)
+
+	return exfilResults, nil
+}
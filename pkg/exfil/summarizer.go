@@ -0,0 +1,130 @@
+package exfil
+
+import (
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	//summarizer records summary data for individual hosts using data exfiltration data
+	summarizer struct {
+		chunk              int                        // current chunk (0 if not on rolling summary)
+		db                 *database.DB               // provides access to MongoDB
+		conf               *config.Config             // contains details needed to access MongoDB
+		log                *log.Logger                // main logger for RITA
+		summarizedCallback func(database.BulkChanges) // called on each summarized result
+		closedCallback     func()                     // called when .close() is called and no more calls to summarizedCallback will be made
+		summaryChannel     chan *Input                // holds unsummarized data
+		summaryWg          sync.WaitGroup             // wait for summary to finish
+	}
+)
+
+// newSummarizer creates a new summarizer for data exfiltration data
+func newSummarizer(chunk int, db *database.DB, conf *config.Config, log *log.Logger, summarizedCallback func(database.BulkChanges), closedCallback func()) *summarizer {
+	return &summarizer{
+		chunk:              chunk,
+		db:                 db,
+		conf:               conf,
+		log:                log,
+		summarizedCallback: summarizedCallback,
+		closedCallback:     closedCallback,
+		summaryChannel:     make(chan *Input),
+	}
+}
+
+// collect collects an internal host to be summarized
+func (s *summarizer) collect(datum *Input) {
+	s.summaryChannel <- datum
+}
+
+// close waits for the summarizer to finish
+func (s *summarizer) close() {
+	close(s.summaryChannel)
+	s.summaryWg.Wait()
+	s.closedCallback()
+}
+
+// start kicks off a new summary thread
+func (s *summarizer) start() {
+	s.summaryWg.Add(1)
+	go func() {
+
+		ssn := s.db.Session.Copy()
+		defer ssn.Close()
+
+		for datum := range s.summaryChannel {
+			exfilCollection := ssn.DB(s.db.GetSelectedDB()).C(s.conf.T.Exfil.ExfilTable)
+
+			exfilUpdate, err := maxExfilUpdate(datum, exfilCollection, s.chunk)
+			if err != nil {
+				s.log.WithFields(log.Fields{
+					"Module": "exfil",
+					"Data":   datum.Host,
+				}).Error(err)
+				continue
+			}
+
+			s.summarizedCallback(database.BulkChanges{
+				s.conf.T.Structure.HostTable: []database.BulkChange{exfilUpdate},
+			})
+		}
+		s.summaryWg.Done()
+	}()
+}
+
+// maxExfilUpdate records the hours in which the host was active during the current import
+// along with the highest scoring peer the host uploaded data to. A new entry is pushed for
+// each import session so the hours of every session count towards the host's baseline.
+func maxExfilUpdate(datum *Input, exfilColl *mgo.Collection, chunk int) (database.BulkChange, error) {
+	var maxExfil struct {
+		Dst   string  `bson:"dst"`
+		FQDN  string  `bson:"fqdn"`
+		Score float64 `bson:"score"`
+	}
+
+	err := exfilColl.Pipe(maxExfilPipeline(datum, chunk)).One(&maxExfil)
+	if err != nil && err != mgo.ErrNotFound {
+		return database.BulkChange{}, err
+	}
+
+	exfilDst := maxExfil.Dst
+	if exfilDst == "" {
+		exfilDst = maxExfil.FQDN
+	}
+
+	insertQuery := bson.M{
+		"$push": bson.M{
+			"dat": bson.M{
+				"$each": []bson.M{{
+					"exfil_hours": datum.Hours,
+					"exfil_dst":   exfilDst,
+					"exfil_score": maxExfil.Score,
+					"cid":         chunk,
+				}},
+			},
+		},
+	}
+	return database.BulkChange{Selector: datum.Host.BSONKey(), Update: insertQuery, Upsert: true}, nil
+}
+
+func maxExfilPipeline(datum *Input, chunk int) []bson.M {
+	return []bson.M{
+		{"$match": datum.Host.AsSrc().BSONKey()},
+		{"$unwind": "$dat"},
+		{"$match": bson.M{"dat.cid": chunk}},
+		// drop unnecessary data
+		{"$project": bson.M{
+			"dst":   1,
+			"fqdn":  1,
+			"score": "$dat.score",
+		}},
+		// find the peer with the highest score
+		{"$sort": bson.M{"score": -1}},
+		{"$limit": 1},
+	}
+}
@@ -27,4 +27,5 @@ type Result struct {
 	Domain         string `bson:"domain"`
 	SubdomainCount int64  `bson:"subdomain_count"`
 	Visited        int64  `bson:"visited"`
+	Suppressed     bool   `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
@@ -1,19 +1,43 @@
 package explodeddns
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
 // Results returns hostnames and their subdomain/ lookup statistics from the database.
 // limit and noLimit control how many results are returned.
-func Results(res *resources.Resources, limit int, noLimit bool) ([]Result, error) {
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var explodedDNSResults []Result
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.ExplodedDNSTable).Pipe(resultsQuery()).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the domains along with the total number of them. The first
+// skip results are skipped and at most limit results are returned. Results involving
+// allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.ExplodedDNSTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	explodedDNSResults, err := collectResults(res, iter, 0, true, true)
+	return explodedDNSResults, total, err
+}
 
-	explodedDNSQuery := []bson.M{
+// resultsQuery sums the subdomains and lookups of each domain
+func resultsQuery() []bson.M {
+	return []bson.M{
 		bson.M{"$unwind": "$dat"},
 		bson.M{"$project": bson.M{"domain": 1, "subdomain_count": 1, "visited": "$dat.visited"}},
 		bson.M{"$group": bson.M{
@@ -30,13 +54,17 @@ func Results(res *resources.Resources, limit int, noLimit bool) ([]Result, error
 		bson.M{"$sort": bson.M{"visited": -1}},
 		bson.M{"$sort": bson.M{"subdomain_count": -1}},
 	}
+}
 
-	if !noLimit {
-		explodedDNSQuery = append(explodedDNSQuery, bson.M{"$limit": limit})
+// collectResults reads the domains from iter. Results involving allowlisted values are only
+// returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	explodedDNSResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsDomain(r.Domain)
+	})
+	if err != nil {
+		return explodedDNSResults, err
 	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.ExplodedDNSTable).Pipe(explodedDNSQuery).AllowDiskUse().All(&explodedDNSResults)
-
-	return explodedDNSResults, err
-
+	return explodedDNSResults, nil
 }
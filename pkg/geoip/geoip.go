@@ -0,0 +1,192 @@
+package geoip
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+type (
+	// Info records the country and autonomous system which own an IP address
+	Info struct {
+		Country string `bson:"country"` // ISO 3166-1 alpha-2 country code
+		ASN     uint   `bson:"asn"`
+		ASOrg   string `bson:"as_org"`
+	}
+
+	// Reader looks up IP addresses in MaxMind format country and ASN databases.
+	// A nil Reader or a Reader without databases returns empty results.
+	Reader struct {
+		country *maxminddb.Reader
+		asn     *maxminddb.Reader
+	}
+
+	// countryRecord holds the fields read from a GeoIP2/ GeoLite2 country database
+	countryRecord struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+		RegisteredCountry struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"registered_country"`
+	}
+
+	// asnRecord holds the fields read from a GeoLite2 ASN database
+	asnRecord struct {
+		ASN   uint   `maxminddb:"autonomous_system_number"`
+		ASOrg string `maxminddb:"autonomous_system_organization"`
+	}
+
+	// Filter matches Info records against sets of ASNs and countries.
+	// An empty set matches every record.
+	Filter struct {
+		asns      map[uint]bool
+		countries map[string]bool
+	}
+)
+
+// Open opens the country and ASN databases given in the GeoIP config. A nil
+// Reader is returned if GeoIP enrichment is disabled.
+func Open(conf config.GeoIPStaticCfg) (*Reader, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+
+	reader := &Reader{}
+	var err error
+	if conf.CountryDatabase != "" {
+		reader.country, err = maxminddb.Open(conf.CountryDatabase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if conf.ASNDatabase != "" {
+		reader.asn, err = maxminddb.Open(conf.ASNDatabase)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
+// Close releases the underlying databases
+func (r *Reader) Close() {
+	if r == nil {
+		return
+	}
+	if r.country != nil {
+		r.country.Close()
+	}
+	if r.asn != nil {
+		r.asn.Close()
+	}
+}
+
+// Lookup returns the country and autonomous system which own the given IP address.
+// Fields which aren't found in the databases are left empty.
+func (r *Reader) Lookup(ip string) (Info, error) {
+	var info Info
+	if r == nil {
+		return info, nil
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return info, errors.New("invalid IP address: " + ip)
+	}
+
+	if r.country != nil {
+		var record countryRecord
+		if err := r.country.Lookup(parsed, &record); err != nil {
+			return info, err
+		}
+		info.Country = record.Country.ISOCode
+		if info.Country == "" {
+			// anycast and satellite ranges may only carry the registered country
+			info.Country = record.RegisteredCountry.ISOCode
+		}
+	}
+
+	if r.asn != nil {
+		var record asnRecord
+		if err := r.asn.Lookup(parsed, &record); err != nil {
+			return info, err
+		}
+		info.ASN = record.ASN
+		info.ASOrg = record.ASOrg
+	}
+
+	return info, nil
+}
+
+// AS formats the autonomous system as "AS<number> <organization>"
+func (i Info) AS() string {
+	if i.ASN == 0 {
+		return i.ASOrg
+	}
+	asn := "AS" + strconv.FormatUint(uint64(i.ASN), 10)
+	if i.ASOrg == "" {
+		return asn
+	}
+	return asn + " " + i.ASOrg
+}
+
+// NewFilter creates a Filter from comma separated lists of ASNs and country codes.
+// ASNs may be written with or without the "AS" prefix.
+func NewFilter(asns, countries string) (Filter, error) {
+	filter := Filter{
+		asns:      make(map[uint]bool),
+		countries: make(map[string]bool),
+	}
+
+	for _, asn := range splitList(asns) {
+		trimmed := strings.TrimPrefix(strings.ToUpper(asn), "AS")
+		parsed, err := strconv.ParseUint(trimmed, 10, 32)
+		if err != nil {
+			return filter, errors.New("invalid ASN: " + asn)
+		}
+		filter.asns[uint(parsed)] = true
+	}
+
+	for _, country := range splitList(countries) {
+		if len(country) != 2 {
+			return filter, errors.New("invalid country code: " + country + " (expected a two letter ISO code)")
+		}
+		filter.countries[strings.ToUpper(country)] = true
+	}
+
+	return filter, nil
+}
+
+// Empty returns true if the filter matches every record
+func (f Filter) Empty() bool {
+	return len(f.asns) == 0 && len(f.countries) == 0
+}
+
+// Match returns true if the record's ASN and country are in the filter's sets
+func (f Filter) Match(info Info) bool {
+	if len(f.asns) > 0 && !f.asns[info.ASN] {
+		return false
+	}
+	if len(f.countries) > 0 && !f.countries[info.Country] {
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,63 @@
+package geoip
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatch(t *testing.T) {
+	hetzner := Info{Country: "DE", ASN: 24940, ASOrg: "Hetzner Online GmbH"}
+	ovh := Info{Country: "FR", ASN: 16276, ASOrg: "OVH SAS"}
+	unknown := Info{}
+
+	filter, err := NewFilter("", "")
+	require.NoError(t, err)
+	require.True(t, filter.Empty())
+	require.True(t, filter.Match(unknown))
+
+	filter, err = NewFilter("AS24940, 16276", "")
+	require.NoError(t, err)
+	require.False(t, filter.Empty())
+	require.True(t, filter.Match(hetzner))
+	require.True(t, filter.Match(ovh))
+	require.False(t, filter.Match(unknown))
+
+	filter, err = NewFilter("as24940,16276", "fr")
+	require.NoError(t, err)
+	require.False(t, filter.Match(hetzner))
+	require.True(t, filter.Match(ovh))
+
+	_, err = NewFilter("hosting", "")
+	require.Error(t, err)
+
+	_, err = NewFilter("", "France")
+	require.Error(t, err)
+}
+
+func TestInfoAS(t *testing.T) {
+	require.Equal(t, "AS16276 OVH SAS", Info{ASN: 16276, ASOrg: "OVH SAS"}.AS())
+	require.Equal(t, "AS16276", Info{ASN: 16276}.AS())
+	require.Equal(t, "", Info{}.AS())
+}
+
+func TestDisabledReader(t *testing.T) {
+	reader, err := Open(config.GeoIPStaticCfg{
+		Enabled:         false,
+		CountryDatabase: "/does/not/exist.mmdb",
+	})
+	require.NoError(t, err)
+	require.Nil(t, reader)
+
+	info, err := reader.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	require.Equal(t, Info{}, info)
+	reader.Close()
+
+	_, err = Open(config.GeoIPStaticCfg{
+		Enabled:         true,
+		CountryDatabase: "/does/not/exist.mmdb",
+	})
+	require.Error(t, err)
+}
@@ -3,6 +3,8 @@ package host
 import (
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
@@ -18,6 +20,7 @@ type (
 		conf             *config.Config             // contains details needed to access MongoDB
 		db               *database.DB               // provides access to MongoDB
 		log              *log.Logger                // logger for writing out errors and warnings
+		geo              *geoip.Reader              // looks up the country and ASN of external hosts (nil if disabled)
 		analyzedCallback func(database.BulkChanges) // called on each analyzed result
 		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
 		analysisChannel  chan *Input                // holds unanalyzed data
@@ -26,12 +29,13 @@ type (
 )
 
 // newAnalyzer creates a new collector for gathering data
-func newAnalyzer(chunk int, conf *config.Config, db *database.DB, log *log.Logger, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+func newAnalyzer(chunk int, conf *config.Config, db *database.DB, log *log.Logger, geo *geoip.Reader, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
 	return &analyzer{
 		chunk:            chunk,
 		conf:             conf,
 		log:              log,
 		db:               db,
+		geo:              geo,
 		analyzedCallback: analyzedCallback,
 		closedCallback:   closedCallback,
 		analysisChannel:  make(chan *Input),
@@ -74,7 +78,15 @@ func (a *analyzer) start() {
 
 			connCountsUpdate := connCountsQuery(datum, a.chunk)
 
-			totalUpdate := database.MergeBSONMaps(mainUpdate, blUpdate, connCountsUpdate)
+			geoUpdate, err := geoQuery(datum, a.geo)
+			if err != nil {
+				a.log.WithFields(log.Fields{
+					"Module": "host",
+					"Data":   datum.Host,
+				}).Error(err)
+			}
+
+			totalUpdate := database.MergeBSONMaps(mainUpdate, blUpdate, connCountsUpdate, geoUpdate)
 
 			a.analyzedCallback(database.BulkChanges{
 				a.conf.T.Structure.HostTable: []database.BulkChange{{
@@ -101,19 +113,38 @@ func mainQuery(datum *Input, chunk int) bson.M {
 	}
 }
 
-// blQuery marks the given host as blacklisted or not
+// blQuery marks the given host as blacklisted or not and records the
+// threat intel lists and feeds which flagged it
 func blQuery(datum *Input, ssn *mgo.Session, blDB string) (bson.M, error) {
-	// check if blacklisted destination
-	blCount, err := ssn.DB(blDB).C("ip").Find(bson.M{"index": datum.Host.IP}).Count()
-	blacklisted := blCount > 0
+	// look up the entries matching this host in the blacklist database
+	sources, err := blacklist.IPSources(ssn, blDB, datum.Host.IP)
 
 	return bson.M{
 		"$set": bson.M{
-			"blacklisted": blacklisted,
+			"blacklisted": len(sources) > 0,
+			"bl_sources":  sources,
 		},
 	}, err
 }
 
+// geoQuery records the country and autonomous system which own external hosts
+func geoQuery(datum *Input, geo *geoip.Reader) (bson.M, error) {
+	if geo == nil || datum.IsLocal {
+		return bson.M{}, nil
+	}
+
+	info, err := geo.Lookup(datum.Host.IP)
+	if err != nil {
+		return bson.M{}, err
+	}
+
+	return bson.M{
+		"$set": bson.M{
+			"geo": info,
+		},
+	}, nil
+}
+
 // connCountsQuery records the number of connections this host has been a part of
 func connCountsQuery(datum *Input, chunk int) bson.M {
 	return bson.M{
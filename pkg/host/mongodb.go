@@ -1,10 +1,12 @@
 package host
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/geoip"
 	"github.com/activecm/rita-legacy/util"
 
 	"github.com/globalsign/mgo"
@@ -45,6 +47,9 @@ func (r *repo) CreateIndexes() error {
 		{Key: []string{"dat.mdip.ip", "dat.mdip.network_uuid"}},
 		{Key: []string{"dat.mbdst.ip", "dat.mbdst.network_uuid"}},
 		{Key: []string{"dat.mbproxy"}},
+		{Key: []string{"dat.mbdns"}},
+		{Key: []string{"geo.asn"}},
+		{Key: []string{"geo.country"}},
 	}
 
 	for _, index := range indexes {
@@ -61,6 +66,16 @@ func (r *repo) Upsert(hostMap map[string]*Input) {
 
 	// 1st Phase: Analysis
 
+	// open the GeoIP databases, continuing without enrichment if they can't be read
+	geo, err := geoip.Open(r.config.S.GeoIP)
+	if err != nil {
+		r.log.WithFields(log.Fields{
+			"Module": "host",
+		}).Error(err)
+		fmt.Println("\t[!] Could not open the GeoIP databases, hosts will not be enriched:", err)
+	}
+	defer geo.Close()
+
 	// Create the workers
 	writerWorker := database.NewBulkWriter(r.database, r.config, r.log, true, "host")
 
@@ -69,6 +84,7 @@ func (r *repo) Upsert(hostMap map[string]*Input) {
 		r.config,
 		r.database,
 		r.log,
+		geo,
 		writerWorker.Collect,
 		writerWorker.Close,
 	)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 
@@ -111,15 +112,16 @@ func mainQuery(datum *Input, chunk int) bson.M {
 	}
 }
 
-// blQuery marks the given hostname as blacklisted or not
+// blQuery marks the given hostname as blacklisted or not and records the
+// threat intel lists and feeds which flagged it
 func blQuery(datum *Input, ssn *mgo.Session, blDB string) (bson.M, error) {
-	// check if blacklisted destination
-	blCount, err := ssn.DB(blDB).C("hostname").Find(bson.M{"index": datum.Host}).Count()
-	blacklisted := blCount > 0
+	// look up the entries matching this host in the blacklist database
+	sources, err := blacklist.HostnameSources(ssn, blDB, datum.Host)
 
 	return bson.M{
 		"$set": bson.M{
-			"blacklisted": blacklisted,
+			"blacklisted": len(sources) > 0,
+			"bl_sources":  sources,
 		},
 	}, err
 }
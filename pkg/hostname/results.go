@@ -1,6 +1,8 @@
 package hostname
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/resources"
 	"github.com/globalsign/mgo/bson"
@@ -11,7 +13,41 @@ func IPResults(res *resources.Resources, hostname string) ([]data.UniqueIP, erro
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	ipsForHostnameQuery := []bson.M{
+	var ipResults []data.UniqueIP
+	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(ipResultsQuery(hostname)).AllowDiskUse().All(&ipResults)
+	if err != nil {
+		return ipResults, err
+	}
+
+	return labelIPResults(res, ipResults)
+}
+
+// IPResultsPage returns a page of the IP addresses the hostname was seen resolving to
+// along with the total number of them. The first skip results are skipped and at most
+// limit results are returned.
+func IPResultsPage(res *resources.Resources, hostname string, skip, limit int) ([]data.UniqueIP, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable)
+	iter, total, err := database.PipePage(collection, ipResultsQuery(hostname), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ipResults []data.UniqueIP
+	err = iter.All(&ipResults)
+	if err != nil {
+		return ipResults, 0, err
+	}
+
+	ipResults, err = labelIPResults(res, ipResults)
+	return ipResults, total, err
+}
+
+// ipResultsQuery lists the IP addresses the hostname resolved to, sorted by IP
+func ipResultsQuery(hostname string) []bson.M {
+	return []bson.M{
 		{"$match": bson.M{
 			"host": hostname,
 		}},
@@ -37,10 +73,17 @@ func IPResults(res *resources.Resources, hostname string) ([]data.UniqueIP, erro
 			"ip": 1,
 		}},
 	}
+}
 
-	var ipResults []data.UniqueIP
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(ipsForHostnameQuery).AllowDiskUse().All(&ipResults)
-	return ipResults, err
+// labelIPResults labels the IP addresses with the asset inventory
+func labelIPResults(res *resources.Resources, ipResults []data.UniqueIP) ([]data.UniqueIP, error) {
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return ipResults, err
+	}
+	inventory.Label(ipResults)
+
+	return ipResults, nil
 }
 
 // FQDNResults returns the FQDNs the IP address was seen resolving to in the dataset
@@ -48,16 +91,41 @@ func FQDNResults(res *resources.Resources, hostIP string) ([]*FQDNResult, error)
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	fqdnsForHostnameQuery := []bson.M{
+	var fqdnResults []*FQDNResult
+	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(fqdnResultsQuery(hostIP)).AllowDiskUse().All(&fqdnResults)
+	return fqdnResults, err
+}
+
+// FQDNResultsPage returns a page of the FQDNs the IP address was seen resolving to
+// along with the total number of them. The first skip results are skipped and at most
+// limit results are returned.
+func FQDNResultsPage(res *resources.Resources, hostIP string, skip, limit int) ([]*FQDNResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable)
+	iter, total, err := database.PipePage(collection, fqdnResultsQuery(hostIP), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var fqdnResults []*FQDNResult
+	err = iter.All(&fqdnResults)
+	return fqdnResults, total, err
+}
+
+// fqdnResultsQuery lists the FQDNs which resolved to the IP address. The FQDNs are
+// sorted so that pages of the results are stable.
+func fqdnResultsQuery(hostIP string) []bson.M {
+	return []bson.M{
 		{"$match": bson.M{
 			"dat.ips.ip": hostIP,
 		}},
 		{"$group": bson.M{
 			"_id": "$host",
 		}},
+		{"$sort": bson.M{
+			"_id": 1,
+		}},
 	}
-
-	var fqdnResults []*FQDNResult
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.DNS.HostnamesTable).Pipe(fqdnsForHostnameQuery).AllowDiskUse().All(&fqdnResults)
-	return fqdnResults, err
 }
@@ -0,0 +1,156 @@
+package lateral
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/globalsign/mgo/bson"
+)
+
+// maxPeerSample caps the number of peers recorded for each chunk
+const maxPeerSample = 50
+
+type (
+	//analyzer : structure for lateral movement analysis
+	analyzer struct {
+		chunk            int                        //current chunk (0 if not on rolling analysis)
+		db               *database.DB               // provides access to MongoDB
+		conf             *config.Config             // contains details needed to access MongoDB
+		analyzedCallback func(database.BulkChanges) // called on each analyzed result
+		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
+		analysisChannel  chan *Input                // holds unanalyzed data
+		analysisWg       sync.WaitGroup             // wait for analysis to finish
+	}
+)
+
+// newAnalyzer creates a new analyzer for profiling internal connections on administrative ports
+func newAnalyzer(chunk int, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+	return &analyzer{
+		chunk:            chunk,
+		db:               db,
+		conf:             conf,
+		analyzedCallback: analyzedCallback,
+		closedCallback:   closedCallback,
+		analysisChannel:  make(chan *Input),
+	}
+}
+
+// collect sends a host's internal connections to be analyzed
+func (a *analyzer) collect(datum *Input) {
+	a.analysisChannel <- datum
+}
+
+// close waits for the analyzer to finish
+func (a *analyzer) close() {
+	close(a.analysisChannel)
+	a.analysisWg.Wait()
+	a.closedCallback()
+}
+
+// start kicks off a new analysis thread
+func (a *analyzer) start() {
+	a.analysisWg.Add(1)
+	go func() {
+		ssn := a.db.Session.Copy()
+		defer ssn.Close()
+
+		for datum := range a.analysisChannel {
+			// gather the number of peers the host contacted in previous chunks
+			var existing struct {
+				Dat []struct {
+					CID   int   `bson:"cid"`
+					Peers int64 `bson:"peers"`
+				} `bson:"dat"`
+			}
+			_ = ssn.DB(a.db.GetSelectedDB()).C(a.conf.T.Lateral.LateralTable).
+				Find(datum.Host.BSONKey()).Select(bson.M{"dat.cid": 1, "dat.peers": 1}).One(&existing)
+
+			var history []int64
+			for _, prev := range existing.Dat {
+				if prev.CID != a.chunk {
+					history = append(history, prev.Peers)
+				}
+			}
+
+			peers := int64(len(datum.Peers))
+			baseline := averagePeers(history)
+			fanOut := isFanOut(peers, baseline, a.conf.S.LateralMovement)
+
+			datSubdoc := bson.M{
+				"cid":         a.chunk,
+				"peers":       peers,
+				"baseline":    baseline,
+				"fan_out":     fanOut,
+				"connections": datum.Connections,
+				"ports":       portCounts(datum),
+				"peer_sample": peerSample(datum),
+				"first_seen":  datum.FirstSeen,
+				"last_seen":   datum.LastSeen,
+			}
+
+			a.analyzedCallback(database.BulkChanges{
+				a.conf.T.Lateral.LateralTable: []database.BulkChange{{
+					Selector: datum.Host.BSONKey(),
+					Update: bson.M{
+						"$push": bson.M{"dat": datSubdoc},
+						"$set": bson.M{
+							"cid":          a.chunk,
+							"network_name": datum.Host.NetworkName,
+						},
+					},
+					Upsert: true,
+				}},
+			})
+		}
+		a.analysisWg.Done()
+	}()
+}
+
+// averagePeers returns the average number of peers contacted in previous chunks
+func averagePeers(history []int64) float64 {
+	if len(history) == 0 {
+		return 0
+	}
+	var total int64
+	for _, peers := range history {
+		total += peers
+	}
+	return float64(total) / float64(len(history))
+}
+
+// isFanOut returns true if a host contacted enough internal peers to meet the
+// configured threshold, and the number of peers is well above its baseline.
+// Hosts without any history are compared against the threshold alone.
+func isFanOut(peers int64, baseline float64, conf config.LateralMovementStaticCfg) bool {
+	if peers < int64(conf.FanOutThreshold) {
+		return false
+	}
+	return float64(peers) >= baseline*conf.FanOutRatio
+}
+
+// portCounts returns the number of peers contacted on each administrative port
+func portCounts(datum *Input) []PortCount {
+	counts := make([]PortCount, 0, len(datum.PortPeers))
+	for port, peers := range datum.PortPeers {
+		counts = append(counts, PortCount{Port: port, Peers: int64(len(peers))})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Port < counts[j].Port
+	})
+	return counts
+}
+
+// peerSample returns up to maxPeerSample of the peers contacted by the host
+func peerSample(datum *Input) []string {
+	sample := make([]string, 0, len(datum.Peers))
+	for _, peer := range datum.Peers {
+		sample = append(sample, peer.IP)
+	}
+	sort.Strings(sample)
+	if len(sample) > maxPeerSample {
+		sample = sample[:maxPeerSample]
+	}
+	return sample
+}
@@ -0,0 +1,31 @@
+package lateral
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFanOut(t *testing.T) {
+	conf := config.LateralMovementStaticCfg{FanOutThreshold: 10, FanOutRatio: 3}
+
+	testCases := []struct {
+		name     string
+		peers    int64
+		history  []int64
+		expected bool
+	}{
+		{"below threshold without history", 9, nil, false},
+		{"meets threshold without history", 10, nil, true},
+		{"steady host", 12, []int64{10, 14}, false},
+		{"sudden fan out", 40, []int64{2, 4}, true},
+		{"below threshold despite ratio", 6, []int64{1, 1}, false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, isFanOut(test.peers, averagePeers(test.history), conf))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package lateral
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+// Repository for lateral collection
+type Repository interface {
+	CreateIndexes() error
+	Upsert(lateralMap map[string]*Input)
+}
+
+// Input holds the internal peers a host connected to on administrative ports
+// during the current chunk
+type Input struct {
+	Host        data.UniqueIP            // the internal host making the connections
+	Peers       data.UniqueIPSet         // internal hosts contacted on administrative ports
+	PortPeers   map[int]data.UniqueIPSet // internal hosts contacted on each administrative port
+	Connections int64                    // number of connections made on administrative ports
+	FirstSeen   int64                    // timestamp of the first connection
+	LastSeen    int64                    // timestamp of the last connection
+}
+
+// PortCount is the number of internal peers contacted on an administrative port
+type PortCount struct {
+	Port  int   `bson:"port"`
+	Peers int64 `bson:"peers"`
+}
+
+// Result represents the internal connections made by a host on
+// administrative ports during a single chunk
+type Result struct {
+	data.UniqueIP `bson:",inline"`
+	CID           int         `bson:"cid"`
+	Peers         int64       `bson:"peers"`
+	Baseline      float64     `bson:"baseline"`
+	FanOut        bool        `bson:"fan_out"`
+	Connections   int64       `bson:"connections"`
+	Ports         []PortCount `bson:"ports"`
+	PeerSample    []string    `bson:"peer_sample"`
+	FirstSeen     int64       `bson:"first_seen"`
+	LastSeen      int64       `bson:"last_seen"`
+	Suppressed    bool        `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
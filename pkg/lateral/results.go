@@ -0,0 +1,91 @@
+package lateral
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the internal connections made by hosts on administrative ports in
+// each chunk, sorted by the number of peers contacted. If fanOutOnly is set, only the
+// chunks in which a host suddenly fanned out to many peers are returned. limit and
+// noLimit control how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, fanOutOnly bool, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Lateral.LateralTable).Pipe(resultsQuery(fanOutOnly)).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the lateral movement results along with the total number of
+// them. The first skip results are skipped and at most limit results are returned. Results
+// involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, fanOutOnly bool, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Lateral.LateralTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(fanOutOnly), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	lateralResults, err := collectResults(res, iter, 0, true, true)
+	return lateralResults, total, err
+}
+
+// resultsQuery flattens the administrative connections of each chunk, sorted by the number
+// of peers contacted. If fanOutOnly is set, only the chunks in which a host fanned out are kept.
+func resultsQuery(fanOutOnly bool) []bson.M {
+	lateralQuery := []bson.M{
+		{"$unwind": "$dat"},
+	}
+
+	if fanOutOnly {
+		lateralQuery = append(lateralQuery, bson.M{"$match": bson.M{"dat.fan_out": true}})
+	}
+
+	lateralQuery = append(lateralQuery,
+		bson.M{"$project": bson.M{
+			"_id":          0,
+			"ip":           1,
+			"network_uuid": 1,
+			"network_name": 1,
+			"cid":          "$dat.cid",
+			"peers":        "$dat.peers",
+			"baseline":     "$dat.baseline",
+			"fan_out":      "$dat.fan_out",
+			"connections":  "$dat.connections",
+			"ports":        "$dat.ports",
+			"peer_sample":  "$dat.peer_sample",
+			"first_seen":   "$dat.first_seen",
+			"last_seen":    "$dat.last_seen",
+		}},
+		bson.M{"$sort": bson.D{{Name: "peers", Value: -1}, {Name: "last_seen", Value: -1}}},
+	)
+
+	return lateralQuery
+}
+
+// collectResults reads the lateral movement results from iter and labels them with the asset
+// inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	lateralResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.IP)
+	})
+	if err != nil {
+		return lateralResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return lateralResults, err
+	}
+	inventory.Label(lateralResults)
+
+	return lateralResults, nil
+}
@@ -0,0 +1,27 @@
+package raretls
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+// Result represents a JA3, JA3S, and SNI combination used by few internal hosts
+type Result struct {
+	JA3         string          `bson:"ja3"`
+	JA3S        string          `bson:"ja3s"` // empty if the server never replied
+	FQDN        string          `bson:"fqdn"`
+	Hosts       []data.UniqueIP `bson:"hosts"`               // internal hosts which used the combination
+	SNIHosts    int             `bson:"sni_hosts"`           // internal hosts which connected to the SNI at all
+	FleetSize   int             `bson:"fleet_size"`          // internal hosts in the dataset
+	Connections int64           `bson:"conn_count"`          // connections made with the combination
+	Score       float64         `bson:"score"`               // 0-1, higher is rarer
+	Suppressed  bool            `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
+
+// combo holds the internal hosts which used a JA3, JA3S, and SNI combination
+type combo struct {
+	JA3         string          `bson:"ja3"`
+	JA3S        string          `bson:"ja3s"`
+	FQDN        string          `bson:"fqdn"`
+	Hosts       []data.UniqueIP `bson:"hosts"`
+	Connections int64           `bson:"conn_count"`
+}
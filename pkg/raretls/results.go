@@ -0,0 +1,231 @@
+package raretls
+
+import (
+	"net"
+	"sort"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results finds the JA3, JA3S, and SNI combinations used by at most MaxComboHosts
+// internal hosts when at most MaxSNIHosts internal hosts connected to the SNI.
+// The results are sorted by score in descending order. limit and noLimit control
+// how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	db := ssn.DB(res.DB.GetSelectedDB())
+	rareConf := res.Config.S.RareTLS
+
+	internalSubnets, err := util.ParseSubnets(res.Config.S.Filtering.InternalSubnets)
+	if err != nil {
+		return nil, err
+	}
+
+	comboQuery := []bson.M{
+		// find TLS connections which recorded JA3 hashes
+		{"$match": bson.M{"dat.tls.ja3_pairs.0": bson.M{"$exists": true}}},
+		{"$project": bson.M{
+			"src":              1,
+			"src_network_uuid": 1,
+			"src_network_name": 1,
+			"fqdn":             1,
+			"pairs":            "$dat.tls.ja3_pairs",
+		}},
+		// aggregate over time/ chunks
+		{"$unwind": "$pairs"},
+		{"$unwind": "$pairs"},
+		// remove duplicate hosts for each combination. network_name may
+		// change over time so it is left out when determining equality
+		{"$group": bson.M{
+			"_id": bson.M{
+				"ja3":              "$pairs.ja3",
+				"ja3s":             "$pairs.ja3s",
+				"fqdn":             "$fqdn",
+				"src":              "$src",
+				"src_network_uuid": "$src_network_uuid",
+			},
+			"src_network_name": bson.M{"$last": "$src_network_name"},
+			"conns":            bson.M{"$sum": "$pairs.count"},
+		}},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"ja3":  "$_id.ja3",
+				"ja3s": "$_id.ja3s",
+				"fqdn": "$_id.fqdn",
+			},
+			"hosts": bson.M{"$push": bson.M{
+				"ip":           "$_id.src",
+				"network_uuid": "$_id.src_network_uuid",
+				"network_name": "$src_network_name",
+			}},
+			"conn_count": bson.M{"$sum": "$conns"},
+		}},
+		// the hosts can't be limited to MaxComboHosts here since external sources
+		// are only dropped once the hosts are checked against the internal subnets
+		{"$project": bson.M{
+			"_id":        0,
+			"ja3":        "$_id.ja3",
+			"ja3s":       "$_id.ja3s",
+			"fqdn":       "$_id.fqdn",
+			"hosts":      1,
+			"conn_count": 1,
+		}},
+	}
+
+	var combos []combo
+	err = db.C(res.Config.T.Structure.SNIConnTable).Pipe(comboQuery).AllowDiskUse().All(&combos)
+	if err != nil {
+		return nil, err
+	}
+
+	combos = internalCombos(combos, internalSubnets, rareConf.MaxComboHosts)
+
+	fqdnSet := make(data.StringSet)
+	for _, c := range combos {
+		fqdnSet.Insert(c.FQDN)
+	}
+
+	// find how many hosts connected to each SNI regardless of the fingerprints involved
+	sniQuery := []bson.M{
+		{"$match": bson.M{"fqdn": bson.M{"$in": fqdnSet.Items()}}},
+		{"$group": bson.M{
+			"_id": "$fqdn",
+			"hosts": bson.M{"$addToSet": bson.M{
+				"ip":           "$src",
+				"network_uuid": "$src_network_uuid",
+			}},
+		}},
+	}
+
+	var sniDocs []struct {
+		FQDN  string          `bson:"_id"`
+		Hosts []data.UniqueIP `bson:"hosts"`
+	}
+	if len(fqdnSet) > 0 {
+		err = db.C(res.Config.T.Structure.SNIConnTable).Pipe(sniQuery).AllowDiskUse().All(&sniDocs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sniHosts := make(map[string]int, len(sniDocs))
+	for _, sniDoc := range sniDocs {
+		sniHosts[sniDoc.FQDN] = len(internalHosts(sniDoc.Hosts, internalSubnets))
+	}
+
+	fleetSize, err := db.C(res.Config.T.Structure.HostTable).Find(bson.M{"local": true}).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	rareResults, err := allowlist.Filter(res, scoreCombos(combos, sniHosts, fleetSize, rareConf), limit, noLimit, showSuppressed,
+		func(allowed *allowlist.Allowlist, r *Result) bool {
+			return allowed.ContainsJA3(r.JA3) || allowed.ContainsDomain(r.FQDN)
+		})
+	if err != nil {
+		return rareResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return rareResults, err
+	}
+	inventory.Label(rareResults)
+
+	return rareResults, nil
+}
+
+// scoreCombos keeps the combinations which satisfy the RareTLS thresholds and scores
+// them by how few of the fleetSize internal hosts used the combination and the SNI.
+// The score is the mean of 1 - (combination hosts / fleet size) and 1 - (SNI hosts / fleet size).
+func scoreCombos(combos []combo, sniHosts map[string]int, fleetSize int, conf config.RareTLSStaticCfg) []Result {
+	results := []Result{}
+	for _, c := range combos {
+		comboHosts := len(c.Hosts)
+		if comboHosts == 0 || comboHosts > conf.MaxComboHosts {
+			continue
+		}
+
+		// every host which used the combination connected to the SNI
+		sniCount := sniHosts[c.FQDN]
+		if sniCount < comboHosts {
+			sniCount = comboHosts
+		}
+		if sniCount > conf.MaxSNIHosts {
+			continue
+		}
+
+		fleet := fleetSize
+		if fleet < sniCount {
+			fleet = sniCount
+		}
+
+		comboRarity := 1 - float64(comboHosts)/float64(fleet)
+		sniRarity := 1 - float64(sniCount)/float64(fleet)
+
+		results = append(results, Result{
+			JA3:         c.JA3,
+			JA3S:        c.JA3S,
+			FQDN:        c.FQDN,
+			Hosts:       c.Hosts,
+			SNIHosts:    sniCount,
+			FleetSize:   fleet,
+			Connections: c.Connections,
+			Score:       (comboRarity + sniRarity) / 2,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Connections != results[j].Connections {
+			return results[i].Connections > results[j].Connections
+		}
+		if results[i].FQDN != results[j].FQDN {
+			return results[i].FQDN < results[j].FQDN
+		}
+		if results[i].JA3 != results[j].JA3 {
+			return results[i].JA3 < results[j].JA3
+		}
+		return results[i].JA3S < results[j].JA3S
+	})
+	return results
+}
+
+// internalCombos limits the hosts of each combination to those within the internal
+// subnets and keeps the combinations used by between 1 and maxComboHosts internal hosts.
+// The SNIconn collection holds external sources if FilterExternalToInternal is disabled,
+// so the threshold must be applied after the external hosts are dropped.
+func internalCombos(combos []combo, internalSubnets []*net.IPNet, maxComboHosts int) []combo {
+	var kept []combo
+	for _, c := range combos {
+		c.Hosts = internalHosts(c.Hosts, internalSubnets)
+		if len(c.Hosts) == 0 || len(c.Hosts) > maxComboHosts {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// internalHosts returns the hosts which fall within the internal subnets
+func internalHosts(hosts []data.UniqueIP, internalSubnets []*net.IPNet) []data.UniqueIP {
+	var internal []data.UniqueIP
+	for _, host := range hosts {
+		ip := net.ParseIP(host.IP)
+		if ip != nil && util.ContainsIP(internalSubnets, ip) {
+			internal = append(internal, host)
+		}
+	}
+	return internal
+}
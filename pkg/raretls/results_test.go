@@ -0,0 +1,85 @@
+package raretls
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreCombos(t *testing.T) {
+	hostA := data.UniqueIP{IP: "10.0.0.1"}
+	hostB := data.UniqueIP{IP: "10.0.0.2"}
+	hostC := data.UniqueIP{IP: "10.0.0.3"}
+
+	combos := []combo{
+		// one host talking to an SNI nobody else uses
+		{JA3: "a", JA3S: "b", FQDN: "rare.example", Hosts: []data.UniqueIP{hostA}, Connections: 10},
+		// a rare fingerprint to a popular SNI
+		{JA3: "c", JA3S: "d", FQDN: "popular.example", Hosts: []data.UniqueIP{hostB}, Connections: 20},
+		// two hosts sharing a combination to a quiet SNI
+		{JA3: "e", JA3S: "", FQDN: "quiet.example", Hosts: []data.UniqueIP{hostA, hostB}, Connections: 5},
+		// too many hosts share the combination
+		{JA3: "f", JA3S: "g", FQDN: "quiet.example", Hosts: []data.UniqueIP{hostA, hostB, hostC}, Connections: 7},
+	}
+	sniHosts := map[string]int{
+		"popular.example": 8,
+		"quiet.example":   3,
+	}
+
+	results := scoreCombos(combos, sniHosts, 10, config.RareTLSStaticCfg{MaxComboHosts: 2, MaxSNIHosts: 5})
+	require.Len(t, results, 2)
+
+	require.Equal(t, "rare.example", results[0].FQDN)
+	require.Equal(t, 1, results[0].SNIHosts)
+	require.Equal(t, 10, results[0].FleetSize)
+	require.InDelta(t, 0.9, results[0].Score, 1e-9)
+
+	require.Equal(t, "quiet.example", results[1].FQDN)
+	require.Equal(t, "e", results[1].JA3)
+	require.Equal(t, 3, results[1].SNIHosts)
+	require.InDelta(t, 0.75, results[1].Score, 1e-9)
+
+	// the fleet can't be smaller than the hosts seen
+	results = scoreCombos(combos[:1], nil, 0, config.RareTLSStaticCfg{MaxComboHosts: 2, MaxSNIHosts: 5})
+	require.Len(t, results, 1)
+	require.Equal(t, 1, results[0].FleetSize)
+	require.Zero(t, results[0].Score)
+}
+
+func TestInternalHosts(t *testing.T) {
+	subnets, err := util.ParseSubnets([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	hosts := internalHosts([]data.UniqueIP{{IP: "10.1.1.1"}, {IP: "8.8.8.8"}, {IP: "bogus"}}, subnets)
+	require.Equal(t, []data.UniqueIP{{IP: "10.1.1.1"}}, hosts)
+}
+
+func TestInternalCombos(t *testing.T) {
+	subnets, err := util.ParseSubnets([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	internalA := data.UniqueIP{IP: "10.0.0.1"}
+	internalB := data.UniqueIP{IP: "10.0.0.2"}
+	internalC := data.UniqueIP{IP: "10.0.0.3"}
+	external := []data.UniqueIP{{IP: "8.8.8.8"}, {IP: "1.1.1.1"}, {IP: "9.9.9.9"}}
+
+	combos := []combo{
+		// seen from two internal hosts and many external sources
+		{JA3: "a", FQDN: "rare.example", Hosts: append([]data.UniqueIP{internalA, internalB}, external...)},
+		// only seen from external sources
+		{JA3: "b", FQDN: "rare.example", Hosts: external},
+		// too many internal hosts
+		{JA3: "c", FQDN: "common.example", Hosts: []data.UniqueIP{internalA, internalB, internalC}},
+	}
+
+	kept := internalCombos(combos, subnets, 2)
+	require.Len(t, kept, 1)
+	require.Equal(t, "a", kept[0].JA3)
+	require.Equal(t, []data.UniqueIP{internalA, internalB}, kept[0].Hosts)
+
+	// the original combinations are left untouched
+	require.Len(t, combos[0].Hosts, 5)
+}
@@ -102,14 +102,20 @@ func (r *remover) removeOutdatedCIDs(cid int) error {
 		r.config.T.Beacon.BeaconTable,
 		r.config.T.BeaconProxy.BeaconProxyTable,
 		r.config.T.BeaconSNI.BeaconSNITable,
+		r.config.T.BeaconDNS.BeaconDNSTable,
 		r.config.T.Structure.HostTable,
 		r.config.T.Structure.UniqueConnTable,
 		r.config.T.Structure.UniqueConnProxyTable,
+		r.config.T.Structure.UniqueConnDNSTable,
 		r.config.T.Structure.SNIConnTable,
 		r.config.T.DNS.ExplodedDNSTable,
 		r.config.T.DNS.HostnamesTable,
+		r.config.T.DNS.DNSTunnelTable,
 		r.config.T.Cert.CertificateTable,
 		r.config.T.UserAgent.UserAgentTable,
+		r.config.T.Scan.ScanTable,
+		r.config.T.Lateral.LateralTable,
+		r.config.T.Exfil.ExfilTable,
 	}
 
 	//Create the workers
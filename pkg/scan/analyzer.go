@@ -0,0 +1,175 @@
+package scan
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	// failedTargetWeight is how much a target counts towards the scan score
+	// when every connection made to it was rejected or went unanswered
+	failedTargetWeight = 1.0
+
+	// answeredTargetWeight is how much a target counts towards the scan score
+	// when every connection made to it was answered
+	answeredTargetWeight = 0.05
+
+	// maxSampleTargets caps the number of targets recorded for each scan window
+	maxSampleTargets = 50
+)
+
+type (
+	//analyzer : structure for port scan analysis
+	analyzer struct {
+		chunk            int                        //current chunk (0 if not on rolling analysis)
+		db               *database.DB               // provides access to MongoDB
+		conf             *config.Config             // contains details needed to access MongoDB
+		analyzedCallback func(database.BulkChanges) // called on each analyzed result
+		closedCallback   func()                     // called when .close() is called and no more calls to analyzedCallback will be made
+		analysisChannel  chan *Input                // holds unanalyzed data
+		analysisWg       sync.WaitGroup             // wait for analysis to finish
+	}
+
+	// window summarizes the targets contacted by a source during a single chunk
+	window struct {
+		CID         int      `bson:"cid"`
+		Start       int64    `bson:"start"`
+		End         int64    `bson:"end"`
+		Targets     int64    `bson:"targets"`
+		Connections int64    `bson:"connections"`
+		Failed      int64    `bson:"failed"`
+		Score       float64  `bson:"score"`
+		Sample      []string `bson:"sample"`
+	}
+)
+
+// newAnalyzer creates a new analyzer for finding port scans
+func newAnalyzer(chunk int, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+	return &analyzer{
+		chunk:            chunk,
+		db:               db,
+		conf:             conf,
+		analyzedCallback: analyzedCallback,
+		closedCallback:   closedCallback,
+		analysisChannel:  make(chan *Input),
+	}
+}
+
+// collect sends a group of scan targets to be analyzed
+func (a *analyzer) collect(datum *Input) {
+	a.analysisChannel <- datum
+}
+
+// close waits for the analyzer to finish
+func (a *analyzer) close() {
+	close(a.analysisChannel)
+	a.analysisWg.Wait()
+	a.closedCallback()
+}
+
+// start kicks off a new analysis thread
+func (a *analyzer) start() {
+	a.analysisWg.Add(1)
+	go func() {
+
+		for datum := range a.analysisChannel {
+			threshold := a.conf.S.Scan.VerticalThreshold
+			if datum.Type == HorizontalScan {
+				threshold = a.conf.S.Scan.HorizontalThreshold
+			}
+
+			// the number of targets alone can't reach the threshold
+			if float64(len(datum.Targets)) < threshold {
+				continue
+			}
+
+			scanWindow := summarizeWindow(datum, a.chunk)
+			if scanWindow.Score < threshold {
+				continue
+			}
+
+			setDoc := bson.M{
+				"cid":              a.chunk,
+				"src_network_name": datum.Src.NetworkName,
+			}
+			if datum.Type == VerticalScan {
+				setDoc["dst_network_name"] = datum.Dst.NetworkName
+			}
+
+			a.analyzedCallback(database.BulkChanges{
+				a.conf.T.Scan.ScanTable: []database.BulkChange{{
+					Selector: scanSelector(datum),
+					Update: bson.M{
+						"$push": bson.M{"dat": scanWindow},
+						"$set":  setDoc,
+					},
+					Upsert: true,
+				}},
+			})
+		}
+		a.analysisWg.Done()
+	}()
+}
+
+// scanSelector returns the selector for the scan collection entry which records the given scan
+func scanSelector(datum *Input) bson.M {
+	selector := bson.M{
+		"type":             datum.Type,
+		"src":              datum.Src.IP,
+		"src_network_uuid": datum.Src.NetworkUUID,
+	}
+	if datum.Type == VerticalScan {
+		selector["dst"] = datum.Dst.IP
+		selector["dst_network_uuid"] = datum.Dst.NetworkUUID
+	} else {
+		selector["port"] = datum.Port
+		selector["proto"] = datum.Proto
+	}
+	return selector
+}
+
+// summarizeWindow scores the targets contacted by a source in the current chunk.
+// Each target contributes between answeredTargetWeight and failedTargetWeight
+// to the score depending on the fraction of its connections which failed.
+func summarizeWindow(datum *Input, chunk int) window {
+	scanWindow := window{
+		CID:     chunk,
+		Start:   datum.FirstSeen,
+		End:     datum.LastSeen,
+		Targets: int64(len(datum.Targets)),
+	}
+
+	probes := make([]*Probe, 0, len(datum.Targets))
+	score := 0.0
+	for _, probe := range datum.Targets {
+		scanWindow.Connections += probe.Connections
+		scanWindow.Failed += probe.Failed
+
+		failedRatio := float64(probe.Failed) / float64(probe.Connections)
+		score += failedRatio*failedTargetWeight + (1-failedRatio)*answeredTargetWeight
+
+		probes = append(probes, probe)
+	}
+	scanWindow.Score = math.Ceil(score*1000) / 1000
+
+	// record the targets which look the most like scan probes
+	sort.Slice(probes, func(i, j int) bool {
+		if probes[i].Failed != probes[j].Failed {
+			return probes[i].Failed > probes[j].Failed
+		}
+		return probes[i].Target < probes[j].Target
+	})
+	if len(probes) > maxSampleTargets {
+		probes = probes[:maxSampleTargets]
+	}
+	for _, probe := range probes {
+		scanWindow.Sample = append(scanWindow.Sample, probe.Target)
+	}
+
+	return scanWindow
+}
@@ -0,0 +1,43 @@
+package scan
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeWindow(t *testing.T) {
+	datum := &Input{
+		Type:      VerticalScan,
+		Targets:   make(map[string]*Probe),
+		FirstSeen: 100,
+		LastSeen:  200,
+	}
+
+	// 100 closed ports and 20 open ports
+	for port := 1; port <= 120; port++ {
+		target := strconv.Itoa(port) + ":tcp"
+		probe := &Probe{Target: target, Connections: 1}
+		if port <= 100 {
+			probe.Failed = 1
+		}
+		datum.Targets[target] = probe
+	}
+
+	scanWindow := summarizeWindow(datum, 3)
+
+	require.Equal(t, 3, scanWindow.CID)
+	require.Equal(t, int64(100), scanWindow.Start)
+	require.Equal(t, int64(200), scanWindow.End)
+	require.Equal(t, int64(120), scanWindow.Targets)
+	require.Equal(t, int64(120), scanWindow.Connections)
+	require.Equal(t, int64(100), scanWindow.Failed)
+	require.Equal(t, 101.0, scanWindow.Score)
+
+	// the sample is capped and favors the failed probes
+	require.Len(t, scanWindow.Sample, maxSampleTargets)
+	for _, target := range scanWindow.Sample {
+		require.Equal(t, int64(1), datum.Targets[target].Failed)
+	}
+}
@@ -0,0 +1,57 @@
+package scan
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+const (
+	// VerticalScan is a source contacting many ports on a single destination
+	VerticalScan = "vertical"
+	// HorizontalScan is a source contacting many destinations on a single port
+	HorizontalScan = "horizontal"
+)
+
+// Repository for scan collection
+type Repository interface {
+	CreateIndexes() error
+	Upsert(scanMap map[string]*Input)
+}
+
+// Input holds the targets contacted by a source during the current chunk.
+// Vertical scans target the ports of a single destination, while horizontal
+// scans target the destinations listening on a single port.
+type Input struct {
+	Type      string            // VerticalScan or HorizontalScan
+	Src       data.UniqueIP     // the host performing the scan
+	Dst       data.UniqueIP     // the host being scanned (vertical scans only)
+	Port      int               // the port being scanned (horizontal scans only)
+	Proto     string            // the transport protocol being scanned (horizontal scans only)
+	Targets   map[string]*Probe // ports (vertical) or destinations (horizontal) contacted
+	FirstSeen int64             // timestamp of the first connection
+	LastSeen  int64             // timestamp of the last connection
+}
+
+// Probe tracks the connections made to a single scan target
+type Probe struct {
+	Target      string // port:protocol (vertical) or IP address (horizontal)
+	Connections int64  // number of connections made to the target
+	Failed      int64  // number of connections which were rejected or went unanswered
+}
+
+// Result represents a port scan and the windows of time in which it was seen
+type Result struct {
+	data.UniqueSrcIP  `bson:",inline"`
+	data.UniqueDstIP  `bson:",inline"`
+	Type              string   `bson:"type"`
+	Port              int      `bson:"port"`
+	Proto             string   `bson:"proto"`
+	Score             float64  `bson:"score"`
+	Targets           int64    `bson:"targets"`
+	Connections       int64    `bson:"connections"`
+	FailedConnections int64    `bson:"failed"`
+	Windows           int      `bson:"windows"`
+	Start             int64    `bson:"start"`
+	End               int64    `bson:"end"`
+	SampleTargets     []string `bson:"sample"`
+	Suppressed        bool     `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the port scans of the given type (VerticalScan, HorizontalScan,
+// or "" for both) sorted by the highest score of any scan window. limit and
+// noLimit control how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, scanType string, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Scan.ScanTable).Pipe(resultsQuery(scanType)).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the port scans of the given type along with the total number
+// of them. The first skip results are skipped and at most limit results are returned.
+// Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, scanType string, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Scan.ScanTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(scanType), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	scanResults, err := collectResults(res, iter, 0, true, true)
+	return scanResults, total, err
+}
+
+// resultsQuery summarizes the scan windows of each port scan of the given type, sorted by score
+func resultsQuery(scanType string) []bson.M {
+	match := bson.M{}
+	if scanType != "" {
+		match["type"] = scanType
+	}
+
+	scanQuery := []bson.M{
+		{"$match": match},
+		{"$project": bson.M{
+			"_id":              0,
+			"type":             1,
+			"src":              1,
+			"src_network_uuid": 1,
+			"src_network_name": 1,
+			"dst":              1,
+			"dst_network_uuid": 1,
+			"dst_network_name": 1,
+			"port":             1,
+			"proto":            1,
+			"score":            bson.M{"$max": "$dat.score"},
+			"targets":          bson.M{"$max": "$dat.targets"},
+			"connections":      bson.M{"$sum": "$dat.connections"},
+			"failed":           bson.M{"$sum": "$dat.failed"},
+			"windows":          bson.M{"$size": "$dat"},
+			"start":            bson.M{"$min": "$dat.start"},
+			"end":              bson.M{"$max": "$dat.end"},
+			"sample":           bson.M{"$arrayElemAt": []interface{}{"$dat.sample", -1}},
+		}},
+		{"$sort": bson.M{"score": -1}},
+	}
+
+	return scanQuery
+}
+
+// collectResults reads the port scans from iter and labels them with the asset inventory.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	scanResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP)
+	})
+	if err != nil {
+		return scanResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return scanResults, err
+	}
+	inventory.Label(scanResults)
+
+	return scanResults, nil
+}
@@ -1,10 +1,12 @@
 package sniconn
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/globalsign/mgo/bson"
 )
@@ -14,6 +16,7 @@ type (
 	analyzer struct {
 		chunk            int                        //current chunk (0 if not on rolling analysis)
 		connLimit        int64                      // limit for strobe classification
+		fingerprints     *blacklist.Fingerprints    // blacklisted JA3, JA3S, and certificate fingerprints
 		db               *database.DB               // provides access to MongoDB
 		conf             *config.Config             // contains details needed to access MongoDB
 		analyzedCallback func(database.BulkChanges) // called on each analyzed result
@@ -24,10 +27,11 @@ type (
 )
 
 // newAnalyzer creates a new analyzer for recording sni connection records
-func newAnalyzer(chunk int, connLimit int64, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
+func newAnalyzer(chunk int, connLimit int64, fingerprints *blacklist.Fingerprints, db *database.DB, conf *config.Config, analyzedCallback func(database.BulkChanges), closedCallback func()) *analyzer {
 	return &analyzer{
 		chunk:            chunk,
 		connLimit:        connLimit,
+		fingerprints:     fingerprints,
 		db:               db,
 		conf:             conf,
 		analyzedCallback: analyzedCallback,
@@ -63,7 +67,7 @@ func (a *analyzer) start() {
 			}
 
 			netNameUpdate := mainQuery(selector, a.chunk)
-			tlsUpdate := tlsQuery(datum.TLS, datum.TLSZeekRecords, a.connLimit, a.chunk)
+			tlsUpdate := tlsQuery(datum.TLS, datum.TLSZeekRecords, a.fingerprints, a.connLimit, a.chunk)
 			httpUpdate := httpQuery(datum.HTTP, datum.HTTPZeekRecords, a.connLimit, a.chunk)
 
 			totalUpdate := database.MergeBSONMaps(netNameUpdate, tlsUpdate, httpUpdate)
@@ -90,7 +94,7 @@ func mainQuery(selector data.UniqueSrcFQDNPair, chunk int) bson.M {
 	}
 }
 
-func tlsQuery(datum *TLSInput, zeekRecords []*data.ZeekUIDRecord, strobeLimit int64, chunk int) bson.M {
+func tlsQuery(datum *TLSInput, zeekRecords []*data.ZeekUIDRecord, fingerprints *blacklist.Fingerprints, strobeLimit int64, chunk int) bson.M {
 	if datum == nil {
 		return bson.M{}
 	}
@@ -116,6 +120,9 @@ func tlsQuery(datum *TLSInput, zeekRecords []*data.ZeekUIDRecord, strobeLimit in
 		bytes = []int64{}
 	}
 
+	// flag the JA3, JA3S, and certificate fingerprints which appear on a blacklist
+	blFingerprints := fingerprints.Match(datum.JA3s.Items(), datum.JA3Ss.Items(), datum.CertFingerprints.Items())
+
 	return bson.M{
 		"$push": bson.M{
 			"dat": bson.M{
@@ -135,6 +142,8 @@ func tlsQuery(datum *TLSInput, zeekRecords []*data.ZeekUIDRecord, strobeLimit in
 						"subjects":         datum.Subjects.Items(),
 						"ja3":              datum.JA3s.Items(),
 						"ja3s":             datum.JA3Ss.Items(),
+						"bl_fingerprints":  blFingerprints,
+						"ja3_pairs":        ja3PairCounts(datum.JA3Pairs),
 					},
 				}},
 			},
@@ -143,6 +152,26 @@ func tlsQuery(datum *TLSInput, zeekRecords []*data.ZeekUIDRecord, strobeLimit in
 
 }
 
+// ja3PairCounts lists the connection counts of each JA3/ JA3S pairing in a stable order
+func ja3PairCounts(pairs map[JA3Pair]int64) []bson.M {
+	keys := make([]JA3Pair, 0, len(pairs))
+	for pair := range pairs {
+		keys = append(keys, pair)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].JA3 != keys[j].JA3 {
+			return keys[i].JA3 < keys[j].JA3
+		}
+		return keys[i].JA3S < keys[j].JA3S
+	})
+
+	counts := make([]bson.M, 0, len(keys))
+	for _, pair := range keys {
+		counts = append(counts, bson.M{"ja3": pair.JA3, "ja3s": pair.JA3S, "count": pairs[pair]})
+	}
+	return counts
+}
+
 func httpQuery(datum *HTTPInput, zeekRecords []*data.ZeekUIDRecord, strobeLimit int64, chunk int) bson.M {
 	if datum == nil {
 		return bson.M{}
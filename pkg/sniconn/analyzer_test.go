@@ -0,0 +1,56 @@
+package sniconn
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+func testZeekRecord(origBytes, respBytes, respIPBytes int64) *data.ZeekUIDRecord {
+	record := &data.ZeekUIDRecord{}
+	record.Conn.OrigBytes = origBytes
+	record.Conn.RespBytes = respBytes
+	record.Conn.RespIPBytes = respIPBytes
+	record.Conn.Duration = 1
+	return record
+}
+
+func TestQueriesTotalBytes(t *testing.T) {
+	zeekRecords := []*data.ZeekUIDRecord{
+		testZeekRecord(100, 1000, 1200),
+		testZeekRecord(50, 500, 600),
+	}
+
+	tlsInput := &TLSInput{
+		ConnectionCount: 2,
+		Timestamps:      []int64{1, 2},
+		RespondingIPs:   make(data.UniqueIPSet),
+		RespondingPorts: make(data.IntSet),
+		Subjects:        make(data.StringSet),
+		JA3s:            make(data.StringSet),
+		JA3Ss:           make(data.StringSet),
+	}
+	httpInput := &HTTPInput{
+		ConnectionCount: 2,
+		Timestamps:      []int64{1, 2},
+		RespondingIPs:   make(data.UniqueIPSet),
+		RespondingPorts: make(data.IntSet),
+		Methods:         make(data.StringSet),
+		UserAgents:      make(data.StringSet),
+	}
+
+	datEntry := func(query bson.M, field string) bson.M {
+		return query["$push"].(bson.M)["dat"].(bson.M)["$each"].([]bson.M)[0][field].(bson.M)
+	}
+
+	// the total counts the originator's IP bytes and the responder's payload bytes
+	tls := datEntry(tlsQuery(tlsInput, zeekRecords, &blacklist.Fingerprints{}, 100, 0), "tls")
+	require.Equal(t, int64(1650), tls["tbytes"])
+	require.Equal(t, []int64{100, 50}, tls["bytes"])
+
+	http := datEntry(httpQuery(httpInput, zeekRecords, 100, 0), "http")
+	require.Equal(t, int64(1650), http["tbytes"])
+}
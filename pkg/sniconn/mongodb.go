@@ -5,6 +5,7 @@ import (
 
 	"github.com/activecm/rita-legacy/config"
 	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/pkg/data"
 	"github.com/activecm/rita-legacy/pkg/host"
 	"github.com/activecm/rita-legacy/util"
@@ -54,6 +55,7 @@ func (r *repo) CreateIndexes() error {
 		{Key: []string{"fqdn"}},
 		{Key: []string{"dat.http.count"}},
 		{Key: []string{"dat.tls.count"}},
+		{Key: []string{"dat.tls.bl_fingerprints.hash"}},
 	}
 
 	// create collection
@@ -74,12 +76,19 @@ func (r *repo) Upsert(tlsMap map[string]*TLSInput, httpMap map[string]*HTTPInput
 	// Merge separate input maps from the parser
 	linkedInputMap := linkInputMaps(tlsMap, httpMap, zeekUIDMap)
 
+	// Load the blacklisted TLS fingerprints. Lists which can't be read are skipped.
+	fingerprints, err := blacklist.LoadFingerprints(r.config)
+	if err != nil {
+		r.log.Error(err)
+	}
+
 	// Create the workers for analysis
 	writerWorker := database.NewBulkWriter(r.database, r.config, r.log, true, "sniconn")
 
 	analyzerWorker := newAnalyzer(
 		r.config.S.Rolling.CurrentChunk,
 		int64(r.config.S.Strobe.ConnectionLimit),
+		fingerprints,
 		r.database,
 		r.config,
 		writerWorker.Collect,
@@ -34,9 +34,24 @@ type TLSInput struct {
 	JA3s                  data.StringSet
 	JA3Ss                 data.StringSet
 
+	// LeafCertIDs holds the file ids the ssl log uses to refer to the server's
+	// certificate. They are resolved to CertFingerprints via the x509 log.
+	LeafCertIDs      data.StringSet
+	CertFingerprints data.StringSet
+
+	// JA3Pairs counts the connections made with each client/ server fingerprint pairing
+	JA3Pairs map[JA3Pair]int64
+
 	ZeekUIDs []string
 }
 
+// JA3Pair is the JA3 hash of a client hello and the JA3S hash of the server's reply.
+// JA3S is empty if the server didn't respond.
+type JA3Pair struct {
+	JA3  string
+	JA3S string
+}
+
 type HTTPInput struct {
 	Hosts data.UniqueSrcFQDNPair
 
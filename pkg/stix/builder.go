@@ -0,0 +1,355 @@
+package stix
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/beaconproxy"
+	"github.com/activecm/rita-legacy/pkg/beaconsni"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+)
+
+// Confidence assigned to findings which don't carry a score of their own.
+// Beacons use their score scaled to the 0-100 STIX confidence range.
+const (
+	// BlacklistConfidence is used for hosts found on threat intel lists
+	BlacklistConfidence = 90
+	// StrobeConfidence is used for strobes, which exceed the configured connection
+	// count threshold but may be caused by benign software
+	StrobeConfidence = 50
+)
+
+// maxCount is the largest count allowed by STIX for sightings and observed data
+const maxCount = 999999999
+
+// finding is a single RITA result expressed in terms of STIX objects
+type finding struct {
+	key           string      // unique within the dataset
+	description   string      // describes the finding on the sighting
+	indicatorType string      // anomalous-activity or malicious-activity
+	value         string      // IP address or domain name the indicator matches
+	confidence    int         // 0-100
+	count         int64       // number of connections behind the finding
+	traffic       [][2]string // source and destination of each network flow
+	protocol      string      // application protocol of the traffic, if known
+	extraRefs     []string    // addresses involved in the finding but not the traffic
+}
+
+// Builder accumulates RITA findings from one dataset into a STIX bundle.
+// Findings matching the same indicator share it, and the indicator keeps
+// the highest confidence among them.
+type Builder struct {
+	dataset       string
+	created       string
+	firstObserved string
+	lastObserved  string
+	identity      Identity
+
+	indicators     map[string]*Indicator
+	indicatorOrder []string
+	observables    map[string]bool
+	observableObjs []interface{}
+	observations   []interface{}
+}
+
+// NewBuilder creates a Builder for the given dataset. tsMin and tsMax bound
+// the timestamps of the dataset and are used as the time range of every
+// observation. created is recorded as the creation time of each object.
+func NewBuilder(dataset string, tsMin, tsMax int64, created time.Time) *Builder {
+	createdStr := created.UTC().Format(timestampFormat)
+	return &Builder{
+		dataset:       dataset,
+		created:       createdStr,
+		firstObserved: timestamp(tsMin),
+		lastObserved:  timestamp(tsMax),
+		identity: Identity{
+			Type:          "identity",
+			SpecVersion:   SpecVersion,
+			ID:            domainObjectID("identity", "", "rita"),
+			Created:       createdStr,
+			Modified:      createdStr,
+			Name:          "RITA",
+			IdentityClass: "system",
+		},
+		indicators:  make(map[string]*Indicator),
+		observables: make(map[string]bool),
+	}
+}
+
+// AddBeacon adds a beacon between two IP addresses
+func (b *Builder) AddBeacon(r beacon.Result) {
+	b.add(finding{
+		key:           "beacon|" + r.SrcIP + "|" + r.DstIP,
+		description:   "Beacon from " + r.SrcIP + " to " + r.DstIP + " with score " + strconv.FormatFloat(r.Score, 'f', 3, 64),
+		indicatorType: anomalousActivity,
+		value:         r.DstIP,
+		confidence:    scoreConfidence(r.Score),
+		count:         r.Connections,
+		traffic:       [][2]string{{r.SrcIP, r.DstIP}},
+	})
+}
+
+// AddBeaconSNI adds a beacon from an IP address to an FQDN seen in TLS SNI or HTTP Host headers
+func (b *Builder) AddBeaconSNI(r beaconsni.Result) {
+	b.add(finding{
+		key:           "sni-beacon|" + r.SrcIP + "|" + r.FQDN,
+		description:   "SNI beacon from " + r.SrcIP + " to " + r.FQDN + " with score " + strconv.FormatFloat(r.Score, 'f', 3, 64),
+		indicatorType: anomalousActivity,
+		value:         r.FQDN,
+		confidence:    scoreConfidence(r.Score),
+		count:         r.Connections,
+		traffic:       [][2]string{{r.SrcIP, r.FQDN}},
+		protocol:      "tls",
+	})
+}
+
+// AddBeaconProxy adds a beacon from an IP address to an FQDN through an HTTP proxy
+func (b *Builder) AddBeaconProxy(r beaconproxy.Result) {
+	b.add(finding{
+		key:           "proxy-beacon|" + r.SrcIP + "|" + r.FQDN,
+		description:   "Proxy beacon from " + r.SrcIP + " to " + r.FQDN + " via " + r.Proxy.IP + " with score " + strconv.FormatFloat(r.Score, 'f', 3, 64),
+		indicatorType: anomalousActivity,
+		value:         r.FQDN,
+		confidence:    scoreConfidence(r.Score),
+		count:         r.Connections,
+		traffic:       [][2]string{{r.SrcIP, r.Proxy.IP}},
+		protocol:      "http",
+		extraRefs:     []string{r.FQDN},
+	})
+}
+
+// AddBlacklistedSrcIP adds a blacklisted IP address which initiated connections to its peers
+func (b *Builder) AddBlacklistedSrcIP(r blacklist.IPResult) {
+	var traffic [][2]string
+	for _, peer := range r.Peers {
+		traffic = append(traffic, [2]string{r.Host.IP, peer.IP})
+	}
+	b.add(finding{
+		key:           "bl-source-ip|" + r.Host.IP,
+		description:   "Blacklisted IP " + r.Host.IP + " connected to " + strconv.Itoa(len(r.Peers)) + " hosts",
+		indicatorType: maliciousActivity,
+		value:         r.Host.IP,
+		confidence:    BlacklistConfidence,
+		count:         int64(r.Connections),
+		traffic:       traffic,
+	})
+}
+
+// AddBlacklistedDstIP adds a blacklisted IP address which received connections from its peers
+func (b *Builder) AddBlacklistedDstIP(r blacklist.IPResult) {
+	var traffic [][2]string
+	for _, peer := range r.Peers {
+		traffic = append(traffic, [2]string{peer.IP, r.Host.IP})
+	}
+	b.add(finding{
+		key:           "bl-dest-ip|" + r.Host.IP,
+		description:   "Blacklisted IP " + r.Host.IP + " was contacted by " + strconv.Itoa(len(r.Peers)) + " hosts",
+		indicatorType: maliciousActivity,
+		value:         r.Host.IP,
+		confidence:    BlacklistConfidence,
+		count:         int64(r.Connections),
+		traffic:       traffic,
+	})
+}
+
+// AddBlacklistedHostname adds a blacklisted hostname and the hosts which connected to it
+func (b *Builder) AddBlacklistedHostname(r blacklist.HostnameResult) {
+	var traffic [][2]string
+	for _, src := range r.ConnectedHosts {
+		traffic = append(traffic, [2]string{src.IP, r.Host})
+	}
+	b.add(finding{
+		key:           "bl-hostname|" + r.Host,
+		description:   "Blacklisted hostname " + r.Host + " was contacted by " + strconv.Itoa(len(r.ConnectedHosts)) + " hosts",
+		indicatorType: maliciousActivity,
+		value:         r.Host,
+		confidence:    BlacklistConfidence,
+		count:         int64(r.Connections),
+		traffic:       traffic,
+	})
+}
+
+// AddStrobe adds a pair of hosts which connected to each other an excessive number of times
+func (b *Builder) AddStrobe(r beacon.StrobeResult) {
+	b.add(finding{
+		key:           "strobe|" + r.SrcIP + "|" + r.DstIP,
+		description:   "Strobe from " + r.SrcIP + " to " + r.DstIP + " with " + strconv.FormatInt(r.ConnectionCount, 10) + " connections",
+		indicatorType: anomalousActivity,
+		value:         r.DstIP,
+		confidence:    StrobeConfidence,
+		count:         r.ConnectionCount,
+		traffic:       [][2]string{{r.SrcIP, r.DstIP}},
+	})
+}
+
+// Bundle returns the accumulated objects as a STIX bundle. The identity comes
+// first, followed by the indicators, the observables, and the observed data
+// and sightings in the order the findings were added.
+func (b *Builder) Bundle() Bundle {
+	objects := []interface{}{b.identity}
+	for _, pattern := range b.indicatorOrder {
+		objects = append(objects, *b.indicators[pattern])
+	}
+	objects = append(objects, b.observableObjs...)
+	objects = append(objects, b.observations...)
+
+	return Bundle{
+		Type:    "bundle",
+		ID:      domainObjectID("bundle", b.dataset, b.created),
+		Objects: objects,
+	}
+}
+
+// add records the indicator, observables, observed data, and sighting for a finding
+func (b *Builder) add(f finding) {
+	indicator := b.indicator(f)
+
+	var refs []string
+	for _, flow := range f.traffic {
+		refs = append(refs, b.networkTraffic(flow[0], flow[1], f.protocol))
+	}
+	for _, value := range f.extraRefs {
+		refs = append(refs, b.address(value))
+	}
+	// observed data must reference at least one observable
+	if len(refs) == 0 {
+		refs = append(refs, b.address(f.value))
+	}
+
+	observedData := ObservedData{
+		Type:           "observed-data",
+		SpecVersion:    SpecVersion,
+		ID:             domainObjectID("observed-data", b.dataset, f.key),
+		CreatedByRef:   b.identity.ID,
+		Created:        b.created,
+		Modified:       b.created,
+		FirstObserved:  b.firstObserved,
+		LastObserved:   b.lastObserved,
+		NumberObserved: clampCount(f.count, 1),
+		ObjectRefs:     refs,
+	}
+
+	sighting := Sighting{
+		Type:             "sighting",
+		SpecVersion:      SpecVersion,
+		ID:               domainObjectID("sighting", b.dataset, f.key),
+		CreatedByRef:     b.identity.ID,
+		Created:          b.created,
+		Modified:         b.created,
+		Description:      f.description,
+		FirstSeen:        b.firstObserved,
+		LastSeen:         b.lastObserved,
+		Count:            clampCount(f.count, 0),
+		SightingOfRef:    indicator.ID,
+		ObservedDataRefs: []string{observedData.ID},
+		WhereSightedRefs: []string{b.identity.ID},
+		Confidence:       f.confidence,
+	}
+
+	b.observations = append(b.observations, observedData, sighting)
+}
+
+// indicator returns the indicator matching the finding's value, creating it
+// if needed and raising its confidence to the finding's confidence
+func (b *Builder) indicator(f finding) *Indicator {
+	pattern := patternFor(f.value)
+	key := f.indicatorType + "|" + pattern
+
+	if indicator, ok := b.indicators[key]; ok {
+		if f.confidence > indicator.Confidence {
+			indicator.Confidence = f.confidence
+		}
+		return indicator
+	}
+
+	name := "Anomalous traffic involving " + f.value
+	description := "Beacons or strobes involving " + f.value + " were found by RITA"
+	if f.indicatorType == maliciousActivity {
+		name = "Blacklisted " + f.value
+		description = f.value + " appears on a threat intelligence list used by RITA"
+	}
+
+	indicator := &Indicator{
+		Type:           "indicator",
+		SpecVersion:    SpecVersion,
+		ID:             domainObjectID("indicator", b.dataset, key),
+		CreatedByRef:   b.identity.ID,
+		Created:        b.created,
+		Modified:       b.created,
+		Name:           name,
+		Description:    description,
+		IndicatorTypes: []string{f.indicatorType},
+		Pattern:        pattern,
+		PatternType:    "stix",
+		ValidFrom:      b.firstObserved,
+		Confidence:     f.confidence,
+	}
+	b.indicators[key] = indicator
+	b.indicatorOrder = append(b.indicatorOrder, key)
+	return indicator
+}
+
+// address returns the identifier of the observable for an IP address or
+// domain name, adding the observable to the bundle if needed
+func (b *Builder) address(value string) string {
+	objType := addressType(value)
+	id := observableID(objType, map[string]interface{}{"value": value})
+	if !b.observables[id] {
+		b.observables[id] = true
+		b.observableObjs = append(b.observableObjs, Address{
+			Type:        objType,
+			SpecVersion: SpecVersion,
+			ID:          id,
+			Value:       value,
+		})
+	}
+	return id
+}
+
+// networkTraffic returns the identifier of the observable for traffic between
+// two addresses, adding the observables to the bundle if needed
+func (b *Builder) networkTraffic(src, dst, protocol string) string {
+	srcRef := b.address(src)
+	dstRef := b.address(dst)
+
+	protocols := []string{ipFamily(src)}
+	if protocol != "" {
+		protocols = append(protocols, protocol)
+	}
+
+	id := observableID("network-traffic", map[string]interface{}{
+		"src_ref":   srcRef,
+		"dst_ref":   dstRef,
+		"protocols": protocols,
+	})
+	if !b.observables[id] {
+		b.observables[id] = true
+		b.observableObjs = append(b.observableObjs, NetworkTraffic{
+			Type:        "network-traffic",
+			SpecVersion: SpecVersion,
+			ID:          id,
+			SrcRef:      srcRef,
+			DstRef:      dstRef,
+			Protocols:   protocols,
+		})
+	}
+	return id
+}
+
+// scoreConfidence converts a 0-1 score into a 0-100 STIX confidence
+func scoreConfidence(score float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, score)) * 100))
+}
+
+// clampCount keeps a count within the range STIX allows
+func clampCount(count, floor int64) int64 {
+	if count < floor {
+		return floor
+	}
+	if count > maxCount {
+		return maxCount
+	}
+	return count
+}
@@ -0,0 +1,104 @@
+package stix
+
+import (
+	"time"
+
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/beaconproxy"
+	"github.com/activecm/rita-legacy/pkg/beaconsni"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/resources"
+)
+
+// Export gathers the beacons, SNI and proxy beacons, blacklist hits, and strobes
+// from the selected database into a STIX bundle. Beacons scoring below
+// cutoffScore are left out, as are findings suppressed by the allowlist or
+// triaged as benign.
+func Export(res *resources.Resources, cutoffScore float64) (Bundle, error) {
+	db := res.DB.GetSelectedDB()
+
+	tsMin, tsMax, err := res.MetaDB.GetTSRange(db)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	builder := NewBuilder(db, tsMin, tsMax, time.Now())
+
+	beacons, err := beacon.Results(res, cutoffScore, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range beacons {
+		if !triage.IsBenign(database.NewIPPairTriageKey(r.UniqueIPPair)) {
+			builder.AddBeacon(r)
+		}
+	}
+
+	sniBeacons, err := beaconsni.Results(res, cutoffScore, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range sniBeacons {
+		if !triage.IsBenign(database.NewSrcFQDNTriageKey(r.UniqueSrcFQDNPair)) {
+			builder.AddBeaconSNI(r)
+		}
+	}
+
+	proxyBeacons, err := beaconproxy.Results(res, cutoffScore, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range proxyBeacons {
+		if !triage.IsBenign(database.NewSrcFQDNTriageKey(r.UniqueSrcFQDNPair)) {
+			builder.AddBeaconProxy(r)
+		}
+	}
+
+	blSrcIPs, err := blacklist.SrcIPResults(res, "conn_count", 0, true, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range blSrcIPs {
+		if !triage.IsBenign(database.NewSrcTriageKey(r.Host)) {
+			builder.AddBlacklistedSrcIP(r)
+		}
+	}
+
+	blDstIPs, err := blacklist.DstIPResults(res, "conn_count", 0, true, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range blDstIPs {
+		if !triage.IsBenign(database.NewDstTriageKey(r.Host)) {
+			builder.AddBlacklistedDstIP(r)
+		}
+	}
+
+	blHostnames, err := blacklist.HostnameResults(res, "conn_count", 0, true, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range blHostnames {
+		if !triage.IsBenign(database.NewFQDNTriageKey(r.Host)) {
+			builder.AddBlacklistedHostname(r)
+		}
+	}
+
+	strobes, err := beacon.StrobeResults(res, -1, 0, true, false)
+	if err != nil {
+		return Bundle{}, err
+	}
+	for _, r := range strobes {
+		if !triage.IsBenign(database.NewIPPairTriageKey(r.UniqueIPPair)) {
+			builder.AddStrobe(r)
+		}
+	}
+
+	return builder.Bundle(), nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package stix
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+const exportTestDB = "tmp_test_stix_export"
+
+func TestExportBlacklistedDestinations(t *testing.T) {
+	res := resources.InitIntegrationTestingResources(t)
+	res.DB.SelectDB(exportTestDB)
+	defer res.MetaDB.DeleteDB(exportTestDB)
+
+	require.NoError(t, res.MetaDB.AddNewDB(exportTestDB, 0, 1))
+	require.NoError(t, res.MetaDB.AddTSRange(exportTestDB, 1700000000, 1700086400))
+
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+	db := ssn.DB(exportTestDB)
+
+	// a blacklisted destination contacted by an internal host and a
+	// blacklisted source which should not be exported as a destination
+	err := db.C(res.Config.T.Structure.HostTable).Insert(
+		bson.M{
+			"ip":           "203.0.113.9",
+			"network_uuid": util.PublicNetworkUUID,
+			"network_name": util.PublicNetworkName,
+			"blacklisted":  true,
+			"dat":          []bson.M{{"count_dst": 1, "cid": 0}},
+		},
+		bson.M{
+			"ip":           "198.51.100.7",
+			"network_uuid": util.PublicNetworkUUID,
+			"network_name": util.PublicNetworkName,
+			"blacklisted":  true,
+			"dat":          []bson.M{{"count_src": 1, "cid": 0}},
+		},
+	)
+	require.NoError(t, err)
+
+	err = db.C(res.Config.T.Structure.UniqueConnTable).Insert(
+		bson.M{
+			"src":              "10.0.0.1",
+			"src_network_uuid": util.UnknownPrivateNetworkUUID,
+			"src_network_name": util.UnknownPrivateNetworkName,
+			"dst":              "203.0.113.9",
+			"dst_network_uuid": util.PublicNetworkUUID,
+			"dst_network_name": util.PublicNetworkName,
+			"dat":              []bson.M{{"count": 5, "tbytes": 100, "cid": 0}},
+		},
+		bson.M{
+			"src":              "198.51.100.7",
+			"src_network_uuid": util.PublicNetworkUUID,
+			"src_network_name": util.PublicNetworkName,
+			"dst":              "10.0.0.2",
+			"dst_network_uuid": util.UnknownPrivateNetworkUUID,
+			"dst_network_name": util.UnknownPrivateNetworkName,
+			"dat":              []bson.M{{"count": 3, "tbytes": 50, "cid": 0}},
+		},
+	)
+	require.NoError(t, err)
+
+	bundle, err := Export(res, 1)
+	require.NoError(t, err)
+
+	var patterns []string
+	var sightings []string
+	for _, obj := range bundle.Objects {
+		switch o := obj.(type) {
+		case Indicator:
+			patterns = append(patterns, o.Pattern)
+		case Sighting:
+			sightings = append(sightings, o.Description)
+		}
+	}
+	require.Contains(t, patterns, patternFor("203.0.113.9"))
+	require.Contains(t, patterns, patternFor("198.51.100.7"))
+
+	// the destination is only reported as contacted by its peers, never as a source
+	require.Contains(t, sightings, "Blacklisted IP 203.0.113.9 was contacted by 1 hosts")
+	require.Contains(t, sightings, "Blacklisted IP 198.51.100.7 connected to 1 hosts")
+	require.NotContains(t, sightings, "Blacklisted IP 203.0.113.9 connected to 1 hosts")
+}
@@ -0,0 +1,162 @@
+package stix
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SpecVersion is the version of STIX the exported objects conform to
+const SpecVersion = "2.1"
+
+// timestampFormat is the STIX timestamp format with millisecond precision
+const timestampFormat = "2006-01-02T15:04:05.000Z"
+
+// Indicator types from the STIX 2.1 indicator-type-ov vocabulary
+const (
+	anomalousActivity = "anomalous-activity"
+	maliciousActivity = "malicious-activity"
+)
+
+// scoNamespace is the namespace STIX 2.1 defines for deterministic identifiers
+// of cyber-observable objects
+var scoNamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+// ritaNamespace is used to derive the identifiers of the domain objects RITA
+// creates so exporting the same dataset twice produces the same identifiers
+var ritaNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/activecm/rita-legacy"))
+
+// Bundle is a collection of STIX objects
+type Bundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []interface{} `json:"objects"`
+}
+
+// Identity describes RITA as the producer of the exported objects
+type Identity struct {
+	Type          string `json:"type"`
+	SpecVersion   string `json:"spec_version"`
+	ID            string `json:"id"`
+	Created       string `json:"created"`
+	Modified      string `json:"modified"`
+	Name          string `json:"name"`
+	IdentityClass string `json:"identity_class"`
+}
+
+// Indicator is a pattern matching a suspicious IP address or domain name
+type Indicator struct {
+	Type           string   `json:"type"`
+	SpecVersion    string   `json:"spec_version"`
+	ID             string   `json:"id"`
+	CreatedByRef   string   `json:"created_by_ref"`
+	Created        string   `json:"created"`
+	Modified       string   `json:"modified"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	IndicatorTypes []string `json:"indicator_types"`
+	Pattern        string   `json:"pattern"`
+	PatternType    string   `json:"pattern_type"`
+	ValidFrom      string   `json:"valid_from"`
+	Confidence     int      `json:"confidence"`
+}
+
+// ObservedData records the network traffic behind a finding
+type ObservedData struct {
+	Type           string   `json:"type"`
+	SpecVersion    string   `json:"spec_version"`
+	ID             string   `json:"id"`
+	CreatedByRef   string   `json:"created_by_ref"`
+	Created        string   `json:"created"`
+	Modified       string   `json:"modified"`
+	FirstObserved  string   `json:"first_observed"`
+	LastObserved   string   `json:"last_observed"`
+	NumberObserved int64    `json:"number_observed"`
+	ObjectRefs     []string `json:"object_refs"`
+}
+
+// Sighting ties an indicator to the observed data which matched it
+type Sighting struct {
+	Type             string   `json:"type"`
+	SpecVersion      string   `json:"spec_version"`
+	ID               string   `json:"id"`
+	CreatedByRef     string   `json:"created_by_ref"`
+	Created          string   `json:"created"`
+	Modified         string   `json:"modified"`
+	Description      string   `json:"description"`
+	FirstSeen        string   `json:"first_seen"`
+	LastSeen         string   `json:"last_seen"`
+	Count            int64    `json:"count"`
+	SightingOfRef    string   `json:"sighting_of_ref"`
+	ObservedDataRefs []string `json:"observed_data_refs"`
+	WhereSightedRefs []string `json:"where_sighted_refs"`
+	Confidence       int      `json:"confidence"`
+}
+
+// Address is an ipv4-addr, ipv6-addr, or domain-name observable
+type Address struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	Value       string `json:"value"`
+}
+
+// NetworkTraffic is a network-traffic observable between two addresses
+type NetworkTraffic struct {
+	Type        string   `json:"type"`
+	SpecVersion string   `json:"spec_version"`
+	ID          string   `json:"id"`
+	SrcRef      string   `json:"src_ref"`
+	DstRef      string   `json:"dst_ref"`
+	Protocols   []string `json:"protocols"`
+}
+
+// timestamp formats a unix timestamp as a STIX timestamp
+func timestamp(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(timestampFormat)
+}
+
+// addressType returns the observable type used for an IP address or domain name
+func addressType(value string) string {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return "domain-name"
+	}
+	if ip.To4() != nil {
+		return "ipv4-addr"
+	}
+	return "ipv6-addr"
+}
+
+// ipFamily returns the network layer protocol for a network-traffic observable
+// originating from the given address
+func ipFamily(value string) string {
+	if addressType(value) == "ipv6-addr" {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// patternFor returns a STIX pattern matching an IP address or domain name
+func patternFor(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "[" + addressType(value) + ":value = '" + escaped + "']"
+}
+
+// observableID derives the deterministic identifier of a cyber-observable
+// object from its ID contributing properties as described in the STIX 2.1 spec
+func observableID(objType string, contributing map[string]interface{}) string {
+	// json.Marshal sorts map keys, which is all the canonicalization these
+	// simple property values need
+	name, _ := json.Marshal(contributing)
+	return objType + "--" + uuid.NewSHA1(scoNamespace, name).String()
+}
+
+// domainObjectID derives a deterministic identifier for a domain object RITA
+// creates from the dataset name and a key unique to the object within it
+func domainObjectID(objType, dataset, key string) string {
+	return objType + "--" + uuid.NewSHA1(ritaNamespace, []byte(dataset+"|"+objType+"|"+key)).String()
+}
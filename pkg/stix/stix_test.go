@@ -0,0 +1,136 @@
+package stix
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/beacon"
+	"github.com/activecm/rita-legacy/pkg/beaconproxy"
+	"github.com/activecm/rita-legacy/pkg/blacklist"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testCreated = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testBeacon(src, dst string, score float64) beacon.Result {
+	return beacon.Result{
+		UniqueIPPair: data.NewUniqueIPPair(data.UniqueIP{IP: src}, data.UniqueIP{IP: dst}),
+		Connections:  100,
+		Score:        score,
+	}
+}
+
+func TestPatternFor(t *testing.T) {
+	require.Equal(t, "[ipv4-addr:value = '1.2.3.4']", patternFor("1.2.3.4"))
+	require.Equal(t, "[ipv6-addr:value = '2001:db8::1']", patternFor("2001:db8::1"))
+	require.Equal(t, "[domain-name:value = 'evil.com']", patternFor("evil.com"))
+	require.Equal(t, `[domain-name:value = 'a\'b\\c']`, patternFor(`a'b\c`))
+}
+
+func TestObservableID(t *testing.T) {
+	// UUIDv5 in the STIX namespace over the canonical JSON of the contributing properties
+	id := observableID("ipv4-addr", map[string]interface{}{"value": "198.51.100.3"})
+	require.Equal(t, "ipv4-addr--28bb3599-77cd-5a82-a950-b5bc3caf07c4", id)
+
+	id = observableID("network-traffic", map[string]interface{}{"src_ref": "a", "dst_ref": "b", "protocols": []string{"ipv4"}})
+	require.Equal(t, "network-traffic--"+uuid.NewSHA1(scoNamespace, []byte(`{"dst_ref":"b","protocols":["ipv4"],"src_ref":"a"}`)).String(), id)
+}
+
+func TestBuilderSharesIndicators(t *testing.T) {
+	b := NewBuilder("dataset", 1700000000, 1700086400, testCreated)
+	b.AddBeacon(testBeacon("10.0.0.1", "1.2.3.4", 0.754))
+	b.AddBeacon(testBeacon("10.0.0.2", "1.2.3.4", 0.91))
+	b.AddStrobe(beacon.StrobeResult{
+		UniqueIPPair:    data.NewUniqueIPPair(data.UniqueIP{IP: "10.0.0.1"}, data.UniqueIP{IP: "1.2.3.4"}),
+		ConnectionCount: 90000,
+	})
+	b.AddBlacklistedDstIP(blacklist.IPResult{
+		Host:        data.UniqueIP{IP: "1.2.3.4"},
+		Connections: 5,
+		Peers:       []data.UniqueIP{{IP: "10.0.0.1"}},
+	})
+
+	bundle := b.Bundle()
+	var indicators []Indicator
+	var sightings []Sighting
+	var observed []ObservedData
+	var traffic []NetworkTraffic
+	var addresses []Address
+	for _, obj := range bundle.Objects {
+		switch o := obj.(type) {
+		case Indicator:
+			indicators = append(indicators, o)
+		case Sighting:
+			sightings = append(sightings, o)
+		case ObservedData:
+			observed = append(observed, o)
+		case NetworkTraffic:
+			traffic = append(traffic, o)
+		case Address:
+			addresses = append(addresses, o)
+		}
+	}
+
+	// the beacons and strobe share an anomalous-activity indicator while the
+	// blacklist hit gets a malicious-activity indicator
+	require.Len(t, indicators, 2)
+	require.Equal(t, []string{anomalousActivity}, indicators[0].IndicatorTypes)
+	require.Equal(t, 91, indicators[0].Confidence)
+	require.Equal(t, "2023-11-14T22:13:20.000Z", indicators[0].ValidFrom)
+	require.Equal(t, []string{maliciousActivity}, indicators[1].IndicatorTypes)
+	require.Equal(t, BlacklistConfidence, indicators[1].Confidence)
+
+	require.Len(t, sightings, 4)
+	require.Len(t, observed, 4)
+	require.Equal(t, 75, sightings[0].Confidence)
+	require.Equal(t, StrobeConfidence, sightings[2].Confidence)
+	require.Equal(t, indicators[0].ID, sightings[2].SightingOfRef)
+	require.Equal(t, indicators[1].ID, sightings[3].SightingOfRef)
+	require.Equal(t, int64(90000), observed[2].NumberObserved)
+	require.Equal(t, "2023-11-15T22:13:20.000Z", observed[0].LastObserved)
+
+	// 10.0.0.1 -> 1.2.3.4 is shared by a beacon, the strobe, and the blacklist hit
+	require.Len(t, traffic, 2)
+	require.Len(t, addresses, 3)
+	require.Equal(t, observed[0].ObjectRefs, observed[2].ObjectRefs)
+	require.Equal(t, observed[0].ObjectRefs, observed[3].ObjectRefs)
+}
+
+func TestBuilderProxyBeacon(t *testing.T) {
+	b := NewBuilder("dataset", 1700000000, 1700086400, testCreated)
+	b.AddBeaconProxy(beaconproxy.Result{
+		UniqueSrcFQDNPair: data.NewUniqueSrcFQDNPair(data.UniqueIP{IP: "10.0.0.1"}, "evil.com"),
+		Proxy:             data.UniqueIP{IP: "10.0.0.254"},
+		Connections:       0,
+		Score:             0.8,
+	})
+
+	bundle := b.Bundle()
+	indicator := bundle.Objects[1].(Indicator)
+	require.Equal(t, "[domain-name:value = 'evil.com']", indicator.Pattern)
+
+	observed := bundle.Objects[len(bundle.Objects)-2].(ObservedData)
+	require.Len(t, observed.ObjectRefs, 2)
+	require.Equal(t, int64(1), observed.NumberObserved)
+
+	traffic := bundle.Objects[4].(NetworkTraffic)
+	require.Equal(t, []string{"ipv4", "http"}, traffic.Protocols)
+}
+
+func TestBundleIsDeterministic(t *testing.T) {
+	build := func() []byte {
+		b := NewBuilder("dataset", 1700000000, 1700086400, testCreated)
+		b.AddBeacon(testBeacon("10.0.0.1", "1.2.3.4", 0.8))
+		out, err := json.Marshal(b.Bundle())
+		require.NoError(t, err)
+		return out
+	}
+	require.Equal(t, build(), build())
+
+	b := NewBuilder("other", 1700000000, 1700086400, testCreated)
+	b.AddBeacon(testBeacon("10.0.0.1", "1.2.3.4", 0.8))
+	require.NotEqual(t, build(), func() []byte { out, _ := json.Marshal(b.Bundle()); return out }())
+}
@@ -0,0 +1,54 @@
+package threat
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String formats the finding for display
+func (f Finding) String() string {
+	out := f.Name + " " + strconv.FormatFloat(f.Score, 'f', 3, 64)
+	if f.Detail != "" {
+		out += " (" + f.Detail + ")"
+	}
+	return out
+}
+
+// Findings returns the non-zero components of the host's threat score along with
+// the evidence behind them, highest scoring first
+func (r Result) Findings() []Finding {
+	var findings []Finding
+	add := func(name string, score float64, detail string) {
+		if score > 0 {
+			findings = append(findings, Finding{Name: name, Score: score, Detail: detail})
+		}
+	}
+
+	add("beacon", r.Components.Beacon, r.Evidence.BeaconDst)
+	add("sni beacon", r.Components.BeaconSNI, r.Evidence.BeaconSNI)
+	add("proxy beacon", r.Components.BeaconProxy, r.Evidence.BeaconProxy)
+	add("dns beacon", r.Components.BeaconDNS, r.Evidence.BeaconDNS)
+	add("long connection", r.Components.LongConn,
+		r.Evidence.LongConnPeer+" for "+(time.Duration(r.Evidence.LongConnDuration)*time.Second).String())
+	add("exfil", r.Components.Exfil, r.Evidence.ExfilDst)
+	add("blacklisted peers", r.Components.Blacklisted,
+		sampleDetail(r.Evidence.BlacklistedCount, r.Evidence.BlacklistedPeers))
+	add("rare signatures", r.Components.RareSignature,
+		sampleDetail(r.Evidence.RareSignatureCount, r.Evidence.RareSignatures))
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Score > findings[j].Score
+	})
+	return findings
+}
+
+// sampleDetail formats a count along with a sample of the values counted
+func sampleDetail(count int, sample []string) string {
+	detail := strconv.Itoa(count) + ": " + strings.Join(sample, " ")
+	if count > len(sample) {
+		detail += " ..."
+	}
+	return detail
+}
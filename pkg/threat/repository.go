@@ -0,0 +1,56 @@
+package threat
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/pkg/host"
+)
+
+// Repository for host threat scores
+type Repository interface {
+	CreateIndexes() error
+	Upsert(hostMap map[string]*host.Input)
+}
+
+// Components holds the subscores, each between 0 and 1, which make up a host's threat score
+type Components struct {
+	Beacon        float64 `bson:"beacon"`
+	BeaconSNI     float64 `bson:"beacon_sni"`
+	BeaconProxy   float64 `bson:"beacon_proxy"`
+	BeaconDNS     float64 `bson:"beacon_dns"`
+	LongConn      float64 `bson:"long_conn"`
+	Exfil         float64 `bson:"exfil"`
+	Blacklisted   float64 `bson:"blacklisted"`
+	RareSignature float64 `bson:"rare_signature"`
+}
+
+// Evidence records the findings behind the components of a host's threat score
+type Evidence struct {
+	BeaconDst          string   `bson:"beacon_dst"`           // peer with the highest beacon score
+	BeaconSNI          string   `bson:"beacon_sni"`           // FQDN with the highest SNI beacon score
+	BeaconProxy        string   `bson:"beacon_proxy"`         // FQDN with the highest proxy beacon score
+	BeaconDNS          string   `bson:"beacon_dns"`           // domain with the highest DNS beacon score
+	LongConnPeer       string   `bson:"long_conn_peer"`       // peer with the longest total connection time
+	LongConnDuration   float64  `bson:"long_conn_duration"`   // total connection time in seconds
+	ExfilDst           string   `bson:"exfil_dst"`            // peer or FQDN with the highest exfil score
+	BlacklistedPeers   []string `bson:"blacklisted_peers"`    // sample of the blacklisted peers
+	BlacklistedCount   int      `bson:"blacklisted_count"`    // number of blacklisted peers
+	RareSignatures     []string `bson:"rare_signatures"`      // sample of the rare signatures
+	RareSignatureCount int      `bson:"rare_signature_count"` // number of rare signatures
+}
+
+// Result represents the most recent threat score of an internal host
+type Result struct {
+	data.UniqueIP `bson:",inline"`
+	CID           int        `bson:"cid"`
+	Score         float64    `bson:"score"`
+	Components    Components `bson:"components"`
+	Evidence      Evidence   `bson:"evidence"`
+	Suppressed    bool       `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
+}
+
+// Finding describes a single component of a host's threat score
+type Finding struct {
+	Name   string  // the analysis which produced the finding
+	Score  float64 // the component of the threat score
+	Detail string  // the evidence behind the component
+}
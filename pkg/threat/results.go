@@ -0,0 +1,93 @@
+package threat
+
+import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// Results returns the most recent threat score of each internal host, sorted by score.
+// limit and noLimit control how many results are returned.
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable).Pipe(resultsQuery()).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the internal hosts' threat scores, sorted by score, along
+// with the total number of them. The first skip results are skipped and at most limit
+// results are returned. Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	threatResults, err := collectResults(res, iter, 0, true, true)
+	return threatResults, total, err
+}
+
+// resultsQuery gathers the threat score from the most recent chunk of each internal host
+func resultsQuery() []bson.M {
+	return []bson.M{
+		{"$match": bson.M{
+			"local":            true,
+			"dat.threat_score": bson.M{"$gt": 0},
+		}},
+		{"$unwind": "$dat"},
+		{"$match": bson.M{"dat.threat_score": bson.M{"$exists": true}}},
+		// keep the score from the most recent chunk
+		{"$sort": bson.M{"dat.cid": -1}},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"ip":           "$ip",
+				"network_uuid": "$network_uuid",
+			},
+			"network_name": bson.M{"$first": "$network_name"},
+			"cid":          bson.M{"$first": "$dat.cid"},
+			"score":        bson.M{"$first": "$dat.threat_score"},
+			"components":   bson.M{"$first": "$dat.threat_components"},
+			"evidence":     bson.M{"$first": "$dat.threat_evidence"},
+		}},
+		{"$match": bson.M{"score": bson.M{"$gt": 0}}},
+		{"$project": bson.M{
+			"_id":          0,
+			"ip":           "$_id.ip",
+			"network_uuid": "$_id.network_uuid",
+			"network_name": 1,
+			"cid":          1,
+			"score":        1,
+			"components":   1,
+			"evidence":     1,
+		}},
+		{"$sort": bson.D{{Name: "score", Value: -1}, {Name: "ip", Value: 1}}},
+	}
+}
+
+// collectResults reads the threat scores from iter and labels the hosts with the asset
+// inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	threatResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsIP(r.IP)
+	})
+	if err != nil {
+		return threatResults, err
+	}
+
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return threatResults, err
+	}
+	inventory.Label(threatResults)
+
+	return threatResults, nil
+}
@@ -0,0 +1,131 @@
+package threat
+
+import (
+	"math"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+// maxEvidenceSample is the number of blacklisted peers and rare signatures kept as evidence
+const maxEvidenceSample = 10
+
+// hostDat holds the summary fields the other analysis modules record in a host's dat entries.
+// Each entry only sets the fields of the module which created it.
+type hostDat struct {
+	MaxBeaconScore      float64       `bson:"max_beacon_score"`
+	BeaconDst           data.UniqueIP `bson:"mbdst"`
+	MaxBeaconSNIScore   float64       `bson:"max_beacon_sni_score"`
+	BeaconSNI           string        `bson:"mbsni"`
+	MaxBeaconProxyScore float64       `bson:"max_beacon_proxy_score"`
+	BeaconProxy         string        `bson:"mbproxy"`
+	MaxBeaconDNSScore   float64       `bson:"max_beacon_dns_score"`
+	BeaconDNS           string        `bson:"mbdns"`
+	MaxDuration         float64       `bson:"max_duration"`
+	LongConnPeer        data.UniqueIP `bson:"mdip"`
+	ExfilScore          float64       `bson:"exfil_score"`
+	ExfilDst            string        `bson:"exfil_dst"`
+	Blacklisted         data.UniqueIP `bson:"bl"`
+	RareSignature       string        `bson:"rsig"`
+	ThreatScore         *float64      `bson:"threat_score"` // nil unless the entry records a threat score
+}
+
+// summarize combines the dat entries of a host into the components of its threat score
+// along with the evidence behind them
+func summarize(dats []hostDat, conf config.ThreatScoreStaticCfg) (Components, Evidence) {
+	var components Components
+	var evidence Evidence
+
+	blacklisted := make(map[string]struct{})
+	rareSignatures := make(map[string]struct{})
+
+	for _, dat := range dats {
+		if dat.MaxBeaconScore > components.Beacon {
+			components.Beacon = dat.MaxBeaconScore
+			evidence.BeaconDst = dat.BeaconDst.IP
+		}
+		if dat.MaxBeaconSNIScore > components.BeaconSNI {
+			components.BeaconSNI = dat.MaxBeaconSNIScore
+			evidence.BeaconSNI = dat.BeaconSNI
+		}
+		if dat.MaxBeaconProxyScore > components.BeaconProxy {
+			components.BeaconProxy = dat.MaxBeaconProxyScore
+			evidence.BeaconProxy = dat.BeaconProxy
+		}
+		if dat.MaxBeaconDNSScore > components.BeaconDNS {
+			components.BeaconDNS = dat.MaxBeaconDNSScore
+			evidence.BeaconDNS = dat.BeaconDNS
+		}
+		if dat.MaxDuration > evidence.LongConnDuration {
+			evidence.LongConnDuration = dat.MaxDuration
+			evidence.LongConnPeer = dat.LongConnPeer.IP
+		}
+		if dat.ExfilScore > components.Exfil {
+			components.Exfil = dat.ExfilScore
+			evidence.ExfilDst = dat.ExfilDst
+		}
+		if dat.Blacklisted.IP != "" {
+			if _, ok := blacklisted[dat.Blacklisted.IP]; !ok {
+				blacklisted[dat.Blacklisted.IP] = struct{}{}
+				if len(evidence.BlacklistedPeers) < maxEvidenceSample {
+					evidence.BlacklistedPeers = append(evidence.BlacklistedPeers, dat.Blacklisted.IP)
+				}
+			}
+		}
+		if dat.RareSignature != "" {
+			if _, ok := rareSignatures[dat.RareSignature]; !ok {
+				rareSignatures[dat.RareSignature] = struct{}{}
+				if len(evidence.RareSignatures) < maxEvidenceSample {
+					evidence.RareSignatures = append(evidence.RareSignatures, dat.RareSignature)
+				}
+			}
+		}
+	}
+
+	if conf.LongConnHours > 0 {
+		components.LongConn = math.Min(evidence.LongConnDuration/(conf.LongConnHours*3600), 1)
+	}
+
+	evidence.BlacklistedCount = len(blacklisted)
+	if evidence.BlacklistedCount > 0 {
+		components.Blacklisted = 1
+	}
+
+	evidence.RareSignatureCount = len(rareSignatures)
+	if evidence.RareSignatureCount > 0 {
+		components.RareSignature = 1
+	}
+
+	return components, evidence
+}
+
+// score returns the weighted average of the components using the configured weights
+func score(components Components, conf config.ThreatScoreStaticCfg) float64 {
+	weighted := []struct {
+		weight float64
+		value  float64
+	}{
+		{conf.BeaconWeight, components.Beacon},
+		{conf.BeaconSNIWeight, components.BeaconSNI},
+		{conf.BeaconProxyWeight, components.BeaconProxy},
+		{conf.BeaconDNSWeight, components.BeaconDNS},
+		{conf.LongConnWeight, components.LongConn},
+		{conf.ExfilWeight, components.Exfil},
+		{conf.BlacklistedWeight, components.Blacklisted},
+		{conf.RareSignatureWeight, components.RareSignature},
+	}
+
+	var total, weights float64
+	for _, entry := range weighted {
+		if entry.weight <= 0 {
+			continue
+		}
+		total += entry.weight * entry.value
+		weights += entry.weight
+	}
+
+	if weights == 0 {
+		return 0
+	}
+	return math.Round(total/weights*1000) / 1000
+}
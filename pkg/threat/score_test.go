@@ -0,0 +1,98 @@
+package threat
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/stretchr/testify/require"
+)
+
+var testThreatConfig = config.ThreatScoreStaticCfg{
+	Enabled:             true,
+	BeaconWeight:        0.2,
+	BeaconSNIWeight:     0.1,
+	BeaconProxyWeight:   0.1,
+	BeaconDNSWeight:     0.1,
+	LongConnWeight:      0.15,
+	ExfilWeight:         0.1,
+	BlacklistedWeight:   0.15,
+	RareSignatureWeight: 0.1,
+	LongConnHours:       12,
+}
+
+func TestSummarize(t *testing.T) {
+	dats := []hostDat{
+		{MaxBeaconScore: 0.9, BeaconDst: data.UniqueIP{IP: "1.2.3.4"}},
+		{MaxBeaconDNSScore: 0.7, BeaconDNS: "evil.com"},
+		{MaxDuration: 6 * 3600, LongConnPeer: data.UniqueIP{IP: "5.6.7.8"}},
+		{ExfilScore: 0.5, ExfilDst: "upload.example.com"},
+		{ExfilScore: 0.2, ExfilDst: "other.example.com"},
+		{Blacklisted: data.UniqueIP{IP: "6.6.6.6"}},
+		{Blacklisted: data.UniqueIP{IP: "6.6.6.6"}},
+		{RareSignature: "curl/1.0"},
+	}
+
+	components, evidence := summarize(dats, testThreatConfig)
+
+	require.Equal(t, Components{
+		Beacon:        0.9,
+		BeaconDNS:     0.7,
+		LongConn:      0.5,
+		Exfil:         0.5,
+		Blacklisted:   1,
+		RareSignature: 1,
+	}, components)
+	require.Equal(t, "1.2.3.4", evidence.BeaconDst)
+	require.Equal(t, "evil.com", evidence.BeaconDNS)
+	require.Equal(t, "5.6.7.8", evidence.LongConnPeer)
+	require.Equal(t, "upload.example.com", evidence.ExfilDst)
+	require.Equal(t, 1, evidence.BlacklistedCount)
+	require.Equal(t, []string{"6.6.6.6"}, evidence.BlacklistedPeers)
+	require.Equal(t, 1, evidence.RareSignatureCount)
+
+	// 0.2*0.9 + 0.1*0.7 + 0.15*0.5 + 0.1*0.5 + 0.15*1 + 0.1*1
+	require.InDelta(t, 0.625, score(components, testThreatConfig), 0.0005)
+}
+
+func TestSummarizeCapsLongConnAndSamples(t *testing.T) {
+	var dats []hostDat
+	for i := 0; i < maxEvidenceSample+5; i++ {
+		dats = append(dats, hostDat{Blacklisted: data.UniqueIP{IP: "6.6.6." + strconv.Itoa(i)}})
+	}
+	dats = append(dats, hostDat{MaxDuration: 48 * 3600, LongConnPeer: data.UniqueIP{IP: "5.6.7.8"}})
+
+	components, evidence := summarize(dats, testThreatConfig)
+	require.Equal(t, 1.0, components.LongConn)
+	require.Equal(t, maxEvidenceSample+5, evidence.BlacklistedCount)
+	require.Len(t, evidence.BlacklistedPeers, maxEvidenceSample)
+}
+
+func TestScoreWeights(t *testing.T) {
+	components := Components{Beacon: 1, Blacklisted: 1}
+
+	require.Equal(t, 0.0, score(Components{}, testThreatConfig))
+	require.Equal(t, 0.0, score(components, config.ThreatScoreStaticCfg{}))
+
+	// only the weighted components count towards the score
+	onlyBeacons := config.ThreatScoreStaticCfg{BeaconWeight: 1}
+	require.Equal(t, 1.0, score(components, onlyBeacons))
+	require.Equal(t, 0.0, score(Components{Blacklisted: 1}, onlyBeacons))
+}
+
+func TestFindings(t *testing.T) {
+	result := Result{
+		Components: Components{Beacon: 0.5, Blacklisted: 1},
+		Evidence: Evidence{
+			BeaconDst:        "1.2.3.4",
+			BlacklistedCount: 3,
+			BlacklistedPeers: []string{"6.6.6.6", "7.7.7.7"},
+		},
+	}
+
+	findings := result.Findings()
+	require.Len(t, findings, 2)
+	require.Equal(t, "blacklisted peers 1.000 (3: 6.6.6.6 7.7.7.7 ...)", findings[0].String())
+	require.Equal(t, "beacon 0.500 (1.2.3.4)", findings[1].String())
+}
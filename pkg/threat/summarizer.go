@@ -0,0 +1,156 @@
+package threat
+
+import (
+	"sync"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	//summarizer records the combined threat score of individual hosts using the summaries of the other modules
+	summarizer struct {
+		chunk              int                        // current chunk (0 if not on rolling summary)
+		db                 *database.DB               // provides access to MongoDB
+		conf               *config.Config             // contains details needed to access MongoDB
+		log                *log.Logger                // main logger for RITA
+		summarizedCallback func(database.BulkChanges) // called on each summarized result
+		closedCallback     func()                     // called when .close() is called and no more calls to summarizedCallback will be made
+		summaryChannel     chan data.UniqueIP         // holds unsummarized data
+		summaryWg          sync.WaitGroup             // wait for summary to finish
+	}
+)
+
+// newSummarizer creates a new summarizer for host threat scores
+func newSummarizer(chunk int, db *database.DB, conf *config.Config, log *log.Logger, summarizedCallback func(database.BulkChanges), closedCallback func()) *summarizer {
+	return &summarizer{
+		chunk:              chunk,
+		db:                 db,
+		conf:               conf,
+		log:                log,
+		summarizedCallback: summarizedCallback,
+		closedCallback:     closedCallback,
+		summaryChannel:     make(chan data.UniqueIP),
+	}
+}
+
+// collect collects an internal host to create summary data for
+func (s *summarizer) collect(datum data.UniqueIP) {
+	s.summaryChannel <- datum
+}
+
+// close waits for the summarizer to finish
+func (s *summarizer) close() {
+	close(s.summaryChannel)
+	s.summaryWg.Wait()
+	s.closedCallback()
+}
+
+// start kicks off a new summary thread
+func (s *summarizer) start() {
+	s.summaryWg.Add(1)
+	go func() {
+
+		ssn := s.db.Session.Copy()
+		defer ssn.Close()
+
+		for datum := range s.summaryChannel {
+			hostCollection := ssn.DB(s.db.GetSelectedDB()).C(s.conf.T.Structure.HostTable)
+
+			threatSelector, threatQuery, err := threatScoreUpdate(datum, hostCollection, s.conf.S.ThreatScore, s.chunk)
+			if err != nil {
+				if err != mgo.ErrNotFound {
+					s.log.WithFields(log.Fields{
+						"Module": "threat",
+						"Data":   datum,
+					}).Error(err)
+				}
+				continue
+			}
+
+			if len(threatQuery) > 0 {
+				s.summarizedCallback(database.BulkChanges{
+					s.conf.T.Structure.HostTable: []database.BulkChange{{
+						Selector: threatSelector,
+						Update:   threatQuery,
+						Upsert:   true,
+					}},
+				})
+			}
+		}
+		s.summaryWg.Done()
+	}()
+}
+
+// threatScoreUpdate scores a host using the summaries recorded in its host document. The
+// score for the current chunk is replaced if it already exists. Hosts which have never been
+// given a score are skipped if their score is 0. Otherwise a score of 0 is recorded so the
+// score from an earlier chunk no longer stands.
+func threatScoreUpdate(datum data.UniqueIP, hostColl *mgo.Collection, conf config.ThreatScoreStaticCfg, chunk int) (bson.M, bson.M, error) {
+	var hostEntry struct {
+		Dat []hostDat `bson:"dat"`
+	}
+
+	hostSelector := datum.BSONKey()
+	err := hostColl.Find(hostSelector).Select(bson.M{"dat": 1}).One(&hostEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	components, evidence := summarize(hostEntry.Dat, conf)
+	threatScore := score(components, conf)
+
+	hostWithDatEntrySelector := database.MergeBSONMaps(
+		hostSelector,
+		bson.M{"dat": bson.M{"$elemMatch": bson.M{"threat_score": bson.M{"$exists": true}, "cid": chunk}}},
+	)
+
+	nExistingEntries, err := hostColl.Find(hostWithDatEntrySelector).Count()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if nExistingEntries > 0 {
+		updateQuery := bson.M{
+			"$set": bson.M{
+				"dat.$.threat_score":      threatScore,
+				"dat.$.threat_components": components,
+				"dat.$.threat_evidence":   evidence,
+			},
+		}
+		return hostWithDatEntrySelector, updateQuery, nil
+	}
+
+	if threatScore == 0 && !hasThreatScore(hostEntry.Dat) {
+		return nil, nil, nil
+	}
+
+	insertQuery := bson.M{
+		"$push": bson.M{
+			"dat": bson.M{
+				"$each": []bson.M{{
+					"threat_score":      threatScore,
+					"threat_components": components,
+					"threat_evidence":   evidence,
+					"cid":               chunk,
+				}},
+			},
+		},
+	}
+
+	return hostSelector, insertQuery, nil
+}
+
+// hasThreatScore returns true if a score was recorded in any of the host's dat entries
+func hasThreatScore(dats []hostDat) bool {
+	for _, dat := range dats {
+		if dat.ThreatScore != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package threat
+
+import (
+	"testing"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasThreatScore(t *testing.T) {
+	decode := func(dats []bson.M) []hostDat {
+		raw, err := bson.Marshal(bson.M{"dat": dats})
+		require.NoError(t, err)
+		var hostEntry struct {
+			Dat []hostDat `bson:"dat"`
+		}
+		require.NoError(t, bson.Unmarshal(raw, &hostEntry))
+		return hostEntry.Dat
+	}
+
+	// never scored, so a score of 0 doesn't need to be recorded
+	require.False(t, hasThreatScore(decode([]bson.M{{"max_beacon_score": 0.2, "cid": 0}})))
+
+	// scored in an earlier chunk, so a score of 0 must replace it
+	require.True(t, hasThreatScore(decode([]bson.M{
+		{"max_beacon_score": 0.9, "cid": 0},
+		{"threat_score": 0.45, "cid": 0},
+	})))
+
+	// a recorded score of 0 still counts as a score
+	require.True(t, hasThreatScore(decode([]bson.M{{"threat_score": 0.0, "cid": 1}})))
+}
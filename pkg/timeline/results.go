@@ -0,0 +1,587 @@
+package timeline
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/certificate"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+)
+
+// maxDetailItems caps the number of resolved IPs and similar lists shown in an event's detail
+const maxDetailItems = 5
+
+// flaggedCertMatch selects certificates which were expired, self-signed, short lived, or freshly issued
+var flaggedCertMatch = bson.M{"$or": []bson.M{
+	{"dat.certs.self_signed": true},
+	{"dat.certs.expired": true},
+	{"dat.certs.short_lived": true},
+	{"dat.certs.freshly_issued": true},
+}}
+
+// eventSource is an aggregation over one of the analysis collections along with
+// the conversion of each of its results into timeline events
+type eventSource struct {
+	collection string
+	pipeline   []bson.M
+	events     func(raw bson.Raw) ([]Event, error)
+}
+
+// run executes the source's aggregation and converts the results into events
+func (s eventSource) run(db *mgo.Database) ([]Event, error) {
+	var events []Event
+	var raw bson.Raw
+
+	iter := db.C(s.collection).Pipe(s.pipeline).AllowDiskUse().Iter()
+	for iter.Next(&raw) {
+		sourceEvents, err := s.events(raw)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		events = append(events, sourceEvents...)
+	}
+	return events, iter.Close()
+}
+
+// Results gathers every artifact involving the given host across the analysis
+// collections and returns them as a single timeline ordered by chunk and time.
+// The host is matched by its IP address and network UUID.
+func Results(res *resources.Resources, host data.UniqueIP) ([]Event, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	db := ssn.DB(res.DB.GetSelectedDB())
+
+	var events []Event
+	peers := make(data.UniqueIPSet)
+	for _, source := range hostSources(res.Config, host, peers) {
+		sourceEvents, err := source.run(db)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, sourceEvents...)
+	}
+
+	// the peers are known once the connection source has run
+	if len(peers) > 0 {
+		peerEvents, err := peerCertificateSource(res.Config, peers.Items()).run(db)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, peerEvents...)
+	}
+
+	sortEvents(events)
+	return events, nil
+}
+
+// ErrHostNotFound is returned by ResolveHost if no host has the given IP address
+var ErrHostNotFound = errors.New("no host with the given IP address was found")
+
+// AmbiguousHostError is returned by ResolveHost if hosts on several networks
+// share the given IP address
+type AmbiguousHostError struct {
+	Hosts []data.UniqueIP
+}
+
+func (e AmbiguousHostError) Error() string {
+	networks := make([]string, 0, len(e.Hosts))
+	for _, host := range e.Hosts {
+		id, _ := uuid.FromBytes(host.NetworkUUID.Data)
+		networks = append(networks, host.NetworkName+" ("+id.String()+")")
+	}
+	return "the IP address was seen on several networks, select one of " + strings.Join(networks, ", ")
+}
+
+// ResolveHost finds the host with the given IP address in the selected database.
+// A private IP address may belong to several hosts on different networks, in which
+// case networkUUID selects between them. Pass uuid.Nil to accept any network.
+func ResolveHost(res *resources.Resources, ip net.IP, networkUUID uuid.UUID) (data.UniqueIP, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	selector := bson.M{"ip": ip.String()}
+	if networkUUID != uuid.Nil {
+		selector["network_uuid"] = bson.Binary{Kind: bson.BinaryUUID, Data: networkUUID[:]}
+	}
+
+	var hosts []data.UniqueIP
+	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.HostTable).
+		Find(selector).Select(bson.M{"ip": 1, "network_uuid": 1, "network_name": 1}).All(&hosts)
+	if err != nil {
+		return data.UniqueIP{}, err
+	}
+
+	switch len(hosts) {
+	case 0:
+		return data.UniqueIP{}, ErrHostNotFound
+	case 1:
+		return hosts[0], nil
+	default:
+		return data.UniqueIP{}, AmbiguousHostError{Hosts: hosts}
+	}
+}
+
+// hostSources returns the sources of the events involving the host. The connection
+// source records the peers the host connected to in peers as its results are converted.
+func hostSources(conf *config.Config, host data.UniqueIP, peers data.UniqueIPSet) []eventSource {
+	return []eventSource{
+		connectionSource(conf, host, peers),
+		beaconSource(conf, host),
+		fqdnBeaconSource(conf.T.BeaconSNI.BeaconSNITable, host, BeaconSNIEvent),
+		fqdnBeaconSource(conf.T.BeaconProxy.BeaconProxyTable, host, BeaconProxyEvent),
+		fqdnBeaconSource(conf.T.BeaconDNS.BeaconDNSTable, host, BeaconDNSEvent),
+		dnsSource(conf, host),
+		userAgentSource(conf, host),
+		invalidCertificateSource(conf, host),
+		presentedCertificateSource(conf, host.BSONKey(), "presented certificate", false),
+		blacklistSource(conf, host),
+	}
+}
+
+// pairMatch selects the entries keyed by a UniqueIPPair in which the host was the source or destination
+func pairMatch(host data.UniqueIP) bson.M {
+	return bson.M{"$match": bson.M{"$or": []bson.M{host.AsSrc().BSONKey(), host.AsDst().BSONKey()}}}
+}
+
+// connectionSource returns an event for each chunk in which the host connected to or was
+// contacted by a peer. The peers the host connected to are added to peers.
+func connectionSource(conf *config.Config, host data.UniqueIP, peers data.UniqueIPSet) eventSource {
+	pipeline := []bson.M{
+		pairMatch(host),
+		{"$unwind": "$dat"},
+		{"$project": bson.M{
+			"src":              1,
+			"dst":              1,
+			"src_network_uuid": 1,
+			"dst_network_uuid": 1,
+			"src_network_name": 1,
+			"dst_network_name": 1,
+			"strobe":           1,
+			"cid":              "$dat.cid",
+			"count":            "$dat.count",
+			"tbytes":           "$dat.tbytes",
+			"tdur":             "$dat.tdur",
+			"first_seen":       bson.M{"$min": "$dat.ts"},
+			"last_seen":        bson.M{"$max": "$dat.ts"},
+		}},
+	}
+
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			data.UniqueIPPair `bson:",inline"`
+			Strobe            bool    `bson:"strobe"`
+			CID               int     `bson:"cid"`
+			Count             int64   `bson:"count"`
+			TotalBytes        int64   `bson:"tbytes"`
+			TotalDuration     float64 `bson:"tdur"`
+			FirstSeen         int64   `bson:"first_seen"`
+			LastSeen          int64   `bson:"last_seen"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		event := Event{
+			CID:       entry.CID,
+			FirstSeen: entry.FirstSeen,
+			LastSeen:  entry.LastSeen,
+			Type:      ConnectionEvent,
+		}
+		if entry.Strobe {
+			event.Type = StrobeEvent
+		}
+
+		direction := "outbound"
+		if entry.UniqueSrcIP.Unpair().Equal(host) {
+			event.NetworkName = entry.SrcNetworkName
+			event.Peer = entry.DstIP
+			peers.Insert(entry.UniqueDstIP.Unpair())
+		} else {
+			direction = "inbound"
+			event.NetworkName = entry.DstNetworkName
+			event.Peer = entry.SrcIP
+		}
+
+		event.Detail = fmt.Sprintf("%s: %d connections; %d bytes; %.0fs total duration",
+			direction, entry.Count, entry.TotalBytes, entry.TotalDuration)
+		return []Event{event}, nil
+	}
+
+	return eventSource{collection: conf.T.Structure.UniqueConnTable, pipeline: pipeline, events: events}
+}
+
+// beaconSource returns the beacons the host was the source or destination of
+func beaconSource(conf *config.Config, host data.UniqueIP) eventSource {
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			data.UniqueIPPair `bson:",inline"`
+			CID               int     `bson:"cid"`
+			Score             float64 `bson:"score"`
+			Connections       int64   `bson:"connection_count"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		event := Event{CID: entry.CID, Type: BeaconEvent}
+		direction := "outbound"
+		if entry.UniqueSrcIP.Unpair().Equal(host) {
+			event.NetworkName = entry.SrcNetworkName
+			event.Peer = entry.DstIP
+		} else {
+			direction = "inbound"
+			event.NetworkName = entry.DstNetworkName
+			event.Peer = entry.SrcIP
+		}
+		event.Detail = fmt.Sprintf("%s: score %.3f; %d connections", direction, entry.Score, entry.Connections)
+		return []Event{event}, nil
+	}
+
+	return eventSource{collection: conf.T.Beacon.BeaconTable, pipeline: []bson.M{pairMatch(host)}, events: events}
+}
+
+// fqdnBeaconSource returns the beacons from the host to FQDNs in the given collection
+func fqdnBeaconSource(collection string, host data.UniqueIP, eventType string) eventSource {
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			SrcNetworkName string  `bson:"src_network_name"`
+			FQDN           string  `bson:"fqdn"`
+			CID            int     `bson:"cid"`
+			Score          float64 `bson:"score"`
+			Connections    int64   `bson:"connection_count"`
+			Proxy          struct {
+				IP string `bson:"ip"`
+			} `bson:"proxy"`
+			Resolver struct {
+				IP string `bson:"ip"`
+			} `bson:"resolver"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		detail := fmt.Sprintf("score %.3f; %d connections", entry.Score, entry.Connections)
+		if entry.Proxy.IP != "" {
+			detail += " via proxy " + entry.Proxy.IP
+		}
+		if entry.Resolver.IP != "" {
+			detail += " via resolver " + entry.Resolver.IP
+		}
+		return []Event{{
+			CID:         entry.CID,
+			Type:        eventType,
+			NetworkName: entry.SrcNetworkName,
+			Peer:        entry.FQDN,
+			Detail:      detail,
+		}}, nil
+	}
+
+	return eventSource{
+		collection: collection,
+		pipeline:   []bson.M{{"$match": host.AsSrc().BSONKey()}},
+		events:     events,
+	}
+}
+
+// uniqueIPArrayMatch selects the entries with a dat subdocument listing the host
+// in the given array of UniqueIPs
+func uniqueIPArrayMatch(array string, host data.UniqueIP) bson.M {
+	return bson.M{"$match": bson.M{"dat." + array: bson.M{"$elemMatch": host.BSONKey()}}}
+}
+
+// filterUniqueIP builds an expression which keeps the entries of an array of UniqueIPs
+// matching the host
+func filterUniqueIP(input string, host data.UniqueIP) bson.M {
+	return bson.M{"$filter": bson.M{
+		"input": input,
+		"cond": bson.M{"$and": []bson.M{
+			{"$eq": []interface{}{"$$this.ip", host.IP}},
+			{"$eq": []interface{}{"$$this.network_uuid", host.NetworkUUID}},
+		}},
+	}}
+}
+
+// uniqueIPArrayEntry holds the host's entry in an array of UniqueIPs after filterUniqueIP
+type uniqueIPArrayEntry []struct {
+	NetworkName string `bson:"network_name"`
+}
+
+// networkName returns the network name of the host's entry
+func (e uniqueIPArrayEntry) networkName() string {
+	if len(e) == 0 {
+		return ""
+	}
+	return e[0].NetworkName
+}
+
+// dnsSource returns the hostnames the host queried along with the IPs they resolved to
+func dnsSource(conf *config.Config, host data.UniqueIP) eventSource {
+	pipeline := []bson.M{
+		uniqueIPArrayMatch("src_ips", host),
+		{"$unwind": "$dat"},
+		uniqueIPArrayMatch("src_ips", host),
+		{"$project": bson.M{
+			"host":        1,
+			"blacklisted": 1,
+			"cid":         "$dat.cid",
+			"ips":         "$dat.ips.ip",
+			"src":         filterUniqueIP("$dat.src_ips", host),
+		}},
+	}
+
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			Host        string             `bson:"host"`
+			Blacklisted bool               `bson:"blacklisted"`
+			CID         int                `bson:"cid"`
+			IPs         []string           `bson:"ips"`
+			Src         uniqueIPArrayEntry `bson:"src"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		detail := "no answers"
+		if len(entry.IPs) > 0 {
+			detail = "resolved to " + joinSample(entry.IPs)
+		}
+		events := []Event{{
+			CID:         entry.CID,
+			Type:        DNSEvent,
+			NetworkName: entry.Src.networkName(),
+			Peer:        entry.Host,
+			Detail:      detail,
+		}}
+
+		if entry.Blacklisted {
+			events = append(events, Event{
+				CID:         entry.CID,
+				Type:        BlacklistEvent,
+				NetworkName: entry.Src.networkName(),
+				Peer:        entry.Host,
+				Detail:      "queried blacklisted hostname",
+			})
+		}
+		return events, nil
+	}
+
+	return eventSource{collection: conf.T.DNS.HostnamesTable, pipeline: pipeline, events: events}
+}
+
+// userAgentSource returns the user agents and JA3 hashes used by the host
+func userAgentSource(conf *config.Config, host data.UniqueIP) eventSource {
+	pipeline := []bson.M{
+		uniqueIPArrayMatch("orig_ips", host),
+		{"$unwind": "$dat"},
+		uniqueIPArrayMatch("orig_ips", host),
+		{"$project": bson.M{
+			"user_agent": 1,
+			"ja3":        1,
+			"cid":        "$dat.cid",
+			"seen":       "$dat.seen",
+			"src":        filterUniqueIP("$dat.orig_ips", host),
+		}},
+	}
+
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			UserAgent string             `bson:"user_agent"`
+			JA3       bool               `bson:"ja3"`
+			CID       int                `bson:"cid"`
+			Seen      int64              `bson:"seen"`
+			Src       uniqueIPArrayEntry `bson:"src"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		detail := "user agent: " + entry.UserAgent
+		if entry.JA3 {
+			detail = "ja3: " + entry.UserAgent
+		}
+		return []Event{{
+			CID:         entry.CID,
+			Type:        UserAgentEvent,
+			NetworkName: entry.Src.networkName(),
+			Detail:      fmt.Sprintf("%s (seen %d times across all hosts)", detail, entry.Seen),
+		}}, nil
+	}
+
+	return eventSource{collection: conf.T.UserAgent.UserAgentTable, pipeline: pipeline, events: events}
+}
+
+// invalidCertificateSource returns the invalid certificates the host was presented with by servers
+func invalidCertificateSource(conf *config.Config, host data.UniqueIP) eventSource {
+	pipeline := []bson.M{
+		uniqueIPArrayMatch("orig_ips", host),
+		{"$unwind": "$dat"},
+		uniqueIPArrayMatch("orig_ips", host),
+		{"$project": bson.M{
+			"ip":     1,
+			"cid":    "$dat.cid",
+			"icodes": "$dat.icodes",
+			"src":    filterUniqueIP("$dat.orig_ips", host),
+		}},
+	}
+
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			IP     string             `bson:"ip"`
+			CID    int                `bson:"cid"`
+			ICodes []string           `bson:"icodes"`
+			Src    uniqueIPArrayEntry `bson:"src"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		return []Event{{
+			CID:         entry.CID,
+			Type:        CertificateEvent,
+			NetworkName: entry.Src.networkName(),
+			Peer:        entry.IP,
+			Detail:      "presented invalid certificate: " + strings.Join(entry.ICodes, " "),
+		}}, nil
+	}
+
+	return eventSource{collection: conf.T.Cert.CertificateTable, pipeline: pipeline, events: events}
+}
+
+// peerCertificateSource returns the flagged certificates presented by the peers the host connected to
+func peerCertificateSource(conf *config.Config, peers []data.UniqueIP) eventSource {
+	keys := make([]bson.M, 0, len(peers))
+	for _, peer := range peers {
+		keys = append(keys, peer.BSONKey())
+	}
+	return presentedCertificateSource(conf, bson.M{"$or": keys}, "peer presented certificate", true)
+}
+
+// presentedCertificateSource returns the certificates presented by the servers matching
+// the given selector. If flaggedOnly is set, only flagged certificates are returned and the
+// server is recorded as the peer.
+func presentedCertificateSource(conf *config.Config, selector bson.M, description string, flaggedOnly bool) eventSource {
+	pipeline := []bson.M{
+		{"$match": selector},
+		{"$unwind": "$dat"},
+		{"$unwind": "$dat.certs"},
+	}
+	if flaggedOnly {
+		pipeline = append(pipeline, bson.M{"$match": flaggedCertMatch})
+	}
+	pipeline = append(pipeline, bson.M{"$project": bson.M{
+		"ip":           1,
+		"network_name": 1,
+		"cid":          "$dat.cid",
+		"cert":         "$dat.certs",
+	}})
+
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			IP          string              `bson:"ip"`
+			NetworkName string              `bson:"network_name"`
+			CID         int                 `bson:"cid"`
+			Cert        certificate.Details `bson:"cert"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		detail := description + " " + entry.Cert.Subject + " issued by " + entry.Cert.Issuer
+		if flags := entry.Cert.Flags(); len(flags) > 0 {
+			detail += " (" + strings.Join(flags, " ") + ")"
+		}
+
+		event := Event{
+			CID:       entry.CID,
+			FirstSeen: entry.Cert.FirstSeen,
+			LastSeen:  entry.Cert.LastSeen,
+			Type:      CertificateEvent,
+			Detail:    detail,
+		}
+		if flaggedOnly {
+			event.Peer = entry.IP
+		} else {
+			event.NetworkName = entry.NetworkName
+		}
+		return []Event{event}, nil
+	}
+
+	return eventSource{collection: conf.T.Cert.CertificateTable, pipeline: pipeline, events: events}
+}
+
+// blacklistSource returns the blacklisted peers the host talked to, along with an
+// event for the host itself if it appears on a threat intel list
+func blacklistSource(conf *config.Config, host data.UniqueIP) eventSource {
+	events := func(raw bson.Raw) ([]Event, error) {
+		var entry struct {
+			NetworkName string `bson:"network_name"`
+			Blacklisted bool   `bson:"blacklisted"`
+			CID         int    `bson:"cid"`
+			Dat         []struct {
+				BL struct {
+					IP string `bson:"ip"`
+				} `bson:"bl"`
+				InCount    int64 `bson:"bl_in_count"`
+				OutCount   int64 `bson:"bl_out_count"`
+				ConnCount  int64 `bson:"bl_conn_count"`
+				TotalBytes int64 `bson:"bl_total_bytes"`
+				CID        int   `bson:"cid"`
+			} `bson:"dat"`
+		}
+		if err := raw.Unmarshal(&entry); err != nil {
+			return nil, err
+		}
+
+		var events []Event
+		if entry.Blacklisted {
+			events = append(events, Event{
+				CID:         entry.CID,
+				Type:        BlacklistEvent,
+				NetworkName: entry.NetworkName,
+				Detail:      "host appears on a threat intel list",
+			})
+		}
+
+		for _, dat := range entry.Dat {
+			if dat.BL.IP == "" {
+				continue
+			}
+			direction := "connected to"
+			if dat.InCount > 0 {
+				direction = "contacted by"
+			}
+			events = append(events, Event{
+				CID:         dat.CID,
+				Type:        BlacklistEvent,
+				NetworkName: entry.NetworkName,
+				Peer:        dat.BL.IP,
+				Detail: fmt.Sprintf("%s blacklisted host: %d connections; %d bytes",
+					direction, dat.ConnCount, dat.TotalBytes),
+			})
+		}
+		return events, nil
+	}
+
+	return eventSource{
+		collection: conf.T.Structure.HostTable,
+		pipeline:   []bson.M{{"$match": host.BSONKey()}},
+		events:     events,
+	}
+}
+
+// joinSample joins up to maxDetailItems values for display
+func joinSample(values []string) string {
+	if len(values) <= maxDetailItems {
+		return strings.Join(values, " ")
+	}
+	return strings.Join(values[:maxDetailItems], " ") + fmt.Sprintf(" and %d more", len(values)-maxDetailItems)
+}
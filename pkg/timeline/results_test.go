@@ -0,0 +1,211 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/activecm/rita-legacy/config"
+	"github.com/activecm/rita-legacy/pkg/data"
+	"github.com/activecm/rita-legacy/util"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/require"
+)
+
+var sensorNetworkUUID = bson.Binary{
+	Kind: bson.BinaryUUID,
+	Data: []byte{0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x4a, 0x5b, 0x8c, 0x7d, 0x9e, 0x8f, 0x7a, 0x6b, 0x5c, 0x4d},
+}
+
+func testHost() data.UniqueIP {
+	return data.UniqueIP{IP: "10.0.0.1", NetworkUUID: sensorNetworkUUID, NetworkName: "sensor"}
+}
+
+func testConfig(t *testing.T) *config.Config {
+	conf, err := config.LoadTestingConfig("mongodb://localhost:27017")
+	require.NoError(t, err)
+	return conf
+}
+
+// toRaw encodes a document the way it is handed to an eventSource by the aggregation
+func toRaw(t *testing.T, doc bson.M) bson.Raw {
+	encoded, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return bson.Raw{Kind: 0x03, Data: encoded}
+}
+
+// sourceEvents converts each document with the source
+func sourceEvents(t *testing.T, source eventSource, docs ...bson.M) []Event {
+	var events []Event
+	for _, doc := range docs {
+		docEvents, err := source.events(toRaw(t, doc))
+		require.NoError(t, err)
+		events = append(events, docEvents...)
+	}
+	return events
+}
+
+func TestHostSourcesPipelines(t *testing.T) {
+	conf := testConfig(t)
+	host := testHost()
+
+	pairKey := bson.M{"$match": bson.M{"$or": []bson.M{
+		{"src": "10.0.0.1", "src_network_uuid": sensorNetworkUUID},
+		{"dst": "10.0.0.1", "dst_network_uuid": sensorNetworkUUID},
+	}}}
+	srcKey := bson.M{"$match": bson.M{"src": "10.0.0.1", "src_network_uuid": sensorNetworkUUID}}
+	hostKey := bson.M{"ip": "10.0.0.1", "network_uuid": sensorNetworkUUID}
+	arrayKey := func(array string) bson.M {
+		return bson.M{"$match": bson.M{"dat." + array: bson.M{"$elemMatch": hostKey}}}
+	}
+
+	testCases := []struct {
+		collection string
+		match      bson.M
+	}{
+		{conf.T.Structure.UniqueConnTable, pairKey},
+		{conf.T.Beacon.BeaconTable, pairKey},
+		{conf.T.BeaconSNI.BeaconSNITable, srcKey},
+		{conf.T.BeaconProxy.BeaconProxyTable, srcKey},
+		{conf.T.BeaconDNS.BeaconDNSTable, srcKey},
+		{conf.T.DNS.HostnamesTable, arrayKey("src_ips")},
+		{conf.T.UserAgent.UserAgentTable, arrayKey("orig_ips")},
+		{conf.T.Cert.CertificateTable, arrayKey("orig_ips")},
+		{conf.T.Cert.CertificateTable, bson.M{"$match": hostKey}},
+		{conf.T.Structure.HostTable, bson.M{"$match": hostKey}},
+	}
+
+	sources := hostSources(conf, host, make(data.UniqueIPSet))
+	require.Len(t, sources, len(testCases))
+	for i, test := range testCases {
+		require.Equal(t, test.collection, sources[i].collection, "source %d", i)
+		require.Equal(t, test.match, sources[i].pipeline[0], "source %d", i)
+	}
+
+	// the array sources only keep the host's own entry when projecting
+	project := sources[5].pipeline[len(sources[5].pipeline)-1]["$project"].(bson.M)
+	require.Equal(t, bson.M{"$filter": bson.M{
+		"input": "$dat.src_ips",
+		"cond": bson.M{"$and": []bson.M{
+			{"$eq": []interface{}{"$$this.ip", "10.0.0.1"}},
+			{"$eq": []interface{}{"$$this.network_uuid", sensorNetworkUUID}},
+		}},
+	}}, project["src"])
+
+	peer := data.UniqueIP{IP: "1.2.3.4", NetworkUUID: util.PublicNetworkUUID}
+	peerSource := peerCertificateSource(conf, []data.UniqueIP{peer})
+	require.Equal(t, conf.T.Cert.CertificateTable, peerSource.collection)
+	require.Equal(t, bson.M{"$match": bson.M{"$or": []bson.M{peer.BSONKey()}}}, peerSource.pipeline[0])
+	require.Equal(t, bson.M{"$match": flaggedCertMatch}, peerSource.pipeline[3])
+}
+
+func TestHostSourcesEvents(t *testing.T) {
+	conf := testConfig(t)
+	host := testHost()
+	peers := make(data.UniqueIPSet)
+	sources := hostSources(conf, host, peers)
+
+	var events []Event
+
+	events = append(events, sourceEvents(t, sources[0],
+		bson.M{
+			"src": "10.0.0.1", "src_network_uuid": sensorNetworkUUID, "src_network_name": "sensor",
+			"dst": "1.2.3.4", "dst_network_uuid": util.PublicNetworkUUID, "dst_network_name": util.PublicNetworkName,
+			"cid": 0, "count": 10, "tbytes": 2000, "tdur": 30.0, "first_seen": 100, "last_seen": 900,
+		},
+		// the same IP on the sensor network is the destination, not the source
+		bson.M{
+			"src": "10.0.0.1", "src_network_uuid": util.UnknownPrivateNetworkUUID, "src_network_name": util.UnknownPrivateNetworkName,
+			"dst": "10.0.0.1", "dst_network_uuid": sensorNetworkUUID, "dst_network_name": "sensor",
+			"strobe": true, "cid": 1, "count": 90000, "tbytes": 0, "tdur": 0.0,
+		},
+	)...)
+	require.Equal(t, []data.UniqueIP{{IP: "1.2.3.4", NetworkUUID: util.PublicNetworkUUID, NetworkName: util.PublicNetworkName}}, peers.Items())
+
+	events = append(events, sourceEvents(t, sources[1], bson.M{
+		"src": "10.0.0.1", "src_network_uuid": sensorNetworkUUID, "src_network_name": "sensor",
+		"dst": "1.2.3.4", "dst_network_uuid": util.PublicNetworkUUID, "dst_network_name": util.PublicNetworkName,
+		"cid": 0, "score": 0.9, "connection_count": 10,
+	})...)
+	events = append(events, sourceEvents(t, sources[2], bson.M{
+		"src_network_name": "sensor", "fqdn": "c2.example.com", "cid": 0, "score": 0.8, "connection_count": 20,
+	})...)
+	events = append(events, sourceEvents(t, sources[3], bson.M{
+		"src_network_name": "sensor", "fqdn": "proxied.example.com", "cid": 1, "score": 0.7, "connection_count": 30,
+		"proxy": bson.M{"ip": "10.0.0.254"},
+	})...)
+	events = append(events, sourceEvents(t, sources[4], bson.M{
+		"src_network_name": "sensor", "fqdn": "dns.example.com", "cid": 1, "score": 0.6, "connection_count": 40,
+		"resolver": bson.M{"ip": "10.0.0.53"},
+	})...)
+	events = append(events, sourceEvents(t, sources[5], bson.M{
+		"host": "bad.example.com", "blacklisted": true, "cid": 0, "ips": []string{"5.6.7.8"},
+		"src": []bson.M{{"ip": "10.0.0.1", "network_uuid": sensorNetworkUUID, "network_name": "sensor"}},
+	})...)
+	events = append(events, sourceEvents(t, sources[6], bson.M{
+		"user_agent": "curl/7.68.0", "cid": 0, "seen": 3,
+		"src": []bson.M{{"ip": "10.0.0.1", "network_uuid": sensorNetworkUUID, "network_name": "sensor"}},
+	})...)
+	events = append(events, sourceEvents(t, sources[7], bson.M{
+		"ip": "5.6.7.8", "cid": 1, "icodes": []string{"self signed certificate"},
+		"src": []bson.M{{"ip": "10.0.0.1", "network_uuid": sensorNetworkUUID, "network_name": "sensor"}},
+	})...)
+	events = append(events, sourceEvents(t, sources[8], bson.M{
+		"ip": "10.0.0.1", "network_name": "sensor", "cid": 1,
+		"cert": bson.M{"subject": "CN=host", "issuer": "CN=host", "self_signed": true, "first_seen": 500, "last_seen": 600},
+	})...)
+	events = append(events, sourceEvents(t, sources[9], bson.M{
+		"network_name": "sensor", "blacklisted": true, "cid": 0,
+		"dat": []bson.M{
+			{"cid": 0},
+			{"bl": bson.M{"ip": "1.2.3.4"}, "bl_out_count": 1, "bl_conn_count": 10, "bl_total_bytes": 2000, "cid": 0},
+		},
+	})...)
+	events = append(events, sourceEvents(t, peerCertificateSource(conf, peers.Items()), bson.M{
+		"ip": "1.2.3.4", "network_name": util.PublicNetworkName, "cid": 0,
+		"cert": bson.M{"subject": "CN=bad", "issuer": "CN=bad", "self_signed": true, "first_seen": 200, "last_seen": 300},
+	})...)
+
+	sortEvents(events)
+
+	require.Equal(t, []Event{
+		{CID: 0, FirstSeen: 100, LastSeen: 900, Type: ConnectionEvent, NetworkName: "sensor", Peer: "1.2.3.4",
+			Detail: "outbound: 10 connections; 2000 bytes; 30s total duration"},
+		{CID: 0, FirstSeen: 200, LastSeen: 300, Type: CertificateEvent, Peer: "1.2.3.4",
+			Detail: "peer presented certificate CN=bad issued by CN=bad (self-signed)"},
+		{CID: 0, Type: BeaconEvent, NetworkName: "sensor", Peer: "1.2.3.4",
+			Detail: "outbound: score 0.900; 10 connections"},
+		{CID: 0, Type: BlacklistEvent, NetworkName: "sensor",
+			Detail: "host appears on a threat intel list"},
+		{CID: 0, Type: BlacklistEvent, NetworkName: "sensor", Peer: "1.2.3.4",
+			Detail: "connected to blacklisted host: 10 connections; 2000 bytes"},
+		{CID: 0, Type: BlacklistEvent, NetworkName: "sensor", Peer: "bad.example.com",
+			Detail: "queried blacklisted hostname"},
+		{CID: 0, Type: DNSEvent, NetworkName: "sensor", Peer: "bad.example.com",
+			Detail: "resolved to 5.6.7.8"},
+		{CID: 0, Type: BeaconSNIEvent, NetworkName: "sensor", Peer: "c2.example.com",
+			Detail: "score 0.800; 20 connections"},
+		{CID: 0, Type: UserAgentEvent, NetworkName: "sensor",
+			Detail: "user agent: curl/7.68.0 (seen 3 times across all hosts)"},
+		{CID: 1, FirstSeen: 500, LastSeen: 600, Type: CertificateEvent, NetworkName: "sensor",
+			Detail: "presented certificate CN=host issued by CN=host (self-signed)"},
+		{CID: 1, Type: CertificateEvent, NetworkName: "sensor", Peer: "5.6.7.8",
+			Detail: "presented invalid certificate: self signed certificate"},
+		{CID: 1, Type: BeaconDNSEvent, NetworkName: "sensor", Peer: "dns.example.com",
+			Detail: "score 0.600; 40 connections via resolver 10.0.0.53"},
+		{CID: 1, Type: BeaconProxyEvent, NetworkName: "sensor", Peer: "proxied.example.com",
+			Detail: "score 0.700; 30 connections via proxy 10.0.0.254"},
+		{CID: 1, Type: StrobeEvent, NetworkName: "sensor", Peer: "10.0.0.1",
+			Detail: "inbound: 90000 connections; 0 bytes; 0s total duration"},
+	}, events)
+}
+
+func TestAmbiguousHostError(t *testing.T) {
+	err := AmbiguousHostError{Hosts: []data.UniqueIP{
+		testHost(),
+		{IP: "10.0.0.1", NetworkUUID: util.UnknownPrivateNetworkUUID, NetworkName: util.UnknownPrivateNetworkName},
+	}}
+	require.Equal(t,
+		"the IP address was seen on several networks, select one of "+
+			"sensor (a1b2c3d4-e5f6-4a5b-8c7d-9e8f7a6b5c4d), Unknown Private (ffffffff-ffff-ffff-ffff-fffffffffffe)",
+		err.Error(),
+	)
+}
@@ -0,0 +1,51 @@
+package timeline
+
+import "sort"
+
+// Event types recorded in a host's timeline
+const (
+	ConnectionEvent  = "connection"
+	StrobeEvent      = "strobe"
+	BeaconEvent      = "beacon"
+	BeaconSNIEvent   = "sni-beacon"
+	BeaconProxyEvent = "proxy-beacon"
+	BeaconDNSEvent   = "dns-beacon"
+	DNSEvent         = "dns"
+	UserAgentEvent   = "useragent"
+	CertificateEvent = "certificate"
+	BlacklistEvent   = "blacklist"
+)
+
+// Event is a single artifact involving the host under investigation. Artifacts
+// are recorded per chunk. Only some of them record when they occurred; the
+// others leave FirstSeen and LastSeen at 0.
+type Event struct {
+	CID         int    `bson:"cid"`
+	FirstSeen   int64  `bson:"first_seen"`
+	LastSeen    int64  `bson:"last_seen"`
+	Type        string `bson:"type"`
+	NetworkName string `bson:"network_name"` // network of the host under investigation
+	Peer        string `bson:"peer"`         // IP address or FQDN the host interacted with, if any
+	Detail      string `bson:"detail"`
+}
+
+// sortEvents orders events by chunk and then by time. Events without a time
+// are placed after the timed events of their chunk since they summarize the chunk.
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.CID != b.CID {
+			return a.CID < b.CID
+		}
+		if (a.FirstSeen == 0) != (b.FirstSeen == 0) {
+			return b.FirstSeen == 0
+		}
+		if a.FirstSeen != b.FirstSeen {
+			return a.FirstSeen < b.FirstSeen
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Peer < b.Peer
+	})
+}
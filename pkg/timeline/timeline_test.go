@@ -0,0 +1,34 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortEvents(t *testing.T) {
+	events := []Event{
+		{CID: 1, Type: BeaconEvent, Peer: "1.2.3.4"},
+		{CID: 1, FirstSeen: 200, Type: ConnectionEvent, Peer: "1.2.3.4"},
+		{CID: 0, Type: DNSEvent, Peer: "b.example.com"},
+		{CID: 0, Type: DNSEvent, Peer: "a.example.com"},
+		{CID: 0, FirstSeen: 300, Type: CertificateEvent},
+		{CID: 0, FirstSeen: 100, Type: ConnectionEvent, Peer: "5.6.7.8"},
+	}
+
+	sortEvents(events)
+
+	require.Equal(t, []Event{
+		{CID: 0, FirstSeen: 100, Type: ConnectionEvent, Peer: "5.6.7.8"},
+		{CID: 0, FirstSeen: 300, Type: CertificateEvent},
+		{CID: 0, Type: DNSEvent, Peer: "a.example.com"},
+		{CID: 0, Type: DNSEvent, Peer: "b.example.com"},
+		{CID: 1, FirstSeen: 200, Type: ConnectionEvent, Peer: "1.2.3.4"},
+		{CID: 1, Type: BeaconEvent, Peer: "1.2.3.4"},
+	}, events)
+}
+
+func TestJoinSample(t *testing.T) {
+	require.Equal(t, "1.1.1.1 2.2.2.2", joinSample([]string{"1.1.1.1", "2.2.2.2"}))
+	require.Equal(t, "a b c d e and 2 more", joinSample([]string{"a", "b", "c", "d", "e", "f", "g"}))
+}
@@ -39,6 +39,7 @@ type LongConnResult struct {
 	MaxDuration       float64  `bson:"maxdur"`
 	Tuples            []string `bson:"tuples"`
 	Open              bool     `bson:"open"`
+	Suppressed        bool     `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
 
 // OpenConnResult represents a pair of hosts that currently
@@ -52,6 +53,7 @@ type OpenConnResult struct {
 	Duration          float64 `bson:"duration"`
 	Tuple             string  `bson:"tuple"`
 	UID               string  `bson:"uid"`
+	Suppressed        bool    `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
 
 // ConnState is used to determine if a particular
@@ -1,20 +1,46 @@
 package uconn
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
+	"github.com/activecm/rita-legacy/pkg/asset"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
 // LongConnResults returns long connections longer than the given thresh in
 // seconds. The results will be sorted, descending by duration.
 // limit and noLimit control how many results are returned.
-func LongConnResults(res *resources.Resources, thresh int, limit int, noLimit bool) ([]LongConnResult, error) {
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func LongConnResults(res *resources.Resources, thresh int, limit int, noLimit bool, showSuppressed bool) ([]LongConnResult, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var longConnResults []LongConnResult
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(longConnResultsQuery(thresh)).AllowDiskUse().Iter()
+	return collectLongConnResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// LongConnResultsPage returns a page of the connections longer than the given thresh, sorted by
+// duration, along with the total number of them. The first skip results are skipped and at
+// most limit results are returned. Results involving allowlisted values are flagged rather
+// than dropped.
+func LongConnResultsPage(res *resources.Resources, thresh int, skip, limit int) ([]LongConnResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable)
+	iter, total, err := database.PipePage(collection, longConnResultsQuery(thresh), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	longConnResults, err := collectLongConnResults(res, iter, 0, true, true)
+	return longConnResults, total, err
+}
 
-	longConnQuery := []bson.M{
+// longConnResultsQuery gathers the connections longer than the given thresh, sorted by duration
+func longConnResultsQuery(thresh int) []bson.M {
+	return []bson.M{
 		{"$match": bson.M{"dat.maxdur": bson.M{"$gt": thresh}}},
 		{"$project": bson.M{
 			"src":              1,
@@ -64,26 +90,58 @@ func LongConnResults(res *resources.Resources, thresh int, limit int, noLimit bo
 		}},
 		{"$sort": bson.M{"tdur": -1, "maxdur": -1}},
 	}
+}
 
-	if !noLimit {
-		longConnQuery = append(longConnQuery, bson.M{"$limit": limit})
+// collectLongConnResults reads the long connections from iter and labels them with the asset
+// inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectLongConnResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]LongConnResult, error) {
+	longConnResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *LongConnResult) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP)
+	})
+	if err != nil {
+		return longConnResults, err
 	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(longConnQuery).AllowDiskUse().All(&longConnResults)
-
-	return longConnResults, err
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return longConnResults, err
+	}
+	inventory.Label(longConnResults)
 
+	return longConnResults, nil
 }
 
 // OpenConnResults returns open connections. The results will be sorted, descending by duration.
 // limit and noLimit control how many results are returned.
-func OpenConnResults(res *resources.Resources, thresh int, limit int, noLimit bool) ([]OpenConnResult, error) {
+// Results involving allowlisted values are only returned if showSuppressed is set.
+func OpenConnResults(res *resources.Resources, thresh int, limit int, noLimit bool, showSuppressed bool) ([]OpenConnResult, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var openConnResults []OpenConnResult
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(openConnResultsQuery(thresh)).AllowDiskUse().Iter()
+	return collectOpenConnResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// OpenConnResultsPage returns a page of the open connections longer than the given thresh, sorted
+// by duration, along with the total number of them. The first skip results are skipped and at
+// most limit results are returned. Results involving allowlisted values are flagged rather
+// than dropped.
+func OpenConnResultsPage(res *resources.Resources, thresh int, skip, limit int) ([]OpenConnResult, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable)
+	iter, total, err := database.PipePage(collection, openConnResultsQuery(thresh), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	openConnResults, err := collectOpenConnResults(res, iter, 0, true, true)
+	return openConnResults, total, err
+}
 
-	openConnQuery := []bson.M{
+// openConnResultsQuery gathers the open connections longer than the given thresh, sorted by duration
+func openConnResultsQuery(thresh int) []bson.M {
+	return []bson.M{
 		{"$match": bson.M{"open": true}},
 		{"$project": bson.M{
 			"dst":              1,
@@ -113,13 +171,23 @@ func OpenConnResults(res *resources.Resources, thresh int, limit int, noLimit bo
 		}},
 		{"$sort": bson.M{"duration": -1}},
 	}
+}
 
-	if !noLimit {
-		openConnQuery = append(openConnQuery, bson.M{"$limit": limit})
+// collectOpenConnResults reads the open connections from iter and labels them with the asset
+// inventory. Results involving allowlisted values are only returned if showSuppressed is set.
+func collectOpenConnResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]OpenConnResult, error) {
+	openConnResults, err := allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *OpenConnResult) bool {
+		return allowed.ContainsIP(r.SrcIP, r.DstIP)
+	})
+	if err != nil {
+		return openConnResults, err
 	}
 
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.Structure.UniqueConnTable).Pipe(openConnQuery).AllowDiskUse().All(&openConnResults)
-
-	return openConnResults, err
+	inventory, err := asset.Load(res)
+	if err != nil {
+		return openConnResults, err
+	}
+	inventory.Label(openConnResults)
 
+	return openConnResults, nil
 }
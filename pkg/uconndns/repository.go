@@ -0,0 +1,26 @@
+package uconndns
+
+import (
+	"github.com/activecm/rita-legacy/pkg/data"
+)
+
+// Repository for uconndns collection
+type Repository interface {
+	CreateIndexes() error
+	Upsert(uconnDNSMap map[string]*Input)
+}
+
+// Input structure for sending data
+// to the analyzer. Contains a tuple of
+// Src IP/UUID/Name and a domain which the Src IP
+// looked up via DNS.
+// Contains a list of unique time stamps for the
+// queries from the Src for the domain, the last
+// resolver the Src queried, and a count of the queries.
+type Input struct {
+	Hosts           data.UniqueSrcFQDNPair
+	TsList          []int64
+	TsListFull      []int64
+	Resolver        data.UniqueIP
+	ConnectionCount int64
+}
@@ -23,6 +23,7 @@ type Input struct {
 // Result represents a user agent and how many times that user agent
 // was seen in the dataset
 type Result struct {
-	UserAgent string `bson:"user_agent"`
-	TimesUsed int64  `bson:"seen"`
+	UserAgent  string `bson:"user_agent"`
+	TimesUsed  int64  `bson:"seen"`
+	Suppressed bool   `bson:"-" json:"suppressed"` // set if the result involves an allowlisted value
 }
@@ -1,21 +1,45 @@
 package useragent
 
 import (
+	"github.com/activecm/rita-legacy/database"
+	"github.com/activecm/rita-legacy/pkg/allowlist"
 	"github.com/activecm/rita-legacy/resources"
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 )
 
 // Results returns useragents sorted by how many times each useragent was
 // seen in the dataset. sortDirection controls where the useragents are
 // sorted in descending (sortDirection=-1) or ascending order (sortDirection=1).
-// limit and noLimit control how many results are returned.
-func Results(res *resources.Resources, sortDirection, limit int, noLimit bool) ([]Result, error) {
+// limit and noLimit control how many results are returned. Results involving allowlisted
+// values are only returned if showSuppressed is set.
+func Results(res *resources.Resources, sortDirection, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
 	ssn := res.DB.Session.Copy()
 	defer ssn.Close()
 
-	var useragentResults []Result
+	iter := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.UserAgent.UserAgentTable).Pipe(resultsQuery(sortDirection)).AllowDiskUse().Iter()
+	return collectResults(res, iter, limit, noLimit, showSuppressed)
+}
+
+// ResultsPage returns a page of the useragents sorted in sortDirection along with the total
+// number of them. The first skip results are skipped and at most limit results are returned.
+// Results involving allowlisted values are flagged rather than dropped.
+func ResultsPage(res *resources.Resources, sortDirection, skip, limit int) ([]Result, int, error) {
+	ssn := res.DB.Session.Copy()
+	defer ssn.Close()
+
+	collection := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.UserAgent.UserAgentTable)
+	iter, total, err := database.PipePage(collection, resultsQuery(sortDirection), skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	agents, err := collectResults(res, iter, 0, true, true)
+	return agents, total, err
+}
 
-	useragentQuery := []bson.M{
+// resultsQuery sums the times each useragent was seen and sorts them in sortDirection
+func resultsQuery(sortDirection int) []bson.M {
+	return []bson.M{
 		{"$project": bson.M{"user_agent": 1, "seen": "$dat.seen"}},
 		{"$unwind": "$seen"},
 		{"$group": bson.M{
@@ -29,13 +53,12 @@ func Results(res *resources.Resources, sortDirection, limit int, noLimit bool) (
 		}},
 		{"$sort": bson.M{"seen": sortDirection}},
 	}
+}
 
-	if !noLimit {
-		useragentQuery = append(useragentQuery, bson.M{"$limit": limit})
-	}
-
-	err := ssn.DB(res.DB.GetSelectedDB()).C(res.Config.T.UserAgent.UserAgentTable).Pipe(useragentQuery).AllowDiskUse().All(&useragentResults)
-
-	return useragentResults, err
-
+// collectResults reads the useragents from iter. Results involving allowlisted values are only
+// returned if showSuppressed is set.
+func collectResults(res *resources.Resources, iter *mgo.Iter, limit int, noLimit bool, showSuppressed bool) ([]Result, error) {
+	return allowlist.Collect(res, iter, limit, noLimit, showSuppressed, func(allowed *allowlist.Allowlist, r *Result) bool {
+		return allowed.ContainsUserAgent(r.UserAgent) || allowed.ContainsJA3(r.UserAgent)
+	})
 }
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"os"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/beacon"
 	"github.com/activecm/rita-legacy/reporting/templates"
 	"github.com/activecm/rita-legacy/resources"
@@ -30,7 +31,12 @@ func printBeacons(db string, showNetNames bool, res *resources.Resources, logsGe
 		return err
 	}
 
-	data, err := beacon.Results(res, 0)
+	data, err := beacon.Results(res, 0, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -38,27 +44,31 @@ func printBeacons(db string, showNetNames bool, res *resources.Resources, logsGe
 	if len(data) == 0 {
 		w = ""
 	} else {
-		w, err = getBeaconWriter(data, showNetNames)
+		w, err = getBeaconWriter(data, showNetNames, res.Config.S.GeoIP.Enabled, triage)
 		if err != nil {
 			return err
 		}
 	}
 
-	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt, GeoIP: res.Config.S.GeoIP.Enabled})
 }
 
-func getBeaconWriter(beacons []beacon.Result, showNetNames bool) (string, error) {
+func getBeaconWriter(beacons []beacon.Result, showNetNames, showGeo bool, triage database.TriageIndex) (string, error) {
 	tmpl := "<tr>"
 
 	tmpl += "<td>{{printf \"%.3f\" .Score}}</td>"
 
 	if showNetNames {
-		tmpl += "<td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td>{{.SrcIP}}</td><td>{{.DstIP}}</td>"
+		tmpl += "<td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td>"
 	} else {
-		tmpl += "<td>{{.SrcIP}}</td><td>{{.DstIP}}</td>"
+		tmpl += "<td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td>"
 	}
 	tmpl += "<td>{{.Connections}}</td><td>{{printf \"%.3f\" .AvgBytes}}</td><td>{{.TotalBytes}}</td><td>{{printf \"%.3f\" .Ts.Score}}</td>"
 	tmpl += "<td>{{printf \"%.3f\" .Ds.Score}}</td><td>{{printf \"%.3f\" .DurScore}}</td><td>{{printf \"%.3f\" .HistScore}}</td><td>{{.Ts.Mode}}</td>"
+	if showGeo {
+		tmpl += "<td>{{.DstGeo.Country}}</td><td>{{.DstGeo.AS}}</td>"
+	}
+	tmpl += triageCell
 	tmpl += "</tr>\n"
 
 	out, err := template.New("beacon").Parse(tmpl)
@@ -69,7 +79,16 @@ func getBeaconWriter(beacons []beacon.Result, showNetNames bool) (string, error)
 	w := new(bytes.Buffer)
 
 	for _, result := range beacons {
-		err = out.Execute(w, result)
+		// findings triaged as benign are left out of the report
+		entry := triage.Lookup(database.NewIPPairTriageKey(result.UniqueIPPair))
+		if entry.Status == database.TriageBenign {
+			continue
+		}
+
+		err = out.Execute(w, struct {
+			beacon.Result
+			Triage database.TriageEntry
+		}{result, entry})
 		if err != nil {
 			return "", err
 		}
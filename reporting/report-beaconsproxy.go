@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"os"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/beaconproxy"
 	"github.com/activecm/rita-legacy/reporting/templates"
 	"github.com/activecm/rita-legacy/resources"
@@ -30,7 +31,12 @@ func printBeaconsProxy(db string, showNetNames bool, res *resources.Resources, l
 		return err
 	}
 
-	data, err := beaconproxy.Results(res, 0)
+	data, err := beaconproxy.Results(res, 0, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -38,7 +44,7 @@ func printBeaconsProxy(db string, showNetNames bool, res *resources.Resources, l
 	if len(data) == 0 {
 		w = ""
 	} else {
-		w, err = getBeaconProxyWriter(data, showNetNames)
+		w, err = getBeaconProxyWriter(data, showNetNames, triage)
 		if err != nil {
 			return err
 		}
@@ -47,7 +53,7 @@ func printBeaconsProxy(db string, showNetNames bool, res *resources.Resources, l
 	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
 }
 
-func getBeaconProxyWriter(beaconsProxy []beaconproxy.Result, showNetNames bool) (string, error) {
+func getBeaconProxyWriter(beaconsProxy []beaconproxy.Result, showNetNames bool, triage database.TriageIndex) (string, error) {
 	tmpl := "<tr>"
 
 	tmpl += "<td>{{printf \"%.3f\" .Score}}</td>"
@@ -56,16 +62,17 @@ func getBeaconProxyWriter(beaconsProxy []beaconproxy.Result, showNetNames bool)
 		tmpl += "<td>{{.SrcNetworkName}}</td>"
 	}
 
-	tmpl += "<td>{{.SrcIP}}</td><td>{{.FQDN}}</td>"
+	tmpl += "<td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td>{{.FQDN}}</td>"
 
 	if showNetNames {
 		tmpl += "<td>{{.Proxy.NetworkName}}</td>"
 	}
 
-	tmpl += "<td>{{.Proxy.IP}}</td>"
+	tmpl += "<td title=\"{{.Proxy.Asset.Details}}\">{{.Proxy.LabeledIP}}</td>"
 
 	tmpl += "<td>{{.Connections}}</td><td>{{printf \"%.3f\" .Ts.Score}}</td>"
 	tmpl += "<td>{{printf \"%.3f\" .DurScore}}</td><td>{{printf \"%.3f\" .HistScore}}</td><td>{{.Ts.Mode}}</td>"
+	tmpl += triageCell
 	tmpl += "</tr>\n"
 
 	out, err := template.New("beaconproxy").Parse(tmpl)
@@ -76,7 +83,16 @@ func getBeaconProxyWriter(beaconsProxy []beaconproxy.Result, showNetNames bool)
 	w := new(bytes.Buffer)
 
 	for _, result := range beaconsProxy {
-		err = out.Execute(w, result)
+		// findings triaged as benign are left out of the report
+		entry := triage.Lookup(database.NewSrcFQDNTriageKey(result.UniqueSrcFQDNPair))
+		if entry.Status == database.TriageBenign {
+			continue
+		}
+
+		err = out.Execute(w, struct {
+			beaconproxy.Result
+			Triage database.TriageEntry
+		}{result, entry})
 		if err != nil {
 			return "", err
 		}
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"os"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/beaconsni"
 	"github.com/activecm/rita-legacy/reporting/templates"
 	"github.com/activecm/rita-legacy/resources"
@@ -30,7 +31,12 @@ func printBeaconsSNI(db string, showNetNames bool, res *resources.Resources, log
 		return err
 	}
 
-	data, err := beaconsni.Results(res, 0)
+	data, err := beaconsni.Results(res, 0, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -38,7 +44,7 @@ func printBeaconsSNI(db string, showNetNames bool, res *resources.Resources, log
 	if len(data) == 0 {
 		w = ""
 	} else {
-		w, err = getBeaconSNIWriter(data, showNetNames)
+		w, err = getBeaconSNIWriter(data, showNetNames, triage)
 		if err != nil {
 			return err
 		}
@@ -47,18 +53,19 @@ func printBeaconsSNI(db string, showNetNames bool, res *resources.Resources, log
 	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
 }
 
-func getBeaconSNIWriter(beaconsSNI []beaconsni.Result, showNetNames bool) (string, error) {
+func getBeaconSNIWriter(beaconsSNI []beaconsni.Result, showNetNames bool, triage database.TriageIndex) (string, error) {
 	tmpl := "<tr>"
 
 	tmpl += "<td>{{printf \"%.3f\" .Score}}</td>"
 
 	if showNetNames {
-		tmpl += "<td>{{.SrcNetworkName}}</td><td>{{.SrcIP}}</td><td>{{.FQDN}}</td>"
+		tmpl += "<td>{{.SrcNetworkName}}</td><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td>{{.FQDN}}</td>"
 	} else {
-		tmpl += "<td>{{.SrcIP}}</td><td>{{.FQDN}}</td>"
+		tmpl += "<td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td>{{.FQDN}}</td>"
 	}
 	tmpl += "<td>{{.Connections}}</td><td>{{printf \"%.3f\" .AvgBytes}}</td><td>{{.TotalBytes}}</td><td>{{printf \"%.3f\" .Ts.Score}}</td>"
 	tmpl += "<td>{{printf \"%.3f\" .Ds.Score}}</td><td>{{printf \"%.3f\" .DurScore}}</td><td>{{printf \"%.3f\" .HistScore}}</td><td>{{.Ts.Mode}}</td>"
+	tmpl += triageCell
 	tmpl += "</tr>\n"
 
 	out, err := template.New("beaconsni").Parse(tmpl)
@@ -69,7 +76,16 @@ func getBeaconSNIWriter(beaconsSNI []beaconsni.Result, showNetNames bool) (strin
 	w := new(bytes.Buffer)
 
 	for _, result := range beaconsSNI {
-		err = out.Execute(w, result)
+		// findings triaged as benign are left out of the report
+		entry := triage.Lookup(database.NewSrcFQDNTriageKey(result.UniqueSrcFQDNPair))
+		if entry.Status == database.TriageBenign {
+			continue
+		}
+
+		err = out.Execute(w, struct {
+			beaconsni.Result
+			Triage database.TriageEntry
+		}{result, entry})
 		if err != nil {
 			return "", err
 		}
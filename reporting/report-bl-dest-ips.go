@@ -16,7 +16,12 @@ func printBLDestIPs(db string, showNetNames bool, res *resources.Resources, logs
 	}
 	defer f.Close()
 
-	data, err := blacklist.DstIPResults(res, "conn_count", 1000, false)
+	data, err := blacklist.DstIPResults(res, "conn_count", 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -33,10 +38,10 @@ func printBLDestIPs(db string, showNetNames bool, res *resources.Resources, logs
 		return err
 	}
 
-	w, err := getBLIPWriter(data, showNetNames)
+	w, err := getBLIPWriter(data, showNetNames, false, res.Config.S.GeoIP.Enabled, triage)
 	if err != nil {
 		return err
 	}
 
-	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt, GeoIP: res.Config.S.GeoIP.Enabled})
 }
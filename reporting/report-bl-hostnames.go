@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/reporting/templates"
 	"github.com/activecm/rita-legacy/resources"
@@ -19,7 +20,12 @@ func printBLHostnames(db string, showNetNames bool, res *resources.Resources, lo
 	}
 	defer f.Close()
 
-	data, err := blacklist.HostnameResults(res, "conn_count", 1000, false)
+	data, err := blacklist.HostnameResults(res, "conn_count", 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -29,7 +35,7 @@ func printBLHostnames(db string, showNetNames bool, res *resources.Resources, lo
 		return err
 	}
 
-	w, err := getBLHostnameWriter(data, showNetNames)
+	w, err := getBLHostnameWriter(data, showNetNames, triage)
 	if err != nil {
 		return err
 	}
@@ -37,11 +43,12 @@ func printBLHostnames(db string, showNetNames bool, res *resources.Resources, lo
 	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
 }
 
-func getBLHostnameWriter(results []blacklist.HostnameResult, showNetNames bool) (string, error) {
+func getBLHostnameWriter(results []blacklist.HostnameResult, showNetNames bool, triage database.TriageIndex) (string, error) {
 	tmpl := "<tr><td>{{.Host}}</td><td>{{.Connections}}</td><td>{{.UniqueConnections}}</td>" +
 		"<td>{{.TotalBytes}}</td>" +
 		"<td>{{range $idx, $host := .ConnectedHostStrs}}{{if $idx}}, {{end}}{{ $host }}{{end}}</td>" +
-		"</tr>\n"
+		"<td>{{range $idx, $src := .Sources}}{{if $idx}}<br>{{end}}{{ $src }}{{end}}</td>" +
+		triageCell + "</tr>\n"
 
 	out, err := template.New("blhostname").Parse(tmpl)
 	if err != nil {
@@ -51,6 +58,11 @@ func getBLHostnameWriter(results []blacklist.HostnameResult, showNetNames bool)
 	w := new(bytes.Buffer)
 
 	for _, result := range results {
+		// findings triaged as benign are left out of the report
+		entry := triage.Lookup(database.NewFQDNTriageKey(result.Host))
+		if entry.Status == database.TriageBenign {
+			continue
+		}
 
 		//format UniqueIP destinations
 		var connectedHostStrs []string
@@ -60,9 +72,9 @@ func getBLHostnameWriter(results []blacklist.HostnameResult, showNetNames bool)
 			if showNetNames {
 				escapedNetName := strings.ReplaceAll(connectedUniqIP.NetworkName, " ", "_")
 				escapedNetName = strings.ReplaceAll(escapedNetName, ":", "_")
-				connectedIPStr = escapedNetName + ":" + connectedUniqIP.IP
+				connectedIPStr = escapedNetName + ":" + connectedUniqIP.LabeledIP()
 			} else {
-				connectedIPStr = connectedUniqIP.IP
+				connectedIPStr = connectedUniqIP.LabeledIP()
 			}
 
 			connectedHostStrs = append(connectedHostStrs, connectedIPStr)
@@ -72,7 +84,8 @@ func getBLHostnameWriter(results []blacklist.HostnameResult, showNetNames bool)
 		formattedResult := struct {
 			blacklist.HostnameResult
 			ConnectedHostStrs []string
-		}{result, connectedHostStrs}
+			Triage            database.TriageEntry
+		}{result, connectedHostStrs, entry}
 
 		err := out.Execute(w, formattedResult)
 		if err != nil {
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/activecm/rita-legacy/database"
 	"github.com/activecm/rita-legacy/pkg/blacklist"
 	"github.com/activecm/rita-legacy/reporting/templates"
 	"github.com/activecm/rita-legacy/resources"
@@ -19,7 +20,12 @@ func printBLSourceIPs(db string, showNetNames bool, res *resources.Resources, lo
 	}
 	defer f.Close()
 
-	data, err := blacklist.SrcIPResults(res, "conn_count", 1000, false)
+	data, err := blacklist.SrcIPResults(res, "conn_count", 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	triage, err := res.MetaDB.GetTriageIndex(db)
 	if err != nil {
 		return err
 	}
@@ -36,27 +42,32 @@ func printBLSourceIPs(db string, showNetNames bool, res *resources.Resources, lo
 		return err
 	}
 
-	w, err := getBLIPWriter(data, showNetNames)
+	w, err := getBLIPWriter(data, showNetNames, true, res.Config.S.GeoIP.Enabled, triage)
 	if err != nil {
 		return err
 	}
 
-	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt, GeoIP: res.Config.S.GeoIP.Enabled})
 }
 
-func getBLIPWriter(results []blacklist.IPResult, showNetNames bool) (string, error) {
+// getBLIPWriter renders the blacklisted IPs. source is true if the blacklisted IPs
+// initiated the connections.
+func getBLIPWriter(results []blacklist.IPResult, showNetNames, source, showGeo bool, triage database.TriageIndex) (string, error) {
 	var tmpl string
 	if showNetNames {
-		tmpl = "<tr><td>{{.Host.IP}}</td><td>{{.Host.NetworkName}}</td><td>{{.Connections}}</td><td>{{.UniqueConnections}}</td>" +
+		tmpl = "<tr><td title=\"{{.Host.Asset.Details}}\">{{.Host.LabeledIP}}</td><td>{{.Host.NetworkName}}</td><td>{{.Connections}}</td><td>{{.UniqueConnections}}</td>" +
 			"<td>{{.TotalBytes}}</td>" +
-			"<td>{{range $idx, $host := .ConnectedHostStrs}}{{if $idx}}, {{end}}{{ $host }}{{end}}</td>" +
-			"</tr>\n"
+			"<td>{{range $idx, $host := .ConnectedHostStrs}}{{if $idx}}, {{end}}{{ $host }}{{end}}</td>"
 	} else {
-		tmpl = "<tr><td>{{.Host.IP}}</td><td>{{.Connections}}</td><td>{{.UniqueConnections}}</td>" +
+		tmpl = "<tr><td title=\"{{.Host.Asset.Details}}\">{{.Host.LabeledIP}}</td><td>{{.Connections}}</td><td>{{.UniqueConnections}}</td>" +
 			"<td>{{.TotalBytes}}</td>" +
-			"<td>{{range $idx, $host := .ConnectedHostStrs}}{{if $idx}}, {{end}}{{ $host }}{{end}}</td>" +
-			"</tr>\n"
+			"<td>{{range $idx, $host := .ConnectedHostStrs}}{{if $idx}}, {{end}}{{ $host }}{{end}}</td>"
 	}
+	tmpl += "<td>{{range $idx, $src := .Sources}}{{if $idx}}<br>{{end}}{{ $src }}{{end}}</td>"
+	if showGeo {
+		tmpl += "<td>{{.Geo.Country}}</td><td>{{.Geo.AS}}</td>"
+	}
+	tmpl += triageCell + "</tr>\n"
 
 	out, err := template.New("blip").Parse(tmpl)
 	if err != nil {
@@ -66,6 +77,16 @@ func getBLIPWriter(results []blacklist.IPResult, showNetNames bool) (string, err
 	w := new(bytes.Buffer)
 
 	for _, result := range results {
+		// findings triaged as benign are left out of the report
+		var entry database.TriageEntry
+		if source {
+			entry = triage.Lookup(database.NewSrcTriageKey(result.Host))
+		} else {
+			entry = triage.Lookup(database.NewDstTriageKey(result.Host))
+		}
+		if entry.Status == database.TriageBenign {
+			continue
+		}
 
 		//format UniqueIP destinations
 		var connectedHostStrs []string
@@ -75,9 +96,9 @@ func getBLIPWriter(results []blacklist.IPResult, showNetNames bool) (string, err
 			if showNetNames {
 				escapedNetName := strings.ReplaceAll(connectedUniqIP.NetworkName, " ", "_")
 				escapedNetName = strings.ReplaceAll(escapedNetName, ":", "_")
-				connectedIPStr = escapedNetName + ":" + connectedUniqIP.IP
+				connectedIPStr = escapedNetName + ":" + connectedUniqIP.LabeledIP()
 			} else {
-				connectedIPStr = connectedUniqIP.IP
+				connectedIPStr = connectedUniqIP.LabeledIP()
 			}
 
 			connectedHostStrs = append(connectedHostStrs, connectedIPStr)
@@ -87,7 +108,8 @@ func getBLIPWriter(results []blacklist.IPResult, showNetNames bool) (string, err
 		formattedResult := struct {
 			blacklist.IPResult
 			ConnectedHostStrs []string
-		}{result, connectedHostStrs}
+			Triage            database.TriageEntry
+		}{result, connectedHostStrs, entry}
 
 		err := out.Execute(w, formattedResult)
 		if err != nil {
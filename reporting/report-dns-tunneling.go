@@ -0,0 +1,77 @@
+package reporting
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+
+	"github.com/activecm/rita-legacy/pkg/dnstunnel"
+	"github.com/activecm/rita-legacy/reporting/templates"
+	"github.com/activecm/rita-legacy/resources"
+)
+
+func printDNSTunneling(db string, showNetNames bool, res *resources.Resources, logsGeneratedAt string) error {
+	var w string
+	f, err := os.Create("dns-tunneling.html")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var dnsTunnelingTempl string
+	if showNetNames {
+		dnsTunnelingTempl = templates.DNSTunnelingNetNamesTempl
+	} else {
+		dnsTunnelingTempl = templates.DNSTunnelingTempl
+	}
+
+	out, err := template.New("dns-tunneling.html").Parse(dnsTunnelingTempl)
+	if err != nil {
+		return err
+	}
+
+	data, err := dnstunnel.Results(res, 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		w = ""
+	} else {
+		w, err = getDNSTunnelingWriter(data, showNetNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+}
+
+func getDNSTunnelingWriter(results []dnstunnel.Result, showNetNames bool) (string, error) {
+	tmpl := "<tr><td>{{printf \"%.3f\" .Score}}</td>"
+
+	if showNetNames {
+		tmpl += "<td>{{.SrcNetworkName}}</td>"
+	}
+
+	tmpl += "<td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td>{{.FQDN}}</td><td>{{.QueryCount}}</td>"
+	tmpl += "<td>{{printf \"%.3f\" .Entropy}}</td><td>{{printf \"%.1f\" .QueryLengthMean}}</td><td>{{.QueryLengthMax}}</td>"
+	tmpl += "<td>{{printf \"%.3f\" .UniqueSubdomainRatio}}</td><td>{{printf \"%.3f\" .TXTNullShare}}</td><td>{{printf \"%.3f\" .NXDomainRate}}</td>"
+	tmpl += "</tr>\n"
+
+	out, err := template.New("dns-tunneling").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	w := new(bytes.Buffer)
+
+	for _, result := range results {
+		err = out.Execute(w, result)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return w.String(), nil
+}
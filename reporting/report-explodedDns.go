@@ -21,7 +21,7 @@ func printDNS(db string, showNetNames bool, res *resources.Resources, logsGenera
 
 	limit := 1000
 
-	data, err := explodeddns.Results(res, limit, false)
+	data, err := explodeddns.Results(res, limit, false, false)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,76 @@
+package reporting
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+
+	"github.com/activecm/rita-legacy/pkg/threat"
+	"github.com/activecm/rita-legacy/reporting/templates"
+	"github.com/activecm/rita-legacy/resources"
+)
+
+func printHosts(db string, showNetNames bool, res *resources.Resources, logsGeneratedAt string) error {
+	var w string
+	f, err := os.Create("hosts.html")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var hostsTempl string
+	if showNetNames {
+		hostsTempl = templates.HostsNetNamesTempl
+	} else {
+		hostsTempl = templates.HostsTempl
+	}
+
+	out, err := template.New("hosts.html").Parse(hostsTempl)
+	if err != nil {
+		return err
+	}
+
+	data, err := threat.Results(res, 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		w = ""
+	} else {
+		w, err = getHostsWriter(data, showNetNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+}
+
+func getHostsWriter(results []threat.Result, showNetNames bool) (string, error) {
+	tmpl := "<tr><td>{{printf \"%.3f\" .Score}}</td>"
+
+	if showNetNames {
+		tmpl += "<td>{{.NetworkName}}</td>"
+	}
+
+	tmpl += "<td title=\"{{.Asset.Details}}\">{{.LabeledIP}}</td>"
+	tmpl += "<td>{{range $idx, $finding := .Findings}}{{if $idx}}<br>{{end}}{{$finding}}{{end}}</td>"
+	tmpl += "</tr>\n"
+
+	out, err := template.New("hosts").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	w := new(bytes.Buffer)
+
+	for _, result := range results {
+		err = out.Execute(w, result)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return w.String(), nil
+}
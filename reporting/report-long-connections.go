@@ -35,7 +35,7 @@ func printLongConns(db string, showNetNames bool, res *resources.Resources, logs
 	res.DB.SelectDB(db)
 
 	thresh := 60 // 1 minute
-	data, err := uconn.LongConnResults(res, thresh, 1000, false)
+	data, err := uconn.LongConnResults(res, thresh, 1000, false, false)
 	if err != nil {
 		return err
 	}
@@ -51,9 +51,9 @@ func printLongConns(db string, showNetNames bool, res *resources.Resources, logs
 func getLongConnWriter(conns []uconn.LongConnResult, showNetNames bool) (string, error) {
 	var tmpl string
 	if showNetNames {
-		tmpl = "<tr><td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td>{{.SrcIP}}</td><td>{{.DstIP}}</td><td>{{.TupleStr}}</td><td>{{.TotalDurationStr}}</td><td>{{.MaxDurationStr}}</td><td>{{.ConnectionCount}}</td><td>{{.TotalBytes}}</td><td>{{.State}}</td></tr>\n"
+		tmpl = "<tr><td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td><td>{{.TupleStr}}</td><td>{{.TotalDurationStr}}</td><td>{{.MaxDurationStr}}</td><td>{{.ConnectionCount}}</td><td>{{.TotalBytes}}</td><td>{{.State}}</td></tr>\n"
 	} else {
-		tmpl = "<tr><td>{{.SrcIP}}</td><td>{{.DstIP}}</td><td>{{.TupleStr}}</td><td>{{.TotalDurationStr}}</td><td>{{.MaxDurationStr}}</td><td>{{.ConnectionCount}}</td><td>{{.TotalBytes}}</td><td>{{.State}}</td></tr>\n"
+		tmpl = "<tr><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td><td>{{.TupleStr}}</td><td>{{.TotalDurationStr}}</td><td>{{.MaxDurationStr}}</td><td>{{.ConnectionCount}}</td><td>{{.TotalBytes}}</td><td>{{.State}}</td></tr>\n"
 	}
 
 	out, err := template.New("Conn").Parse(tmpl)
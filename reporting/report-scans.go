@@ -0,0 +1,102 @@
+package reporting
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/activecm/rita-legacy/pkg/scan"
+	"github.com/activecm/rita-legacy/reporting/templates"
+	"github.com/activecm/rita-legacy/resources"
+)
+
+func printScans(db string, showNetNames bool, res *resources.Resources, logsGeneratedAt string) error {
+	var w string
+	f, err := os.Create("scans.html")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var scansTempl string
+	if showNetNames {
+		scansTempl = templates.ScansNetNamesTempl
+	} else {
+		scansTempl = templates.ScansTempl
+	}
+
+	out, err := template.New("scans.html").Parse(scansTempl)
+	if err != nil {
+		return err
+	}
+
+	data, err := scan.Results(res, "", 1000, false, false)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		w = ""
+	} else {
+		w, err = getScansWriter(data, showNetNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	return out.Execute(f, &templates.ReportingInfo{DB: db, Writer: template.HTML(w), LogsGeneratedAt: logsGeneratedAt})
+}
+
+func getScansWriter(results []scan.Result, showNetNames bool) (string, error) {
+	tmpl := "<tr><td>{{printf \"%.3f\" .Score}}</td><td>{{.Type}}</td>"
+
+	if showNetNames {
+		tmpl += "<td>{{.SrcNetworkName}}</td>"
+	}
+
+	tmpl += "<td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td>"
+
+	if showNetNames {
+		tmpl += "<td>{{.DstNetworkName}}</td>"
+	}
+
+	tmpl += "<td>{{.Scanned}}</td><td>{{.Targets}}</td><td>{{.Connections}}</td><td>{{.FailedConnections}}</td>"
+	tmpl += "<td>{{.Windows}}</td><td>{{.Start}}</td><td>{{.End}}</td><td>{{.Sample}}</td>"
+	tmpl += "</tr>\n"
+
+	out, err := template.New("scans").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	w := new(bytes.Buffer)
+
+	for _, result := range results {
+		scanned := result.LabeledDstIP()
+		if result.Type == scan.HorizontalScan {
+			scanned = strconv.Itoa(result.Port) + ":" + result.Proto
+		}
+
+		err = out.Execute(w, struct {
+			scan.Result
+			Scanned string
+			Start   string
+			End     string
+			Sample  string
+		}{
+			Result:  result,
+			Scanned: scanned,
+			Start:   time.Unix(result.Start, 0).UTC().Format(time.RFC3339),
+			End:     time.Unix(result.End, 0).UTC().Format(time.RFC3339),
+			Sample:  strings.Join(result.SampleTargets, " "),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return w.String(), nil
+}
@@ -29,7 +29,7 @@ func printStrobes(db string, showNetNames bool, res *resources.Resources, logsGe
 		return err
 	}
 
-	data, err := beacon.StrobeResults(res, -1, 1000, false)
+	data, err := beacon.StrobeResults(res, -1, 1000, false, false)
 	if err != nil {
 		return err
 	}
@@ -45,9 +45,9 @@ func printStrobes(db string, showNetNames bool, res *resources.Resources, logsGe
 func getStrobesWriter(strobes []beacon.StrobeResult, showNetNames bool) (string, error) {
 	var tmpl string
 	if showNetNames {
-		tmpl = "<tr><td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td>{{.SrcIP}}</td><td>{{.DstIP}}</td><td>{{.ConnectionCount}}</td></tr>\n"
+		tmpl = "<tr><td>{{.SrcNetworkName}}</td><td>{{.DstNetworkName}}</td><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td><td>{{.ConnectionCount}}</td></tr>\n"
 	} else {
-		tmpl = "<tr><td>{{.SrcIP}}</td><td>{{.DstIP}}</td><td>{{.ConnectionCount}}</td></tr>\n"
+		tmpl = "<tr><td title=\"{{.SrcAsset.Details}}\">{{.LabeledSrcIP}}</td><td title=\"{{.DstAsset.Details}}\">{{.LabeledDstIP}}</td><td>{{.ConnectionCount}}</td></tr>\n"
 	}
 
 	out, err := template.New("Strobes").Parse(tmpl)
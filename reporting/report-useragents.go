@@ -21,7 +21,7 @@ func printUserAgents(db string, showNetNames bool, res *resources.Resources, log
 		return err
 	}
 
-	data, err := useragent.Results(res, 1, 1000, false)
+	data, err := useragent.Results(res, 1, 1000, false, false)
 	if err != nil {
 		return err
 	}
@@ -89,6 +89,9 @@ func PrintHTML(dbsIn []string, showNetNames bool, noBrowser bool, res *resources
 	return nil
 }
 
+// triageCell displays the triage status of a finding with the analyst's notes shown on hover
+const triageCell = "<td title=\"{{.Triage.Notes}}\">{{.Triage.Label}}</td>"
+
 func writeHomePage(Dbs []string) error {
 	f, err := os.Create("index.html")
 	if err != nil {
@@ -152,10 +155,19 @@ func writeDB(db string, wd string, showNetNames bool, res *resources.Resources)
 		fmt.Println("[-] Error writing Home page: " + err.Error())
 	}
 
+	err = printHosts(db, showNetNames, res, maxTime)
+	if err != nil {
+		fmt.Println("[-] Error writing hosts page: " + err.Error())
+	}
+
 	err = printDNS(db, showNetNames, res, maxTime)
 	if err != nil {
 		fmt.Println("[-] Error writing DNS page: " + err.Error())
 	}
+	err = printDNSTunneling(db, showNetNames, res, maxTime)
+	if err != nil {
+		fmt.Println("[-] Error writing DNS tunneling page: " + err.Error())
+	}
 	err = printBLSourceIPs(db, showNetNames, res, maxTime)
 	if err != nil {
 		fmt.Println("[-] Error writing blacklist-source page: " + err.Error())
@@ -189,6 +201,11 @@ func writeDB(db string, wd string, showNetNames bool, res *resources.Resources)
 		fmt.Println("[-] Error writing strobes page: " + err.Error())
 	}
 
+	err = printScans(db, showNetNames, res, maxTime)
+	if err != nil {
+		fmt.Println("[-] Error writing scans page: " + err.Error())
+	}
+
 	err = printLongConns(db, showNetNames, res, maxTime)
 	if err != nil {
 		fmt.Println("[-] Error writing long connections page: " + err.Error())
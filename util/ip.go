@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/globalsign/mgo/bson"
+	"golang.org/x/net/publicsuffix"
 )
 
 var privateIPBlocks []*net.IPNet
@@ -137,6 +138,18 @@ func ContainsDomain(domains []string, host string) bool {
 	return false
 }
 
+// RegisteredDomain returns the domain registered under a public suffix for the given
+// fully qualified domain name (e.g. example.co.uk for a.b.example.co.uk). If the
+// registered domain cannot be determined, the lowercased fqdn is returned.
+func RegisteredDomain(fqdn string) string {
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+	domain, err := publicsuffix.EffectiveTLDPlusOne(fqdn)
+	if err != nil {
+		return fqdn
+	}
+	return domain
+}
+
 // IsIP returns true if string is a valid IP address
 func IsIP(ip string) bool {
 	return net.ParseIP(ip) != nil
@@ -52,6 +52,23 @@ func TestIsIP(t *testing.T) {
 
 // Ensures ParseSubnets returns expected net.IPNets and returns
 // error when invalid IP address/CIDR network is provided.
+func TestRegisteredDomain(t *testing.T) {
+	tables := []struct {
+		fqdn string
+		out  string
+	}{
+		{"www.example.com", "example.com"},
+		{"a.b.example.co.uk", "example.co.uk"},
+		{"WWW.Example.COM.", "example.com"},
+		{"example.com", "example.com"},
+		{"com", "com"},
+	}
+
+	for _, test := range tables {
+		assert.Equal(t, test.out, RegisteredDomain(test.fqdn))
+	}
+}
+
 func TestParseSubnets(t *testing.T) {
 	validIPv4Nets := []string{"192.168.0.0/24", "10.0.0.0/16"}
 	validIPv4NetsOut := parseCIDRs([]string{"192.168.0.0/24", "10.0.0.0/16"})
@@ -0,0 +1,184 @@
+package util
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+)
+
+// OrderedField is a single named value within an OrderedObject
+type OrderedField struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedObject is a serializable representation of a struct which
+// preserves the order in which the fields were declared
+type OrderedObject []OrderedField
+
+// MarshalJSON writes out the object's fields in declaration order
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, field := range o {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+var binaryType = reflect.TypeOf(bson.Binary{})
+
+// ToSerializable converts a value into a tree of OrderedObjects, slices, and
+// scalars which may be marshaled as JSON. Field names are taken from the json
+// tag if one exists, otherwise the bson tag is used so the output matches the
+// names stored in MongoDB. Network UUIDs are rendered in their canonical form.
+func ToSerializable(val interface{}) interface{} {
+	return serializableValue(reflect.ValueOf(val))
+}
+
+func serializableValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return serializableValue(v.Elem())
+	case reflect.Struct:
+		if v.Type() == binaryType {
+			return formatBinary(v.Interface().(bson.Binary))
+		}
+		obj := OrderedObject{}
+		appendSerializableFields(&obj, v)
+		return obj
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for idx := 0; idx < v.Len(); idx++ {
+			items[idx] = serializableValue(v.Index(idx))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// appendSerializableFields adds each exported field of the struct to obj.
+// Embedded and inlined structs have their fields hoisted into obj.
+func appendSerializableFields(obj *OrderedObject, v reflect.Value) {
+	t := v.Type()
+	for idx := 0; idx < t.NumField(); idx++ {
+		name, inline, ok := serializableFieldName(t.Field(idx))
+		if !ok {
+			continue
+		}
+
+		fieldVal := v.Field(idx)
+		if inline && fieldVal.Kind() == reflect.Struct {
+			appendSerializableFields(obj, fieldVal)
+			continue
+		}
+
+		*obj = append(*obj, OrderedField{Key: name, Value: serializableValue(fieldVal)})
+	}
+}
+
+// serializableFieldName returns the name a struct field is serialized under and
+// whether its fields should be hoisted into the parent. ok is false if the field
+// is unexported or excluded by its tag.
+func serializableFieldName(field reflect.StructField) (name string, inline bool, ok bool) {
+	if field.PkgPath != "" {
+		return "", false, false // unexported
+	}
+
+	tag, hasJSON := field.Tag.Lookup("json")
+	if !hasJSON {
+		tag = field.Tag.Get("bson")
+	}
+	tagParts := strings.Split(tag, ",")
+	name = tagParts[0]
+	if name == "-" {
+		return "", false, false
+	}
+
+	inline = field.Anonymous && name == ""
+	for _, opt := range tagParts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+	return name, inline, true
+}
+
+// SerializableColumns lists the dotted names of the scalar and list values
+// ToSerializable produces for values of the given type, in declaration order.
+// Nested structs, including those behind pointers, are expanded into their
+// fields so the columns do not depend on whether a particular value is set.
+func SerializableColumns(t reflect.Type) []string {
+	var columns []string
+	appendSerializableColumns(&columns, "", t)
+	return columns
+}
+
+func appendSerializableColumns(columns *[]string, prefix string, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == binaryType {
+		*columns = append(*columns, prefix)
+		return
+	}
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		name, inline, ok := serializableFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		if inline && fieldType.Kind() == reflect.Struct {
+			appendSerializableColumns(columns, prefix, fieldType)
+			continue
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		appendSerializableColumns(columns, name, fieldType)
+	}
+}
+
+// formatBinary renders UUIDs in their canonical form and any other
+// binary data as hex
+func formatBinary(b bson.Binary) string {
+	if b.Kind == bson.BinaryUUID {
+		if id, err := uuid.FromBytes(b.Data); err == nil {
+			return id.String()
+		}
+	}
+	return hex.EncodeToString(b.Data)
+}
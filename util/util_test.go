@@ -88,6 +88,13 @@ func TestMinMax(t *testing.T) {
 	assert.Equal(t, small, Min(small, large))
 }
 
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, ShannonEntropy(""))
+	assert.Equal(t, 0.0, ShannonEntropy("aaaa"))
+	assert.Equal(t, 1.0, ShannonEntropy("abab"))
+	assert.Equal(t, 2.0, ShannonEntropy("abcd"))
+}
+
 func TestStringInSlice(t *testing.T) {
 	tables := []struct {
 		val  string